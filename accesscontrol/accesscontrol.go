@@ -0,0 +1,26 @@
+// Package accesscontrol implements typed operations for the ONVIF Access Control (ver10) service
+package accesscontrol
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Access Control service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Access Control service client using c to make requests to the Access Control service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceAccessControl, soap.Namespaces{"tac": onvif.NamespaceAccessControl, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Access Control service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
@@ -0,0 +1,38 @@
+package accesscontrol
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF access control ServiceCapabilities type
+type Capabilities struct {
+	MaxLimit                     int  `xml:"MaxLimit,attr"`
+	MaxAccessPoints              int  `xml:"MaxAccessPoints,attr"`
+	MaxAreas                     int  `xml:"MaxAreas,attr"`
+	ClientSuppliedTokenSupported bool `xml:"ClientSuppliedTokenSupported,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tac:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the access control service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
@@ -0,0 +1,166 @@
+package accesscontrol
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/korylprince/go-onvif/events"
+)
+
+// Access control event topics
+const (
+	TopicAccessGranted  = "AccessControl/AccessGranted"
+	TopicAccessTaken    = "AccessControl/AccessTaken"
+	TopicAccessNotTaken = "AccessControl/AccessNotTaken"
+)
+
+// Event items used by Occupancy
+const (
+	ItemAccessPointToken = "AccessPointToken"
+	ItemCredentialToken  = "CredentialToken"
+)
+
+// Violation is a credential that passed through an access point leading from an area other than the one it was last seen entering,
+// i.e. it wasn't used to leave that area (passback)
+type Violation struct {
+	CredentialToken  string
+	AccessPointToken string
+	// Area is the area the credential was last seen entering, and AreaFrom is the area the access point leads from
+	Area     string
+	AreaFrom string
+}
+
+// Occupancy tracks the number of people in each area from AccessGranted and AccessTaken events.
+// Access passes are counted on AccessTaken for access points that support it (AccessPointCapabilities.AccessTaken),
+// and on AccessGranted otherwise. Credentials are tracked across areas, so a credential that enters an area without leaving
+// the previous one is moved rather than counted twice, and is recorded as a Violation.
+// Counts start at zero and never go negative, so people already in an area when tracking starts are only counted once they pass an access point.
+// Occupancy is safe for concurrent use
+type Occupancy struct {
+	mu         sync.Mutex
+	points     map[string]*AccessPointInfo
+	counts     map[string]int
+	locations  map[string]string
+	violations []*Violation
+}
+
+// NewOccupancy returns a new Occupancy for the given access points, e.g. from Client.GetAllAccessPointInfo.
+// Events from other access points are ignored
+func NewOccupancy(points []*AccessPointInfo) *Occupancy {
+	o := &Occupancy{
+		points:    make(map[string]*AccessPointInfo, len(points)),
+		counts:    make(map[string]int),
+		locations: make(map[string]string),
+	}
+	for _, p := range points {
+		o.points[p.Token] = p
+	}
+	return o
+}
+
+// Run calls Handle for each notification from notifications until it's closed or ctx is done.
+// ctx's error is returned if ctx is done first
+func (o *Occupancy) Run(ctx context.Context, notifications <-chan *events.Notification) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n, ok := <-notifications:
+			if !ok {
+				return nil
+			}
+			o.Handle(n)
+		}
+	}
+}
+
+// Handle updates the occupancy from n. It returns true if n was counted as an access pass
+func (o *Occupancy) Handle(n *events.Notification) bool {
+	token, _ := n.Source.Get(ItemAccessPointToken)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	point, ok := o.points[token]
+	if !ok {
+		return false
+	}
+	topic := TopicAccessGranted
+	if point.Capabilities != nil && point.Capabilities.AccessTaken {
+		topic = TopicAccessTaken
+	}
+	if !n.Is(topic) {
+		return false
+	}
+
+	from := point.AreaFrom
+	credential, _ := n.Data.Get(ItemCredentialToken)
+	if credential != "" {
+		if area, ok := o.locations[credential]; ok && area != from {
+			o.violations = append(o.violations, &Violation{CredentialToken: credential, AccessPointToken: token, Area: area, AreaFrom: from})
+			from = area
+		}
+		if point.AreaTo != "" {
+			o.locations[credential] = point.AreaTo
+		} else {
+			delete(o.locations, credential)
+		}
+	}
+
+	if from != "" && o.counts[from] > 0 {
+		o.counts[from]--
+	}
+	if point.AreaTo != "" {
+		o.counts[point.AreaTo]++
+	}
+	return true
+}
+
+// Count returns the number of people in the area with the given token
+func (o *Occupancy) Count(area string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.counts[area]
+}
+
+// Counts returns the number of people in each area, by area token
+func (o *Occupancy) Counts() map[string]int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	counts := make(map[string]int, len(o.counts))
+	for area, n := range o.counts {
+		counts[area] = n
+	}
+	return counts
+}
+
+// Location returns the token of the area the credential with the given token last entered and true,
+// or false if it hasn't been seen or it last left to an area outside the system
+func (o *Occupancy) Location(credential string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	area, ok := o.locations[credential]
+	return area, ok
+}
+
+// Occupants returns the sorted tokens of the credentials last seen entering the area with the given token, e.g. for mustering.
+// Anonymous accesses are counted but have no credential
+func (o *Occupancy) Occupants(area string) []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var credentials []string
+	for credential, a := range o.locations {
+		if a == area {
+			credentials = append(credentials, credential)
+		}
+	}
+	sort.Strings(credentials)
+	return credentials
+}
+
+// Violations returns the passback violations seen, in order
+func (o *Occupancy) Violations() []*Violation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]*Violation(nil), o.violations...)
+}
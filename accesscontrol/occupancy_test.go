@@ -0,0 +1,83 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/korylprince/go-onvif/accesscontrol"
+	"github.com/korylprince/go-onvif/events"
+)
+
+func access(topic, point, credential string) *events.Notification {
+	n := &events.Notification{
+		Topic:  "tns1:" + topic,
+		Source: events.Items{accesscontrol.ItemAccessPointToken: point},
+		Data:   events.Items{},
+	}
+	if credential != "" {
+		n.Data[accesscontrol.ItemCredentialToken] = credential
+	}
+	return n
+}
+
+func TestOccupancy(t *testing.T) {
+	o := accesscontrol.NewOccupancy([]*accesscontrol.AccessPointInfo{
+		// the lobby door reports AccessTaken, the office doors don't
+		{Token: "LobbyIn", AreaTo: "Lobby", Capabilities: &accesscontrol.AccessPointCapabilities{AccessTaken: true}},
+		{Token: "LobbyOut", AreaFrom: "Lobby", Capabilities: &accesscontrol.AccessPointCapabilities{AccessTaken: true}},
+		{Token: "OfficeIn", AreaFrom: "Lobby", AreaTo: "Office"},
+		{Token: "OfficeOut", AreaFrom: "Office", AreaTo: "Lobby"},
+	})
+
+	notifications := make(chan *events.Notification, 10)
+	for _, n := range []*events.Notification{
+		access(accesscontrol.TopicAccessGranted+"/Credential", "LobbyIn", "alice"),
+		access(accesscontrol.TopicAccessTaken+"/Credential", "LobbyIn", "alice"),
+		access(accesscontrol.TopicAccessTaken+"/Credential", "LobbyIn", "bob"),
+		// granted but not taken
+		access(accesscontrol.TopicAccessGranted+"/Credential", "LobbyIn", "carol"),
+		access(accesscontrol.TopicAccessNotTaken+"/Credential", "LobbyIn", "carol"),
+		access(accesscontrol.TopicAccessTaken+"/Anonymous", "LobbyIn", ""),
+		access(accesscontrol.TopicAccessGranted+"/Credential", "OfficeIn", "alice"),
+		// unknown access points are ignored
+		access(accesscontrol.TopicAccessGranted+"/Credential", "Garage", "bob"),
+	} {
+		notifications <- n
+	}
+	close(notifications)
+	if err := o.Run(context.Background(), notifications); err != nil {
+		t.Fatalf("could not run: %v", err)
+	}
+
+	if counts := fmt.Sprint(o.Counts()); counts != "map[Lobby:2 Office:1]" {
+		t.Errorf("unexpected counts: %s", counts)
+	}
+	if occupants := fmt.Sprint(o.Occupants("Lobby")); occupants != "[bob]" {
+		t.Errorf("unexpected lobby occupants: %s", occupants)
+	}
+	if area, ok := o.Location("alice"); !ok || area != "Office" {
+		t.Errorf("unexpected location for alice: %s", area)
+	}
+
+	// alice leaves through the lobby without badging out of the office, so she's moved rather than counted twice
+	if !o.Handle(access(accesscontrol.TopicAccessTaken+"/Credential", "LobbyOut", "alice")) {
+		t.Fatal("expected access to be counted")
+	}
+	if o.Count("Office") != 0 || o.Count("Lobby") != 2 {
+		t.Errorf("unexpected counts after passback: %v", o.Counts())
+	}
+	if _, ok := o.Location("alice"); ok {
+		t.Error("expected alice to have left")
+	}
+	violations := o.Violations()
+	if len(violations) != 1 || *violations[0] != (accesscontrol.Violation{CredentialToken: "alice", AccessPointToken: "LobbyOut", Area: "Office", AreaFrom: "Lobby"}) {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+
+	// counts don't go negative for people who were in an area before tracking started
+	o.Handle(access(accesscontrol.TopicAccessGranted+"/Credential", "OfficeOut", "dave"))
+	if o.Count("Office") != 0 || o.Count("Lobby") != 3 {
+		t.Errorf("unexpected counts: %v", o.Counts())
+	}
+}
@@ -0,0 +1,158 @@
+package accesscontrol
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif"
+)
+
+// GetAccessPointInfoList is an ONVIF GetAccessPointInfoList operation
+type GetAccessPointInfoList struct {
+	XMLName xml.Name `xml:"tac:GetAccessPointInfoList"`
+	// Limit is the maximum number of access points to return. If zero, the device's limit is used
+	Limit int `xml:"tac:Limit,omitempty"`
+	// StartReference is the NextStartReference from a previous response. If empty, the first page is returned
+	StartReference string `xml:"tac:StartReference,omitempty"`
+}
+
+// GetAccessPointInfoListResponse is an ONVIF GetAccessPointInfoListResponse response
+type GetAccessPointInfoListResponse struct {
+	// NextStartReference is empty if there are no more access points
+	NextStartReference string
+	AccessPointInfo    []*AccessPointInfo
+}
+
+// GetAccessPointInfoList returns a page of access points. See GetAllAccessPointInfo to get all pages
+func (c *Client) GetAccessPointInfoList(req *GetAccessPointInfoList) (*GetAccessPointInfoListResponse, error) {
+	return c.GetAccessPointInfoListContext(context.Background(), req)
+}
+
+// GetAccessPointInfoListContext is like GetAccessPointInfoList, but ctx controls the request
+func (c *Client) GetAccessPointInfoListContext(ctx context.Context, req *GetAccessPointInfoList) (*GetAccessPointInfoListResponse, error) {
+	resp := new(GetAccessPointInfoListResponse)
+	if err := c.CallContext(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetAllAccessPointInfo returns all of the device's access points, calling GetAccessPointInfoList until there are no more pages. See onvif.Pages
+func (c *Client) GetAllAccessPointInfo() ([]*AccessPointInfo, error) {
+	return c.GetAllAccessPointInfoContext(context.Background())
+}
+
+// GetAllAccessPointInfoContext is like GetAllAccessPointInfo, but ctx controls the requests
+func (c *Client) GetAllAccessPointInfoContext(ctx context.Context) ([]*AccessPointInfo, error) {
+	return onvif.Pages(ctx, func(ctx context.Context, start string) ([]*AccessPointInfo, string, error) {
+		resp, err := c.GetAccessPointInfoListContext(ctx, &GetAccessPointInfoList{StartReference: start})
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.AccessPointInfo, resp.NextStartReference, nil
+	})
+}
+
+// GetAccessPointState is an ONVIF GetAccessPointState operation
+type GetAccessPointState struct {
+	XMLName xml.Name `xml:"tac:GetAccessPointState"`
+	Token   string   `xml:"tac:Token"`
+}
+
+// GetAccessPointStateResponse is an ONVIF GetAccessPointStateResponse response
+type GetAccessPointStateResponse struct {
+	AccessPointState *AccessPointState
+}
+
+// GetAccessPointState returns the state of the access point with the given token
+func (c *Client) GetAccessPointState(token string) (*AccessPointState, error) {
+	return c.GetAccessPointStateContext(context.Background(), token)
+}
+
+// GetAccessPointStateContext is like GetAccessPointState, but ctx controls the request
+func (c *Client) GetAccessPointStateContext(ctx context.Context, token string) (*AccessPointState, error) {
+	resp := new(GetAccessPointStateResponse)
+	if err := c.CallContext(ctx, &GetAccessPointState{Token: token}, resp); err != nil {
+		return nil, err
+	}
+	return resp.AccessPointState, nil
+}
+
+// EnableAccessPoint is an ONVIF EnableAccessPoint operation
+type EnableAccessPoint struct {
+	XMLName xml.Name `xml:"tac:EnableAccessPoint"`
+	Token   string   `xml:"tac:Token"`
+}
+
+// EnableAccessPoint enables the access point with the given token
+func (c *Client) EnableAccessPoint(token string) error {
+	return c.EnableAccessPointContext(context.Background(), token)
+}
+
+// EnableAccessPointContext is like EnableAccessPoint, but ctx controls the request
+func (c *Client) EnableAccessPointContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &EnableAccessPoint{Token: token}, nil)
+}
+
+// DisableAccessPoint is an ONVIF DisableAccessPoint operation
+type DisableAccessPoint struct {
+	XMLName xml.Name `xml:"tac:DisableAccessPoint"`
+	Token   string   `xml:"tac:Token"`
+}
+
+// DisableAccessPoint disables the access point with the given token, so access requests are denied.
+// The access point must support it. See AccessPointCapabilities.DisableAccessPoint
+func (c *Client) DisableAccessPoint(token string) error {
+	return c.DisableAccessPointContext(context.Background(), token)
+}
+
+// DisableAccessPointContext is like DisableAccessPoint, but ctx controls the request
+func (c *Client) DisableAccessPointContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &DisableAccessPoint{Token: token}, nil)
+}
+
+// GetAreaInfoList is an ONVIF GetAreaInfoList operation
+type GetAreaInfoList struct {
+	XMLName xml.Name `xml:"tac:GetAreaInfoList"`
+	// Limit is the maximum number of areas to return. If zero, the device's limit is used
+	Limit int `xml:"tac:Limit,omitempty"`
+	// StartReference is the NextStartReference from a previous response. If empty, the first page is returned
+	StartReference string `xml:"tac:StartReference,omitempty"`
+}
+
+// GetAreaInfoListResponse is an ONVIF GetAreaInfoListResponse response
+type GetAreaInfoListResponse struct {
+	// NextStartReference is empty if there are no more areas
+	NextStartReference string
+	AreaInfo           []*AreaInfo
+}
+
+// GetAreaInfoList returns a page of areas. See GetAllAreaInfo to get all pages
+func (c *Client) GetAreaInfoList(req *GetAreaInfoList) (*GetAreaInfoListResponse, error) {
+	return c.GetAreaInfoListContext(context.Background(), req)
+}
+
+// GetAreaInfoListContext is like GetAreaInfoList, but ctx controls the request
+func (c *Client) GetAreaInfoListContext(ctx context.Context, req *GetAreaInfoList) (*GetAreaInfoListResponse, error) {
+	resp := new(GetAreaInfoListResponse)
+	if err := c.CallContext(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetAllAreaInfo returns all of the device's areas, calling GetAreaInfoList until there are no more pages. See onvif.Pages
+func (c *Client) GetAllAreaInfo() ([]*AreaInfo, error) {
+	return c.GetAllAreaInfoContext(context.Background())
+}
+
+// GetAllAreaInfoContext is like GetAllAreaInfo, but ctx controls the requests
+func (c *Client) GetAllAreaInfoContext(ctx context.Context) ([]*AreaInfo, error) {
+	return onvif.Pages(ctx, func(ctx context.Context, start string) ([]*AreaInfo, string, error) {
+		resp, err := c.GetAreaInfoListContext(ctx, &GetAreaInfoList{StartReference: start})
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.AreaInfo, resp.NextStartReference, nil
+	})
+}
@@ -0,0 +1,37 @@
+package accesscontrol
+
+// AccessPointCapabilities is an ONVIF AccessPointCapabilities type
+type AccessPointCapabilities struct {
+	DisableAccessPoint    bool `xml:"DisableAccessPoint,attr"`
+	Duress                bool `xml:"Duress,attr"`
+	AnonymousAccess       bool `xml:"AnonymousAccess,attr"`
+	AccessTaken           bool `xml:"AccessTaken,attr"`
+	ExternalAuthorization bool `xml:"ExternalAuthorization,attr"`
+}
+
+// AccessPointInfo is an ONVIF AccessPointInfo type
+type AccessPointInfo struct {
+	Token       string `xml:"token,attr"`
+	Name        string
+	Description string
+	// AreaFrom and AreaTo are the tokens of the areas the access point leads from and to
+	AreaFrom string
+	AreaTo   string
+	// EntityType is the type of Entity, e.g. tdc:Door. If empty, a door is assumed
+	EntityType string
+	// Entity is the token of the entity controlled by the access point, e.g. a door token. See the doorcontrol package
+	Entity       string
+	Capabilities *AccessPointCapabilities
+}
+
+// AccessPointState is an ONVIF AccessPointState type
+type AccessPointState struct {
+	Enabled bool
+}
+
+// AreaInfo is an ONVIF AreaInfo type
+type AreaInfo struct {
+	Token       string `xml:"token,attr"`
+	Name        string
+	Description string
+}
@@ -0,0 +1,43 @@
+package onvif
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// bodyName returns the name of the root element of the marshaled body buf, with its namespace prefix resolved using namespaces
+func bodyName(buf []byte, namespaces soap.Namespaces) (xml.Name, error) {
+	d := xml.NewDecoder(bytes.NewReader(buf))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			name := start.Name
+			if ns, ok := namespaces[name.Space]; ok {
+				name.Space = ns
+			}
+			return name, nil
+		}
+	}
+}
+
+// action returns the SOAP action URI for the marshaled body buf, e.g. http://www.onvif.org/ver10/device/wsdl/GetServices.
+// ONVIF actions are the operation's namespace followed by the operation name
+func action(buf []byte, namespaces soap.Namespaces) (string, error) {
+	name, err := bodyName(buf, namespaces)
+	if err != nil {
+		return "", err
+	}
+
+	if name.Space == "" {
+		return "", errors.New("body element has no namespace")
+	}
+
+	return strings.TrimSuffix(name.Space, "/") + "/" + name.Local, nil
+}
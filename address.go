@@ -0,0 +1,69 @@
+package onvif
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// deviceServiceURL returns the device service URL for addr, which may be a host, a host:port pair, or a full URL.
+// If addr doesn't have a scheme, https is used if Client.UseTLS is true, otherwise http.
+// If addr is a URL without a path, the standard device service path is used
+func (c *Client) deviceServiceURL(addr string) string {
+	if strings.Contains(addr, "://") {
+		if u, err := url.Parse(addr); err == nil && (u.Path == "" || u.Path == "/") {
+			u.Path = "/onvif/device_service"
+			return u.String()
+		}
+		return addr
+	}
+
+	scheme := "http"
+	if c.UseTLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/onvif/device_service", scheme, addr)
+}
+
+// deviceHost returns the host:port pair (or just the host) for addr. See deviceServiceURL
+func (c *Client) deviceHost(addr string) string {
+	u, err := url.Parse(c.deviceServiceURL(addr))
+	if err != nil {
+		return addr
+	}
+	return u.Host
+}
+
+// setDefaultHTTPClient sets Client.HTTPClient to the default *http.Client if it's nil. authMu must be held
+func (c *Client) setDefaultHTTPClient() {
+	if c.HTTPClient != nil {
+		return
+	}
+	c.HTTPClient = c.newHTTPClient()
+	// without TLS options, the client uses http.DefaultTransport, which is shared
+	if c.HTTPClient.Transport != nil {
+		c.ownedClient = c.HTTPClient
+	}
+}
+
+// newHTTPClient returns the default *http.Client, configured with Client.TLSConfig and Client.InsecureSkipVerify
+func (c *Client) newHTTPClient() *http.Client {
+	if c.TLSConfig == nil && !c.InsecureSkipVerify {
+		return &http.Client{}
+	}
+
+	config := new(tls.Config)
+	if c.TLSConfig != nil {
+		config = c.TLSConfig.Clone()
+	}
+	if c.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = config
+
+	return &http.Client{Transport: t}
+}
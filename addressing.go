@@ -0,0 +1,36 @@
+package onvif
+
+import (
+	"fmt"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// addressing returns a copy of r.Addressing with empty fields filled in. body is the marshaled request body
+func (c *Client) addressing(r *Request, body []byte) (*soap.Addressing, error) {
+	a := *r.Addressing
+
+	if a.Action == "" {
+		a.Action = r.Action
+	}
+	if a.Action == "" {
+		var err error
+		if a.Action, err = action(body, r.Namespaces); err != nil {
+			return nil, fmt.Errorf("could not determine SOAP action: %w", err)
+		}
+	}
+
+	if a.To == "" {
+		a.To = r.URL
+	}
+
+	if a.MessageID == "" {
+		id, err := newCorrelationID(c.rand())
+		if err != nil {
+			return nil, fmt.Errorf("could not create message id: %w", err)
+		}
+		a.MessageID = "urn:uuid:" + id
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,26 @@
+// Package analytics implements helpers for the ONVIF analytics service and analytics metadata
+package analytics
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Analytics service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Analytics service client using c to make requests to the Analytics service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceAnalytics, soap.Namespaces{"tan": onvif.NamespaceAnalytics, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Analytics service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF analytics Capabilities type
+type Capabilities struct {
+	RuleSupport                        bool `xml:"RuleSupport,attr"`
+	AnalyticsModuleSupport             bool `xml:"AnalyticsModuleSupport,attr"`
+	CellBasedSceneDescriptionSupported bool `xml:"CellBasedSceneDescriptionSupported,attr"`
+	RuleOptionsSupported               bool `xml:"RuleOptionsSupported,attr"`
+	AnalyticsModuleOptionsSupported    bool `xml:"AnalyticsModuleOptionsSupported,attr"`
+	SupportedMetadata                  bool `xml:"SupportedMetadata,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tan:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the analytics service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
@@ -0,0 +1,83 @@
+package analytics
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// SimpleItem is an ONVIF ItemList SimpleItem, a named value
+type SimpleItem struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:"Value,attr"`
+}
+
+// ElementItem is an ONVIF ItemList ElementItem, a named XML element, e.g. a tt:Polygon
+type ElementItem struct {
+	Name string `xml:"Name,attr"`
+	// InnerXML is the raw content of the item. When marshaling, elements must use namespace prefixes declared in the envelope,
+	// since unprefixed elements are given the tt prefix
+	InnerXML []byte `xml:",innerxml"`
+}
+
+// ItemList is an ONVIF ItemList type
+type ItemList struct {
+	SimpleItem  []*SimpleItem  `xml:",omitempty"`
+	ElementItem []*ElementItem `xml:",omitempty"`
+}
+
+// Simple returns the value of the SimpleItem with the given name and true, or false if it's not found
+func (l *ItemList) Simple(name string) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	for _, item := range l.SimpleItem {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+// Config is an ONVIF Config type, used for analytics rules and modules
+type Config struct {
+	Name string `xml:"Name,attr"`
+	// Type is the QName of the rule or module type, e.g. tt:CellMotionDetector. Its prefix must be declared in the envelope
+	Type       string `xml:"Type,attr"`
+	Parameters *ItemList
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (c *Config) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type config Config
+	return soap.EncodeElementPrefixed(enc, (*config)(c), start, "tt")
+}
+
+// ItemDescription is an ONVIF SimpleItemDescription or ElementItemDescription type
+type ItemDescription struct {
+	Name string `xml:"Name,attr"`
+	// Type is the QName of the item type, e.g. xs:boolean or tt:Polygon
+	Type string `xml:"Type,attr"`
+}
+
+// ConfigDescription is an ONVIF ConfigDescription type, which describes the parameters of a rule or module type
+type ConfigDescription struct {
+	// Name is the QName of the rule or module type
+	Name                   string             `xml:"Name,attr"`
+	SimpleItemDescription  []*ItemDescription `xml:"Parameters>SimpleItemDescription"`
+	ElementItemDescription []*ItemDescription `xml:"Parameters>ElementItemDescription"`
+	// MaxInstances is the maximum number of instances of the type, or zero if unknown
+	MaxInstances int `xml:"maxInstances,attr"`
+}
+
+// SupportedRules is an ONVIF SupportedRules type
+type SupportedRules struct {
+	RuleContentSchemaLocation []string
+	RuleDescription           []*ConfigDescription
+}
+
+// SupportedAnalyticsModules is an ONVIF SupportedAnalyticsModules type
+type SupportedAnalyticsModules struct {
+	AnalyticsModuleContentSchemaLocation []string
+	AnalyticsModuleDescription           []*ConfigDescription
+}
@@ -0,0 +1,64 @@
+package analytics_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif/analytics"
+)
+
+func TestConfigMarshal(t *testing.T) {
+	req := &analytics.CreateRules{
+		ConfigurationToken: "VideoAnalyticsToken",
+		Rule: []*analytics.Config{{
+			Name: "Motion",
+			Type: "tt:CellMotionDetector",
+			Parameters: &analytics.ItemList{
+				SimpleItem:  []*analytics.SimpleItem{{Name: "MinCount", Value: "5"}},
+				ElementItem: []*analytics.ElementItem{{Name: "Field", InnerXML: []byte(`<tt:Polygon></tt:Polygon>`)}},
+			},
+		}},
+	}
+
+	buf, err := xml.Marshal(req)
+	if err != nil {
+		t.Fatalf("could not marshal request: %v", err)
+	}
+
+	for _, want := range []string{
+		`<tan:Rule Name="Motion" Type="tt:CellMotionDetector"><tt:Parameters>`,
+		`<tt:SimpleItem Name="MinCount" Value="5"></tt:SimpleItem>`,
+		`<tt:ElementItem Name="Field"><tt:Polygon></tt:Polygon></tt:ElementItem>`,
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Errorf("expected %s in %s", want, buf)
+		}
+	}
+}
+
+func TestConfigUnmarshal(t *testing.T) {
+	const body = `<GetRulesResponse xmlns:tt="http://www.onvif.org/ver10/schema">
+<tan:Rule xmlns:tan="http://www.onvif.org/ver20/analytics/wsdl" Name="Line" Type="tt:LineDetector">
+<tt:Parameters>
+<tt:SimpleItem Name="Direction" Value="Any"/>
+<tt:ElementItem Name="Segments"><tt:Polyline/></tt:ElementItem>
+</tt:Parameters>
+</tan:Rule>
+</GetRulesResponse>`
+
+	resp := new(analytics.GetRulesResponse)
+	if err := xml.Unmarshal([]byte(body), resp); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+
+	if len(resp.Rule) != 1 || resp.Rule[0].Name != "Line" || resp.Rule[0].Type != "tt:LineDetector" {
+		t.Fatalf("unexpected rules: %#v", resp.Rule)
+	}
+	if v, ok := resp.Rule[0].Parameters.Simple("Direction"); !ok || v != "Any" {
+		t.Errorf("expected Direction=Any, got %q, %v", v, ok)
+	}
+	if items := resp.Rule[0].Parameters.ElementItem; len(items) != 1 || string(items[0].InnerXML) != "<tt:Polyline/>" {
+		t.Errorf("unexpected element items: %#v", items)
+	}
+}
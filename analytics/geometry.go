@@ -0,0 +1,134 @@
+package analytics
+
+import (
+	"image"
+	"math"
+)
+
+// Vector is a tt:Vector. In the normalized coordinate system, X ranges from -1 (left) to 1 (right) and Y ranges from -1 (bottom) to 1 (top)
+type Vector struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+}
+
+// Rectangle is a tt:Rectangle, e.g. an object BoundingBox
+type Rectangle struct {
+	Bottom float64 `xml:"bottom,attr"`
+	Top    float64 `xml:"top,attr"`
+	Right  float64 `xml:"right,attr"`
+	Left   float64 `xml:"left,attr"`
+}
+
+// IntRectangle is a tt:IntRectangle, e.g. video source configuration Bounds
+type IntRectangle struct {
+	X      int `xml:"x,attr"`
+	Y      int `xml:"y,attr"`
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+}
+
+// Transformation is a tt:Transformation, which maps metadata frame coordinates to normalized coordinates
+type Transformation struct {
+	Translate *Vector
+	Scale     *Vector
+}
+
+// Apply returns v transformed to normalized coordinates. A nil Transformation returns v unchanged
+func (t *Transformation) Apply(v Vector) Vector {
+	if t == nil {
+		return v
+	}
+	if t.Scale != nil {
+		v.X *= t.Scale.X
+		v.Y *= t.Scale.Y
+	}
+	if t.Translate != nil {
+		v.X += t.Translate.X
+		v.Y += t.Translate.Y
+	}
+	return v
+}
+
+// Frame describes how normalized coordinates relate to the video source and the encoded stream
+type Frame struct {
+	// Bounds is the video source configuration Bounds, i.e. the region of the sensor that is encoded
+	Bounds IntRectangle
+	// Width and Height are the encoded resolution
+	Width  int
+	Height int
+	// Rotation is the clockwise rotation in degrees applied by the device before encoding, e.g. from the video source configuration Rotate extension.
+	// Only multiples of 90 are supported. Other values are treated as 0
+	Rotation int
+}
+
+// rotation returns f.Rotation normalized to 0, 90, 180, or 270
+func (f *Frame) rotation() int {
+	r := ((f.Rotation % 360) + 360) % 360
+	if r%90 != 0 {
+		return 0
+	}
+	return r
+}
+
+// ToPixel converts normalized coordinates to encoded stream pixel coordinates, with the origin at the top left
+func (f *Frame) ToPixel(v Vector) (x, y float64) {
+	return (v.X + 1) / 2 * float64(f.Width), (1 - v.Y) / 2 * float64(f.Height)
+}
+
+// FromPixel converts encoded stream pixel coordinates to normalized coordinates
+func (f *Frame) FromPixel(x, y float64) Vector {
+	return Vector{X: x/float64(f.Width)*2 - 1, Y: 1 - y/float64(f.Height)*2}
+}
+
+// ToSource converts normalized coordinates to video source pixel coordinates, accounting for Bounds cropping and Rotation
+func (f *Frame) ToSource(v Vector) (x, y float64) {
+	// fraction of the encoded image, with the origin at the top left
+	u, w := (v.X+1)/2, (1-v.Y)/2
+
+	// undo rotation
+	switch f.rotation() {
+	case 90:
+		u, w = w, 1-u
+	case 180:
+		u, w = 1-u, 1-w
+	case 270:
+		u, w = 1-w, u
+	}
+
+	return float64(f.Bounds.X) + u*float64(f.Bounds.Width), float64(f.Bounds.Y) + w*float64(f.Bounds.Height)
+}
+
+// FromSource converts video source pixel coordinates to normalized coordinates, accounting for Bounds cropping and Rotation
+func (f *Frame) FromSource(x, y float64) Vector {
+	// fraction of the bounds, with the origin at the top left
+	u, w := (x-float64(f.Bounds.X))/float64(f.Bounds.Width), (y-float64(f.Bounds.Y))/float64(f.Bounds.Height)
+
+	// apply rotation
+	switch f.rotation() {
+	case 90:
+		u, w = 1-w, u
+	case 180:
+		u, w = 1-u, 1-w
+	case 270:
+		u, w = w, 1-u
+	}
+
+	return Vector{X: u*2 - 1, Y: 1 - w*2}
+}
+
+// RectangleToPixel converts a normalized rectangle to an encoded stream pixel rectangle, rounded outward so the object is fully contained
+func (f *Frame) RectangleToPixel(r Rectangle) image.Rectangle {
+	x0, y0 := f.ToPixel(Vector{X: r.Left, Y: r.Top})
+	x1, y1 := f.ToPixel(Vector{X: r.Right, Y: r.Bottom})
+	return image.Rect(
+		int(math.Floor(math.Min(x0, x1))), int(math.Floor(math.Min(y0, y1))),
+		int(math.Ceil(math.Max(x0, x1))), int(math.Ceil(math.Max(y0, y1))),
+	)
+}
+
+// RectangleFromPixel converts an encoded stream pixel rectangle to a normalized rectangle
+func (f *Frame) RectangleFromPixel(r image.Rectangle) Rectangle {
+	tl := f.FromPixel(float64(r.Min.X), float64(r.Min.Y))
+	br := f.FromPixel(float64(r.Max.X), float64(r.Max.Y))
+	return Rectangle{Bottom: br.Y, Top: tl.Y, Right: br.X, Left: tl.X}
+}
@@ -0,0 +1,55 @@
+package analytics_test
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/korylprince/go-onvif/analytics"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFrameRotation(t *testing.T) {
+	bounds := analytics.IntRectangle{X: 100, Y: 50, Width: 1600, Height: 900}
+	cases := []struct {
+		rotation int
+		// source coordinates of the top left corner of the encoded image
+		x, y float64
+	}{
+		{0, 100, 50},
+		{90, 100, 950},
+		{180, 1700, 950},
+		{270, 1700, 50},
+		{-90, 1700, 50},
+	}
+
+	for _, c := range cases {
+		f := &analytics.Frame{Bounds: bounds, Width: 1280, Height: 720, Rotation: c.rotation}
+		x, y := f.ToSource(analytics.Vector{X: -1, Y: 1})
+		if !near(x, c.x) || !near(y, c.y) {
+			t.Errorf("rotation %d: expected top left at (%v, %v), got (%v, %v)", c.rotation, c.x, c.y, x, y)
+		}
+
+		v := analytics.Vector{X: 0.25, Y: -0.5}
+		x, y = f.ToSource(v)
+		if got := f.FromSource(x, y); !near(got.X, v.X) || !near(got.Y, v.Y) {
+			t.Errorf("rotation %d: expected %v after round trip, got %v", c.rotation, v, got)
+		}
+	}
+}
+
+func TestRectangleToPixel(t *testing.T) {
+	f := &analytics.Frame{Width: 640, Height: 480}
+	r := f.RectangleToPixel(analytics.Rectangle{Left: -1, Top: 1, Right: 0, Bottom: 0})
+	if expected := image.Rect(0, 0, 320, 240); r != expected {
+		t.Errorf("expected %v, got %v", expected, r)
+	}
+
+	tr := &analytics.Transformation{Translate: &analytics.Vector{X: -1, Y: -1}, Scale: &analytics.Vector{X: 0.5, Y: 0.5}}
+	if v := tr.Apply(analytics.Vector{X: 2, Y: 2}); !near(v.X, 0) || !near(v.Y, 0) {
+		t.Errorf("expected origin, got %v", v)
+	}
+}
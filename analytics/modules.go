@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetSupportedAnalyticsModules is an ONVIF GetSupportedAnalyticsModules operation
+type GetSupportedAnalyticsModules struct {
+	XMLName            xml.Name `xml:"tan:GetSupportedAnalyticsModules"`
+	ConfigurationToken string   `xml:"tan:ConfigurationToken"`
+}
+
+// GetSupportedAnalyticsModulesResponse is an ONVIF GetSupportedAnalyticsModulesResponse response
+type GetSupportedAnalyticsModulesResponse struct {
+	SupportedAnalyticsModules *SupportedAnalyticsModules
+}
+
+// GetSupportedAnalyticsModules returns the analytics module types supported by the video analytics configuration with the given token
+func (c *Client) GetSupportedAnalyticsModules(configurationToken string) (*SupportedAnalyticsModules, error) {
+	return c.GetSupportedAnalyticsModulesContext(context.Background(), configurationToken)
+}
+
+// GetSupportedAnalyticsModulesContext is like GetSupportedAnalyticsModules, but ctx controls the request
+func (c *Client) GetSupportedAnalyticsModulesContext(ctx context.Context, configurationToken string) (*SupportedAnalyticsModules, error) {
+	resp := new(GetSupportedAnalyticsModulesResponse)
+	if err := c.CallContext(ctx, &GetSupportedAnalyticsModules{ConfigurationToken: configurationToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.SupportedAnalyticsModules, nil
+}
+
+// GetAnalyticsModules is an ONVIF GetAnalyticsModules operation
+type GetAnalyticsModules struct {
+	XMLName            xml.Name `xml:"tan:GetAnalyticsModules"`
+	ConfigurationToken string   `xml:"tan:ConfigurationToken"`
+}
+
+// GetAnalyticsModulesResponse is an ONVIF GetAnalyticsModulesResponse response
+type GetAnalyticsModulesResponse struct {
+	AnalyticsModule []*Config
+}
+
+// GetAnalyticsModules returns the analytics modules of the video analytics configuration with the given token
+func (c *Client) GetAnalyticsModules(configurationToken string) ([]*Config, error) {
+	return c.GetAnalyticsModulesContext(context.Background(), configurationToken)
+}
+
+// GetAnalyticsModulesContext is like GetAnalyticsModules, but ctx controls the request
+func (c *Client) GetAnalyticsModulesContext(ctx context.Context, configurationToken string) ([]*Config, error) {
+	resp := new(GetAnalyticsModulesResponse)
+	if err := c.CallContext(ctx, &GetAnalyticsModules{ConfigurationToken: configurationToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.AnalyticsModule, nil
+}
+
+// CreateAnalyticsModules is an ONVIF CreateAnalyticsModules operation
+type CreateAnalyticsModules struct {
+	XMLName            xml.Name  `xml:"tan:CreateAnalyticsModules"`
+	ConfigurationToken string    `xml:"tan:ConfigurationToken"`
+	AnalyticsModule    []*Config `xml:"tan:AnalyticsModule"`
+}
+
+// CreateAnalyticsModules adds analytics modules to the video analytics configuration with the given token
+func (c *Client) CreateAnalyticsModules(configurationToken string, modules []*Config) error {
+	return c.CreateAnalyticsModulesContext(context.Background(), configurationToken, modules)
+}
+
+// CreateAnalyticsModulesContext is like CreateAnalyticsModules, but ctx controls the request
+func (c *Client) CreateAnalyticsModulesContext(ctx context.Context, configurationToken string, modules []*Config) error {
+	return c.CallContext(ctx, &CreateAnalyticsModules{ConfigurationToken: configurationToken, AnalyticsModule: modules}, nil)
+}
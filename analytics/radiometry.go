@@ -0,0 +1,142 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/korylprince/go-onvif/events"
+)
+
+// Radiometry event topics, sent by radiometric (thermal) cameras with temperature readings in a Reading ElementItem
+const (
+	TopicSpotTemperature = "VideoAnalytics/Radiometry/SpotTemperatureReading"
+	TopicBoxTemperature  = "VideoAnalytics/Radiometry/BoxTemperatureReading"
+)
+
+// ErrNoTemperatureReadings is returned by DecodeTemperatureEvent if the notification doesn't have any temperature readings
+var ErrNoTemperatureReadings = errors.New("no temperature readings")
+
+// absoluteZero is 0 K in degrees Celsius
+const absoluteZero = -273.15
+
+// Temperature is a temperature in kelvin, the unit ONVIF radiometry uses
+type Temperature float64
+
+// Celsius returns the Temperature for c degrees Celsius
+func Celsius(c float64) Temperature {
+	return Temperature(c - absoluteZero)
+}
+
+// Fahrenheit returns the Temperature for f degrees Fahrenheit
+func Fahrenheit(f float64) Temperature {
+	return Celsius((f - 32) * 5 / 9)
+}
+
+// Kelvin returns t in kelvin
+func (t Temperature) Kelvin() float64 {
+	return float64(t)
+}
+
+// Celsius returns t in degrees Celsius
+func (t Temperature) Celsius() float64 {
+	return float64(t) + absoluteZero
+}
+
+// Fahrenheit returns t in degrees Fahrenheit
+func (t Temperature) Fahrenheit() float64 {
+	return t.Celsius()*9/5 + 32
+}
+
+func (t Temperature) String() string {
+	return fmt.Sprintf("%.2fK", float64(t))
+}
+
+// SpotTemperatureReading is a tt:SpotTemperatureReading, the temperature of a spot measurement
+type SpotTemperatureReading struct {
+	// ItemID is the name of the spot measurement in the radiometry module configuration
+	ItemID      string      `xml:"ItemID,attr"`
+	Temperature Temperature `xml:"Temperature,attr"`
+}
+
+// BoxTemperatureReading is a tt:BoxTemperatureReading, the temperatures in a box measurement
+type BoxTemperatureReading struct {
+	// ItemID is the name of the box measurement in the radiometry module configuration
+	ItemID         string      `xml:"ItemID,attr"`
+	MaxTemperature Temperature `xml:"MaxTemperature,attr"`
+	MinTemperature Temperature `xml:"MinTemperature,attr"`
+	// AverageTemperature and MedianTemperature are nil if the device doesn't report them
+	AverageTemperature *Temperature `xml:"AverageTemperature,attr"`
+	MedianTemperature  *Temperature `xml:"MedianTemperature,attr"`
+}
+
+// TemperatureReadings are the spot and box temperature readings in a notification or metadata frame
+type TemperatureReadings struct {
+	Spots []*SpotTemperatureReading
+	Boxes []*BoxTemperatureReading
+}
+
+// DecodeTemperatureReadings decodes all tt:SpotTemperatureReading and tt:BoxTemperatureReading elements in r,
+// e.g. a metadata stream frame or an event ElementItem
+func DecodeTemperatureReadings(r io.Reader) (*TemperatureReadings, error) {
+	dec := xml.NewDecoder(r)
+	readings := new(TemperatureReadings)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return readings, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read token: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "SpotTemperatureReading":
+			spot := new(SpotTemperatureReading)
+			if err = dec.DecodeElement(spot, &start); err != nil {
+				return nil, fmt.Errorf("could not decode spot temperature reading: %w", err)
+			}
+			readings.Spots = append(readings.Spots, spot)
+		case "BoxTemperatureReading":
+			box := new(BoxTemperatureReading)
+			if err = dec.DecodeElement(box, &start); err != nil {
+				return nil, fmt.Errorf("could not decode box temperature reading: %w", err)
+			}
+			readings.Boxes = append(readings.Boxes, box)
+		}
+	}
+}
+
+// TemperatureEvent is a notification with temperature readings, e.g. a TopicSpotTemperature event or a temperature alarm that includes the reading
+type TemperatureEvent struct {
+	// Topic is the notification's TopicPath
+	Topic string
+	Time  time.Time
+	// Source identifies the measurement's source, e.g. its VideoSourceConfigurationToken and AnalyticsModuleName
+	Source events.Items
+	TemperatureReadings
+}
+
+// DecodeTemperatureEvent decodes the temperature readings in n's ElementItems.
+// It returns an error wrapping ErrNoTemperatureReadings if n doesn't have any
+func DecodeTemperatureEvent(n *events.Notification) (*TemperatureEvent, error) {
+	buf := new(bytes.Buffer)
+	for _, el := range n.Elements {
+		buf.Write(el.InnerXML)
+	}
+	readings, err := DecodeTemperatureReadings(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(readings.Spots) == 0 && len(readings.Boxes) == 0 {
+		return nil, fmt.Errorf("could not decode %s event: %w", n.TopicPath(), ErrNoTemperatureReadings)
+	}
+	return &TemperatureEvent{Topic: n.TopicPath(), Time: n.Time, Source: n.Source, TemperatureReadings: *readings}, nil
+}
@@ -0,0 +1,80 @@
+package analytics_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif/analytics"
+	"github.com/korylprince/go-onvif/events"
+)
+
+const temperatureNotify = `<wsnt:Notify xmlns:wsnt="http://docs.oasis-open.org/wsn/b-2" xmlns:tt="http://www.onvif.org/ver10/schema">
+<wsnt:NotificationMessage>
+<wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:VideoAnalytics/Radiometry/BoxTemperatureReading</wsnt:Topic>
+<wsnt:Message><tt:Message UtcTime="2020-01-01T00:00:00Z" PropertyOperation="Changed">
+<tt:Source><tt:SimpleItem Name="VideoSourceConfigurationToken" Value="VSC_1"/><tt:SimpleItem Name="AnalyticsModuleName" Value="Radiometry"/></tt:Source>
+<tt:Data><tt:ElementItem Name="Reading"><tt:BoxTemperatureReading ItemID="Box_1" MaxTemperature="310.15" MinTemperature="293.15" AverageTemperature="300"/></tt:ElementItem></tt:Data>
+</tt:Message></wsnt:Message>
+</wsnt:NotificationMessage>
+<wsnt:NotificationMessage>
+<wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:VideoSource/MotionAlarm</wsnt:Topic>
+<wsnt:Message><tt:Message UtcTime="2020-01-01T00:00:00Z"><tt:Data><tt:SimpleItem Name="State" Value="true"/></tt:Data></tt:Message></wsnt:Message>
+</wsnt:NotificationMessage>
+</wsnt:Notify>`
+
+func TestDecodeTemperatureEvent(t *testing.T) {
+	notifications, err := events.Decode(strings.NewReader(temperatureNotify))
+	if err != nil || len(notifications) != 2 {
+		t.Fatalf("could not decode notifications: %v", err)
+	}
+
+	e, err := analytics.DecodeTemperatureEvent(notifications[0])
+	if err != nil {
+		t.Fatalf("could not decode temperature event: %v", err)
+	}
+	if e.Topic != analytics.TopicBoxTemperature || e.Source["VideoSourceConfigurationToken"] != "VSC_1" || e.Time.IsZero() {
+		t.Errorf("unexpected event: %#v", e)
+	}
+	if len(e.Spots) != 0 || len(e.Boxes) != 1 {
+		t.Fatalf("unexpected readings: %#v", e.TemperatureReadings)
+	}
+	box := e.Boxes[0]
+	if box.ItemID != "Box_1" || !near(box.MaxTemperature.Celsius(), 37) || !near(box.MinTemperature.Celsius(), 20) {
+		t.Errorf("unexpected box reading: %#v", box)
+	}
+	if box.AverageTemperature == nil || *box.AverageTemperature != 300 || box.MedianTemperature != nil {
+		t.Errorf("unexpected optional temperatures: %v, %v", box.AverageTemperature, box.MedianTemperature)
+	}
+
+	if _, err = analytics.DecodeTemperatureEvent(notifications[1]); !errors.Is(err, analytics.ErrNoTemperatureReadings) {
+		t.Errorf("expected no readings error, got %v", err)
+	}
+
+	// metadata frames hold readings without the notification wrapper
+	readings, err := analytics.DecodeTemperatureReadings(strings.NewReader(`<tt:Frame UtcTime="2020-01-01T00:00:00Z">
+<tt:SpotTemperatureReading ItemID="Spot_1" Temperature="373.15"/></tt:Frame>`))
+	if err != nil || len(readings.Spots) != 1 {
+		t.Fatalf("could not decode readings: %v", err)
+	}
+	if spot := readings.Spots[0]; spot.ItemID != "Spot_1" || !near(spot.Temperature.Fahrenheit(), 212) {
+		t.Errorf("unexpected spot reading: %#v", spot)
+	}
+}
+
+func TestTemperatureUnits(t *testing.T) {
+	for _, test := range []struct {
+		temp       analytics.Temperature
+		kelvin     float64
+		celsius    float64
+		fahrenheit float64
+	}{
+		{analytics.Celsius(0), 273.15, 0, 32},
+		{analytics.Fahrenheit(-40), 233.15, -40, -40},
+		{analytics.Temperature(0), 0, -273.15, -459.67},
+	} {
+		if !near(test.temp.Kelvin(), test.kelvin) || !near(test.temp.Celsius(), test.celsius) || !near(test.temp.Fahrenheit(), test.fahrenheit) {
+			t.Errorf("unexpected conversions for %v: %v K, %v C, %v F", test.temp, test.temp.Kelvin(), test.temp.Celsius(), test.temp.Fahrenheit())
+		}
+	}
+}
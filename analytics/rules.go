@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetSupportedRules is an ONVIF GetSupportedRules operation
+type GetSupportedRules struct {
+	XMLName            xml.Name `xml:"tan:GetSupportedRules"`
+	ConfigurationToken string   `xml:"tan:ConfigurationToken"`
+}
+
+// GetSupportedRulesResponse is an ONVIF GetSupportedRulesResponse response
+type GetSupportedRulesResponse struct {
+	SupportedRules *SupportedRules
+}
+
+// GetSupportedRules returns the rule types supported by the video analytics configuration with the given token
+func (c *Client) GetSupportedRules(configurationToken string) (*SupportedRules, error) {
+	return c.GetSupportedRulesContext(context.Background(), configurationToken)
+}
+
+// GetSupportedRulesContext is like GetSupportedRules, but ctx controls the request
+func (c *Client) GetSupportedRulesContext(ctx context.Context, configurationToken string) (*SupportedRules, error) {
+	resp := new(GetSupportedRulesResponse)
+	if err := c.CallContext(ctx, &GetSupportedRules{ConfigurationToken: configurationToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.SupportedRules, nil
+}
+
+// GetRules is an ONVIF GetRules operation
+type GetRules struct {
+	XMLName            xml.Name `xml:"tan:GetRules"`
+	ConfigurationToken string   `xml:"tan:ConfigurationToken"`
+}
+
+// GetRulesResponse is an ONVIF GetRulesResponse response
+type GetRulesResponse struct {
+	Rule []*Config
+}
+
+// GetRules returns the rules of the video analytics configuration with the given token
+func (c *Client) GetRules(configurationToken string) ([]*Config, error) {
+	return c.GetRulesContext(context.Background(), configurationToken)
+}
+
+// GetRulesContext is like GetRules, but ctx controls the request
+func (c *Client) GetRulesContext(ctx context.Context, configurationToken string) ([]*Config, error) {
+	resp := new(GetRulesResponse)
+	if err := c.CallContext(ctx, &GetRules{ConfigurationToken: configurationToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Rule, nil
+}
+
+// CreateRules is an ONVIF CreateRules operation
+type CreateRules struct {
+	XMLName            xml.Name  `xml:"tan:CreateRules"`
+	ConfigurationToken string    `xml:"tan:ConfigurationToken"`
+	Rule               []*Config `xml:"tan:Rule"`
+}
+
+// CreateRules adds rules to the video analytics configuration with the given token
+func (c *Client) CreateRules(configurationToken string, rules []*Config) error {
+	return c.CreateRulesContext(context.Background(), configurationToken, rules)
+}
+
+// CreateRulesContext is like CreateRules, but ctx controls the request
+func (c *Client) CreateRulesContext(ctx context.Context, configurationToken string, rules []*Config) error {
+	return c.CallContext(ctx, &CreateRules{ConfigurationToken: configurationToken, Rule: rules}, nil)
+}
+
+// ModifyRules is an ONVIF ModifyRules operation
+type ModifyRules struct {
+	XMLName            xml.Name  `xml:"tan:ModifyRules"`
+	ConfigurationToken string    `xml:"tan:ConfigurationToken"`
+	Rule               []*Config `xml:"tan:Rule"`
+}
+
+// ModifyRules replaces the rules with matching names in the video analytics configuration with the given token
+func (c *Client) ModifyRules(configurationToken string, rules []*Config) error {
+	return c.ModifyRulesContext(context.Background(), configurationToken, rules)
+}
+
+// ModifyRulesContext is like ModifyRules, but ctx controls the request
+func (c *Client) ModifyRulesContext(ctx context.Context, configurationToken string, rules []*Config) error {
+	return c.CallContext(ctx, &ModifyRules{ConfigurationToken: configurationToken, Rule: rules}, nil)
+}
+
+// DeleteRules is an ONVIF DeleteRules operation
+type DeleteRules struct {
+	XMLName            xml.Name `xml:"tan:DeleteRules"`
+	ConfigurationToken string   `xml:"tan:ConfigurationToken"`
+	RuleName           []string `xml:"tan:RuleName"`
+}
+
+// DeleteRules removes the rules with the given names from the video analytics configuration with the given token
+func (c *Client) DeleteRules(configurationToken string, names ...string) error {
+	return c.DeleteRulesContext(context.Background(), configurationToken, names...)
+}
+
+// DeleteRulesContext is like DeleteRules, but ctx controls the request
+func (c *Client) DeleteRulesContext(ctx context.Context, configurationToken string, names ...string) error {
+	return c.CallContext(ctx, &DeleteRules{ConfigurationToken: configurationToken, RuleName: names}, nil)
+}
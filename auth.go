@@ -0,0 +1,114 @@
+package onvif
+
+import (
+	"net/http"
+
+	"github.com/icholy/digest"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// CurrentAuthMode returns Client.AuthMode. Use it instead of reading the field while requests are in flight, since authentication mode detection updates it
+func (c *Client) CurrentAuthMode() AuthMode {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return c.AuthMode
+}
+
+// CurrentSOAPVersion returns the Client's SOAP version, which may have been changed by SOAP 1.1 detection. See Client.SOAPVersion
+func (c *Client) CurrentSOAPVersion() soap.Version {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.SOAPVersion
+}
+
+// soapVersion returns the SOAP version to use for r
+func (c *Client) soapVersion(r *Request) soap.Version {
+	if r.SOAPVersion == soap.Version11 {
+		return soap.Version11
+	}
+	return c.CurrentSOAPVersion()
+}
+
+// CurrentAuthModeFor returns the authentication mode detected for requests overriding the credentials with username,
+// or AuthModeNone if none has been detected. See Request.AuthMode
+func (c *Client) CurrentAuthModeFor(username string) AuthMode {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return c.requestAuthModes[username]
+}
+
+// setSOAPVersion sets the detected SOAP version for the Client. It's a property of the device, so it's set even if the request overrides the credentials
+func (c *Client) setSOAPVersion(version soap.Version) {
+	c.versionMu.Lock()
+	c.SOAPVersion = version
+	c.versionMu.Unlock()
+}
+
+// requestAuthMode returns the authentication mode for r, which overrides the credentials:
+// the mode detected for r's username, or r.AuthMode if none has been detected
+func (c *Client) requestAuthMode(r *Request) AuthMode {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if mode, ok := c.requestAuthModes[r.Username]; ok {
+		return mode
+	}
+	return r.AuthMode
+}
+
+// setAuthMode sets the detected authentication mode for the Client, or for r's username if r overrides the credentials. r is never modified
+func (c *Client) setAuthMode(r *Request, mode AuthMode) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if r.Username != "" && r.Password != "" {
+		if c.requestAuthModes == nil {
+			c.requestAuthModes = make(map[string]AuthMode)
+		}
+		c.requestAuthModes[r.Username] = mode
+		return
+	}
+
+	c.AuthMode = mode
+}
+
+// httpClient returns the *http.Client to use for a request with the given authentication mode.
+// Client.HTTPClient is never modified, except to set a default if it's nil.
+// For AuthModeDigest, a copy using a digest transport wrapping Client.HTTPClient's transport is returned
+func (c *Client) httpClient(mode AuthMode) *http.Client {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	c.setDefaultHTTPClient()
+
+	if mode != AuthModeDigest {
+		return c.HTTPClient
+	}
+
+	// user configured digest transport
+	if _, ok := c.HTTPClient.Transport.(*digest.Transport); ok {
+		return c.HTTPClient
+	}
+
+	// recreate the digest transport if HTTPClient was replaced
+	if c.digest == nil || c.digestClient != c.HTTPClient {
+		c.digest = &digest.Transport{Transport: c.HTTPClient.Transport, Digest: digestCredentials}
+		c.digestClient = c.HTTPClient
+	}
+
+	client := *c.HTTPClient
+	client.Transport = c.digest
+	return &client
+}
+
+// replayDigest saves the digest challenge from a 401 response to req, so the next digest request doesn't need an extra round trip
+func (c *Client) replayDigest(req *http.Request, resp *http.Response) {
+	d := &digest.Transport{Transport: &fakeTransport{resp: resp}, Digest: digestCredentials}
+	d.RoundTrip(req)
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	c.setDefaultHTTPClient()
+	d.Transport = c.HTTPClient.Transport
+	c.digest = d
+	c.digestClient = c.HTTPClient
+}
@@ -0,0 +1,139 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBroadcastParallelism is the number of devices Broadcast operates on concurrently if BroadcastOptions.Parallelism isn't set
+const DefaultBroadcastParallelism = 16
+
+// BroadcastOptions configures Broadcast
+type BroadcastOptions struct {
+	// Parallelism is the maximum number of devices operated on concurrently. If less than 1, DefaultBroadcastParallelism is used
+	Parallelism int
+	// Timeout, if greater than zero, limits the operation on each device
+	Timeout time.Duration
+}
+
+// BroadcastResult is the result of an operation on a single device
+type BroadcastResult[D, T any] struct {
+	Device D
+	Value  T
+	Err    error
+	// Duration is the time the operation took, not including time spent waiting to start
+	Duration time.Duration
+}
+
+// BroadcastReport is the aggregated result of Broadcast
+type BroadcastReport[D, T any] struct {
+	// Results is the result for each device, in the order the devices were given
+	Results []*BroadcastResult[D, T]
+}
+
+// Succeeded returns the results of the devices the operation succeeded on
+func (r *BroadcastReport[D, T]) Succeeded() []*BroadcastResult[D, T] {
+	var results []*BroadcastResult[D, T]
+	for _, res := range r.Results {
+		if res.Err == nil {
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// Failed returns the results of the devices the operation failed on
+func (r *BroadcastReport[D, T]) Failed() []*BroadcastResult[D, T] {
+	var results []*BroadcastResult[D, T]
+	for _, res := range r.Results {
+		if res.Err != nil {
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// Err returns a *BroadcastError if the operation failed on any device, or nil otherwise
+func (r *BroadcastReport[D, T]) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(failed))
+	for _, res := range failed {
+		errs = append(errs, res.Err)
+	}
+	return &BroadcastError{Total: len(r.Results), Errs: errs}
+}
+
+// BroadcastError indicates an operation failed on some devices. See BroadcastReport.Failed for the failed devices
+type BroadcastError struct {
+	// Total is the number of devices the operation was run on
+	Total int
+	// Errs is the error for each failed device
+	Errs []error
+}
+
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("operation failed on %d of %d devices: %s", len(e.Errs), e.Total, e.Errs[0].Error())
+}
+
+// Unwrap allows BroadcastError to be used with errors.Is and errors.As, which match any of the device errors
+func (e *BroadcastError) Unwrap() []error {
+	return e.Errs
+}
+
+// Broadcast runs op concurrently for each of devices, e.g. *Device, *Client, or device addresses, and returns the result for each device.
+// opts may be nil. The ctx passed to op is limited by BroadcastOptions.Timeout.
+// If ctx is done, devices that haven't started fail with ctx.Err()
+func Broadcast[D, T any](ctx context.Context, devices []D, opts *BroadcastOptions, op func(ctx context.Context, device D) (T, error)) *BroadcastReport[D, T] {
+	parallelism := DefaultBroadcastParallelism
+	var timeout time.Duration
+	if opts != nil {
+		if opts.Parallelism > 0 {
+			parallelism = opts.Parallelism
+		}
+		timeout = opts.Timeout
+	}
+
+	report := &BroadcastReport[D, T]{Results: make([]*BroadcastResult[D, T], len(devices))}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, device := range devices {
+		res := &BroadcastResult[D, T]{Device: device}
+		report.Results[i] = res
+
+		select {
+		case <-ctx.Done():
+			res.Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			opCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				opCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			start := SystemClock.Now()
+			res.Value, res.Err = op(opCtx, res.Device)
+			res.Duration = SystemClock.Now().Sub(start)
+		}()
+	}
+
+	wg.Wait()
+	return report
+}
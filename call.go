@@ -0,0 +1,27 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+)
+
+// Call executes r and unmarshals the response body into a new Resp, combining Client.Do and soap.Body.Unmarshal.
+// Errors are returned as from Client.Do
+func Call[Resp any](c *Client, r *Request) (*Resp, error) {
+	return CallContext[Resp](context.Background(), c, r)
+}
+
+// CallContext is like Call, but ctx controls the request. See Client.DoContext
+func CallContext[Resp any](ctx context.Context, c *Client, r *Request) (*Resp, error) {
+	env, err := c.DoContext(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("could not complete operation: %w", err)
+	}
+
+	resp := new(Resp)
+	if err := env.Body.Unmarshal(resp); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,273 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Version is an ONVIF OnvifVersion
+type Version struct {
+	Major int
+	Minor int
+}
+
+// Capabilities is an ONVIF Capabilities type, as returned by GetCapabilities.
+// Capabilities for services the device doesn't support will be nil
+type Capabilities struct {
+	Analytics *AnalyticsCapabilities
+	Device    *DeviceCapabilities
+	Events    *EventCapabilities
+	Imaging   *ImagingCapabilities
+	Media     *MediaCapabilities
+	PTZ       *PTZCapabilities
+	Extension *CapabilitiesExtension
+}
+
+// AnalyticsCapabilities is an ONVIF AnalyticsCapabilities type
+type AnalyticsCapabilities struct {
+	URL                    string `xml:"XAddr"`
+	RuleSupport            bool
+	AnalyticsModuleSupport bool
+}
+
+// DeviceCapabilities is an ONVIF DeviceCapabilities type
+type DeviceCapabilities struct {
+	URL      string `xml:"XAddr"`
+	Network  *NetworkCapabilities
+	System   *SystemCapabilities
+	IO       *IOCapabilities
+	Security *SecurityCapabilities
+}
+
+// NetworkCapabilities is an ONVIF NetworkCapabilities type
+type NetworkCapabilities struct {
+	IPFilter           bool
+	ZeroConfiguration  bool
+	IPVersion6         bool
+	DynDNS             bool
+	Dot11Configuration bool `xml:"Extension>Dot11Configuration"`
+}
+
+// SystemCapabilities is an ONVIF SystemCapabilities type
+type SystemCapabilities struct {
+	DiscoveryResolve       bool
+	DiscoveryBye           bool
+	RemoteDiscovery        bool
+	SystemBackup           bool
+	SystemLogging          bool
+	FirmwareUpgrade        bool
+	SupportedVersions      []*Version
+	HTTPFirmwareUpgrade    bool `xml:"Extension>HttpFirmwareUpgrade"`
+	HTTPSystemBackup       bool `xml:"Extension>HttpSystemBackup"`
+	HTTPSystemLogging      bool `xml:"Extension>HttpSystemLogging"`
+	HTTPSupportInformation bool `xml:"Extension>HttpSupportInformation"`
+}
+
+// IOCapabilities is an ONVIF IOCapabilities type
+type IOCapabilities struct {
+	InputConnectors   int
+	RelayOutputs      int
+	Auxiliary         bool     `xml:"Extension>Auxiliary"`
+	AuxiliaryCommands []string `xml:"Extension>AuxiliaryCommands"`
+}
+
+// SecurityCapabilities is an ONVIF SecurityCapabilities type
+type SecurityCapabilities struct {
+	TLS10                bool `xml:"Extension>TLS1.0"`
+	TLS11                bool `xml:"TLS1.1"`
+	TLS12                bool `xml:"TLS1.2"`
+	OnboardKeyGeneration bool
+	AccessPolicyConfig   bool
+	X509Token            bool `xml:"X.509Token"`
+	SAMLToken            bool
+	KerberosToken        bool
+	RELToken             bool
+}
+
+// EventCapabilities is an ONVIF EventCapabilities type
+type EventCapabilities struct {
+	URL                                           string `xml:"XAddr"`
+	WSSubscriptionPolicySupport                   bool
+	WSPullPointSupport                            bool
+	WSPausableSubscriptionManagerInterfaceSupport bool
+}
+
+// ImagingCapabilities is an ONVIF ImagingCapabilities type
+type ImagingCapabilities struct {
+	URL string `xml:"XAddr"`
+}
+
+// MediaCapabilities is an ONVIF MediaCapabilities type
+type MediaCapabilities struct {
+	URL                     string `xml:"XAddr"`
+	StreamingCapabilities   *RealTimeStreamingCapabilities
+	MaximumNumberOfProfiles int `xml:"Extension>ProfileCapabilities>MaximumNumberOfProfiles"`
+}
+
+// RealTimeStreamingCapabilities is an ONVIF RealTimeStreamingCapabilities type
+type RealTimeStreamingCapabilities struct {
+	RTPMulticast bool
+	RTPTCP       bool `xml:"RTP_TCP"`
+	RTPRTSPTCP   bool `xml:"RTP_RTSP_TCP"`
+}
+
+// PTZCapabilities is an ONVIF PTZCapabilities type
+type PTZCapabilities struct {
+	URL string `xml:"XAddr"`
+}
+
+// CapabilitiesExtension is an ONVIF CapabilitiesExtension type
+type CapabilitiesExtension struct {
+	DeviceIO        *DeviceIOCapabilities
+	Display         *DisplayCapabilities
+	Recording       *RecordingCapabilities
+	Search          *SearchCapabilities
+	Replay          *ReplayCapabilities
+	Receiver        *ReceiverCapabilities
+	AnalyticsDevice *AnalyticsDeviceCapabilities
+}
+
+// DeviceIOCapabilities is an ONVIF DeviceIOCapabilities type
+type DeviceIOCapabilities struct {
+	URL          string `xml:"XAddr"`
+	VideoSources int
+	VideoOutputs int
+	AudioSources int
+	AudioOutputs int
+	RelayOutputs int
+}
+
+// DisplayCapabilities is an ONVIF DisplayCapabilities type
+type DisplayCapabilities struct {
+	URL         string `xml:"XAddr"`
+	FixedLayout bool
+}
+
+// RecordingCapabilities is an ONVIF RecordingCapabilities type
+type RecordingCapabilities struct {
+	URL                string `xml:"XAddr"`
+	ReceiverSource     bool
+	MediaProfileSource bool
+	DynamicRecordings  bool
+	DynamicTracks      bool
+	MaxStringLength    int
+}
+
+// SearchCapabilities is an ONVIF SearchCapabilities type
+type SearchCapabilities struct {
+	URL            string `xml:"XAddr"`
+	MetadataSearch bool
+}
+
+// ReplayCapabilities is an ONVIF ReplayCapabilities type
+type ReplayCapabilities struct {
+	URL string `xml:"XAddr"`
+}
+
+// ReceiverCapabilities is an ONVIF ReceiverCapabilities type
+type ReceiverCapabilities struct {
+	URL                  string `xml:"XAddr"`
+	RTPMulticast         bool   `xml:"RTP_Multicast"`
+	RTPTCP               bool   `xml:"RTP_TCP"`
+	RTPRTSPTCP           bool   `xml:"RTP_RTSP_TCP"`
+	SupportedReceivers   int
+	MaximumRTSPURILength int
+}
+
+// AnalyticsDeviceCapabilities is an ONVIF AnalyticsDeviceCapabilities type
+type AnalyticsDeviceCapabilities struct {
+	URL         string `xml:"XAddr"`
+	RuleSupport bool
+}
+
+// Services returns the services with URLs in the capabilities
+func (c *Capabilities) Services() Services {
+	var services Services
+	add := func(namespace, url string) {
+		if url != "" {
+			services = append(services, &Service{Namespace: namespace, URL: url})
+		}
+	}
+
+	if c.Device != nil {
+		add(NamespaceDevice, c.Device.URL)
+	}
+	if c.Events != nil {
+		add(NamespaceEvents, c.Events.URL)
+	}
+	if c.Imaging != nil {
+		add(NamespaceImaging, c.Imaging.URL)
+	}
+	if c.Media != nil {
+		add(NamespaceMedia, c.Media.URL)
+	}
+	if c.PTZ != nil {
+		add(NamespacePTZ, c.PTZ.URL)
+	}
+	if c.Analytics != nil {
+		add(NamespaceAnalytics, c.Analytics.URL)
+	}
+
+	if e := c.Extension; e != nil {
+		if e.DeviceIO != nil {
+			add(NamespaceDeviceIO, e.DeviceIO.URL)
+		}
+		if e.Display != nil {
+			add(NamespaceDisplay, e.Display.URL)
+		}
+		if e.Recording != nil {
+			add(NamespaceRecording, e.Recording.URL)
+		}
+		if e.Search != nil {
+			add(NamespaceSearch, e.Search.URL)
+		}
+		if e.Replay != nil {
+			add(NamespaceReplay, e.Replay.URL)
+		}
+		if e.Receiver != nil {
+			add(NamespaceReceiver, e.Receiver.URL)
+		}
+		if e.AnalyticsDevice != nil {
+			add(NamespaceAnalyticsDevice, e.AnalyticsDevice.URL)
+		}
+	}
+
+	return services
+}
+
+type getAllCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetAllCapabilities returns the fully parsed capabilities from the remote device.
+// This is useful for legacy devices that don't return capability information with GetServices.
+// addr is the host:port pair of the device, or a full URL. See GetServices.
+func (c *Client) GetAllCapabilities(addr string) (*Capabilities, error) {
+	return c.GetAllCapabilitiesContext(context.Background(), addr)
+}
+
+// GetAllCapabilitiesContext is like GetAllCapabilities, but ctx controls the request
+func (c *Client) GetAllCapabilitiesContext(ctx context.Context, addr string) (*Capabilities, error) {
+	req := &Request{
+		URL:        c.deviceServiceURL(addr),
+		Namespaces: soap.Namespaces{"tds": NamespaceDevice},
+		Body:       &GetCapabilities{Category: "All"},
+	}
+	env, err := c.DoContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("could not complete operation: %w", err)
+	}
+
+	resp := new(getAllCapabilitiesResponse)
+	if err := env.Body.Unmarshal(resp); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+	}
+
+	if resp.Capabilities == nil {
+		return nil, fmt.Errorf("could not get capabilities: %w", soap.ErrNoResponse)
+	}
+
+	return resp.Capabilities, nil
+}
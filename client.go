@@ -3,11 +3,17 @@ package onvif
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/icholy/digest"
 	"github.com/korylprince/go-onvif/soap"
@@ -21,6 +27,9 @@ const (
 	AuthModeNone AuthMode = iota
 	AuthModeDigest
 	AuthModeWSSecurity
+	// AuthModeWSSecurityText is AuthModeWSSecurity with a plain text password, for devices that reject PasswordDigest tokens.
+	// It should only be used over TLS. See Client.AllowPasswordText
+	AuthModeWSSecurityText
 )
 
 // Request is a SOAP request
@@ -30,9 +39,37 @@ type Request struct {
 	Namespaces soap.Namespaces
 	// Body will be marshaled to XML as the SOAP body contents
 	Body interface{}
+	// Username and Password, if set, override the Client's credentials for this request.
+	// This allows a single Client (and its transport) to be shared between many sets of credentials
+	Username string
+	Password string
+	// AuthMode overrides Client.AuthMode for this request if Username and Password are set.
+	// Authentication mode detection doesn't modify the Request. Instead the Client keeps the detected mode for Username,
+	// which is used instead of AuthMode for later requests with the same Username. See Client.CurrentAuthModeFor
+	AuthMode AuthMode
+	// CorrelationID identifies the request in debug output and errors. If empty, a random ID is generated for each call to Client.Do
+	CorrelationID string
+	// Trace, if set, is attached to the HTTP request(s) made for this request. See Timings for collecting common timings
+	Trace *httptrace.ClientTrace
+	// Action is the SOAP action URI sent if Client.SendAction is true.
+	// If empty, it is derived from the namespace and name of the body element, e.g. http://www.onvif.org/ver10/device/wsdl/GetServices
+	Action string
+	// Timeout, if greater than zero, limits each attempt of the request, overriding RetryPolicy.Timeout
+	Timeout time.Duration
+	// SOAPVersion, if set to soap.Version11, sends the request as SOAP 1.1, overriding Client.SOAPVersion
+	SOAPVersion soap.Version
+	// Addressing, if set, is sent as WS-Addressing headers, which some operations (e.g. event subscription management) require.
+	// Empty fields are filled in for each HTTP request: Action with Request.Action (or the derived action), To with URL, and MessageID with a random urn:uuid
+	Addressing *soap.Addressing
+
+	// noAuth disables authentication for the request, e.g. for GetSystemDateAndTime before the time offset is known
+	noAuth bool
 }
 
-// Client is an ONVIF client
+// Client is an ONVIF client.
+// A Client is safe for concurrent use by multiple goroutines, including authentication mode detection.
+// Its exported fields should be set before the first request and not modified while requests are in flight.
+// See Client.CurrentAuthMode to read the detected authentication mode
 type Client struct {
 	// AuthMode specifies which authentication mode to use to authenticate requests.
 	// If set to AuthModeNone (the default value), the Client will not use authentication unless an authorization error occurs.
@@ -40,13 +77,113 @@ type Client struct {
 	// update Client.AuthMode, and authenticate all future requests.
 	// If AuthMode is set to AuthModeWSSecurity and an HTTP 401 response is returned (indicated WS Security tokens are not supported),
 	// AuthMode will be set to AuthModeDigest.
+	// See Client.AllowPasswordText for falling back to AuthModeWSSecurityText
 	AuthMode
 	Username string
 	Password string
-	// HTTPClient is the *http.Client to use for the request. If nil, http.DefaultClient is used
+	// If AllowPasswordText is true and the device rejects a WS-Security PasswordDigest token as unauthorized,
+	// AuthMode is set to AuthModeWSSecurityText and the request is retried. This only happens for HTTPS requests,
+	// so passwords are never sent in plain text over an unencrypted connection unless AuthModeWSSecurityText is set explicitly
+	AllowPasswordText bool
+	// Secrets, if set, is used to get the credentials for each request instead of Username and Password
+	Secrets SecretProvider
+	// HTTPClient is the *http.Client to use for the request. If nil, a default client is used, configured with TLSConfig and InsecureSkipVerify.
+	// If HTTPClient uses a custom transport, authentication wraps it, so its TLS configuration is used for all requests
 	HTTPClient *http.Client
-	// If Debug is true, the client will print the full request and response to stdout
+	// If UseTLS is true, https is used for device addresses without a scheme. See GetServices
+	UseTLS bool
+	// TLSConfig, if set, is used for HTTPS requests if HTTPClient is nil. See PinnedTLSConfig and CATLSConfig
+	TLSConfig *tls.Config
+	// If InsecureSkipVerify is true, HTTPS certificates aren't verified if HTTPClient is nil, e.g. for devices with self-signed certificates.
+	// Prefer TLSConfig with PinnedTLSConfig when the device's certificate is known
+	InsecureSkipVerify bool
+	// If Debug is true, the client will print the full request and response to stdout.
+	// WS-Security passwords and nonces are redacted. See Logger for production use
 	Debug bool
+	// If DebugIndent is true, debug output will be indented
+	DebugIndent bool
+	// Logger, if set, receives a LogEntry for each HTTP request made by the Client
+	Logger Logger
+	// If LogBodies is true, LogEntry includes the request and response bodies, with WS-Security passwords and nonces redacted
+	LogBodies bool
+	// If CorrelationHeader is set, the request correlation ID will be sent in the HTTP header with this name, e.g. X-Correlation-ID
+	CorrelationHeader string
+	// RetryPolicy, if set, controls which failed requests are retried
+	RetryPolicy *RetryPolicy
+	// EnvelopeHook, if set, is called with the fully built request envelope before it's marshaled, e.g. to add vendor specific headers.
+	// Raw header elements can be added with soap.Header.InnerXML. If an error is returned, the request is aborted
+	EnvelopeHook func(r *Request, env *soap.Envelope) error
+	// If Compression is true, the Client will request gzip compressed responses and decompress them.
+	// This works with any transport, including transports with compression disabled
+	Compression bool
+	// If CompressRequests is true, request bodies will be gzip compressed. Only enable this for devices known to support it
+	CompressRequests bool
+	// If SendAction is true, the SOAP action is sent as the action parameter of the Content-Type header, which some strict SOAP stacks require.
+	// See Request.Action
+	SendAction bool
+	// Hedge, if set, sends a second request for whitelisted operations that haven't completed within a delay. See HedgePolicy
+	Hedge *HedgePolicy
+	// OperationBudgets limits the total time of a call to DoContext for an operation, including retries and hedged requests.
+	// Keys match operations like HedgePolicy.Operations, e.g. "Stop" or http://www.onvif.org/ver20/ptz/wsdl/Stop
+	OperationBudgets map[string]time.Duration
+	// Middleware wraps each attempt of a request, outermost first. See Client.Use
+	Middleware []Middleware
+	// Telemetry, if set, receives the start and end of each call to DoContext, e.g. for tracing and metrics
+	Telemetry Telemetry
+	// Quirks, if set, adjusts the Client's behavior for non-conformant devices.
+	// NewDevice sets it from the quirks registry by manufacturer and model. See Client.ApplyQuirks and RegisterQuirks
+	Quirks *Quirks
+	// If DisableQuirkDetection is true, NewDevice doesn't call GetDeviceInformation to look up and apply a quirks profile
+	DisableQuirkDetection bool
+	// SecurityReuse, if greater than zero, reuses a WS-Security header (nonce, created time, and digest) for requests within this duration of its creation,
+	// instead of generating a new header for each request. This is for devices that rate limit token validation during frequent polling.
+	// Devices that reject replayed nonces will fail while a header is reused, so only enable this for devices known to accept them.
+	// The default of zero disables reuse. Values larger than MaxSecurityReuse are clamped
+	SecurityReuse time.Duration
+	// TimestampTTL, if greater than zero, adds a wsu:Timestamp to WS-Security headers that expires TimestampTTL after it's created.
+	// Some devices require it
+	TimestampTTL time.Duration
+	// TimeOffset is added to the local time when creating WS-Security timestamps,
+	// to compensate for a device clock that differs from the local clock. See Client.SyncTime
+	TimeOffset time.Duration
+	// If AutoTimeSync is true, the Client calls GetSystemDateAndTime to set TimeOffset before its first WS-Security authenticated request.
+	// If the device rejects a WS-Security authenticated request as unauthorized, the time is synced again and the request is retried once
+	AutoTimeSync bool
+	// Clock is used for WS-Security timestamps and retry backoff. If nil, SystemClock is used
+	Clock Clock
+	// Rand is used for WS-Security nonces and correlation IDs. If nil, crypto/rand.Reader is used
+	Rand io.Reader
+	// Strictness controls how unexpected tokens in response envelopes are handled. See soap.Envelope.Strictness
+	Strictness soap.Strictness
+	// SOAPVersion is the SOAP version used for requests. The default is SOAP 1.2.
+	// If a SOAP 1.2 request returns a SOAP 1.1 fault (e.g. an HTTP 500 from a device that only speaks SOAP 1.1),
+	// SOAPVersion is set to soap.Version11 and the request is retried. See Client.CurrentSOAPVersion
+	SOAPVersion soap.Version
+
+	// authMu protects AuthMode, requestAuthModes, HTTPClient (when it's nil), ownedClient, and the digest transport
+	authMu sync.Mutex
+	// ownedClient is the default HTTPClient, if the Client created it with its own transport. See Client.Close
+	ownedClient *http.Client
+	// requestAuthModes is the authentication modes detected for requests overriding the credentials, by username
+	requestAuthModes map[string]AuthMode
+	digest           *digest.Transport
+	digestClient     *http.Client
+
+	securityMu    sync.Mutex
+	securityCache map[string]*cachedSecurity
+
+	timeMu     sync.Mutex
+	timeSynced bool
+
+	// versionMu protects SOAPVersion and Quirks
+	versionMu sync.Mutex
+
+	// lifeMu protects closed and shutdownHooks. inflight counts requests in progress, including open Streams. See Client.Shutdown
+	lifeMu        sync.Mutex
+	closed        bool
+	shutdownHooks map[int]func(context.Context)
+	nextHook      int
+	inflight      sync.WaitGroup
 }
 
 type fakeTransport struct {
@@ -59,35 +196,123 @@ func (f *fakeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
 
 // Do executes a SOAP request.
 // The response envelope is returned, which can be further unmarshaled with soap.Body.Unmarshal
-// If the device returns a *soap.Fault, it will be returned as an error
+// All errors are wrapped in a *RequestError containing the request correlation ID.
+// If the device returns a *soap.Fault, it will be returned as the wrapped error and can be retrieved with errors.As.
+// Decoding errors and faults also contain the request and response bodies. See Payloads
 func (c *Client) Do(r *Request) (*soap.Envelope, error) {
-	// set default Client
-	if c.HTTPClient == nil {
-		c.HTTPClient = &http.Client{}
+	return c.DoContext(context.Background(), r)
+}
+
+// DoContext is like Do, but ctx controls the request, including any retries and authentication mode detection.
+// If ctx is canceled or its deadline passes, the in-flight request is aborted and the context's error is returned
+func (c *Client) DoContext(ctx context.Context, r *Request) (*soap.Envelope, error) {
+	id := r.CorrelationID
+	if id == "" {
+		var err error
+		if id, err = newCorrelationID(c.rand()); err != nil {
+			return nil, fmt.Errorf("could not create correlation id: %w", err)
+		}
 	}
 
+	if c.Telemetry != nil {
+		return c.instrument(ctx, r, id, func(ctx context.Context) (*soap.Envelope, error) {
+			return c.doContext(ctx, r, id)
+		})
+	}
+	return c.doContext(ctx, r, id)
+}
+
+// doContext executes r with the correlation id, including retries
+func (c *Client) doContext(ctx context.Context, r *Request, id string) (*soap.Envelope, error) {
+	if !c.acquire() {
+		return nil, &RequestError{CorrelationID: id, Err: ErrClientClosed}
+	}
+	defer c.release()
+
+	do := c.send
+	if c.Hedge != nil || len(c.OperationBudgets) > 0 {
+		op, err := requestOperation(r)
+		if err != nil {
+			return nil, &RequestError{CorrelationID: id, Err: fmt.Errorf("could not marshal request: %w", err)}
+		}
+		if d, ok := c.budget(op); ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancelLater(ctx, cancel)
+		}
+		if c.Hedge.match(op) && stream(ctx) == nil {
+			do = c.doHedged
+		}
+	}
+
+	timeout := c.RetryPolicy.timeout(r)
+	for attempt := 1; ; attempt++ {
+		env, err := c.attempt(ctx, timeout, do, r, id)
+		if err == nil {
+			return env, nil
+		}
+		if ctx.Err() != nil {
+			return nil, &RequestError{CorrelationID: id, Err: ctx.Err()}
+		}
+
+		delay, ok := c.RetryPolicy.retry(attempt, err)
+		if !ok {
+			return nil, &RequestError{CorrelationID: id, Err: err}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &RequestError{CorrelationID: id, Err: ctx.Err()}
+		case <-c.clock().After(delay):
+		}
+	}
+}
+
+// attempt calls do, limited by timeout if it's greater than zero
+func (c *Client) attempt(ctx context.Context, timeout time.Duration, do func(context.Context, *Request, string) (*soap.Envelope, error), r *Request, id string) (*soap.Envelope, error) {
+	if timeout <= 0 {
+		return do(ctx, r, id)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancelLater(attemptCtx, cancel)
+	env, err := do(attemptCtx, r, id)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%w after %v: %v", ErrRequestTimeout, timeout, err)
+	}
+	return env, err
+}
+
+func (c *Client) do(ctx context.Context, r *Request, id string) (*soap.Envelope, error) {
 	var (
 		s   *soap.Security
 		err error
 	)
 
+	cred, mode, err := c.credentials(r)
+	if err != nil {
+		return nil, err
+	}
+	defer cred.release()
+
 	// set auth params
-	if c.Username != "" && c.Password != "" {
-		switch c.AuthMode {
+	authMode := AuthModeNone
+	if cred != nil {
+		authMode = mode
+		switch mode {
 		case AuthModeNone:
-		case AuthModeWSSecurity:
-			s, err = soap.NewSecurity(c.Username, c.Password)
+		case AuthModeWSSecurity, AuthModeWSSecurityText:
+			c.autoSyncTime(ctx, r.URL)
+			s, err = c.security(cred, mode == AuthModeWSSecurityText)
 			if err != nil {
 				return nil, fmt.Errorf("could not create security header: %w", err)
 			}
 		case AuthModeDigest:
-			if _, ok := c.HTTPClient.Transport.(*digest.Transport); !ok {
-				c.HTTPClient.Transport = &digest.Transport{Username: c.Username, Password: c.Password}
-			}
 		default:
-			return nil, fmt.Errorf("invalid SecurityType: %d", c.AuthMode)
+			return nil, fmt.Errorf("invalid SecurityType: %d", mode)
 		}
 	}
+	httpClient := c.httpClient(authMode)
 
 	// marshal request
 	buf, err := xml.Marshal(r.Body)
@@ -95,14 +320,30 @@ func (c *Client) Do(r *Request) (*soap.Envelope, error) {
 		return nil, fmt.Errorf("could not marshal request: %w", err)
 	}
 
+	var addressing *soap.Addressing
+	if r.Addressing != nil {
+		if addressing, err = c.addressing(r, buf); err != nil {
+			return nil, fmt.Errorf("could not create addressing headers: %w", err)
+		}
+	}
+
+	version := c.soapVersion(r)
 	env := &soap.Envelope{
+		Version:    version,
 		Namespaces: r.Namespaces,
 		Header: &soap.Header{
-			Security: s,
+			Security:   s,
+			Addressing: addressing,
 		},
 		Body: &soap.Body{InnerXML: buf},
 	}
 
+	if c.EnvelopeHook != nil {
+		if err = c.EnvelopeHook(r, env); err != nil {
+			return nil, fmt.Errorf("could not apply envelope hook: %w", err)
+		}
+	}
+
 	buf2 := bytes.NewBufferString(xml.Header)
 
 	if err = xml.NewEncoder(buf2).Encode(env); err != nil {
@@ -110,65 +351,200 @@ func (c *Client) Do(r *Request) (*soap.Envelope, error) {
 	}
 
 	if c.Debug {
-		fmt.Printf("Request:\n%s\n", buf2.String())
+		fmt.Printf("Request (%s):\n%s\n", id, c.debugXML(buf2.Bytes()))
+	}
+	reqBody := buf2.Bytes()
+
+	if c.CompressRequests {
+		if buf2, err = gzipBuffer(buf2.Bytes()); err != nil {
+			return nil, fmt.Errorf("could not compress request: %w", err)
+		}
 	}
 
 	// create http request
-	httpReq, err := http.NewRequest(http.MethodPost, r.URL, buf2)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, buf2)
 	if err != nil {
 		return nil, fmt.Errorf("could not create http request: %w", err)
 	}
-	httpReq.Header.Set("Content-Type", "application/soap+xml")
+	httpReq.Header.Set("Content-Type", version.ContentType())
+	soapAction := r.Action
+	if soapAction == "" && (c.SendAction || c.Logger != nil || version == soap.Version11) {
+		if soapAction, err = action(buf, r.Namespaces); err != nil && c.SendAction {
+			return nil, fmt.Errorf("could not determine SOAP action: %w", err)
+		}
+	}
+	if version == soap.Version11 {
+		// SOAP 1.1 requires the header, even if it's empty
+		httpReq.Header.Set("SOAPAction", fmt.Sprintf("%q", soapAction))
+	} else if c.SendAction {
+		httpReq.Header.Set("Content-Type", fmt.Sprintf("%s; action=%q", version.ContentType(), soapAction))
+	}
+	if q := c.CurrentQuirks(); q != nil {
+		if q.ContentType != "" {
+			httpReq.Header.Set("Content-Type", q.ContentType)
+		}
+		httpReq.Close = q.DisableKeepAlives
+	}
+	if c.CompressRequests {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	if c.Compression {
+		httpReq.Header.Set("Accept-Encoding", "gzip")
+	}
+	if c.CorrelationHeader != "" {
+		httpReq.Header.Set(c.CorrelationHeader, id)
+	}
+
+	// pass credentials to digest transport
+	if cred != nil {
+		httpReq = httpReq.WithContext(context.WithValue(httpReq.Context(), credentialsKey{}, cred))
+	}
+
+	if r.Trace != nil {
+		httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), r.Trace))
+	}
+
+	entry := &LogEntry{CorrelationID: id, URL: r.URL, Action: soapAction}
+	if c.LogBodies {
+		entry.Request = redact(reqBody)
+	}
+	start := c.clock().Now()
 
 	// send request
-	soapResp, err := c.HTTPClient.Do(httpReq)
+	soapResp, err := httpClient.Do(httpReq)
 	if err != nil {
+		entry.Duration, entry.Err = c.clock().Now().Sub(start), err
+		c.log(entry)
+		recordResponse(ctx, 0)
 		return nil, fmt.Errorf("could not POST request: %w", err)
 	}
-	defer soapResp.Body.Close()
+	// the body is kept open for a successful DoStream request
+	var keepBody bool
+	defer func() {
+		if !keepBody {
+			soapResp.Body.Close()
+		}
+	}()
+	entry.StatusCode = soapResp.StatusCode
+	recordResponse(ctx, soapResp.StatusCode)
+
+	if err = decompress(soapResp); err != nil {
+		entry.Duration, entry.Err = c.clock().Now().Sub(start), err
+		c.log(entry)
+		return nil, err
+	}
+	if err = decodeMTOM(soapResp); err != nil {
+		entry.Duration, entry.Err = c.clock().Now().Sub(start), err
+		c.log(entry)
+		return nil, err
+	}
 
-	if c.Debug && soapResp != nil {
+	if c.Debug || (c.Logger != nil && c.LogBodies) {
 		buf2 = new(bytes.Buffer)
 		if _, err := buf2.ReadFrom(soapResp.Body); err != nil {
+			entry.Duration, entry.Err = c.clock().Now().Sub(start), err
+			c.log(entry)
 			return nil, fmt.Errorf("could not read response body: %w", err)
 		}
-		fmt.Printf("Response:\n%s\n", buf2.String())
-		soapResp.Body = io.NopCloser(buf2)
+		if c.Debug {
+			fmt.Printf("Response (%s):\n%s\n", id, c.debugXML(buf2.Bytes()))
+		}
+		if c.LogBodies {
+			entry.Response = redact(buf2.Bytes())
+		}
+		// keep the original Closer so the connection is released when the body is closed
+		soapResp.Body = struct {
+			io.Reader
+			io.Closer
+		}{buf2, soapResp.Body}
 	}
 
+	entry.Duration = c.clock().Now().Sub(start)
+	c.log(entry)
+
 	// check for digest auth error
 	if soapResp.StatusCode == http.StatusUnauthorized {
-		if c.AuthMode != AuthModeDigest && c.Username != "" && c.Password != "" {
-			c.AuthMode = AuthModeDigest
-			if _, ok := c.HTTPClient.Transport.(*digest.Transport); !ok {
-				// replay request to save digest headers
-				d := &digest.Transport{Transport: &fakeTransport{resp: soapResp}, Username: c.Username, Password: c.Password}
-				d.RoundTrip(httpReq)
-				d.Transport = nil
-				c.HTTPClient.Transport = d
-			}
-			return c.Do(r)
+		if mode != AuthModeDigest && cred != nil {
+			c.setAuthMode(r, AuthModeDigest)
+			c.replayDigest(httpReq, soapResp)
+			soapResp.Body.Close()
+			return c.do(ctx, r, id)
 		}
 		return nil, &soap.UnauthorizedError{Err: errors.New(soapResp.Status)}
 	}
 
 	// parse response
-	env = new(soap.Envelope)
-	if err = xml.NewDecoder(soapResp.Body).Decode(env); err != nil {
-		return nil, fmt.Errorf("could not decode response: %w", err)
+	env = &soap.Envelope{Strictness: c.Strictness}
+	payload := new(payloadBuffer)
+	if st := stream(ctx); st != nil {
+		var dec *soap.Decoder
+		if dec, err = soap.NewDecoder(io.TeeReader(soapResp.Body, payload), c.Strictness); err == nil {
+			env = dec.Envelope
+			if env.Body.Fault == nil {
+				st.Decoder, st.body, keepBody = dec, soapResp.Body, true
+			}
+		}
+	} else {
+		err = xml.NewDecoder(io.TeeReader(soapResp.Body, payload)).Decode(env)
+	}
+	if err != nil {
+		if soapResp.StatusCode < 200 || soapResp.StatusCode > 299 {
+			return nil, newPayloadError(&HTTPError{StatusCode: soapResp.StatusCode, Status: soapResp.Status, Err: err}, reqBody, payload)
+		}
+		return nil, newPayloadError(fmt.Errorf("could not decode response: %w", err), reqBody, payload)
+	}
+	if c.Debug {
+		for _, w := range env.Warnings {
+			fmt.Printf("Warning (%s): %v\n", id, w)
+		}
 	}
 
 	// check for soap fault
 	if env.Body.Fault != nil {
+		// the device only speaks SOAP 1.1, so retry with it
+		if version == soap.Version12 && env.Version == soap.Version11 {
+			c.setSOAPVersion(soap.Version11)
+			soapResp.Body.Close()
+			return c.do(ctx, r, id)
+		}
 		if env.Body.Fault.IsUnauthorizedError() {
-			if c.AuthMode == AuthModeNone && c.Username != "" && c.Password != "" {
-				c.AuthMode = AuthModeWSSecurity
-				return c.Do(r)
+			if cred != nil && (mode == AuthModeWSSecurity || mode == AuthModeWSSecurityText) {
+				c.forgetSecurity(cred)
+				// the device may have rejected the timestamp, so sync the time and retry once
+				if c.AutoTimeSync && ctx.Value(timeRetryKey{}) == nil {
+					// release the connection first, since syncing makes another request
+					soapResp.Body.Close()
+					if u, err := deviceURL(r.URL); err == nil && c.syncTime(ctx, u) == nil {
+						return c.do(context.WithValue(ctx, timeRetryKey{}, true), r, id)
+					}
+				}
+			}
+			// the device may only accept plain text passwords
+			if mode == AuthModeWSSecurity && cred != nil && c.AllowPasswordText && strings.HasPrefix(strings.ToLower(r.URL), "https://") {
+				c.setAuthMode(r, AuthModeWSSecurityText)
+				soapResp.Body.Close()
+				return c.do(ctx, r, id)
 			}
-			return nil, &soap.UnauthorizedError{Err: env.Body.Fault}
+			if mode == AuthModeNone && cred != nil {
+				c.setAuthMode(r, AuthModeWSSecurity)
+				soapResp.Body.Close()
+				return c.do(ctx, r, id)
+			}
+			return nil, newPayloadError(&soap.UnauthorizedError{Err: env.Body.Fault}, reqBody, payload)
 		}
-		return nil, env.Body.Fault
+		return nil, newPayloadError(env.Body.Fault, reqBody, payload)
 	}
 
 	return env, nil
 }
+
+// debugXML returns buf formatted for debug output
+func (c *Client) debugXML(buf []byte) []byte {
+	buf = redact(buf)
+	if c.DebugIndent {
+		if indented, err := soap.Indent(buf, "  "); err == nil {
+			return indented
+		}
+	}
+	return buf
+}
@@ -0,0 +1,2116 @@
+package onvif_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/ptz"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+const faultNotAuthorized = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body><env:Fault>
+<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:NotAuthorized</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">Sender not Authorized</env:Text></env:Reason>
+</env:Fault></env:Body>
+</env:Envelope>`
+
+const responseUser = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+<env:Body><User>%s</User></env:Body>
+</env:Envelope>`
+
+type testRequest struct {
+	XMLName xml.Name `xml:"tds:Test"`
+}
+
+type testResponse struct {
+	User string `xml:",chardata"`
+}
+
+var usernameRegexp = regexp.MustCompile(`<wsse:Username>([^<]*)</wsse:Username>`)
+
+// wsSecurityServer returns a server that requires a WS-Security header and echoes the username
+func wsSecurityServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		m := usernameRegexp.FindSubmatch(buf)
+		if m == nil {
+			w.Write([]byte(faultNotAuthorized))
+			return
+		}
+		fmt.Fprintf(w, responseUser, m[1])
+	}))
+}
+
+func TestRequestCredentials(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	c := &onvif.Client{Username: "admin", Password: "admin"}
+
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+		Username:   "user",
+		Password:   "password",
+	}
+
+	env, err := c.Do(r)
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	resp := new(testResponse)
+	if err = env.Body.Unmarshal(resp); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+
+	if resp.User != "user" {
+		t.Errorf("expected username %q, got %q", "user", resp.User)
+	}
+	if r.AuthMode != onvif.AuthModeNone {
+		t.Errorf("expected Request.AuthMode to be unmodified, got %d", r.AuthMode)
+	}
+	if mode := c.CurrentAuthModeFor("user"); mode != onvif.AuthModeWSSecurity {
+		t.Errorf("expected detected mode %d for user, got %d", onvif.AuthModeWSSecurity, mode)
+	}
+	if c.AuthMode != onvif.AuthModeNone {
+		t.Errorf("expected Client.AuthMode %d, got %d", onvif.AuthModeNone, c.AuthMode)
+	}
+
+	// client credentials should be unaffected
+	env, err = c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	resp = new(testResponse)
+	if err = env.Body.Unmarshal(resp); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+
+	if resp.User != "admin" {
+		t.Errorf("expected username %q, got %q", "admin", resp.User)
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Correlation-ID")
+		w.Write([]byte(faultNotAuthorized))
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{CorrelationHeader: "X-Correlation-ID"}
+
+	_, err := c.Do(&onvif.Request{
+		URL:           srv.URL,
+		Namespaces:    soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:          &testRequest{},
+		CorrelationID: "test-id",
+	})
+
+	var reqErr *onvif.RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("expected *onvif.RequestError, got %T", err)
+	}
+	if reqErr.CorrelationID != "test-id" {
+		t.Errorf("expected correlation id %q, got %q", "test-id", reqErr.CorrelationID)
+	}
+	if header != "test-id" {
+		t.Errorf("expected correlation header %q, got %q", "test-id", header)
+	}
+
+	var authErr *soap.UnauthorizedError
+	if !errors.As(err, &authErr) {
+		t.Errorf("expected wrapped *soap.UnauthorizedError, got %v", err)
+	}
+}
+
+func TestUnauthorizedError(t *testing.T) {
+	fsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(faultNotAuthorized))
+	}))
+	defer fsrv.Close()
+	hsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer hsrv.Close()
+
+	c := &onvif.Client{}
+	for _, test := range []struct {
+		name  string
+		url   string
+		fault bool
+	}{
+		{"fault", fsrv.URL, true},
+		{"401", hsrv.URL, false},
+	} {
+		_, err := c.Do(&onvif.Request{
+			URL:        test.url,
+			Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+			Body:       &testRequest{},
+		})
+		if !errors.Is(err, soap.ErrNotAuthorized) {
+			t.Errorf("%s: expected ErrNotAuthorized, got %v", test.name, err)
+		}
+		var f *soap.Fault
+		if errors.As(err, &f) != test.fault {
+			t.Errorf("%s: expected *soap.Fault to be returned: %v, got %v", test.name, test.fault, err)
+		}
+	}
+}
+
+const faultBusy = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body><env:Fault>
+<env:Code><env:Value>env:Receiver</env:Value><env:Subcode><env:Value>ter:Action</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">Device busy</env:Text></env:Reason>
+</env:Fault></env:Body>
+</env:Envelope>`
+
+func TestRetryPolicyFault(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Write([]byte(faultBusy))
+			return
+		}
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{RetryPolicy: &onvif.RetryPolicy{
+		MaxAttempts: 3,
+		Faults: []*onvif.FaultRetry{
+			{SubCode: "ter:NotAuthorized"},
+			{SubCode: "Action", Reason: regexp.MustCompile(`(?i)busy`), Backoff: time.Millisecond},
+		},
+	}}
+
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}
+
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("expected request to succeed after retries, got: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	c.RetryPolicy.MaxAttempts = 2
+	_, err := c.Do(r)
+	var f *soap.Fault
+	if !errors.As(err, &f) {
+		t.Fatalf("expected *soap.Fault after exhausting retries, got: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestPayloads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(faultBusy))
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{AuthMode: onvif.AuthModeWSSecurity, Username: "user", Password: "pass"}
+	_, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	var f *soap.Fault
+	if !errors.As(err, &f) {
+		t.Fatalf("expected *soap.Fault, got: %v", err)
+	}
+
+	req, resp := onvif.Payloads(err)
+	if !bytes.Contains(req, []byte("<tds:Test>")) || !bytes.Contains(req, []byte("[REDACTED]")) {
+		t.Errorf("expected redacted request body, got %s", req)
+	}
+	if string(resp) != faultBusy {
+		t.Errorf("expected response body:\n%s\ngot:\n%s", faultBusy, resp)
+	}
+}
+
+func TestRetryPolicyTransient(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		io.ReadAll(r.Body)
+		switch n {
+		case 1:
+			// drop the connection
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case 2:
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		case 3:
+			// stall until the attempt times out
+			<-r.Context().Done()
+		default:
+			fmt.Fprintf(w, responseUser, "")
+		}
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{RetryPolicy: &onvif.RetryPolicy{
+		MaxAttempts:  4,
+		Transient:    true,
+		ServerErrors: true,
+		Backoff:      time.Millisecond,
+	}}
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+		Timeout:    50 * time.Millisecond,
+	}
+
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("expected request to succeed after retries, got: %v", err)
+	}
+	mu.Lock()
+	if attempts != 4 {
+		t.Errorf("expected 4 attempts, got %d", attempts)
+	}
+	attempts = 1
+	mu.Unlock()
+
+	// server errors aren't retried unless enabled
+	c.RetryPolicy.ServerErrors = false
+	_, err := c.Do(r)
+	var h *onvif.HTTPError
+	if !errors.As(err, &h) || h.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected *onvif.HTTPError, got: %v", err)
+	}
+}
+
+func TestDownloadBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "password" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="device"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("log contents"))
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{Username: "admin", Password: "password"}
+
+	buf := new(bytes.Buffer)
+	if _, err := c.Download(srv.URL, buf); err != nil {
+		t.Fatalf("could not download: %v", err)
+	}
+	if buf.String() != "log contents" {
+		t.Errorf("expected %q, got %q", "log contents", buf.String())
+	}
+
+	c.Password = "wrong"
+	var authErr *soap.UnauthorizedError
+	if _, err := c.Download(srv.URL, io.Discard); !errors.As(err, &authErr) {
+		t.Errorf("expected *soap.UnauthorizedError, got %v", err)
+	}
+}
+
+func TestDownloadDigestReuse(t *testing.T) {
+	var challenges int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			challenges++
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="abc", qop="auth", algorithm=MD5`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write([]byte("log contents"))
+			return
+		}
+		io.ReadAll(r.Body)
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{Username: "admin", Password: "password"}
+	if _, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}); err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+	if mode := c.CurrentAuthMode(); mode != onvif.AuthModeDigest {
+		t.Fatalf("expected AuthModeDigest, got %d", mode)
+	}
+
+	challenges = 0
+	for i := 0; i < 3; i++ {
+		buf := new(bytes.Buffer)
+		if _, err := c.Download(srv.URL+"/log", buf); err != nil {
+			t.Fatalf("could not download: %v", err)
+		}
+		if buf.String() != "log contents" {
+			t.Errorf("expected %q, got %q", "log contents", buf.String())
+		}
+	}
+	if err := c.Upload(srv.URL+"/upload", "application/octet-stream", bytes.NewReader([]byte("firmware")), nil); err != nil {
+		t.Fatalf("could not upload: %v", err)
+	}
+	if challenges != 0 {
+		t.Errorf("expected saved digest challenge to be reused, got %d challenges", challenges)
+	}
+}
+
+func TestGetSnapshot(t *testing.T) {
+	jpeg := []byte{0xff, 0xd8, 0xff, 0xd9}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="abc", qop="auth", algorithm=MD5`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/error" {
+			w.Write([]byte("<html>error</html>"))
+			return
+		}
+		w.Write(jpeg)
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{Username: "admin", Password: "password"}
+	buf, err := c.GetSnapshot(context.Background(), srv.URL+"/snapshot.jpg")
+	if err != nil {
+		t.Fatalf("could not get snapshot: %v", err)
+	}
+	if !bytes.Equal(buf, jpeg) {
+		t.Errorf("expected %x, got %x", jpeg, buf)
+	}
+
+	if _, err = c.GetSnapshot(context.Background(), srv.URL+"/error"); !errors.Is(err, onvif.ErrNotJPEG) {
+		t.Errorf("expected not JPEG error, got %v", err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	firmware := bytes.Repeat([]byte("firmware"), 1<<12)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="abc", qop="auth", algorithm=MD5`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		buf, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/octet-stream" || !bytes.Equal(buf, firmware) {
+			t.Errorf("unexpected upload: %s, %d bytes", r.Header.Get("Content-Type"), len(buf))
+		}
+	}))
+	defer srv.Close()
+
+	var sent, total int64
+	c := &onvif.Client{Username: "admin", Password: "password"}
+	if err := c.Upload(srv.URL+"/upload", "application/octet-stream", bytes.NewReader(firmware), func(s, t int64) {
+		sent, total = s, t
+	}); err != nil {
+		t.Fatalf("could not upload: %v", err)
+	}
+	if sent != int64(len(firmware)) || total != int64(len(firmware)) {
+		t.Errorf("unexpected progress: %d/%d", sent, total)
+	}
+}
+
+const responseMTOM = "--boundary\r\n" +
+	"Content-Type: application/xop+xml; charset=UTF-8; type=\"application/soap+xml\"\r\n" +
+	"Content-ID: <root@device>\r\n\r\n" +
+	`<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body><tds:GetSystemBackupResponse><tds:BackupFiles><tt:Name>config.bin</tt:Name>
+<tt:Data><xop:Include xmlns:xop="http://www.w3.org/2004/08/xop/include" href="cid:backup%40device"/></tt:Data>
+</tds:BackupFiles></tds:GetSystemBackupResponse></env:Body>
+</env:Envelope>` + "\r\n--boundary\r\n" +
+	"Content-Type: application/octet-stream\r\n" +
+	"Content-ID: <backup@device>\r\n\r\n" +
+	"\x00\x01binary\r\n--boundary--\r\n"
+
+func TestMTOM(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", `multipart/related; type="application/xop+xml"; start="<root@device>"; boundary=boundary`)
+		w.Write([]byte(responseMTOM))
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{}
+	env, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	var resp struct {
+		Name string `xml:"BackupFiles>Name"`
+		Data []byte `xml:"BackupFiles>Data"`
+	}
+	if err = env.Body.Unmarshal(&resp); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(string(resp.Data))
+	if err != nil {
+		t.Fatalf("could not decode data: %v", err)
+	}
+	if resp.Name != "config.bin" || string(data) != "\x00\x01binary" {
+		t.Errorf("unexpected response: %s, %q", resp.Name, data)
+	}
+}
+
+const responseCapabilities = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body><tds:GetCapabilitiesResponse><tds:Capabilities>
+<tt:Device><tt:XAddr>http://192.168.0.64/onvif/device_service</tt:XAddr>
+<tt:IO><tt:InputConnectors>2</tt:InputConnectors><tt:RelayOutputs>1</tt:RelayOutputs></tt:IO>
+<tt:Security><tt:TLS1.1>false</tt:TLS1.1><tt:TLS1.2>true</tt:TLS1.2><tt:X.509Token>false</tt:X.509Token></tt:Security>
+</tt:Device>
+<tt:Events><tt:XAddr>http://192.168.0.64/onvif/event_service</tt:XAddr><tt:WSSubscriptionPolicySupport>true</tt:WSSubscriptionPolicySupport><tt:WSPullPointSupport>true</tt:WSPullPointSupport></tt:Events>
+<tt:Media><tt:XAddr>http://192.168.0.64/onvif/media_service</tt:XAddr><tt:StreamingCapabilities><tt:RTPMulticast>true</tt:RTPMulticast><tt:RTP_TCP>true</tt:RTP_TCP><tt:RTP_RTSP_TCP>true</tt:RTP_RTSP_TCP></tt:StreamingCapabilities></tt:Media>
+<tt:Extension><tt:DeviceIO><tt:XAddr>http://192.168.0.64/onvif/deviceio_service</tt:XAddr><tt:RelayOutputs>1</tt:RelayOutputs></tt:DeviceIO></tt:Extension>
+</tds:Capabilities></tds:GetCapabilitiesResponse></env:Body>
+</env:Envelope>`
+
+func TestGetAllCapabilities(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responseCapabilities))
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{}
+	cap, err := c.GetAllCapabilities(strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("could not get capabilities: %v", err)
+	}
+
+	if cap.Device.IO.InputConnectors != 2 {
+		t.Errorf("expected 2 input connectors, got %d", cap.Device.IO.InputConnectors)
+	}
+	if !cap.Device.Security.TLS12 || cap.Device.Security.TLS11 {
+		t.Errorf("expected only TLS1.2 support, got %#v", cap.Device.Security)
+	}
+	if !cap.Events.WSPullPointSupport {
+		t.Error("expected pull point support")
+	}
+	if !cap.Media.StreamingCapabilities.RTPRTSPTCP {
+		t.Error("expected RTP/RTSP/TCP support")
+	}
+	if cap.Extension.DeviceIO.RelayOutputs != 1 {
+		t.Errorf("expected 1 relay output, got %d", cap.Extension.DeviceIO.RelayOutputs)
+	}
+
+	services := cap.Services()
+	if len(services) != 4 {
+		t.Errorf("expected 4 services, got %d", len(services))
+	}
+	if url := services.URL(onvif.NamespaceDeviceIO); url != "http://192.168.0.64/onvif/deviceio_service" {
+		t.Errorf("unexpected DeviceIO url: %q", url)
+	}
+}
+
+type testSecrets struct {
+	password []byte
+}
+
+func (s *testSecrets) Credentials() (string, []byte, error) {
+	s.password = []byte("password")
+	return "secret-user", s.password, nil
+}
+
+func TestSecretProvider(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	secrets := new(testSecrets)
+	c := &onvif.Client{Secrets: secrets}
+
+	env, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	resp := new(testResponse)
+	if err = env.Body.Unmarshal(resp); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+
+	if resp.User != "secret-user" {
+		t.Errorf("expected username %q, got %q", "secret-user", resp.User)
+	}
+	if !bytes.Equal(secrets.password, make([]byte, len("password"))) {
+		t.Errorf("expected password to be zeroed after the request, got %q", secrets.password)
+	}
+}
+
+func TestEnvelopeHook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received [][]byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, buf)
+		mu.Unlock()
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	hookErr := errors.New("hook error")
+	var fail bool
+	c := &onvif.Client{
+		Username: "admin",
+		Password: "admin",
+		AuthMode: onvif.AuthModeWSSecurity,
+		EnvelopeHook: func(r *onvif.Request, env *soap.Envelope) error {
+			// the hook runs after the security header is built, but before the request is sent
+			if env.Header.Security == nil {
+				t.Error("expected security header")
+			}
+			mu.Lock()
+			sent := len(received)
+			mu.Unlock()
+			if sent != 0 {
+				t.Errorf("expected hook to run before sending, got %d requests", sent)
+			}
+
+			if fail {
+				return hookErr
+			}
+			env.Header.InnerXML = append(env.Header.InnerXML, `<vnd:Session xmlns:vnd="urn:vendor">1234</vnd:Session>`...)
+			env.Body.InnerXML = bytes.Replace(env.Body.InnerXML, []byte("tds:Test"), []byte("tds:Hooked"), -1)
+			return nil
+		},
+	}
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}
+
+	fail = true
+	if _, err := c.Do(r); !errors.Is(err, hookErr) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+
+	fail = false
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("could not do request: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("expected only the successful request to be sent, got %d requests", len(received))
+	}
+	buf := received[0]
+	// mutations made by the hook reach the wire, alongside the security header
+	if !bytes.Contains(buf, []byte(`<vnd:Session xmlns:vnd="urn:vendor">1234</vnd:Session>`)) || !bytes.Contains(buf, []byte("<tds:Hooked>")) {
+		t.Errorf("expected hook changes: %s", buf)
+	}
+	if !usernameRegexp.Match(buf) {
+		t.Errorf("expected security header: %s", buf)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	for _, test := range []struct {
+		name   string
+		config *tls.Config
+		valid  bool
+	}{
+		{"pinned", onvif.PinnedTLSConfig(srv.Certificate()), true},
+		{"not pinned", onvif.PinnedTLSConfig(&x509.Certificate{Raw: []byte("other")}), false},
+		{"trusted ca", onvif.CATLSConfig(pool), true},
+		{"untrusted ca", onvif.CATLSConfig(x509.NewCertPool()), false},
+	} {
+		c := &onvif.Client{HTTPClient: &http.Client{Transport: &http.Transport{TLSClientConfig: test.config}}}
+		_, err := c.Do(&onvif.Request{
+			URL:        srv.URL,
+			Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+			Body:       &testRequest{},
+		})
+		if test.valid && err != nil {
+			t.Errorf("%s: expected request to succeed, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected request to fail", test.name)
+		}
+	}
+}
+
+func TestTimings(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{HTTPClient: srv.Client()}
+	timings := new(onvif.Timings)
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+		Trace:      timings.ClientTrace(),
+	}
+
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("could not do request: %v", err)
+	}
+	if timings.ConnReused {
+		t.Error("expected new connection")
+	}
+	if timings.Connect <= 0 || timings.TLSHandshake <= 0 || timings.FirstByte < 5*time.Millisecond {
+		t.Errorf("expected non-zero timings, got %#v", timings)
+	}
+	// Total spans connecting, the handshake, and waiting for the response, in order
+	if timings.Total < timings.Connect+timings.TLSHandshake+timings.FirstByte {
+		t.Errorf("expected total to include all phases, got %#v", timings)
+	}
+
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("could not do request: %v", err)
+	}
+	if !timings.ConnReused || timings.Connect != 0 || timings.TLSHandshake != 0 {
+		t.Errorf("expected reused connection timings, got %#v", timings)
+	}
+	if timings.FirstByte < 5*time.Millisecond || timings.Total < timings.FirstByte {
+		t.Errorf("unexpected response timings, got %#v", timings)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" || r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected gzip headers, got %v", r.Header)
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("could not read compressed request: %v", err)
+		}
+		if _, err = io.ReadAll(zr); err != nil {
+			t.Fatalf("could not read compressed request: %v", err)
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		fmt.Fprintf(zw, responseUser, "compressed")
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{
+		Compression:      true,
+		CompressRequests: true,
+		HTTPClient:       &http.Client{Transport: &http.Transport{DisableCompression: true}},
+	}
+
+	env, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	resp := new(testResponse)
+	if err = env.Body.Unmarshal(resp); err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+	if resp.User != "compressed" {
+		t.Errorf("expected %q, got %q", "compressed", resp.User)
+	}
+}
+
+func TestSendAction(t *testing.T) {
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{SendAction: true}
+	if _, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}); err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	expected := `application/soap+xml; charset=utf-8; action="http://www.onvif.org/ver10/device/wsdl/Test"`
+	if contentType != expected {
+		t.Errorf("expected Content-Type %q, got %q", expected, contentType)
+	}
+}
+
+func TestQuirks(t *testing.T) {
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.Write([]byte(responseCapabilities))
+	}))
+	defer srv.Close()
+
+	onvif.RegisterQuirks(&onvif.QuirksProfile{
+		Manufacturer: regexp.MustCompile(`^Test$`),
+		Model:        regexp.MustCompile(`^Broken`),
+		Quirks:       &onvif.Quirks{ContentType: "text/xml; charset=utf-8", RewriteXAddrHost: true},
+	})
+	if q := onvif.LookupQuirks("Test", "Working 1"); q != nil {
+		t.Errorf("expected no quirks, got %#v", q)
+	}
+	q := onvif.LookupQuirks("Test", "Broken 1")
+	if q == nil {
+		t.Fatal("expected quirks")
+	}
+
+	c := &onvif.Client{}
+	c.ApplyQuirks(q)
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	services, err := c.GetCapabilities(addr)
+	if err != nil {
+		t.Fatalf("could not get capabilities: %v", err)
+	}
+
+	if contentType != q.ContentType {
+		t.Errorf("expected Content-Type %q, got %q", q.ContentType, contentType)
+	}
+	if url := services.URL(onvif.NamespaceDeviceIO); url != fmt.Sprintf("http://%s/onvif/deviceio_service", addr) {
+		t.Errorf("unexpected DeviceIO url: %q", url)
+	}
+}
+
+func TestApplyQuirksConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{}
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}
+
+	// quirks can be applied (e.g. by device.DetectQuirks) while requests are in flight. Run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := c.Do(r); err != nil {
+					t.Errorf("could not do request: %v", err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		c.ApplyQuirks(&onvif.Quirks{DisableKeepAlives: true})
+		c.ApplyQuirks(nil)
+	}
+	wg.Wait()
+
+	q := &onvif.Quirks{AuthMode: onvif.AuthModeWSSecurity}
+	c.ApplyQuirks(q)
+	if c.CurrentQuirks() != q || c.CurrentAuthMode() != onvif.AuthModeWSSecurity {
+		t.Errorf("expected quirks to be applied, got %#v", c.CurrentQuirks())
+	}
+}
+
+func TestSecurityReuse(t *testing.T) {
+	nonceRegexp := regexp.MustCompile(`<wsse:Nonce[^>]*>([^<]*)</wsse:Nonce>`)
+	var nonces []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		if m := nonceRegexp.FindSubmatch(buf); m != nil {
+			nonces = append(nonces, string(m[1]))
+		}
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{AuthMode: onvif.AuthModeWSSecurity, Username: "admin", Password: "admin", SecurityReuse: time.Second}
+	do := func() {
+		if _, err := c.Do(&onvif.Request{
+			URL:        srv.URL,
+			Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+			Body:       &testRequest{},
+		}); err != nil {
+			t.Fatalf("could not complete request: %v", err)
+		}
+	}
+
+	do()
+	do()
+	c.Password = "other"
+	do()
+
+	if len(nonces) != 3 {
+		t.Fatalf("expected 3 nonces, got %d", len(nonces))
+	}
+	if nonces[0] != nonces[1] {
+		t.Error("expected security header to be reused")
+	}
+	if nonces[1] == nonces[2] {
+		t.Error("expected new security header after password change")
+	}
+
+	// headers with plain text passwords are never reused
+	nonces = nil
+	c.AuthMode = onvif.AuthModeWSSecurityText
+	do()
+	do()
+	if len(nonces) != 2 || nonces[0] == nonces[1] {
+		t.Errorf("expected plain text security header not to be reused, got nonces %v", nonces)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := &onvif.Client{}
+	_, err := c.DoContext(ctx, &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		inFlight, maxSeen int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	f := onvif.NewFactory(nil, 2, &onvif.Client{CorrelationHeader: "X-Correlation-ID"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		c := f.NewClient("admin", "admin")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Do(&onvif.Request{
+				URL:        srv.URL,
+				Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+				Body:       &testRequest{},
+			}); err != nil {
+				t.Errorf("could not complete request: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxSeen > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", maxSeen)
+	}
+}
+
+func TestFactoryTemplate(t *testing.T) {
+	quirks := &onvif.Quirks{DisableKeepAlives: true}
+	template := &onvif.Client{
+		AuthMode:    onvif.AuthModeDigest,
+		Username:    "template",
+		TimeOffset:  time.Hour,
+		HTTPClient:  &http.Client{Timeout: time.Minute},
+		Quirks:      quirks,
+		Strictness:  soap.StrictnessWarn,
+		SOAPVersion: soap.Version11,
+	}
+	c := onvif.NewFactory(nil, 0, template).NewClient("admin", "password")
+
+	if c.Quirks == nil || *c.Quirks != *quirks || c.AuthMode != onvif.AuthModeDigest || c.Strictness != soap.StrictnessWarn || c.SOAPVersion != soap.Version11 {
+		t.Errorf("expected template options to be copied: %#v", c)
+	}
+	if c.Username != "admin" || c.Password != "password" || c.TimeOffset != 0 {
+		t.Errorf("expected per-device state not to be copied: %#v", c)
+	}
+	if c.HTTPClient == template.HTTPClient || c.HTTPClient.Timeout != time.Minute {
+		t.Errorf("expected new HTTPClient with template's timeout: %#v", c.HTTPClient)
+	}
+}
+
+func TestFactoryIsolation(t *testing.T) {
+	mw := func(next onvif.Doer) onvif.Doer { return next }
+	template := &onvif.Client{
+		Middleware:       make([]onvif.Middleware, 1, 4),
+		OperationBudgets: map[string]time.Duration{"Stop": time.Second},
+		RetryPolicy:      &onvif.RetryPolicy{MaxAttempts: 2, Faults: []*onvif.FaultRetry{{SubCode: "TooManySessions"}}},
+		Hedge:            &onvif.HedgePolicy{Delay: time.Second, Operations: []string{"Stop"}},
+		Quirks:           &onvif.Quirks{ContentType: "text/xml"},
+	}
+	template.Middleware[0] = mw
+	f := onvif.NewFactory(nil, 0, template)
+	a, b := f.NewClient("a", "a"), f.NewClient("b", "b")
+
+	a.Use(mw)
+	b.Use(mw)
+	if &a.Middleware[1] == &b.Middleware[1] || len(template.Middleware) != 1 {
+		t.Error("expected Clients not to share Middleware")
+	}
+	a.OperationBudgets["GetStatus"] = time.Second
+	a.RetryPolicy.MaxAttempts = 5
+	a.RetryPolicy.Faults[0].SubCode = "Other"
+	a.Hedge.Operations[0] = "GetStatus"
+	a.Quirks.ContentType = "application/soap+xml"
+
+	for name, c := range map[string]*onvif.Client{"template": template, "b": b} {
+		if len(c.OperationBudgets) != 1 {
+			t.Errorf("expected %s's OperationBudgets to be unchanged: %v", name, c.OperationBudgets)
+		}
+		if c.RetryPolicy.MaxAttempts != 2 || c.RetryPolicy.Faults[0].SubCode != "TooManySessions" {
+			t.Errorf("expected %s's RetryPolicy to be unchanged: %#v", name, c.RetryPolicy)
+		}
+		if c.Hedge.Operations[0] != "Stop" || c.Quirks.ContentType != "text/xml" {
+			t.Errorf("expected %s's Hedge and Quirks to be unchanged", name)
+		}
+	}
+}
+
+const responseDateTime = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+<env:Body><GetSystemDateAndTimeResponse><SystemDateAndTime><UTCDateTime>
+<Time><Hour>%d</Hour><Minute>%d</Minute><Second>%d</Second></Time>
+<Date><Year>%d</Year><Month>%d</Month><Day>%d</Day></Date>
+</UTCDateTime></SystemDateAndTime></GetSystemDateAndTimeResponse></env:Body>
+</env:Envelope>`
+
+func TestAutoTimeSync(t *testing.T) {
+	createdRegexp := regexp.MustCompile(`<wsu:Created>([^<]*)</wsu:Created>`)
+	// device clock is an hour ahead
+	offset := time.Hour
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		now := time.Now().UTC().Add(offset)
+		if bytes.Contains(buf, []byte("GetSystemDateAndTime")) {
+			fmt.Fprintf(w, responseDateTime, now.Hour(), now.Minute(), now.Second(), now.Year(), now.Month(), now.Day())
+			return
+		}
+
+		m := createdRegexp.FindSubmatch(buf)
+		if m == nil {
+			w.Write([]byte(faultNotAuthorized))
+			return
+		}
+		created, err := time.Parse("2006-01-02T15:04:05", string(m[1]))
+		if err != nil || now.Sub(created) > time.Minute || created.Sub(now) > time.Minute {
+			w.Write([]byte(faultNotAuthorized))
+			return
+		}
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{AuthMode: onvif.AuthModeWSSecurity, Username: "admin", Password: "admin", AutoTimeSync: true}
+	r := &onvif.Request{
+		URL:        srv.URL + "/onvif/media_service",
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+	if c.TimeOffset < 59*time.Minute || c.TimeOffset > 61*time.Minute {
+		t.Errorf("expected time offset of about an hour, got %v", c.TimeOffset)
+	}
+
+	// device clock changes
+	offset = -time.Hour
+	if _, err := c.Do(r); err != nil {
+		t.Fatalf("could not complete request after clock change: %v", err)
+	}
+	if c.TimeOffset > -59*time.Minute {
+		t.Errorf("expected time offset of about negative an hour, got %v", c.TimeOffset)
+	}
+}
+
+func TestConcurrentAuthDetection(t *testing.T) {
+	wsSrv := wsSecurityServer()
+	defer wsSrv.Close()
+
+	digestSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="abc", qop="auth", algorithm=MD5`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer digestSrv.Close()
+
+	for _, test := range []struct {
+		url  string
+		mode onvif.AuthMode
+	}{{wsSrv.URL, onvif.AuthModeWSSecurity}, {digestSrv.URL, onvif.AuthModeDigest}} {
+		c := &onvif.Client{Username: "admin", Password: "admin"}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.Do(&onvif.Request{
+					URL:        test.url,
+					Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+					Body:       &testRequest{},
+				}); err != nil {
+					t.Errorf("could not complete request: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if mode := c.CurrentAuthMode(); mode != test.mode {
+			t.Errorf("expected AuthMode %d, got %d", test.mode, mode)
+		}
+	}
+}
+
+func TestHTTPS(t *testing.T) {
+	var path string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(responseCapabilities))
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	c := &onvif.Client{UseTLS: true}
+	if _, err := c.GetAllCapabilities(addr); err == nil {
+		t.Error("expected certificate error")
+	}
+
+	for _, addr := range []string{addr, srv.URL, srv.URL + "/onvif/device_service"} {
+		c = &onvif.Client{UseTLS: true, InsecureSkipVerify: true}
+		if _, err := c.GetAllCapabilities(addr); err != nil {
+			t.Errorf("%s: could not get capabilities: %v", addr, err)
+		}
+		if path != "/onvif/device_service" {
+			t.Errorf("%s: unexpected path: %q", addr, path)
+		}
+	}
+}
+
+func TestHedge(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		count int
+	)
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		n := count
+		mu.Unlock()
+		// stall the first request
+		if n == 1 {
+			select {
+			case <-done:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		fmt.Fprintf(w, responseUser, "hedged")
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c := &onvif.Client{Hedge: &onvif.HedgePolicy{Delay: 10 * time.Millisecond, Operations: []string{"Test"}}}
+	env, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("expected hedged request to succeed, got %v", err)
+	}
+	if !strings.Contains(string(env.Body.InnerXML), "hedged") {
+		t.Errorf("expected hedged response, got %s", env.Body.InnerXML)
+	}
+
+	// operations not in the whitelist aren't hedged
+	mu.Lock()
+	count = 0
+	mu.Unlock()
+	c.Hedge.Operations = []string{"http://www.onvif.org/ver10/device/wsdl/Other"}
+	c.OperationBudgets = map[string]time.Duration{"Test": 50 * time.Millisecond}
+	_, err = c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if count != 1 {
+		t.Errorf("expected 1 request, got %d", count)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	var (
+		mu      sync.Mutex
+		entries []*onvif.LogEntry
+	)
+	c := &onvif.Client{
+		AuthMode:  onvif.AuthModeWSSecurity,
+		Username:  "user",
+		Password:  "pass",
+		LogBodies: true,
+		Logger: onvif.LoggerFunc(func(e *onvif.LogEntry) {
+			mu.Lock()
+			entries = append(entries, e)
+			mu.Unlock()
+		}),
+	}
+	if _, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}); err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.URL != srv.URL || e.StatusCode != http.StatusOK || e.Action != onvif.NamespaceDevice+"/Test" || e.CorrelationID == "" {
+		t.Errorf("unexpected log entry: %+v", e)
+	}
+	if !bytes.Contains(e.Request, []byte("[REDACTED]")) {
+		t.Errorf("expected redacted request body, got %s", e.Request)
+	}
+	if !bytes.Contains(e.Response, []byte("<User>user</User>")) {
+		t.Errorf("expected response body, got %s", e.Response)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) onvif.Middleware {
+		return func(next onvif.Doer) onvif.Doer {
+			return onvif.DoerFunc(func(ctx context.Context, r *onvif.Request) (*soap.Envelope, error) {
+				if onvif.CorrelationID(ctx) != "id" {
+					t.Errorf("unexpected correlation id: %s", onvif.CorrelationID(ctx))
+				}
+				calls = append(calls, name)
+				env, err := next.DoContext(ctx, r)
+				calls = append(calls, name+" done")
+				return env, err
+			})
+		}
+	}
+
+	c := &onvif.Client{}
+	c.Use(record("outer"), record("inner"), func(next onvif.Doer) onvif.Doer {
+		// add credentials for the request
+		return onvif.DoerFunc(func(ctx context.Context, r *onvif.Request) (*soap.Envelope, error) {
+			r.Username, r.Password, r.AuthMode = "user", "pass", onvif.AuthModeWSSecurity
+			return next.DoContext(ctx, r)
+		})
+	})
+
+	env, err := c.Do(&onvif.Request{
+		URL:           srv.URL,
+		Namespaces:    soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:          &testRequest{},
+		CorrelationID: "id",
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+	if !bytes.Contains(env.Body.InnerXML, []byte("<User>user</User>")) {
+		t.Errorf("unexpected response: %s", env.Body.InnerXML)
+	}
+
+	if fmt.Sprint(calls) != "[outer inner inner done outer done]" {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-done
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{}
+	r := &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}
+
+	errc := make(chan error)
+	go func() {
+		_, err := c.Do(r)
+		errc <- err
+	}()
+	<-started
+
+	// in-flight requests aren't interrupted if ctx is done first
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+
+	if _, err := c.Do(r); !errors.Is(err, onvif.ErrClientClosed) {
+		t.Errorf("expected client closed error, got %v", err)
+	}
+
+	close(done)
+	if err := <-errc; err != nil {
+		t.Errorf("expected in-flight request to succeed, got %v", err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected shutdown to succeed, got %v", err)
+	}
+}
+
+func TestShutdownStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, responseUser, "")
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{}
+	s, err := c.DoStream(context.Background(), &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not do request: %v", err)
+	}
+
+	// open Streams are waited for
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err = c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+
+	s.Close()
+	// closing twice doesn't release twice
+	s.Close()
+	if err = c.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected shutdown to succeed, got %v", err)
+	}
+}
+
+// closeTransport counts calls to CloseIdleConnections
+type closeTransport struct {
+	http.RoundTripper
+	closes int
+}
+
+func (t *closeTransport) CloseIdleConnections() {
+	t.closes++
+}
+
+func TestShutdownHooks(t *testing.T) {
+	c := &onvif.Client{}
+	var calls []string
+	c.RegisterShutdown(func(ctx context.Context) { calls = append(calls, "registered") })
+	unregister := c.RegisterShutdown(func(ctx context.Context) { calls = append(calls, "unregistered") })
+	unregister()
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected shutdown to succeed, got %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "registered" {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+
+	// Close doesn't close a transport shared between Clients
+	transport := &closeTransport{RoundTripper: http.DefaultTransport}
+	f := onvif.NewFactory(transport, 0, nil)
+	c = f.NewClient("", "")
+	if err := c.Close(); err != nil {
+		t.Errorf("expected close to succeed, got %v", err)
+	}
+	if err := f.NewClient("", "").Shutdown(context.Background()); err != nil {
+		t.Errorf("expected shutdown to succeed, got %v", err)
+	}
+	if transport.closes != 0 {
+		t.Errorf("expected shared transport not to be closed, got %d closes", transport.closes)
+	}
+}
+
+const responseServices = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body><tds:GetServicesResponse>
+<tds:Service><tds:Namespace>http://www.onvif.org/ver10/device/wsdl</tds:Namespace><tds:XAddr>%[1]s/onvif/device_service</tds:XAddr><tds:Version><tt:Major>2</tt:Major><tt:Minor>60</tt:Minor></tds:Version></tds:Service>
+<tds:Service><tds:Namespace>http://www.onvif.org/ver10/media/wsdl</tds:Namespace><tds:XAddr>%[1]s/onvif/media_service</tds:XAddr><tds:Version><tt:Major>2</tt:Major><tt:Minor>60</tt:Minor></tds:Version></tds:Service>
+</tds:GetServicesResponse></env:Body>
+</env:Envelope>`
+
+func TestNewDevice(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, responseServices, srv.URL)
+	}))
+	defer srv.Close()
+
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+
+	if !dev.HasService(onvif.NamespaceMedia) || dev.HasService(onvif.NamespacePTZ) {
+		t.Errorf("unexpected services: %v", dev.Services)
+	}
+
+	s, err := dev.Service(onvif.NamespaceMedia, nil)
+	if err != nil {
+		t.Fatalf("could not get media service: %v", err)
+	}
+	if s.URL != srv.URL+"/onvif/media_service" {
+		t.Errorf("unexpected media url: %q", s.URL)
+	}
+	if _, err = dev.Service(onvif.NamespacePTZ, nil); !errors.Is(err, onvif.ErrServiceNotSupported) {
+		t.Errorf("expected service not supported error, got %v", err)
+	}
+
+	m, err := media.FromDevice(dev)
+	if err != nil {
+		t.Fatalf("could not create media client: %v", err)
+	}
+	if m.URL != srv.URL+"/onvif/media_service" {
+		t.Errorf("unexpected media url: %q", m.URL)
+	}
+	if _, err = ptz.FromDevice(dev); !errors.Is(err, onvif.ErrServiceNotSupported) {
+		t.Errorf("expected service not supported error, got %v", err)
+	}
+}
+
+func TestNewDeviceQuirks(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		infoCalls int
+		srv       *httptest.Server
+	)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		if bytes.Contains(buf, []byte("GetDeviceInformation")) {
+			mu.Lock()
+			infoCalls++
+			mu.Unlock()
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl">
+<env:Body><tds:GetDeviceInformationResponse><tds:Manufacturer>QuirkVendor</tds:Manufacturer><tds:Model>Q1</tds:Model></tds:GetDeviceInformationResponse></env:Body>
+</env:Envelope>`)
+			return
+		}
+		// the device reports an internal address
+		fmt.Fprintf(w, responseServices, "http://10.0.0.1:8080")
+	}))
+	defer srv.Close()
+
+	q := &onvif.Quirks{RewriteXAddrHost: true, DisableKeepAlives: true}
+	onvif.RegisterQuirks(&onvif.QuirksProfile{Manufacturer: regexp.MustCompile(`^QuirkVendor$`), Quirks: q})
+
+	c := new(onvif.Client)
+	dev, err := onvif.NewDevice(context.Background(), c, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	if c.CurrentQuirks() != q {
+		t.Errorf("expected quirks profile to be applied, got %#v", c.CurrentQuirks())
+	}
+	if url := dev.Services.URL(onvif.NamespaceMedia); url != srv.URL+"/onvif/media_service" {
+		t.Errorf("expected service address to be rewritten, got %q", url)
+	}
+
+	c = &onvif.Client{DisableQuirkDetection: true}
+	if _, err = onvif.NewDevice(context.Background(), c, srv.URL); err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	if c.CurrentQuirks() != nil || infoCalls != 1 {
+		t.Errorf("expected quirk detection to be disabled, got %#v after %d calls", c.CurrentQuirks(), infoCalls)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	policy := &onvif.RetryPolicy{MaxAttempts: 2}
+	c := onvif.NewClient(
+		onvif.WithCredentials("user", "pass"),
+		onvif.WithAuthMode(onvif.AuthModeWSSecurity),
+		func(c *onvif.Client) { c.RetryPolicy = policy },
+		onvif.WithTimeout(time.Second),
+	)
+	if c.RetryPolicy.Timeout != time.Second || c.RetryPolicy.MaxAttempts != 2 || policy.Timeout != 0 {
+		t.Errorf("unexpected retry policy: %+v", c.RetryPolicy)
+	}
+
+	env, err := c.Do(&onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+	if !bytes.Contains(env.Body.InnerXML, []byte("<User>user</User>")) {
+		t.Errorf("unexpected response: %s", env.Body.InnerXML)
+	}
+}
+
+func TestServicesFind(t *testing.T) {
+	services := onvif.Services{
+		{Namespace: "http://www.onvif.org/ver10/device/wsdl/", URL: "device"},
+		{Namespace: "http://www.onvif.org/ver10/media/wsdl", URL: "media"},
+		{Namespace: "http://www.onvif.org/ver10/imaging/wsdl", URL: "imaging"},
+		{Namespace: "http://www.onvif.org/ver10/deviceio/wsdl", URL: "deviceio"},
+	}
+
+	for _, test := range []struct {
+		namespace string
+		url       string
+	}{
+		{onvif.NamespaceDevice, "device"},
+		{onvif.NamespaceMedia, "media"},
+		{onvif.NamespaceMedia2, ""},
+		{onvif.NamespaceImaging, "imaging"},
+		{onvif.NamespaceDeviceIO, "deviceio"},
+		{onvif.NamespacePTZ, ""},
+	} {
+		if url := services.URL(test.namespace); url != test.url {
+			t.Errorf("%s: expected %q, got %q", test.namespace, test.url, url)
+		}
+	}
+
+	if s := services.Best(onvif.NamespaceMedia2, onvif.NamespaceMedia); s == nil || s.URL != "media" {
+		t.Errorf("expected media service, got %v", s)
+	}
+	services = append(services, &onvif.Service{Namespace: onvif.NamespaceMedia2, URL: "media2"})
+	if s := services.Best(onvif.NamespaceMedia2, onvif.NamespaceMedia); s == nil || s.URL != "media2" {
+		t.Errorf("expected media2 service, got %v", s)
+	}
+	if s := services.Best(onvif.NamespacePTZ); s != nil {
+		t.Errorf("expected nil service, got %v", s)
+	}
+}
+
+const responseServicesCapabilities = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body><tds:GetServicesResponse>
+<tds:Service><tds:Namespace>http://www.onvif.org/ver20/ptz/wsdl</tds:Namespace><tds:XAddr>http://192.168.0.64/onvif/ptz_service</tds:XAddr>
+<tds:Capabilities><tptz:Capabilities xmlns:tptz="http://www.onvif.org/ver20/ptz/wsdl" EFlip="true" Reverse="false" MoveStatus="true"/></tds:Capabilities>
+<tds:Version><tt:Major>2</tt:Major><tt:Minor>60</tt:Minor></tds:Version></tds:Service>
+<tds:Service><tds:Namespace>http://www.onvif.org/ver10/media/wsdl</tds:Namespace><tds:XAddr>http://192.168.0.64/onvif/media_service</tds:XAddr>
+<tds:Version><tt:Major>2</tt:Major><tt:Minor>60</tt:Minor></tds:Version></tds:Service>
+</tds:GetServicesResponse></env:Body>
+</env:Envelope>`
+
+func TestGetServicesWithCapabilities(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		if !bytes.Contains(buf, []byte("<tds:IncludeCapability>true</tds:IncludeCapability>")) {
+			t.Errorf("expected IncludeCapability: %s", buf)
+		}
+		w.Write([]byte(responseServicesCapabilities))
+	}))
+	defer srv.Close()
+
+	services, err := (&onvif.Client{}).GetServicesWithCapabilities(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("could not get services: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(services))
+	}
+
+	var caps struct {
+		EFlip      bool `xml:"EFlip,attr"`
+		Reverse    bool `xml:"Reverse,attr"`
+		MoveStatus bool `xml:"MoveStatus,attr"`
+	}
+	if err = services[0].DecodeCapabilities(&caps); err != nil {
+		t.Fatalf("could not decode capabilities: %v", err)
+	}
+	if !caps.EFlip || caps.Reverse || !caps.MoveStatus {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+	if services[0].VersionMajor != 2 {
+		t.Errorf("unexpected version: %d", services[0].VersionMajor)
+	}
+
+	if err = services[1].DecodeCapabilities(&caps); !errors.Is(err, soap.ErrNoResponse) {
+		t.Errorf("expected no response error, got %v", err)
+	}
+}
+
+const responseServicesNormalize = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl">
+<env:Body><tds:GetServicesResponse>
+<tds:Service><tds:Namespace>HTTP://www.onvif.org/ver10/Device/wsdl/</tds:Namespace><tds:XAddr>http://192.168.0.64/onvif/device_service</tds:XAddr></tds:Service>
+<tds:Service><tds:Namespace>http://www.onvif.org/ver10/ptz/wsdl</tds:Namespace><tds:XAddr>http://192.168.0.64/onvif/ptz_service</tds:XAddr></tds:Service>
+</tds:GetServicesResponse></env:Body>
+</env:Envelope>`
+
+func TestGetServicesNormalize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responseServicesNormalize))
+	}))
+	defer srv.Close()
+
+	services, err := (&onvif.Client{}).GetServices(srv.URL)
+	if err != nil {
+		t.Fatalf("could not get services: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(services))
+	}
+
+	if services[0].Namespace != onvif.NamespaceDevice {
+		t.Errorf("expected namespace %q, got %q", onvif.NamespaceDevice, services[0].Namespace)
+	}
+	// a different version isn't rewritten, but is still found
+	if ns := "http://www.onvif.org/ver10/ptz/wsdl"; services[1].Namespace != ns {
+		t.Errorf("expected namespace %q, got %q", ns, services[1].Namespace)
+	}
+	if url := services.URL(onvif.NamespacePTZ); url != "http://192.168.0.64/onvif/ptz_service" {
+		t.Errorf("unexpected ptz url: %q", url)
+	}
+}
+
+func TestCall(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	type userResponse struct {
+		User string `xml:",chardata"`
+	}
+
+	c := &onvif.Client{AuthMode: onvif.AuthModeWSSecurity, Username: "user", Password: "pass"}
+	resp, err := onvif.Call[userResponse](c, &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+	if resp.User != "user" {
+		t.Errorf("expected user, got %q", resp.User)
+	}
+}
+
+const fault11VersionMismatch = `<?xml version="1.0" encoding="UTF-8"?>
+<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/">
+<SOAP-ENV:Body><SOAP-ENV:Fault>
+<faultcode>SOAP-ENV:VersionMismatch</faultcode>
+<faultstring>SOAP version mismatch</faultstring>
+</SOAP-ENV:Fault></SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`
+
+const responseUser11 = `<?xml version="1.0" encoding="UTF-8"?>
+<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/">
+<SOAP-ENV:Body><User>%s</User></SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`
+
+func TestSOAP11Fallback(t *testing.T) {
+	var soapActions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		if !bytes.Contains(buf, []byte(soap.NamespaceEnvelope11)) || !strings.HasPrefix(r.Header.Get("Content-Type"), "text/xml") {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fault11VersionMismatch))
+			return
+		}
+		soapActions = append(soapActions, r.Header.Get("SOAPAction"))
+		fmt.Fprintf(w, responseUser11, "user")
+	}))
+	defer srv.Close()
+
+	c := new(onvif.Client)
+	for i := 0; i < 2; i++ {
+		r := &onvif.Request{
+			URL:        srv.URL,
+			Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+			Body:       &testRequest{},
+		}
+		if i == 0 {
+			// the version is detected for the Client even if the request overrides the credentials
+			r.Username, r.Password = "user", "password"
+		}
+		env, err := c.Do(r)
+		if r.SOAPVersion != soap.Version12 {
+			t.Errorf("expected Request.SOAPVersion to be unmodified, got %v", r.SOAPVersion)
+		}
+		if err != nil {
+			t.Fatalf("could not complete request: %v", err)
+		}
+
+		resp := new(testResponse)
+		if err = env.Body.Unmarshal(resp); err != nil {
+			t.Fatalf("could not unmarshal response: %v", err)
+		}
+		if resp.User != "user" {
+			t.Errorf("expected user, got %q", resp.User)
+		}
+	}
+
+	if v := c.CurrentSOAPVersion(); v != soap.Version11 {
+		t.Errorf("expected %v, got %v", soap.Version11, v)
+	}
+	expected := `"http://www.onvif.org/ver10/device/wsdl/Test"`
+	if len(soapActions) != 2 || soapActions[0] != expected || soapActions[1] != expected {
+		t.Errorf("expected SOAPAction %s twice, got %q", expected, soapActions)
+	}
+}
+
+func TestPasswordTextFallback(t *testing.T) {
+	var types []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(buf, []byte("#PasswordText\">pass</wsse:Password>")):
+			types = append(types, "text")
+			fmt.Fprintf(w, responseUser, "user")
+		case bytes.Contains(buf, []byte("#PasswordDigest\"")):
+			types = append(types, "digest")
+			w.Write([]byte(faultNotAuthorized))
+		default:
+			types = append(types, "none")
+			w.Write([]byte(faultNotAuthorized))
+		}
+	}))
+	defer srv.Close()
+
+	r := &onvif.Request{URL: srv.URL, Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice}, Body: &testRequest{}}
+
+	// without AllowPasswordText, the password is never sent in plain text
+	c := &onvif.Client{AuthMode: onvif.AuthModeWSSecurity, Username: "user", Password: "pass", HTTPClient: srv.Client()}
+	if _, err := c.Do(r); !errors.As(err, new(*soap.UnauthorizedError)) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+
+	types = nil
+	c = &onvif.Client{Username: "user", Password: "pass", HTTPClient: srv.Client(), AllowPasswordText: true}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Do(r); err != nil {
+			t.Fatalf("could not complete request: %v", err)
+		}
+	}
+
+	if mode := c.CurrentAuthMode(); mode != onvif.AuthModeWSSecurityText {
+		t.Errorf("expected AuthModeWSSecurityText, got %d", mode)
+	}
+	if fmt.Sprint(types) != "[none digest text text]" {
+		t.Errorf("unexpected requests: %v", types)
+	}
+}
+
+type telemetryKey struct{}
+
+type testTelemetry struct {
+	mu       sync.Mutex
+	requests []*onvif.TelemetryRequest
+}
+
+func (t *testTelemetry) StartRequest(ctx context.Context, r *onvif.TelemetryRequest) context.Context {
+	return context.WithValue(ctx, telemetryKey{}, r.CorrelationID)
+}
+
+func (t *testTelemetry) EndRequest(ctx context.Context, r *onvif.TelemetryRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctx.Value(telemetryKey{}) != r.CorrelationID {
+		panic("unexpected context")
+	}
+	t.requests = append(t.requests, r)
+}
+
+func TestTelemetry(t *testing.T) {
+	srv := wsSecurityServer()
+	defer srv.Close()
+
+	tel := new(testTelemetry)
+	c := &onvif.Client{Username: "user", Password: "pass", Telemetry: tel}
+	if _, err := c.Do(&onvif.Request{URL: srv.URL, Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice}, Body: &testRequest{}}); err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+
+	c = &onvif.Client{Telemetry: tel}
+	if _, err := c.Do(&onvif.Request{URL: srv.URL, Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice}, Body: &testRequest{}}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(tel.requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(tel.requests))
+	}
+
+	r := tel.requests[0]
+	if r.Operation != "Test" || r.Action != onvif.NamespaceDevice+"/Test" || r.URL != srv.URL || r.CorrelationID == "" {
+		t.Errorf("unexpected request: %#v", r)
+	}
+	// authentication mode detection makes two HTTP requests
+	if r.Requests != 2 || r.StatusCode != http.StatusOK || r.FaultCode != "" || r.Err != nil || r.Duration <= 0 {
+		t.Errorf("unexpected result: %#v", r)
+	}
+
+	if r = tel.requests[1]; r.Requests != 1 || r.FaultCode != "ter:NotAuthorized" || r.Err == nil {
+		t.Errorf("unexpected result: %#v", r)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	var (
+		mu                  sync.Mutex
+		running, maxRunning int
+	)
+
+	devices := []string{"a", "b", "slow", "c", "fail"}
+	report := onvif.Broadcast(context.Background(), devices, &onvif.BroadcastOptions{Parallelism: 2, Timeout: 50 * time.Millisecond},
+		func(ctx context.Context, device string) (string, error) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+
+			switch device {
+			case "slow":
+				<-ctx.Done()
+				return "", ctx.Err()
+			case "fail":
+				return "", errors.New("failed")
+			}
+			time.Sleep(5 * time.Millisecond)
+			return strings.ToUpper(device), nil
+		})
+
+	if maxRunning != 2 {
+		t.Errorf("expected 2 devices in parallel, got %d", maxRunning)
+	}
+
+	var values []string
+	for _, res := range report.Succeeded() {
+		values = append(values, res.Value)
+	}
+	if fmt.Sprint(values) != "[A B C]" {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	failed := report.Failed()
+	if len(failed) != 2 || failed[0].Device != "slow" || failed[1].Device != "fail" {
+		t.Fatalf("unexpected failures: %v", failed)
+	}
+
+	err := report.Err()
+	var berr *onvif.BroadcastError
+	if !errors.As(err, &berr) || berr.Total != 5 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDoStream(t *testing.T) {
+	next := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><User>1</User>`))
+		w.(http.Flusher).Flush()
+		// wait for the client to read the first element, so the rest is read after DoStream returns
+		select {
+		case <-next:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`<User>2</User></env:Body></env:Envelope>`))
+	}))
+	defer srv.Close()
+
+	c := &onvif.Client{RetryPolicy: &onvif.RetryPolicy{Timeout: time.Second}}
+	s, err := c.DoStream(context.Background(), &onvif.Request{
+		URL:        srv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	})
+	if err != nil {
+		t.Fatalf("could not complete request: %v", err)
+	}
+	defer s.Close()
+
+	var users []string
+	for {
+		resp := new(testResponse)
+		if err = s.Decode(resp); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("could not decode response: %v", err)
+		}
+		users = append(users, resp.User)
+		if len(users) == 1 {
+			close(next)
+		}
+	}
+	if fmt.Sprint(users) != "[1 2]" {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	// faults are returned as errors
+	fsrv := wsSecurityServer()
+	defer fsrv.Close()
+	if _, err = c.DoStream(context.Background(), &onvif.Request{
+		URL:        fsrv.URL,
+		Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+		Body:       &testRequest{},
+	}); !errors.As(err, new(*soap.UnauthorizedError)) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestDoStreamAutoTimeSync(t *testing.T) {
+	createdRegexp := regexp.MustCompile(`<wsu:Created>([^<]*)</wsu:Created>`)
+	// device clock is an hour ahead
+	var mu sync.Mutex
+	offset := time.Hour
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		now := time.Now().UTC().Add(offset)
+		mu.Unlock()
+		if bytes.Contains(buf, []byte("GetSystemDateAndTime")) {
+			fmt.Fprintf(w, responseDateTime, now.Hour(), now.Minute(), now.Second(), now.Year(), now.Month(), now.Day())
+			return
+		}
+
+		m := createdRegexp.FindSubmatch(buf)
+		if m == nil {
+			w.Write([]byte(faultNotAuthorized))
+			return
+		}
+		created, err := time.Parse("2006-01-02T15:04:05", string(m[1]))
+		if err != nil || now.Sub(created) > time.Minute || created.Sub(now) > time.Minute {
+			w.Write([]byte(faultNotAuthorized))
+			return
+		}
+		fmt.Fprintf(w, responseUser, "admin")
+	}))
+	defer srv.Close()
+
+	// a single connection slot, so a response body left open blocks the next request
+	c := onvif.NewFactory(nil, 1, &onvif.Client{AuthMode: onvif.AuthModeWSSecurity, AutoTimeSync: true}).NewClient("admin", "admin")
+	stream := func() {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s, err := c.DoStream(ctx, &onvif.Request{
+			URL:        srv.URL + "/onvif/media_service",
+			Namespaces: soap.Namespaces{"tds": onvif.NamespaceDevice},
+			Body:       &testRequest{},
+		})
+		if err != nil {
+			t.Fatalf("could not complete request: %v", err)
+		}
+		defer s.Close()
+		resp := new(testResponse)
+		if err = s.Decode(resp); err != nil || resp.User != "admin" {
+			t.Errorf("unexpected response: %v, %v", resp.User, err)
+		}
+	}
+
+	stream()
+	if offset := c.TimeOffset; offset < 59*time.Minute || offset > 61*time.Minute {
+		t.Errorf("expected time offset of about an hour, got %v", offset)
+	}
+
+	// device clock changes, so the request is rejected and the time is synced again
+	mu.Lock()
+	offset = -time.Hour
+	mu.Unlock()
+	stream()
+	if offset := c.TimeOffset; offset > -59*time.Minute {
+		t.Errorf("expected time offset of about negative an hour, got %v", offset)
+	}
+}
+
+func TestPages(t *testing.T) {
+	pages := map[string]struct {
+		items []int
+		next  string
+	}{"": {[]int{1, 2}, "a"}, "a": {[]int{3}, "b"}, "b": {[]int{4}, ""}}
+	var starts []string
+	items, err := onvif.Pages(context.Background(), func(ctx context.Context, start string) ([]int, string, error) {
+		starts = append(starts, start)
+		return pages[start].items, pages[start].next, nil
+	})
+	if err != nil {
+		t.Fatalf("could not get pages: %v", err)
+	}
+	if fmt.Sprint(items) != "[1 2 3 4]" || fmt.Sprint(starts) != "[ a b]" {
+		t.Errorf("unexpected items %v from starts %q", items, starts)
+	}
+
+	// a device returning the same reference would otherwise page forever
+	var calls int
+	_, err = onvif.Pages(context.Background(), func(ctx context.Context, start string) ([]int, string, error) {
+		calls++
+		return []int{calls}, "same", nil
+	})
+	if !errors.Is(err, onvif.ErrRepeatedStartReference) || calls != 2 {
+		t.Errorf("expected repeated start reference error after 2 calls, got %v after %d calls", err, calls)
+	}
+
+	fetchErr := errors.New("fetch error")
+	if _, err = onvif.Pages(context.Background(), func(ctx context.Context, start string) ([]int, string, error) {
+		return nil, "", fetchErr
+	}); !errors.Is(err, fetchErr) {
+		t.Errorf("expected fetch error, got %v", err)
+	}
+}
+
+func TestDeviceSupports(t *testing.T) {
+	srv := onviftest.NewServer("", "", onviftest.AuthNone)
+	defer srv.Close()
+	// the device rejects the trial call's missing arguments, which means the operation is supported
+	srv.Fail("GetStreamUri", onviftest.Fault(soap.ErrInvalidArgVal, "missing profile token"))
+	srv.Respond("GetProfiles", `<trt:GetProfilesResponse/>`)
+
+	dev := &onvif.Device{Client: new(onvif.Client), Services: onvif.Services{{Namespace: onvif.NamespaceMedia, URL: srv.URL}}}
+	for _, test := range []struct {
+		namespace, operation string
+		supported            bool
+	}{
+		{onvif.NamespaceMedia, "GetProfiles", true},
+		{onvif.NamespaceMedia, "GetStreamUri", true},
+		{onvif.NamespaceMedia, "GetOSDs", false},
+		{onvif.NamespacePTZ, "GetNodes", false},
+	} {
+		for i := 0; i < 2; i++ {
+			supported, err := dev.Supports(context.Background(), test.namespace, test.operation)
+			if err != nil || supported != test.supported {
+				t.Errorf("%s: expected %v, got %v, %v", test.operation, test.supported, supported, err)
+			}
+		}
+	}
+	if reqs := srv.Requests(); len(reqs) != 3 || reqs[0].Namespace != onvif.NamespaceMedia {
+		t.Errorf("expected results to be cached, got %d requests", len(reqs))
+	}
+
+	if _, err := dev.Supports(context.Background(), onvif.NamespaceMedia, "DeleteProfile"); !errors.Is(err, onvif.ErrProbeUnsafe) {
+		t.Errorf("expected unsafe probe error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package onvif
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Clock is a source of time. It can be replaced to make tests deterministic or to fast-forward time in simulations
+type Clock interface {
+	soap.Clock
+	// After waits for d to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the Clock using the system time
+var SystemClock Clock = systemClock{}
+
+// clock returns the Client's Clock
+func (c *Client) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return SystemClock
+}
+
+// rand returns the Client's source of randomness
+func (c *Client) rand() io.Reader {
+	if c.Rand != nil {
+		return c.Rand
+	}
+	return rand.Reader
+}
@@ -0,0 +1,52 @@
+package onvif
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipBuffer returns a gzip compressed copy of buf
+func gzipBuffer(buf []byte) (*bytes.Buffer, error) {
+	out := new(bytes.Buffer)
+	w := gzip.NewWriter(out)
+	if _, err := w.Write(buf); err != nil {
+		return nil, fmt.Errorf("could not compress: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("could not compress: %w", err)
+	}
+	return out, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
+
+// decompress replaces resp.Body with a decompressing reader if the response is gzip encoded
+func decompress(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	r, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read gzip response: %w", err)
+	}
+
+	resp.Body = &gzipReadCloser{Reader: r, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+
+	return nil
+}
@@ -0,0 +1,285 @@
+// Package controls describes the imaging and PTZ settings a device supports as a declarative document (ranges, enums, and supported operations),
+// so front-ends can render appropriate controls without ONVIF knowledge
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/imaging"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/ptz"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Kind is the kind of value a Control takes
+type Kind string
+
+// Control kinds
+const (
+	// KindRange is a number between Min and Max, e.g. a slider
+	KindRange Kind = "range"
+	// KindEnum is one of Values, e.g. a drop down
+	KindEnum Kind = "enum"
+)
+
+// Control is a setting or move parameter
+type Control struct {
+	// Name is the path of the setting, e.g. Exposure/Gain or ContinuousMove/PanTilt/X
+	Name string
+	Kind Kind
+	// Min and Max are the bounds of a KindRange Control
+	Min float64
+	Max float64
+	// Values are the values of a KindEnum Control
+	Values []string
+	// Unit is the unit of a KindRange Control, if it's known, e.g. "s"
+	Unit string
+}
+
+// Source is the controls of a video source's imaging settings or a PTZ configuration
+type Source struct {
+	// Service is the namespace of the service the controls are used with, i.e. onvif.NamespaceImaging or onvif.NamespacePTZ
+	Service string
+	// Token is the video source token for imaging or the PTZ configuration token for PTZ
+	Token string
+	// Operations are the supported operations, e.g. SetImagingSettings or ContinuousMove
+	Operations []string
+	Controls   []*Control
+}
+
+// Document is the controls of a device
+type Document struct {
+	Imaging []*Source
+	PTZ     []*Source
+}
+
+// rangeControl returns a KindRange Control for r, or nil if r is nil
+func rangeControl(name string, r *imaging.FloatRange) *Control {
+	if r == nil {
+		return nil
+	}
+	return &Control{Name: name, Kind: KindRange, Min: r.Min, Max: r.Max}
+}
+
+// enumControl returns a KindEnum Control for values, or nil if values is empty
+func enumControl(name string, values []string) *Control {
+	if len(values) == 0 {
+		return nil
+	}
+	return &Control{Name: name, Kind: KindEnum, Values: values}
+}
+
+// appendControls appends the non-nil controls to s
+func appendControls(s []*Control, controls ...*Control) []*Control {
+	for _, c := range controls {
+		if c != nil {
+			s = append(s, c)
+		}
+	}
+	return s
+}
+
+// ImagingSource returns the Source for the imaging options and focus move options (which may be nil) of the video source with the given token
+func ImagingSource(videoSourceToken string, opts *imaging.ImagingOptions, move *imaging.MoveOptions) *Source {
+	s := &Source{Service: onvif.NamespaceImaging, Token: videoSourceToken}
+	if opts != nil {
+		s.Controls = appendControls(s.Controls,
+			rangeControl("Brightness", opts.Brightness),
+			rangeControl("ColorSaturation", opts.ColorSaturation),
+			rangeControl("Contrast", opts.Contrast),
+			rangeControl("Sharpness", opts.Sharpness),
+		)
+		if len(opts.IrCutFilterModes) > 0 {
+			modes := make([]string, len(opts.IrCutFilterModes))
+			for i, m := range opts.IrCutFilterModes {
+				modes[i] = string(m)
+			}
+			s.Controls = appendControls(s.Controls, enumControl("IrCutFilter", modes))
+		}
+		if o := opts.BacklightCompensation; o != nil {
+			s.Controls = appendControls(s.Controls, enumControl("BacklightCompensation/Mode", o.Mode), rangeControl("BacklightCompensation/Level", o.Level))
+		}
+		if o := opts.Exposure; o != nil {
+			s.Controls = appendControls(s.Controls,
+				enumControl("Exposure/Mode", o.Mode),
+				enumControl("Exposure/Priority", o.Priority),
+				rangeControl("Exposure/MinExposureTime", o.MinExposureTime),
+				rangeControl("Exposure/MaxExposureTime", o.MaxExposureTime),
+				rangeControl("Exposure/MinGain", o.MinGain),
+				rangeControl("Exposure/MaxGain", o.MaxGain),
+				rangeControl("Exposure/MinIris", o.MinIris),
+				rangeControl("Exposure/MaxIris", o.MaxIris),
+				rangeControl("Exposure/ExposureTime", o.ExposureTime),
+				rangeControl("Exposure/Gain", o.Gain),
+				rangeControl("Exposure/Iris", o.Iris),
+			)
+		}
+		if o := opts.Focus; o != nil {
+			s.Controls = appendControls(s.Controls,
+				enumControl("Focus/AutoFocusMode", o.AutoFocusModes),
+				rangeControl("Focus/DefaultSpeed", o.DefaultSpeed),
+				rangeControl("Focus/NearLimit", o.NearLimit),
+				rangeControl("Focus/FarLimit", o.FarLimit),
+			)
+		}
+		if o := opts.WideDynamicRange; o != nil {
+			s.Controls = appendControls(s.Controls, enumControl("WideDynamicRange/Mode", o.Mode), rangeControl("WideDynamicRange/Level", o.Level))
+		}
+		if o := opts.WhiteBalance; o != nil {
+			s.Controls = appendControls(s.Controls,
+				enumControl("WhiteBalance/Mode", o.Mode),
+				rangeControl("WhiteBalance/CrGain", o.YrGain),
+				rangeControl("WhiteBalance/CbGain", o.YbGain),
+			)
+		}
+		if len(s.Controls) > 0 {
+			s.Operations = append(s.Operations, "SetImagingSettings")
+		}
+	}
+
+	if move != nil && (move.Absolute != nil || move.Relative != nil || move.Continuous != nil) {
+		s.Operations = append(s.Operations, "Move", "Stop")
+		if m := move.Absolute; m != nil {
+			s.Controls = appendControls(s.Controls, rangeControl("Move/Absolute/Position", &m.Position), rangeControl("Move/Absolute/Speed", m.Speed))
+		}
+		if m := move.Relative; m != nil {
+			s.Controls = appendControls(s.Controls, rangeControl("Move/Relative/Distance", &m.Distance), rangeControl("Move/Relative/Speed", m.Speed))
+		}
+		if m := move.Continuous; m != nil {
+			s.Controls = appendControls(s.Controls, rangeControl("Move/Continuous/Speed", &m.Speed))
+		}
+	}
+
+	return s
+}
+
+// space2D returns the X and Y range Controls for the first (default) space in spaces, or nil if spaces is empty
+func space2D(name string, spaces []*ptz.Space2DDescription) []*Control {
+	if len(spaces) == 0 {
+		return nil
+	}
+	s := spaces[0]
+	return []*Control{
+		{Name: name + "/X", Kind: KindRange, Min: s.XRange.Min, Max: s.XRange.Max},
+		{Name: name + "/Y", Kind: KindRange, Min: s.YRange.Min, Max: s.YRange.Max},
+	}
+}
+
+// space1D returns the range Control for the first (default) space in spaces, or nil if spaces is empty
+func space1D(name string, spaces []*ptz.Space1DDescription) *Control {
+	if len(spaces) == 0 {
+		return nil
+	}
+	return &Control{Name: name, Kind: KindRange, Min: spaces[0].XRange.Min, Max: spaces[0].XRange.Max}
+}
+
+// PTZSource returns the Source for the options of the PTZ configuration with the given token.
+// Ranges are from the first (default) space of each kind
+func PTZSource(configurationToken string, opts *ptz.PTZConfigurationOptions) *Source {
+	s := &Source{Service: onvif.NamespacePTZ, Token: configurationToken}
+	if opts == nil || opts.Spaces == nil {
+		return s
+	}
+
+	sp := opts.Spaces
+	for _, move := range []struct {
+		name    string
+		panTilt []*ptz.Space2DDescription
+		zoom    []*ptz.Space1DDescription
+	}{
+		{"AbsoluteMove", sp.AbsolutePanTiltPositionSpace, sp.AbsoluteZoomPositionSpace},
+		{"RelativeMove", sp.RelativePanTiltTranslationSpace, sp.RelativeZoomTranslationSpace},
+		{"ContinuousMove", sp.ContinuousPanTiltVelocitySpace, sp.ContinuousZoomVelocitySpace},
+	} {
+		if len(move.panTilt) == 0 && len(move.zoom) == 0 {
+			continue
+		}
+		s.Operations = append(s.Operations, move.name)
+		s.Controls = append(s.Controls, space2D(move.name+"/PanTilt", move.panTilt)...)
+		s.Controls = appendControls(s.Controls, space1D(move.name+"/Zoom", move.zoom))
+	}
+	if len(s.Operations) > 0 {
+		s.Operations = append(s.Operations, "Stop")
+	}
+
+	s.Controls = appendControls(s.Controls, space1D("Speed/PanTilt", sp.PanTiltSpeedSpace), space1D("Speed/Zoom", sp.ZoomSpeedSpace))
+
+	if t := opts.PTZTimeout; t != nil && len(sp.ContinuousPanTiltVelocitySpace)+len(sp.ContinuousZoomVelocitySpace) > 0 {
+		min, err1 := soap.ParseDuration(t.Min)
+		max, err2 := soap.ParseDuration(t.Max)
+		if err1 == nil && err2 == nil {
+			s.Controls = append(s.Controls, &Control{Name: "ContinuousMove/Timeout", Kind: KindRange, Min: min.Seconds(), Max: max.Seconds(), Unit: "s"})
+		}
+	}
+
+	return s
+}
+
+// unsupported returns true if err is a fault other than an authorization fault, i.e. the device doesn't support the operation for the token
+func unsupported(err error) bool {
+	var f *soap.Fault
+	return errors.As(err, &f) && !errors.Is(err, soap.ErrNotAuthorized)
+}
+
+// Describe returns the controls of each video source and PTZ configuration used by dev's media profiles.
+// Sources whose options the device doesn't support (i.e. GetOptions or GetConfigurationOptions faults) are omitted,
+// and the imaging or PTZ list is empty if dev doesn't have the service
+func Describe(ctx context.Context, dev *onvif.Device) (*Document, error) {
+	m, err := media.FromDevice(dev)
+	if err != nil {
+		return nil, fmt.Errorf("could not create media client: %w", err)
+	}
+	profiles, err := m.GetProfilesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get profiles: %w", err)
+	}
+
+	doc := new(Document)
+
+	if img, err := imaging.FromDevice(dev); err == nil {
+		seen := make(map[string]bool)
+		for _, p := range profiles {
+			if p.VideoSourceConfiguration == nil || seen[p.VideoSourceConfiguration.SourceToken] {
+				continue
+			}
+			token := p.VideoSourceConfiguration.SourceToken
+			seen[token] = true
+
+			opts, err := img.GetOptionsContext(ctx, token)
+			if unsupported(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("could not get imaging options for %s: %w", token, err)
+			}
+			move, err := img.GetMoveOptionsContext(ctx, token)
+			if err != nil && !unsupported(err) {
+				return nil, fmt.Errorf("could not get move options for %s: %w", token, err)
+			}
+			doc.Imaging = append(doc.Imaging, ImagingSource(token, opts, move))
+		}
+	}
+
+	if p, err := ptz.FromDevice(dev); err == nil {
+		seen := make(map[string]bool)
+		for _, prof := range profiles {
+			if prof.PTZConfiguration == nil || seen[prof.PTZConfiguration.Token] {
+				continue
+			}
+			token := prof.PTZConfiguration.Token
+			seen[token] = true
+
+			opts, err := p.GetConfigurationOptionsContext(ctx, token)
+			if unsupported(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("could not get PTZ configuration options for %s: %w", token, err)
+			}
+			doc.PTZ = append(doc.PTZ, PTZSource(token, opts))
+		}
+	}
+
+	return doc, nil
+}
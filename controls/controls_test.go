@@ -0,0 +1,69 @@
+package controls_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/controls"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+func TestDescribe(t *testing.T) {
+	srv := onviftest.NewServer("", "", onviftest.AuthNone)
+	defer srv.Close()
+	srv.Respond("GetProfiles", `<trt:GetProfilesResponse>
+<trt:Profiles token="main"><tt:VideoSourceConfiguration token="vsc"><tt:SourceToken>source</tt:SourceToken></tt:VideoSourceConfiguration>
+<tt:PTZConfiguration token="ptzc"><tt:NodeToken>node</tt:NodeToken></tt:PTZConfiguration></trt:Profiles>
+<trt:Profiles token="sub"><tt:VideoSourceConfiguration token="vsc"><tt:SourceToken>source</tt:SourceToken></tt:VideoSourceConfiguration></trt:Profiles>
+</trt:GetProfilesResponse>`)
+	srv.Respond("GetOptions", `<GetOptionsResponse><ImagingOptions>
+<tt:Brightness><tt:Min>0</tt:Min><tt:Max>100</tt:Max></tt:Brightness>
+<tt:IrCutFilterModes>ON</tt:IrCutFilterModes><tt:IrCutFilterModes>AUTO</tt:IrCutFilterModes>
+<tt:Exposure><tt:Mode>AUTO</tt:Mode><tt:Mode>MANUAL</tt:Mode><tt:Gain><tt:Min>0</tt:Min><tt:Max>12</tt:Max></tt:Gain></tt:Exposure>
+</ImagingOptions></GetOptionsResponse>`)
+	srv.Fail("GetMoveOptions", onviftest.Fault(soap.ErrNoImagingForSource, "no focus"))
+	srv.Respond("GetConfigurationOptions", `<tptz:GetConfigurationOptionsResponse><tptz:PTZConfigurationOptions>
+<tt:Spaces><tt:ContinuousPanTiltVelocitySpace><tt:URI>http://www.onvif.org/ver10/tptz/PanTiltSpaces/VelocityGenericSpace</tt:URI>
+<tt:XRange><tt:Min>-1</tt:Min><tt:Max>1</tt:Max></tt:XRange><tt:YRange><tt:Min>-0.5</tt:Min><tt:Max>0.5</tt:Max></tt:YRange></tt:ContinuousPanTiltVelocitySpace></tt:Spaces>
+<tt:PTZTimeout><tt:Min>PT1S</tt:Min><tt:Max>PT1M</tt:Max></tt:PTZTimeout>
+</tptz:PTZConfigurationOptions></tptz:GetConfigurationOptionsResponse>`)
+
+	services := onvif.Services{}
+	for _, ns := range []string{onvif.NamespaceMedia, onvif.NamespaceImaging, onvif.NamespacePTZ} {
+		services = append(services, &onvif.Service{Namespace: ns, URL: srv.URL})
+	}
+	doc, err := controls.Describe(context.Background(), &onvif.Device{Client: new(onvif.Client), Services: services})
+	if err != nil {
+		t.Fatalf("could not describe device: %v", err)
+	}
+
+	format := func(s *controls.Source) string {
+		out := fmt.Sprintf("%s %v", s.Token, s.Operations)
+		for _, c := range s.Controls {
+			out += fmt.Sprintf(" %s:%s%v%s", c.Name, c.Kind, c.Values, c.Unit)
+			if c.Kind == controls.KindRange {
+				out += fmt.Sprintf("[%g,%g]", c.Min, c.Max)
+			}
+		}
+		return out
+	}
+
+	if len(doc.Imaging) != 1 {
+		t.Fatalf("expected one imaging source, got %d", len(doc.Imaging))
+	}
+	if s, expected := format(doc.Imaging[0]), "source [SetImagingSettings] Brightness:range[][0,100] IrCutFilter:enum[ON AUTO] "+
+		"Exposure/Mode:enum[AUTO MANUAL] Exposure/Gain:range[][0,12]"; s != expected {
+		t.Errorf("expected imaging source:\n%s\ngot:\n%s", expected, s)
+	}
+
+	if len(doc.PTZ) != 1 {
+		t.Fatalf("expected one PTZ source, got %d", len(doc.PTZ))
+	}
+	if s, expected := format(doc.PTZ[0]), "ptzc [ContinuousMove Stop] ContinuousMove/PanTilt/X:range[][-1,1] ContinuousMove/PanTilt/Y:range[][-0.5,0.5] "+
+		"ContinuousMove/Timeout:range[]s[1,60]"; s != expected {
+		t.Errorf("expected PTZ source:\n%s\ngot:\n%s", expected, s)
+	}
+}
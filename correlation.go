@@ -0,0 +1,33 @@
+package onvif
+
+import (
+	"fmt"
+	"io"
+)
+
+// RequestError wraps an error returned by Client.Do with the correlation ID of the request
+type RequestError struct {
+	CorrelationID string
+	Err           error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("request %s: %s", e.CorrelationID, e.Err.Error())
+}
+
+// Unwrap allows RequestError to be used with errors.Is and errors.As
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// newCorrelationID returns a random (version 4) UUID read from random
+func newCorrelationID(random io.Reader) (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return "", fmt.Errorf("could not generate uuid: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
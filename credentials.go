@@ -0,0 +1,87 @@
+package onvif
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/icholy/digest"
+)
+
+// SecretProvider provides credentials on demand, so passwords don't need to be held in a long-lived Client
+type SecretProvider interface {
+	// Credentials returns the username and password to use for a request. It's called for each request, so the Client doesn't hold the password between requests.
+	// The Client zeroes password when the request is finished, so implementations must return a new copy for each call.
+	// WS-Security password digests are computed from password directly. HTTP digest and basic authentication and WS-Security PasswordText headers
+	// need the password as a string, so a short-lived copy is made for those requests. If username or password is empty, the request is not authenticated
+	Credentials() (username string, password []byte, err error)
+}
+
+type credentialsKey struct{}
+
+// credentials are the credentials for a single request. password is a copy owned by the request, which is zeroed by release
+type credentials struct {
+	username string
+	password []byte
+}
+
+// release zeroes the password. It's safe to call on nil credentials
+func (c *credentials) release() {
+	if c == nil {
+		return
+	}
+	for i := range c.password {
+		c.password[i] = 0
+	}
+}
+
+// clientCredentials returns the Client's credentials or nil if none are configured. The caller should call release when the request is finished
+func (c *Client) clientCredentials() (*credentials, error) {
+	if c.Secrets != nil {
+		username, password, err := c.Secrets.Credentials()
+		if err != nil {
+			return nil, fmt.Errorf("could not get credentials: %w", err)
+		}
+		cred := &credentials{username: username, password: password}
+		if username == "" || len(password) == 0 {
+			cred.release()
+			return nil, nil
+		}
+		return cred, nil
+	}
+
+	if c.Username == "" || c.Password == "" {
+		return nil, nil
+	}
+
+	return &credentials{username: c.Username, password: []byte(c.Password)}, nil
+}
+
+// credentials returns the credentials (or nil if none are configured) and AuthMode to use for r. The caller should call release when the request is finished
+func (c *Client) credentials(r *Request) (*credentials, AuthMode, error) {
+	if r.noAuth {
+		return nil, r.AuthMode, nil
+	}
+	if r.Username != "" && r.Password != "" {
+		return &credentials{username: r.Username, password: []byte(r.Password)}, c.requestAuthMode(r), nil
+	}
+
+	cred, err := c.clientCredentials()
+	return cred, c.CurrentAuthMode(), err
+}
+
+// digestCredentials sets the digest credentials from the request context, if set
+func digestCredentials(r *http.Request, chal *digest.Challenge, opts digest.Options) (*digest.Credentials, error) {
+	if cred, ok := r.Context().Value(credentialsKey{}).(*credentials); ok {
+		opts.Username = cred.username
+		opts.Password = string(cred.password)
+	}
+	return digest.Digest(chal, opts)
+}
+
+var redactRegexp = regexp.MustCompile(`(<(?:[\w-]+:)?(?:Password|Nonce)\b[^>]*>)[^<]*(</)`)
+
+// redact returns buf with WS-Security passwords and nonces removed
+func redact(buf []byte) []byte {
+	return redactRegexp.ReplaceAll(buf, []byte("${1}[REDACTED]${2}"))
+}
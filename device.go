@@ -0,0 +1,142 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Device is an ONVIF device with its services discovered once, so callers don't need to pass service URLs around.
+// Service clients are created with each service package's FromDevice function, e.g. media.FromDevice(dev), ptz.FromDevice(dev), or events.FromDevice(dev).
+// They aren't Device methods since the service packages import this package
+type Device struct {
+	*Client
+	// Addr is the address the Device was created with. See GetServices
+	Addr string
+	// Services are the device's services returned by GetServices
+	Services Services
+
+	// supportMu protects supported
+	supportMu sync.Mutex
+	// supported caches Supports results by namespace and operation
+	supported map[string]bool
+}
+
+// NewDevice calls GetServices on the device at addr using c, returning a Device with the services cached.
+// addr is the host:port pair of the device, or a full URL. See GetServices.
+// Unless c.Quirks is already set or c.DisableQuirkDetection is true, the quirks profile matching the device's manufacturer and model
+// (from GetDeviceInformation) is applied to c first. Detection is best effort: if GetDeviceInformation fails, no quirks are applied
+func NewDevice(ctx context.Context, c *Client, addr string) (*Device, error) {
+	if !c.DisableQuirkDetection && c.CurrentQuirks() == nil {
+		c.detectQuirks(ctx, addr)
+	}
+
+	services, err := c.GetServicesContext(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not get services: %w", err)
+	}
+	return &Device{Client: c, Addr: addr, Services: services}, nil
+}
+
+// HasService returns true if the device has the service with the given namespace
+func (d *Device) HasService(namespace string) bool {
+	return d.Services.URL(namespace) != ""
+}
+
+// ErrProbeUnsafe is returned (wrapped) by Device.Supports for operations it won't send a trial call for
+var ErrProbeUnsafe = errors.New("operation can't be probed safely")
+
+// probe is an empty request for an operation, used by Device.Supports
+type probe struct {
+	XMLName xml.Name
+}
+
+// Supports returns true if the device supports the operation (e.g. GetProfiles) of the service with the given namespace.
+// It sends a trial call of the operation without arguments: if the device returns an ActionNotSupported or UnknownAction fault,
+// the operation isn't supported. Any other response, including other faults (e.g. for the missing arguments), means it's supported.
+// Only Get operations are probed, since a trial call of other operations could change the device's state; for others ErrProbeUnsafe is returned (wrapped).
+// If the device doesn't have the service, false is returned without a request. Results are cached for the life of the Device,
+// but errors (e.g. network or authorization errors) aren't
+func (d *Device) Supports(ctx context.Context, namespace, operation string) (bool, error) {
+	service := d.Services.Find(namespace)
+	if service == nil {
+		return false, nil
+	}
+	if !strings.HasPrefix(operation, "Get") {
+		return false, fmt.Errorf("could not probe %s: %w", operation, ErrProbeUnsafe)
+	}
+
+	key := service.Namespace + " " + operation
+	d.supportMu.Lock()
+	supported, ok := d.supported[key]
+	d.supportMu.Unlock()
+	if ok {
+		return supported, nil
+	}
+
+	_, err := d.DoContext(ctx, &Request{
+		URL:  service.URL,
+		Body: &probe{XMLName: xml.Name{Space: service.Namespace, Local: operation}},
+	})
+	var f *soap.Fault
+	switch {
+	case errors.Is(err, soap.ErrActionNotSupported) || errors.Is(err, soap.ErrUnknownAction):
+		supported = false
+	case err == nil || errors.As(err, &f) && !errors.Is(err, soap.ErrNotAuthorized):
+		supported = true
+	default:
+		return false, fmt.Errorf("could not probe %s: %w", operation, err)
+	}
+
+	d.supportMu.Lock()
+	if d.supported == nil {
+		d.supported = make(map[string]bool)
+	}
+	d.supported[key] = supported
+	d.supportMu.Unlock()
+	return supported, nil
+}
+
+// Service returns a ServiceClient for the service with the given namespace.
+// namespaces will be added to the SOAP envelope of each request. See NewServiceClient
+func (d *Device) Service(namespace string, namespaces soap.Namespaces) (*ServiceClient, error) {
+	return NewServiceClient(d.Client, d.Services, namespace, namespaces)
+}
+
+// getDeviceInformation is an ONVIF GetDeviceInformation operation. See device.Client.GetDeviceInformation for the full response
+type getDeviceInformation struct {
+	XMLName xml.Name `xml:"tds:GetDeviceInformation"`
+}
+
+type getDeviceInformationResponse struct {
+	Manufacturer string
+	Model        string
+}
+
+// detectQuirks applies the quirks profile matching the manufacturer and model of the device at addr, returning the applied quirks or nil if none match
+func (c *Client) detectQuirks(ctx context.Context, addr string) (*Quirks, error) {
+	env, err := c.DoContext(ctx, &Request{
+		URL:        c.deviceServiceURL(addr),
+		Namespaces: soap.Namespaces{"tds": NamespaceDevice},
+		Body:       &getDeviceInformation{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get device information: %w", err)
+	}
+
+	info := new(getDeviceInformationResponse)
+	if err = env.Body.Unmarshal(info); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+	}
+
+	q := LookupQuirks(info.Manufacturer, info.Model)
+	if q != nil {
+		c.ApplyQuirks(q)
+	}
+	return q, nil
+}
@@ -0,0 +1,74 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+)
+
+// AuxiliaryCommand is an ONVIF AuxiliaryData command
+type AuxiliaryCommand string
+
+// Common ONVIF auxiliary commands. Devices may support other, vendor specific commands.
+// See GetAuxiliaryCommands for the commands supported by a device
+const (
+	AuxiliaryCommandWiperOn             AuxiliaryCommand = "tt:Wiper|On"
+	AuxiliaryCommandWiperOff            AuxiliaryCommand = "tt:Wiper|Off"
+	AuxiliaryCommandWasherOn            AuxiliaryCommand = "tt:Washer|On"
+	AuxiliaryCommandWasherOff           AuxiliaryCommand = "tt:Washer|Off"
+	AuxiliaryCommandWashingProcedureOn  AuxiliaryCommand = "tt:WashingProcedure|On"
+	AuxiliaryCommandWashingProcedureOff AuxiliaryCommand = "tt:WashingProcedure|Off"
+	AuxiliaryCommandIRLampOn            AuxiliaryCommand = "tt:IRLamp|On"
+	AuxiliaryCommandIRLampOff           AuxiliaryCommand = "tt:IRLamp|Off"
+	AuxiliaryCommandIRLampAuto          AuxiliaryCommand = "tt:IRLamp|Auto"
+)
+
+// SendAuxiliaryCommand is an ONVIF SendAuxiliaryCommand operation
+type SendAuxiliaryCommand struct {
+	XMLName          xml.Name         `xml:"tds:SendAuxiliaryCommand"`
+	AuxiliaryCommand AuxiliaryCommand `xml:"tds:AuxiliaryCommand"`
+}
+
+// SendAuxiliaryCommandResponse is an ONVIF SendAuxiliaryCommandResponse response
+type SendAuxiliaryCommandResponse struct {
+	AuxiliaryCommandResponse string
+}
+
+// SendAuxiliaryCommand sends the auxiliary command (e.g. IR lamp or wiper control) to the device and returns the device's response, if any.
+// This is the device management service command, used by fixed cameras; PTZ devices may instead expose auxiliary commands through the PTZ service
+func (c *Client) SendAuxiliaryCommand(cmd AuxiliaryCommand) (string, error) {
+	return c.SendAuxiliaryCommandContext(context.Background(), cmd)
+}
+
+// SendAuxiliaryCommandContext is like SendAuxiliaryCommand, but ctx controls the request
+func (c *Client) SendAuxiliaryCommandContext(ctx context.Context, cmd AuxiliaryCommand) (string, error) {
+	resp := new(SendAuxiliaryCommandResponse)
+	if err := c.CallContext(ctx, &SendAuxiliaryCommand{AuxiliaryCommand: cmd}, resp); err != nil {
+		return "", err
+	}
+	return resp.AuxiliaryCommandResponse, nil
+}
+
+// GetAuxiliaryCommands returns the auxiliary commands supported by the device, as reported by GetServiceCapabilities
+func (c *Client) GetAuxiliaryCommands() ([]AuxiliaryCommand, error) {
+	return c.GetAuxiliaryCommandsContext(context.Background())
+}
+
+// GetAuxiliaryCommandsContext is like GetAuxiliaryCommands, but ctx controls the request(s)
+func (c *Client) GetAuxiliaryCommandsContext(ctx context.Context) ([]AuxiliaryCommand, error) {
+	caps, err := c.GetServiceCapabilitiesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if caps == nil || caps.Misc == nil {
+		return nil, nil
+	}
+
+	var cmds []AuxiliaryCommand
+	for _, cmd := range strings.Fields(caps.Misc.AuxiliaryCommands) {
+		cmds = append(cmds, AuxiliaryCommand(cmd))
+	}
+
+	return cmds, nil
+}
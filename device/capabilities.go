@@ -0,0 +1,98 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// NetworkCapabilities is an ONVIF device NetworkCapabilities type
+type NetworkCapabilities struct {
+	IPFilter            bool `xml:"IPFilter,attr"`
+	ZeroConfiguration   bool `xml:"ZeroConfiguration,attr"`
+	IPVersion6          bool `xml:"IPVersion6,attr"`
+	DynDNS              bool `xml:"DynDNS,attr"`
+	Dot11Configuration  bool `xml:"Dot11Configuration,attr"`
+	Dot1XConfigurations int  `xml:"Dot1XConfigurations,attr"`
+	HostnameFromDHCP    bool `xml:"HostnameFromDHCP,attr"`
+	NTP                 int  `xml:"NTP,attr"`
+	DHCPv6              bool `xml:"DHCPv6,attr"`
+}
+
+// SecurityCapabilities is an ONVIF device SecurityCapabilities type
+type SecurityCapabilities struct {
+	TLS10                bool `xml:"TLS1.0,attr"`
+	TLS11                bool `xml:"TLS1.1,attr"`
+	TLS12                bool `xml:"TLS1.2,attr"`
+	OnboardKeyGeneration bool `xml:"OnboardKeyGeneration,attr"`
+	AccessPolicyConfig   bool `xml:"AccessPolicyConfig,attr"`
+	DefaultAccessPolicy  bool `xml:"DefaultAccessPolicy,attr"`
+	Dot1X                bool `xml:"Dot1X,attr"`
+	RemoteUserHandling   bool `xml:"RemoteUserHandling,attr"`
+	X509Token            bool `xml:"X.509Token,attr"`
+	SAMLToken            bool `xml:"SAMLToken,attr"`
+	KerberosToken        bool `xml:"KerberosToken,attr"`
+	UsernameToken        bool `xml:"UsernameToken,attr"`
+	HTTPDigest           bool `xml:"HttpDigest,attr"`
+	RELToken             bool `xml:"RELToken,attr"`
+	MaxUsers             int  `xml:"MaxUsers,attr"`
+	MaxUserNameLength    int  `xml:"MaxUserNameLength,attr"`
+	MaxPasswordLength    int  `xml:"MaxPasswordLength,attr"`
+}
+
+// SystemCapabilities is an ONVIF device SystemCapabilities type
+type SystemCapabilities struct {
+	DiscoveryResolve         bool `xml:"DiscoveryResolve,attr"`
+	DiscoveryBye             bool `xml:"DiscoveryBye,attr"`
+	RemoteDiscovery          bool `xml:"RemoteDiscovery,attr"`
+	SystemBackup             bool `xml:"SystemBackup,attr"`
+	SystemLogging            bool `xml:"SystemLogging,attr"`
+	FirmwareUpgrade          bool `xml:"FirmwareUpgrade,attr"`
+	HTTPFirmwareUpgrade      bool `xml:"HttpFirmwareUpgrade,attr"`
+	HTTPSystemBackup         bool `xml:"HttpSystemBackup,attr"`
+	HTTPSystemLogging        bool `xml:"HttpSystemLogging,attr"`
+	HTTPSupportInformation   bool `xml:"HttpSupportInformation,attr"`
+	StorageConfiguration     bool `xml:"StorageConfiguration,attr"`
+	MaxStorageConfigurations int  `xml:"MaxStorageConfigurations,attr"`
+	GeoLocationEntries       int  `xml:"GeoLocationEntries,attr"`
+	// AutoGeo and StorageTypesSupported are space separated lists
+	AutoGeo               string `xml:"AutoGeo,attr"`
+	StorageTypesSupported string `xml:"StorageTypesSupported,attr"`
+}
+
+// MiscCapabilities is an ONVIF device MiscCapabilities type
+type MiscCapabilities struct {
+	// AuxiliaryCommands is a space separated list of auxiliary commands. See GetAuxiliaryCommands
+	AuxiliaryCommands string `xml:"AuxiliaryCommands,attr"`
+}
+
+// Capabilities is an ONVIF DeviceServiceCapabilities type
+type Capabilities struct {
+	Network  *NetworkCapabilities
+	Security *SecurityCapabilities
+	System   *SystemCapabilities
+	Misc     *MiscCapabilities
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tds:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the device management service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
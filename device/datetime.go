@@ -0,0 +1,145 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+	"time"
+)
+
+// DateTimeType is an ONVIF SetDateTimeType
+type DateTimeType string
+
+// ONVIF date time types
+const (
+	DateTimeTypeManual DateTimeType = "Manual"
+	DateTimeTypeNTP    DateTimeType = "NTP"
+)
+
+// DateTime is an ONVIF DateTime
+type DateTime struct {
+	Hour   int `xml:"Time>Hour"`
+	Minute int `xml:"Time>Minute"`
+	Second int `xml:"Time>Second"`
+	Year   int `xml:"Date>Year"`
+	Month  int `xml:"Date>Month"`
+	Day    int `xml:"Date>Day"`
+}
+
+// NewDateTime returns a DateTime for t in UTC
+func NewDateTime(t time.Time) *DateTime {
+	t = t.UTC()
+	return &DateTime{
+		Hour:   t.Hour(),
+		Minute: t.Minute(),
+		Second: t.Second(),
+		Year:   t.Year(),
+		Month:  int(t.Month()),
+		Day:    t.Day(),
+	}
+}
+
+// Time returns the DateTime as a time.Time in loc
+func (d *DateTime) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, d.Hour, d.Minute, d.Second, 0, loc)
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (d *DateTime) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	v := struct {
+		Hour   int `xml:"tt:Time>tt:Hour"`
+		Minute int `xml:"tt:Time>tt:Minute"`
+		Second int `xml:"tt:Time>tt:Second"`
+		Year   int `xml:"tt:Date>tt:Year"`
+		Month  int `xml:"tt:Date>tt:Month"`
+		Day    int `xml:"tt:Date>tt:Day"`
+	}{d.Hour, d.Minute, d.Second, d.Year, d.Month, d.Day}
+	return enc.EncodeElement(v, start)
+}
+
+// GetSystemDateAndTime is an ONVIF GetSystemDateAndTime operation
+type GetSystemDateAndTime struct {
+	XMLName xml.Name `xml:"tds:GetSystemDateAndTime"`
+}
+
+// SystemDateAndTime is an ONVIF SystemDateAndTime
+type SystemDateAndTime struct {
+	DateTimeType    DateTimeType
+	DaylightSavings bool
+	// TZ is the POSIX time zone, e.g. CST6CDT,M3.2.0/2:00:00,M11.1.0/2:00:00
+	TZ            string `xml:"TimeZone>TZ"`
+	UTCDateTime   *DateTime
+	LocalDateTime *DateTime
+}
+
+// GetSystemDateAndTimeResponse is an ONVIF GetSystemDateAndTimeResponse response
+type GetSystemDateAndTimeResponse struct {
+	SystemDateAndTime *SystemDateAndTime
+}
+
+// GetSystemDateAndTime returns the device's date, time, and time zone
+func (c *Client) GetSystemDateAndTime() (*SystemDateAndTime, error) {
+	return c.GetSystemDateAndTimeContext(context.Background())
+}
+
+// GetSystemDateAndTimeContext is like GetSystemDateAndTime, but ctx controls the request
+func (c *Client) GetSystemDateAndTimeContext(ctx context.Context) (*SystemDateAndTime, error) {
+	resp := new(GetSystemDateAndTimeResponse)
+	if err := c.CallContext(ctx, &GetSystemDateAndTime{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.SystemDateAndTime, nil
+}
+
+// TimeZone is an ONVIF TimeZone
+type TimeZone struct {
+	// TZ is the POSIX time zone
+	TZ string `xml:"tt:TZ"`
+}
+
+// SetSystemDateAndTime is an ONVIF SetSystemDateAndTime operation
+type SetSystemDateAndTime struct {
+	XMLName         xml.Name     `xml:"tds:SetSystemDateAndTime"`
+	DateTimeType    DateTimeType `xml:"tds:DateTimeType"`
+	DaylightSavings bool         `xml:"tds:DaylightSavings"`
+	// TimeZone, if set, changes the time zone
+	TimeZone *TimeZone `xml:"tds:TimeZone,omitempty"`
+	// UTCDateTime is required if DateTimeType is DateTimeTypeManual
+	UTCDateTime *DateTime `xml:"tds:UTCDateTime,omitempty"`
+}
+
+// SetSystemDateAndTimeResponse is an ONVIF SetSystemDateAndTimeResponse response
+type SetSystemDateAndTimeResponse struct{}
+
+// SetSystemDateAndTime sets the device's date, time, and time zone
+func (c *Client) SetSystemDateAndTime(req *SetSystemDateAndTime) error {
+	return c.SetSystemDateAndTimeContext(context.Background(), req)
+}
+
+// SetSystemDateAndTimeContext is like SetSystemDateAndTime, but ctx controls the request
+func (c *Client) SetSystemDateAndTimeContext(ctx context.Context, req *SetSystemDateAndTime) error {
+	return c.CallContext(ctx, req, nil)
+}
+
+// SystemReboot is an ONVIF SystemReboot operation
+type SystemReboot struct {
+	XMLName xml.Name `xml:"tds:SystemReboot"`
+}
+
+// SystemRebootResponse is an ONVIF SystemRebootResponse response
+type SystemRebootResponse struct {
+	Message string
+}
+
+// SystemReboot reboots the device, returning the device's message, e.g. the expected reboot time
+func (c *Client) SystemReboot() (string, error) {
+	return c.SystemRebootContext(context.Background())
+}
+
+// SystemRebootContext is like SystemReboot, but ctx controls the request
+func (c *Client) SystemRebootContext(ctx context.Context) (string, error) {
+	resp := new(SystemRebootResponse)
+	if err := c.CallContext(ctx, &SystemReboot{}, resp); err != nil {
+		return "", err
+	}
+	return resp.Message, nil
+}
@@ -0,0 +1,26 @@
+// Package device implements typed operations for the ONVIF device management service
+package device
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF device management service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new device management service client using c to make requests to the device service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceDevice, soap.Namespaces{"tds": onvif.NamespaceDevice, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new device management service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
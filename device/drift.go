@@ -0,0 +1,115 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+)
+
+// DefaultDriftThreshold is the drift a DriftPolicy tolerates if its Threshold isn't set
+const DefaultDriftThreshold = 5 * time.Second
+
+// ErrNoDeviceTime is returned (wrapped) by CheckDrift if the device doesn't return its UTC time
+var ErrNoDeviceTime = errors.New("no device UTC time")
+
+// DriftPolicy configures how CheckDrift handles a device clock that has drifted from the local clock.
+// Drift breaks WS-Security authentication (timestamps are only accepted within a few seconds on some devices) and recording timestamps
+type DriftPolicy struct {
+	// Threshold is the drift beyond which the policy acts. If zero, DefaultDriftThreshold is used
+	Threshold time.Duration
+	// Correct, if true, corrects drift beyond Threshold. Otherwise drift is only reported
+	Correct bool
+	// NTPServers, if set, are configured with SetNTP and the device is switched to NTP time to correct drift.
+	// Otherwise the device's time is set manually to the local time, which also switches it off NTP
+	NTPServers []*NetworkHost
+	// OnDrift, if set, is called with each drift beyond Threshold, after any correction
+	OnDrift func(d *Drift)
+}
+
+// Drift is a measurement of a device's clock
+type Drift struct {
+	// Offset is the device's time minus the local time, to the second
+	Offset time.Duration
+	// DateTimeType is how the device's time was set when it was measured
+	DateTimeType DateTimeType
+	// Exceeded is true if the drift was beyond the policy's Threshold
+	Exceeded bool
+	// Corrected is true if the device's time was corrected
+	Corrected bool
+}
+
+// CheckDrift measures the drift of the device's clock from the local clock (onvif.Client.Clock) and applies p to it. p may be nil to only measure.
+// After a correction, the Client's TimeOffset is resynced with SyncTime. Run it periodically for each device, e.g. with onvif.Broadcast
+func (c *Client) CheckDrift(ctx context.Context, p *DriftPolicy) (*Drift, error) {
+	if p == nil {
+		p = &DriftPolicy{}
+	}
+	clock := c.Clock
+	if clock == nil {
+		clock = onvif.SystemClock
+	}
+
+	start := clock.Now()
+	dt, err := c.GetSystemDateAndTimeContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get system date and time: %w", err)
+	}
+	end := clock.Now()
+	if dt == nil || dt.UTCDateTime == nil {
+		return nil, fmt.Errorf("could not get system date and time: %w", ErrNoDeviceTime)
+	}
+
+	// compare to the midpoint of the request
+	local := start.Add(end.Sub(start) / 2)
+	d := &Drift{Offset: dt.UTCDateTime.Time(time.UTC).Sub(local).Truncate(time.Second), DateTimeType: dt.DateTimeType}
+
+	threshold := p.Threshold
+	if threshold <= 0 {
+		threshold = DefaultDriftThreshold
+	}
+	offset := d.Offset
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset <= threshold {
+		return d, nil
+	}
+	d.Exceeded = true
+
+	if p.Correct {
+		if err = c.correctDrift(ctx, p, dt, clock); err != nil {
+			return d, err
+		}
+		d.Corrected = true
+		if err = c.Client.SyncTimeContext(ctx, c.URL); err != nil {
+			return d, fmt.Errorf("could not sync time: %w", err)
+		}
+	}
+
+	if p.OnDrift != nil {
+		p.OnDrift(d)
+	}
+	return d, nil
+}
+
+// correctDrift sets the device's time using p, keeping its time zone and daylight savings setting
+func (c *Client) correctDrift(ctx context.Context, p *DriftPolicy, dt *SystemDateAndTime, clock onvif.Clock) error {
+	req := &SetSystemDateAndTime{DaylightSavings: dt.DaylightSavings}
+	if len(p.NTPServers) > 0 {
+		if err := c.SetNTPContext(ctx, false, p.NTPServers); err != nil {
+			return fmt.Errorf("could not set NTP: %w", err)
+		}
+		req.DateTimeType = DateTimeTypeNTP
+	} else {
+		req.DateTimeType = DateTimeTypeManual
+		req.UTCDateTime = NewDateTime(clock.Now())
+	}
+
+	if err := c.SetSystemDateAndTimeContext(ctx, req); err != nil {
+		return fmt.Errorf("could not set system date and time: %w", err)
+	}
+	return nil
+}
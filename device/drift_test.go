@@ -0,0 +1,108 @@
+package device_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/onviftest"
+)
+
+func TestCheckDrift(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+
+	// the device's clock is a minute behind until it's set
+	var (
+		mu     sync.Mutex
+		offset = -time.Minute
+	)
+	srv.Handle("GetSystemDateAndTime", func(*onviftest.Request) (string, error) {
+		mu.Lock()
+		now := time.Now().UTC().Add(offset)
+		mu.Unlock()
+		return fmt.Sprintf(`<tds:GetSystemDateAndTimeResponse><tds:SystemDateAndTime>
+<tt:DateTimeType>Manual</tt:DateTimeType><tt:DaylightSavings>true</tt:DaylightSavings>
+<tt:UTCDateTime><tt:Time><tt:Hour>%d</tt:Hour><tt:Minute>%d</tt:Minute><tt:Second>%d</tt:Second></tt:Time>
+<tt:Date><tt:Year>%d</tt:Year><tt:Month>%d</tt:Month><tt:Day>%d</tt:Day></tt:Date></tt:UTCDateTime>
+</tds:SystemDateAndTime></tds:GetSystemDateAndTimeResponse>`, now.Hour(), now.Minute(), now.Second(), now.Year(), now.Month(), now.Day()), nil
+	})
+	srv.Handle("SetSystemDateAndTime", func(*onviftest.Request) (string, error) {
+		mu.Lock()
+		offset = 0
+		mu.Unlock()
+		return `<tds:SetSystemDateAndTimeResponse/>`, nil
+	})
+	srv.Respond("SetNTP", `<tds:SetNTPResponse/>`)
+
+	oc := &onvif.Client{Username: "admin", Password: "password"}
+	dev, err := onvif.NewDevice(context.Background(), oc, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	c, err := device.FromDevice(dev)
+	if err != nil {
+		t.Fatalf("could not create device client: %v", err)
+	}
+
+	var reported []*device.Drift
+	p := &device.DriftPolicy{Threshold: 10 * time.Second, OnDrift: func(d *device.Drift) { reported = append(reported, d) }}
+	d, err := c.CheckDrift(context.Background(), p)
+	if err != nil || !d.Exceeded || d.Corrected || d.Offset > -55*time.Second || d.Offset < -65*time.Second {
+		t.Errorf("unexpected drift: %#v, %v", d, err)
+	}
+
+	p.Correct = true
+	if d, err = c.CheckDrift(context.Background(), p); err != nil || !d.Exceeded || !d.Corrected {
+		t.Errorf("unexpected corrected drift: %#v, %v", d, err)
+	}
+	if oc.TimeOffset < -2*time.Second || oc.TimeOffset > 2*time.Second {
+		t.Errorf("expected time offset to be resynced, got %v", oc.TimeOffset)
+	}
+
+	// the device's clock is correct now
+	if d, err = c.CheckDrift(context.Background(), p); err != nil || d.Exceeded {
+		t.Errorf("unexpected drift after correction: %#v, %v", d, err)
+	}
+	if len(reported) != 2 {
+		t.Errorf("expected 2 reported drifts, got %d", len(reported))
+	}
+
+	// correct with NTP
+	mu.Lock()
+	offset = time.Minute
+	mu.Unlock()
+	p.NTPServers = []*device.NetworkHost{{Type: device.NetworkHostTypeDNS, DNSname: "pool.ntp.org"}}
+	if d, err = c.CheckDrift(context.Background(), p); err != nil || !d.Corrected {
+		t.Errorf("unexpected corrected drift: %#v, %v", d, err)
+	}
+
+	var sets []string
+	for _, r := range srv.Requests() {
+		switch r.Operation {
+		case "SetSystemDateAndTime":
+			body := string(r.Body)
+			if !strings.Contains(body, "<tds:DaylightSavings>true</tds:DaylightSavings>") {
+				t.Errorf("expected daylight savings to be kept: %s", body)
+			}
+			if strings.Contains(body, "<tds:DateTimeType>Manual</tds:DateTimeType>") && strings.Contains(body, "<tds:UTCDateTime>") {
+				sets = append(sets, "Manual")
+			} else if strings.Contains(body, "<tds:DateTimeType>NTP</tds:DateTimeType>") {
+				sets = append(sets, "NTP")
+			}
+		case "SetNTP":
+			if !strings.Contains(string(r.Body), "pool.ntp.org") {
+				t.Errorf("unexpected NTP servers: %s", r.Body)
+			}
+			sets = append(sets, "SetNTP")
+		}
+	}
+	if fmt.Sprint(sets) != "[Manual SetNTP NTP]" {
+		t.Errorf("unexpected corrections: %v", sets)
+	}
+}
@@ -0,0 +1,136 @@
+package device
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Fingerprint identifies a device's description independently of the address it was reached at,
+// so a device found via multiple discovery paths or XAddrs can be deduplicated.
+// The fingerprint changes when the device's firmware is upgraded; use EndpointUUID or MACAddresses to follow a device across upgrades
+type Fingerprint struct {
+	// EndpointUUID is the normalized endpoint reference, or empty if the device doesn't support GetEndpointReference. See EndpointUUID
+	EndpointUUID string
+	// Manufacturer, Model, FirmwareVersion, SerialNumber, and HardwareID are from GetDeviceInformation
+	Manufacturer    string
+	Model           string
+	FirmwareVersion string
+	SerialNumber    string
+	HardwareID      string
+	// MACAddresses is the normalized (lower case, no separators) hardware addresses of the device's network interfaces, sorted
+	MACAddresses []string
+	// Scopes is the device's fixed scopes, sorted. Configurable scopes are excluded because they can change
+	Scopes []string
+}
+
+// NewFingerprint returns a Fingerprint from its parts, normalizing them so the result is stable. info may be nil
+func NewFingerprint(endpointRef string, info *GetDeviceInformationResponse, macs []string, scopes []string) *Fingerprint {
+	f := new(Fingerprint)
+	if info != nil {
+		f.Manufacturer = strings.TrimSpace(info.Manufacturer)
+		f.Model = strings.TrimSpace(info.Model)
+		f.FirmwareVersion = strings.TrimSpace(info.FirmwareVersion)
+		f.SerialNumber = strings.TrimSpace(info.SerialNumber)
+		f.HardwareID = strings.TrimSpace(info.HardwareID)
+	}
+	if endpointRef != "" {
+		f.EndpointUUID = EndpointUUID(endpointRef)
+	}
+
+	for _, mac := range macs {
+		mac = strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(mac)))
+		if mac != "" {
+			f.MACAddresses = append(f.MACAddresses, mac)
+		}
+	}
+	f.MACAddresses = sortUnique(f.MACAddresses)
+
+	for _, scope := range scopes {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			f.Scopes = append(f.Scopes, scope)
+		}
+	}
+	f.Scopes = sortUnique(f.Scopes)
+
+	return f
+}
+
+// sortUnique sorts s and removes duplicates
+func sortUnique(s []string) []string {
+	sort.Strings(s)
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Hash returns a stable, hex encoded SHA-256 hash of the fingerprint
+func (f *Fingerprint) Hash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "endpoint=%s\nmanufacturer=%s\nmodel=%s\nfirmware=%s\nserial=%s\nhardware=%s\n",
+		f.EndpointUUID, f.Manufacturer, f.Model, f.FirmwareVersion, f.SerialNumber, f.HardwareID)
+	for _, mac := range f.MACAddresses {
+		fmt.Fprintf(h, "mac=%s\n", mac)
+	}
+	for _, scope := range f.Scopes {
+		fmt.Fprintf(h, "scope=%s\n", scope)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fingerprint returns the device's fingerprint.
+// GetEndpointReference, GetNetworkInterfaces, and GetScopes faults are ignored, since not all devices support them
+func (c *Client) Fingerprint() (*Fingerprint, error) {
+	return c.FingerprintContext(context.Background())
+}
+
+// FingerprintContext is like Fingerprint, but ctx controls the request(s)
+func (c *Client) FingerprintContext(ctx context.Context) (*Fingerprint, error) {
+	info, err := c.GetDeviceInformationContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get device information: %w", err)
+	}
+
+	ref, err := c.GetEndpointReferenceContext(ctx)
+	if err != nil && !isFault(err) && !errors.Is(err, soap.ErrNoResponse) {
+		return nil, fmt.Errorf("could not get endpoint reference: %w", err)
+	}
+
+	var macs []string
+	ifaces, err := c.GetNetworkInterfacesContext(ctx)
+	if err != nil && !isFault(err) {
+		return nil, fmt.Errorf("could not get network interfaces: %w", err)
+	}
+	for _, iface := range ifaces {
+		macs = append(macs, iface.HwAddress)
+	}
+
+	var scopes []string
+	scopeList, err := c.GetScopesContext(ctx)
+	if err != nil && !isFault(err) {
+		return nil, fmt.Errorf("could not get scopes: %w", err)
+	}
+	for _, scope := range scopeList {
+		if scope.ScopeDef == ScopeDefinitionFixed {
+			scopes = append(scopes, scope.ScopeItem)
+		}
+	}
+
+	return NewFingerprint(ref, info, macs, scopes), nil
+}
+
+// isFault returns true if err wraps a *soap.Fault
+func isFault(err error) bool {
+	var f *soap.Fault
+	return errors.As(err, &f)
+}
@@ -0,0 +1,85 @@
+package device_test
+
+import (
+	"testing"
+
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/onviftest"
+)
+
+func TestFingerprintHash(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+	srv.Respond("GetNetworkInterfaces", `<tds:GetNetworkInterfacesResponse><tds:NetworkInterfaces token="eth0"><tt:Enabled>true</tt:Enabled>
+<tt:Info><tt:Name>eth0</tt:Name><tt:HwAddress>00:11:22:AA:BB:CC</tt:HwAddress></tt:Info></tds:NetworkInterfaces></tds:GetNetworkInterfacesResponse>`)
+	c := newClient(t, srv)
+
+	f, err := c.Fingerprint()
+	if err != nil {
+		t.Fatalf("could not get fingerprint: %v", err)
+	}
+	hash := f.Hash()
+
+	// identical responses give the same fingerprint
+	if f, err = c.Fingerprint(); err != nil {
+		t.Fatalf("could not get fingerprint: %v", err)
+	}
+	if f.Hash() != hash {
+		t.Errorf("expected stable hash %s, got %s", hash, f.Hash())
+	}
+
+	for _, test := range []struct {
+		name   string
+		info   *device.GetDeviceInformationResponse
+		macs   []string
+		scopes []string
+		same   bool
+	}{
+		{
+			name: "normalized",
+			info: &device.GetDeviceInformationResponse{
+				Manufacturer: " " + onviftest.Manufacturer, Model: onviftest.Model, FirmwareVersion: onviftest.FirmwareVersion,
+				SerialNumber: onviftest.SerialNumber + " ", HardwareID: onviftest.HardwareID,
+			},
+			macs: []string{"00-11-22-aa-bb-cc", "001122AABBCC"},
+			same: true,
+		},
+		{
+			name: "firmware",
+			info: &device.GetDeviceInformationResponse{
+				Manufacturer: onviftest.Manufacturer, Model: onviftest.Model, FirmwareVersion: onviftest.FirmwareVersion + ".1",
+				SerialNumber: onviftest.SerialNumber, HardwareID: onviftest.HardwareID,
+			},
+			macs: []string{"00:11:22:aa:bb:cc"},
+		},
+		{
+			name: "serial",
+			info: &device.GetDeviceInformationResponse{
+				Manufacturer: onviftest.Manufacturer, Model: onviftest.Model, FirmwareVersion: onviftest.FirmwareVersion,
+				SerialNumber: onviftest.SerialNumber + "1", HardwareID: onviftest.HardwareID,
+			},
+			macs: []string{"00:11:22:aa:bb:cc"},
+		},
+		{
+			name: "mac",
+			info: &device.GetDeviceInformationResponse{
+				Manufacturer: onviftest.Manufacturer, Model: onviftest.Model, FirmwareVersion: onviftest.FirmwareVersion,
+				SerialNumber: onviftest.SerialNumber, HardwareID: onviftest.HardwareID,
+			},
+			macs: []string{"00:11:22:aa:bb:cd"},
+		},
+		{
+			name: "scope",
+			info: &device.GetDeviceInformationResponse{
+				Manufacturer: onviftest.Manufacturer, Model: onviftest.Model, FirmwareVersion: onviftest.FirmwareVersion,
+				SerialNumber: onviftest.SerialNumber, HardwareID: onviftest.HardwareID,
+			},
+			macs:   []string{"00:11:22:aa:bb:cc"},
+			scopes: []string{"onvif://www.onvif.org/hardware/M1234"},
+		},
+	} {
+		if same := device.NewFingerprint("", test.info, test.macs, test.scopes).Hash() == hash; same != test.same {
+			t.Errorf("%s: expected same hash %v, got %v", test.name, test.same, same)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// FirmwareContentType is the content type firmware images are uploaded with
+const FirmwareContentType = "application/octet-stream"
+
+// UpgradeSystemFirmware is an ONVIF UpgradeSystemFirmware operation
+type UpgradeSystemFirmware struct {
+	XMLName  xml.Name        `xml:"tds:UpgradeSystemFirmware"`
+	Firmware *AttachmentData `xml:"tds:Firmware"`
+}
+
+// UpgradeSystemFirmwareResponse is an ONVIF UpgradeSystemFirmwareResponse response
+type UpgradeSystemFirmwareResponse struct {
+	Message string
+}
+
+// UpgradeSystemFirmware sends the firmware image inline in the request and returns the device's message.
+// This operation is deprecated in ONVIF; most devices support UpgradeFirmware instead
+func (c *Client) UpgradeSystemFirmware(firmware []byte) (string, error) {
+	return c.UpgradeSystemFirmwareContext(context.Background(), firmware)
+}
+
+// UpgradeSystemFirmwareContext is like UpgradeSystemFirmware, but ctx controls the request
+func (c *Client) UpgradeSystemFirmwareContext(ctx context.Context, firmware []byte) (string, error) {
+	resp := new(UpgradeSystemFirmwareResponse)
+	if err := c.CallContext(ctx, &UpgradeSystemFirmware{Firmware: &AttachmentData{ContentType: FirmwareContentType, Data: firmware}}, resp); err != nil {
+		return "", err
+	}
+	return resp.Message, nil
+}
+
+// StartFirmwareUpgrade is an ONVIF StartFirmwareUpgrade operation
+type StartFirmwareUpgrade struct {
+	XMLName xml.Name `xml:"tds:StartFirmwareUpgrade"`
+}
+
+// StartFirmwareUpgradeResponse is an ONVIF StartFirmwareUpgradeResponse response
+type StartFirmwareUpgradeResponse struct {
+	// UploadURI is the URI the firmware image must be POSTed to
+	UploadURI string `xml:"UploadUri"`
+	// UploadDelay and ExpectedDownTime are xsd:durations. See soap.ParseDuration
+	UploadDelay      string
+	ExpectedDownTime string
+}
+
+// StartFirmwareUpgrade prepares the device for a firmware upgrade and returns the upload URI. Most users should use UpgradeFirmware instead
+func (c *Client) StartFirmwareUpgrade() (*StartFirmwareUpgradeResponse, error) {
+	return c.StartFirmwareUpgradeContext(context.Background())
+}
+
+// StartFirmwareUpgradeContext is like StartFirmwareUpgrade, but ctx controls the request
+func (c *Client) StartFirmwareUpgradeContext(ctx context.Context) (*StartFirmwareUpgradeResponse, error) {
+	resp := new(StartFirmwareUpgradeResponse)
+	if err := c.CallContext(ctx, &StartFirmwareUpgrade{}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// parseDuration parses the optional xsd:duration s
+func parseDuration(s string) (time.Duration, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	return soap.ParseDuration(s)
+}
+
+// UpgradeFirmware upgrades the device's firmware with StartFirmwareUpgrade, waiting the requested delay before uploading the image from firmware.
+// progress (which may be nil) receives StageWaiting if the device requests a delay, StageUploading as the image is uploaded (see onvif.Client.Upload), and StageRestarting.
+// It returns how long the device expects to be unavailable while it installs the firmware and reboots. See WaitOnline
+func (c *Client) UpgradeFirmware(ctx context.Context, firmware io.ReadSeeker, progress onvif.Progress) (time.Duration, error) {
+	start, err := c.StartFirmwareUpgradeContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not start firmware upgrade: %w", err)
+	}
+
+	downtime, err := c.upload(ctx, start.UploadURI, start.UploadDelay, start.ExpectedDownTime, FirmwareContentType, firmware, progress)
+	if err != nil {
+		return 0, fmt.Errorf("could not upload firmware: %w", err)
+	}
+	return downtime, nil
+}
+
+// upload waits for the optional xsd:duration delay, uploads r to uri, and reports StageRestarting with the xsd:duration downtime, which is returned
+func (c *Client) upload(ctx context.Context, uri, delay, downtime, contentType string, r io.ReadSeeker, progress onvif.Progress) (time.Duration, error) {
+	wait, err := parseDuration(delay)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse upload delay: %w", err)
+	}
+	down, err := parseDuration(downtime)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse expected down time: %w", err)
+	}
+
+	if wait > 0 {
+		if err = c.WaitStage(ctx, progress, onvif.StageWaiting, wait); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = c.UploadContext(ctx, uri, contentType, r, onvif.UploadProgress(progress)); err != nil {
+		return 0, err
+	}
+
+	onvif.Report(progress, onvif.Status{Stage: onvif.StageRestarting, Remaining: down})
+	return down, nil
+}
+
+// WaitOnline waits for the device to become available after a long-running operation, e.g. UpgradeFirmware.
+// It waits for downtime, then calls GetSystemDateAndTime every interval until it succeeds.
+// progress (which may be nil) receives StageRestarting and StageDone
+func (c *Client) WaitOnline(ctx context.Context, downtime, interval time.Duration, progress onvif.Progress) error {
+	if err := c.WaitStage(ctx, progress, onvif.StageRestarting, downtime); err != nil {
+		return err
+	}
+
+	if err := c.Poll(ctx, interval, func(ctx context.Context) (bool, error) {
+		_, err := c.GetSystemDateAndTimeContext(ctx)
+		return err == nil, nil
+	}); err != nil {
+		return err
+	}
+
+	onvif.Report(progress, onvif.Status{Stage: onvif.StageDone})
+	return nil
+}
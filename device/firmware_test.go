@@ -0,0 +1,116 @@
+package device_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/onviftest"
+)
+
+// testClock is a Clock whose After fires immediately, recording the requested durations
+type testClock struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *testClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func TestUpgradeFirmware(t *testing.T) {
+	firmware := bytes.Repeat([]byte("firmware"), 1<<10)
+	var uploaded []byte
+	upload := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded, _ = io.ReadAll(r.Body)
+	}))
+	defer upload.Close()
+
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+	srv.Respond("StartFirmwareUpgrade", fmt.Sprintf(`<tds:StartFirmwareUpgradeResponse><tds:UploadUri>%s/firmware</tds:UploadUri>
+<tds:UploadDelay>PT5S</tds:UploadDelay><tds:ExpectedDownTime>PT2M</tds:ExpectedDownTime></tds:StartFirmwareUpgradeResponse>`, upload.URL))
+	srv.Respond("StartSystemRestore", fmt.Sprintf(`<tds:StartSystemRestoreResponse><tds:UploadUri>%s/restore</tds:UploadUri>
+<tds:ExpectedDownTime>PT30S</tds:ExpectedDownTime></tds:StartSystemRestoreResponse>`, upload.URL))
+
+	clock := new(testClock)
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{Username: "admin", Password: "password", Clock: clock}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	c, err := device.FromDevice(dev)
+	if err != nil {
+		t.Fatalf("could not create device client: %v", err)
+	}
+
+	var stages []onvif.Stage
+	var last onvif.Status
+	progress := onvif.ProgressFunc(func(s onvif.Status) {
+		if len(stages) == 0 || stages[len(stages)-1] != s.Stage {
+			stages = append(stages, s.Stage)
+		}
+		last = s
+	})
+
+	downtime, err := c.UpgradeFirmware(context.Background(), bytes.NewReader(firmware), progress)
+	if err != nil {
+		t.Fatalf("could not upgrade firmware: %v", err)
+	}
+	if downtime != 2*time.Minute || !bytes.Equal(uploaded, firmware) {
+		t.Errorf("unexpected downtime %v or %d bytes uploaded", downtime, len(uploaded))
+	}
+	if fmt.Sprint(stages) != "[waiting uploading restarting]" || last.Remaining != 2*time.Minute {
+		t.Errorf("unexpected stages: %v, last status: %#v", stages, last)
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != 5*time.Second {
+		t.Errorf("expected upload delay wait, got %v", clock.waits)
+	}
+
+	// the device is unavailable for the first status check after the expected downtime
+	var checks int
+	srv.Handle("GetSystemDateAndTime", func(*onviftest.Request) (string, error) {
+		checks++
+		if checks == 1 {
+			return "", onviftest.Fault("ter:Unavailable", "rebooting")
+		}
+		return `<tds:GetSystemDateAndTimeResponse><tds:SystemDateAndTime></tds:SystemDateAndTime></tds:GetSystemDateAndTimeResponse>`, nil
+	})
+	stages, clock.waits = nil, nil
+	if err = c.WaitOnline(context.Background(), downtime, time.Second, progress); err != nil {
+		t.Fatalf("could not wait for device: %v", err)
+	}
+	if fmt.Sprint(stages) != "[restarting done]" || checks != 2 || fmt.Sprint(clock.waits) != "[2m0s 1s]" {
+		t.Errorf("unexpected stages %v, %d checks, waits %v", stages, checks, clock.waits)
+	}
+
+	stages, uploaded = nil, nil
+	if downtime, err = c.RestoreSystemUpload(context.Background(), bytes.NewReader([]byte("backup")), progress); err != nil {
+		t.Fatalf("could not restore system: %v", err)
+	}
+	if downtime != 30*time.Second || string(uploaded) != "backup" || fmt.Sprint(stages) != "[uploading restarting]" {
+		t.Errorf("unexpected downtime %v, upload %q, or stages %v", downtime, uploaded, stages)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = c.WaitOnline(ctx, time.Minute, time.Second, nil); err != context.Canceled {
+		t.Errorf("expected canceled error, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// GetEndpointReference is an ONVIF GetEndpointReference operation
+type GetEndpointReference struct {
+	XMLName xml.Name `xml:"tds:GetEndpointReference"`
+}
+
+// GetEndpointReferenceResponse is an ONVIF GetEndpointReferenceResponse response
+type GetEndpointReferenceResponse struct {
+	GUID string
+}
+
+// GetEndpointReference returns the device's endpoint reference GUID, the same identifier the device advertises with WS-Discovery
+func (c *Client) GetEndpointReference() (string, error) {
+	return c.GetEndpointReferenceContext(context.Background())
+}
+
+// GetEndpointReferenceContext is like GetEndpointReference, but ctx controls the request
+func (c *Client) GetEndpointReferenceContext(ctx context.Context) (string, error) {
+	resp := new(GetEndpointReferenceResponse)
+	if err := c.CallContext(ctx, &GetEndpointReference{}, resp); err != nil {
+		return "", err
+	}
+	if resp.GUID == "" {
+		return "", fmt.Errorf("could not get endpoint reference: %w", soap.ErrNoResponse)
+	}
+	return resp.GUID, nil
+}
+
+// EndpointUUID normalizes an endpoint reference (e.g. "urn:uuid:6B29FC40-CA47-1067-B31D-00DD010662DA" or a bare GUID) to a lower case UUID,
+// so the same device can be recognized whether the reference came from WS-Discovery or GetEndpointReference
+func EndpointUUID(ref string) string {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	ref = strings.TrimPrefix(ref, "urn:")
+	ref = strings.TrimPrefix(ref, "uuid:")
+	return strings.Trim(ref, "{}")
+}
+
+// Identity is a device identified by its endpoint reference
+type Identity struct {
+	// UUID is the normalized endpoint reference. See EndpointUUID
+	UUID string
+	// XAddrs is the last observed device service addresses
+	XAddrs []string
+}
+
+// Identities correlates device addresses by endpoint reference, so a device that changes address (e.g. from DHCP)
+// or has multiple addresses is recognized as the same device. The zero value is ready to use, and it is safe for concurrent use
+type Identities struct {
+	mu  sync.Mutex
+	ids map[string]*Identity
+}
+
+// Observe records that the device with the endpoint reference ref is reachable at xaddrs, replacing any previously observed addresses.
+// The previous identity is returned, or nil if the device wasn't known
+func (i *Identities) Observe(ref string, xaddrs ...string) *Identity {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.ids == nil {
+		i.ids = make(map[string]*Identity)
+	}
+
+	uuid := EndpointUUID(ref)
+	prev := i.ids[uuid]
+	i.ids[uuid] = &Identity{UUID: uuid, XAddrs: append([]string(nil), xaddrs...)}
+
+	return prev
+}
+
+// Lookup returns the identity for the endpoint reference ref, or nil if it isn't known
+func (i *Identities) Lookup(ref string) *Identity {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	id, ok := i.ids[EndpointUUID(ref)]
+	if !ok {
+		return nil
+	}
+	return &Identity{UUID: id.UUID, XAddrs: append([]string(nil), id.XAddrs...)}
+}
+
+// LookupXAddr returns the identity last observed at xaddr, or nil if none is known
+func (i *Identities) LookupXAddr(xaddr string) *Identity {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, id := range i.ids {
+		for _, addr := range id.XAddrs {
+			if addr == xaddr {
+				return &Identity{UUID: id.UUID, XAddrs: append([]string(nil), id.XAddrs...)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Forget removes the identity for the endpoint reference ref
+func (i *Identities) Forget(ref string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.ids, EndpointUUID(ref))
+}
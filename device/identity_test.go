@@ -0,0 +1,166 @@
+package device_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// newClient returns a device client for srv
+func newClient(t *testing.T, srv *onviftest.Server) *device.Client {
+	t.Helper()
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{Username: "admin", Password: "password"}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	c, err := device.FromDevice(dev)
+	if err != nil {
+		t.Fatalf("could not create device client: %v", err)
+	}
+	return c
+}
+
+func TestGetEndpointReference(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+	c := newClient(t, srv)
+
+	srv.Respond("GetEndpointReference", `<tds:GetEndpointReferenceResponse><tds:GUID>urn:uuid:6B29FC40-CA47-1067-B31D-00DD010662DA</tds:GUID></tds:GetEndpointReferenceResponse>`)
+	ref, err := c.GetEndpointReference()
+	if err != nil {
+		t.Fatalf("could not get endpoint reference: %v", err)
+	}
+	if device.EndpointUUID(ref) != "6b29fc40-ca47-1067-b31d-00dd010662da" {
+		t.Errorf("unexpected endpoint reference: %q", ref)
+	}
+
+	srv.Respond("GetEndpointReference", `<tds:GetEndpointReferenceResponse></tds:GetEndpointReferenceResponse>`)
+	if _, err = c.GetEndpointReference(); !errors.Is(err, soap.ErrNoResponse) {
+		t.Errorf("expected no response error, got %v", err)
+	}
+}
+
+func TestEndpointUUID(t *testing.T) {
+	for _, test := range []struct {
+		ref  string
+		uuid string
+	}{
+		{"urn:uuid:6B29FC40-CA47-1067-B31D-00DD010662DA", "6b29fc40-ca47-1067-b31d-00dd010662da"},
+		{"uuid:6b29fc40-ca47-1067-b31d-00dd010662da", "6b29fc40-ca47-1067-b31d-00dd010662da"},
+		{" {6B29FC40-CA47-1067-B31D-00DD010662DA} ", "6b29fc40-ca47-1067-b31d-00dd010662da"},
+		{"6b29fc40-ca47-1067-b31d-00dd010662da", "6b29fc40-ca47-1067-b31d-00dd010662da"},
+		{"", ""},
+	} {
+		if uuid := device.EndpointUUID(test.ref); uuid != test.uuid {
+			t.Errorf("%q: expected %q, got %q", test.ref, test.uuid, uuid)
+		}
+	}
+}
+
+func TestIdentities(t *testing.T) {
+	ids := new(device.Identities)
+
+	if prev := ids.Observe("urn:uuid:6B29FC40-CA47-1067-B31D-00DD010662DA", "http://192.168.1.10/onvif/device_service"); prev != nil {
+		t.Errorf("expected new device, got %#v", prev)
+	}
+
+	// the same device at a new address, with the reference reported differently
+	prev := ids.Observe("6b29fc40-ca47-1067-b31d-00dd010662da", "http://192.168.1.20/onvif/device_service", "http://[fe80::1]/onvif/device_service")
+	if prev == nil || !reflect.DeepEqual(prev.XAddrs, []string{"http://192.168.1.10/onvif/device_service"}) {
+		t.Errorf("unexpected previous identity: %#v", prev)
+	}
+
+	if id := ids.LookupXAddr("http://[fe80::1]/onvif/device_service"); id == nil || id.UUID != "6b29fc40-ca47-1067-b31d-00dd010662da" {
+		t.Errorf("unexpected identity: %#v", id)
+	}
+	if id := ids.LookupXAddr("http://192.168.1.10/onvif/device_service"); id != nil {
+		t.Errorf("expected old address to be replaced, got %#v", id)
+	}
+
+	ids.Forget("urn:uuid:6b29fc40-ca47-1067-b31d-00dd010662da")
+	if id := ids.Lookup("6b29fc40-ca47-1067-b31d-00dd010662da"); id != nil {
+		t.Errorf("expected forgotten device, got %#v", id)
+	}
+}
+
+func TestFingerprintFixtures(t *testing.T) {
+	const (
+		responseInterfaces = `<tds:GetNetworkInterfacesResponse>
+<tds:NetworkInterfaces token="eth0"><tt:Enabled>true</tt:Enabled><tt:Info><tt:Name>eth0</tt:Name><tt:HwAddress>00:11:22:AA:BB:CC</tt:HwAddress></tt:Info></tds:NetworkInterfaces>
+<tds:NetworkInterfaces token="wlan0"><tt:Enabled>false</tt:Enabled><tt:Info><tt:Name>wlan0</tt:Name><tt:HwAddress>00-11-22-dd-ee-ff</tt:HwAddress></tt:Info></tds:NetworkInterfaces>
+</tds:GetNetworkInterfacesResponse>`
+		responseScopes = `<tds:GetScopesResponse>
+<tds:Scopes><tt:ScopeDef>Fixed</tt:ScopeDef><tt:ScopeItem>onvif://www.onvif.org/hardware/M1234</tt:ScopeItem></tds:Scopes>
+<tds:Scopes><tt:ScopeDef>Configurable</tt:ScopeDef><tt:ScopeItem>onvif://www.onvif.org/name/Lobby</tt:ScopeItem></tds:Scopes>
+<tds:Scopes><tt:ScopeDef>Fixed</tt:ScopeDef><tt:ScopeItem>onvif://www.onvif.org/Profile/Streaming</tt:ScopeItem></tds:Scopes>
+</tds:GetScopesResponse>`
+	)
+
+	for _, test := range []struct {
+		name      string
+		responses map[string]string
+		expected  *device.Fingerprint
+	}{
+		{
+			name: "complete",
+			responses: map[string]string{
+				"GetEndpointReference": `<tds:GetEndpointReferenceResponse><tds:GUID>urn:uuid:6B29FC40-CA47-1067-B31D-00DD010662DA</tds:GUID></tds:GetEndpointReferenceResponse>`,
+				"GetNetworkInterfaces": responseInterfaces,
+				"GetScopes":            responseScopes,
+			},
+			expected: &device.Fingerprint{
+				EndpointUUID:    "6b29fc40-ca47-1067-b31d-00dd010662da",
+				Manufacturer:    onviftest.Manufacturer,
+				Model:           onviftest.Model,
+				FirmwareVersion: onviftest.FirmwareVersion,
+				SerialNumber:    onviftest.SerialNumber,
+				HardwareID:      onviftest.HardwareID,
+				MACAddresses:    []string{"001122aabbcc", "001122ddeeff"},
+				Scopes:          []string{"onvif://www.onvif.org/Profile/Streaming", "onvif://www.onvif.org/hardware/M1234"},
+			},
+		},
+		{
+			// GetEndpointReference, GetNetworkInterfaces, and GetScopes aren't supported
+			name: "unsupported",
+			expected: &device.Fingerprint{
+				Manufacturer:    onviftest.Manufacturer,
+				Model:           onviftest.Model,
+				FirmwareVersion: onviftest.FirmwareVersion,
+				SerialNumber:    onviftest.SerialNumber,
+				HardwareID:      onviftest.HardwareID,
+			},
+		},
+		{
+			name: "missing fields",
+			responses: map[string]string{
+				"GetDeviceInformation": `<tds:GetDeviceInformationResponse><tds:Manufacturer>Acme</tds:Manufacturer></tds:GetDeviceInformationResponse>`,
+				"GetEndpointReference": `<tds:GetEndpointReferenceResponse></tds:GetEndpointReferenceResponse>`,
+				"GetNetworkInterfaces": `<tds:GetNetworkInterfacesResponse><tds:NetworkInterfaces token="eth0"><tt:Enabled>true</tt:Enabled></tds:NetworkInterfaces></tds:GetNetworkInterfacesResponse>`,
+				"GetScopes":            `<tds:GetScopesResponse><tds:Scopes><tt:ScopeItem>onvif://www.onvif.org/name/Lobby</tt:ScopeItem></tds:Scopes></tds:GetScopesResponse>`,
+			},
+			expected: &device.Fingerprint{Manufacturer: "Acme"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+			defer srv.Close()
+			for op, body := range test.responses {
+				srv.Respond(op, body)
+			}
+
+			f, err := newClient(t, srv).Fingerprint()
+			if err != nil {
+				t.Fatalf("could not get fingerprint: %v", err)
+			}
+			if !reflect.DeepEqual(f, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif"
+)
+
+// GetDeviceInformation is an ONVIF GetDeviceInformation operation
+type GetDeviceInformation struct {
+	XMLName xml.Name `xml:"tds:GetDeviceInformation"`
+}
+
+// GetDeviceInformationResponse is an ONVIF GetDeviceInformationResponse response
+type GetDeviceInformationResponse struct {
+	Manufacturer    string
+	Model           string
+	FirmwareVersion string
+	SerialNumber    string
+	HardwareID      string `xml:"HardwareId"`
+}
+
+// GetDeviceInformation returns the device manufacturer, model, and firmware information
+func (c *Client) GetDeviceInformation() (*GetDeviceInformationResponse, error) {
+	return c.GetDeviceInformationContext(context.Background())
+}
+
+// GetDeviceInformationContext is like GetDeviceInformation, but ctx controls the request
+func (c *Client) GetDeviceInformationContext(ctx context.Context) (*GetDeviceInformationResponse, error) {
+	resp := new(GetDeviceInformationResponse)
+	if err := c.CallContext(ctx, &GetDeviceInformation{}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DetectQuirks looks up the quirks profile for the device's manufacturer and model and applies it to the Client (shared by all service clients).
+// The applied quirks are returned, or nil if no profile matches. onvif.NewDevice does this automatically, so it's only needed for Clients used without a Device
+func (c *Client) DetectQuirks() (*onvif.Quirks, error) {
+	return c.DetectQuirksContext(context.Background())
+}
+
+// DetectQuirksContext is like DetectQuirks, but ctx controls the request(s)
+func (c *Client) DetectQuirksContext(ctx context.Context) (*onvif.Quirks, error) {
+	info, err := c.GetDeviceInformationContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := onvif.LookupQuirks(info.Manufacturer, info.Model)
+	c.ApplyQuirks(q)
+
+	return q, nil
+}
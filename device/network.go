@@ -0,0 +1,410 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// IPType is an ONVIF IPType
+type IPType string
+
+// ONVIF IP types
+const (
+	IPTypeIPv4 IPType = "IPv4"
+	IPTypeIPv6 IPType = "IPv6"
+)
+
+// NetworkHostType is an ONVIF NetworkHostType
+type NetworkHostType string
+
+// ONVIF network host types
+const (
+	NetworkHostTypeIPv4 NetworkHostType = "IPv4"
+	NetworkHostTypeIPv6 NetworkHostType = "IPv6"
+	NetworkHostTypeDNS  NetworkHostType = "DNS"
+)
+
+// IPv6DHCPConfiguration is an ONVIF IPv6DHCPConfiguration
+type IPv6DHCPConfiguration string
+
+// ONVIF IPv6 DHCP configurations
+const (
+	IPv6DHCPAuto      IPv6DHCPConfiguration = "Auto"
+	IPv6DHCPStateful  IPv6DHCPConfiguration = "Stateful"
+	IPv6DHCPStateless IPv6DHCPConfiguration = "Stateless"
+	IPv6DHCPOff       IPv6DHCPConfiguration = "Off"
+)
+
+// PrefixedAddress is an ONVIF PrefixedIPv4Address or PrefixedIPv6Address, e.g. 192.168.0.64/24
+type PrefixedAddress struct {
+	Address      string
+	PrefixLength int
+}
+
+// IPv4Configuration is an ONVIF IPv4Configuration
+type IPv4Configuration struct {
+	Manual    []*PrefixedAddress
+	LinkLocal *PrefixedAddress
+	FromDHCP  *PrefixedAddress
+	DHCP      bool
+}
+
+// IPv4NetworkInterface is an ONVIF IPv4NetworkInterface
+type IPv4NetworkInterface struct {
+	Enabled bool
+	Config  *IPv4Configuration
+}
+
+// IPv6Configuration is an ONVIF IPv6Configuration
+type IPv6Configuration struct {
+	AcceptRouterAdvert bool
+	DHCP               IPv6DHCPConfiguration
+	Manual             []*PrefixedAddress
+	LinkLocal          []*PrefixedAddress
+	FromDHCP           []*PrefixedAddress
+	FromRA             []*PrefixedAddress
+}
+
+// IPv6NetworkInterface is an ONVIF IPv6NetworkInterface
+type IPv6NetworkInterface struct {
+	Enabled bool
+	Config  *IPv6Configuration
+}
+
+// GetNetworkInterfaces is an ONVIF GetNetworkInterfaces operation
+type GetNetworkInterfaces struct {
+	XMLName xml.Name `xml:"tds:GetNetworkInterfaces"`
+}
+
+// NetworkInterface is a device network interface
+type NetworkInterface struct {
+	Token     string `xml:"token,attr"`
+	Enabled   bool
+	Name      string `xml:"Info>Name"`
+	HwAddress string `xml:"Info>HwAddress"`
+	MTU       int    `xml:"Info>MTU"`
+	IPv4      *IPv4NetworkInterface
+	IPv6      *IPv6NetworkInterface
+}
+
+// GetNetworkInterfacesResponse is an ONVIF GetNetworkInterfacesResponse response
+type GetNetworkInterfacesResponse struct {
+	NetworkInterfaces []*NetworkInterface
+}
+
+// GetNetworkInterfaces returns the device's network interfaces
+func (c *Client) GetNetworkInterfaces() ([]*NetworkInterface, error) {
+	return c.GetNetworkInterfacesContext(context.Background())
+}
+
+// GetNetworkInterfacesContext is like GetNetworkInterfaces, but ctx controls the request
+func (c *Client) GetNetworkInterfacesContext(ctx context.Context) ([]*NetworkInterface, error) {
+	resp := new(GetNetworkInterfacesResponse)
+	if err := c.CallContext(ctx, &GetNetworkInterfaces{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.NetworkInterfaces, nil
+}
+
+// IPv4NetworkInterfaceSetConfiguration is an ONVIF IPv4NetworkInterfaceSetConfiguration. Nil fields aren't changed
+type IPv4NetworkInterfaceSetConfiguration struct {
+	Enabled *bool              `xml:",omitempty"`
+	Manual  []*PrefixedAddress `xml:",omitempty"`
+	DHCP    *bool              `xml:",omitempty"`
+}
+
+// IPv6NetworkInterfaceSetConfiguration is an ONVIF IPv6NetworkInterfaceSetConfiguration. Nil and empty fields aren't changed
+type IPv6NetworkInterfaceSetConfiguration struct {
+	Enabled            *bool                 `xml:",omitempty"`
+	AcceptRouterAdvert *bool                 `xml:",omitempty"`
+	Manual             []*PrefixedAddress    `xml:",omitempty"`
+	DHCP               IPv6DHCPConfiguration `xml:",omitempty"`
+}
+
+// NetworkInterfaceSetConfiguration is an ONVIF NetworkInterfaceSetConfiguration. Nil and zero fields aren't changed
+type NetworkInterfaceSetConfiguration struct {
+	Enabled *bool                                 `xml:",omitempty"`
+	MTU     int                                   `xml:",omitempty"`
+	IPv4    *IPv4NetworkInterfaceSetConfiguration `xml:",omitempty"`
+	IPv6    *IPv6NetworkInterfaceSetConfiguration `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (n *NetworkInterfaceSetConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type config NetworkInterfaceSetConfiguration
+	return soap.EncodeElementPrefixed(enc, (*config)(n), start, "tt")
+}
+
+// SetNetworkInterfaces is an ONVIF SetNetworkInterfaces operation
+type SetNetworkInterfaces struct {
+	XMLName          xml.Name                          `xml:"tds:SetNetworkInterfaces"`
+	InterfaceToken   string                            `xml:"tds:InterfaceToken"`
+	NetworkInterface *NetworkInterfaceSetConfiguration `xml:"tds:NetworkInterface"`
+}
+
+// SetNetworkInterfacesResponse is an ONVIF SetNetworkInterfacesResponse response
+type SetNetworkInterfacesResponse struct {
+	RebootNeeded bool
+}
+
+// SetNetworkInterfaces configures the network interface with the given token.
+// It returns true if the device must be rebooted for the changes to take effect. See SystemReboot
+func (c *Client) SetNetworkInterfaces(token string, config *NetworkInterfaceSetConfiguration) (bool, error) {
+	return c.SetNetworkInterfacesContext(context.Background(), token, config)
+}
+
+// SetNetworkInterfacesContext is like SetNetworkInterfaces, but ctx controls the request
+func (c *Client) SetNetworkInterfacesContext(ctx context.Context, token string, config *NetworkInterfaceSetConfiguration) (bool, error) {
+	resp := new(SetNetworkInterfacesResponse)
+	if err := c.CallContext(ctx, &SetNetworkInterfaces{InterfaceToken: token, NetworkInterface: config}, resp); err != nil {
+		return false, err
+	}
+	return resp.RebootNeeded, nil
+}
+
+// IPAddress is an ONVIF IPAddress
+type IPAddress struct {
+	Type        IPType
+	IPv4Address string `xml:",omitempty"`
+	IPv6Address string `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (a *IPAddress) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type address IPAddress
+	return soap.EncodeElementPrefixed(enc, (*address)(a), start, "tt")
+}
+
+// NetworkHost is an ONVIF NetworkHost
+type NetworkHost struct {
+	Type        NetworkHostType
+	IPv4Address string `xml:",omitempty"`
+	IPv6Address string `xml:",omitempty"`
+	DNSname     string `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (h *NetworkHost) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type host NetworkHost
+	return soap.EncodeElementPrefixed(enc, (*host)(h), start, "tt")
+}
+
+// GetDNS is an ONVIF GetDNS operation
+type GetDNS struct {
+	XMLName xml.Name `xml:"tds:GetDNS"`
+}
+
+// DNSInformation is an ONVIF DNSInformation
+type DNSInformation struct {
+	FromDHCP     bool
+	SearchDomain []string
+	DNSFromDHCP  []*IPAddress
+	DNSManual    []*IPAddress
+}
+
+// GetDNSResponse is an ONVIF GetDNSResponse response
+type GetDNSResponse struct {
+	DNSInformation *DNSInformation
+}
+
+// GetDNS returns the device's DNS configuration
+func (c *Client) GetDNS() (*DNSInformation, error) {
+	return c.GetDNSContext(context.Background())
+}
+
+// GetDNSContext is like GetDNS, but ctx controls the request
+func (c *Client) GetDNSContext(ctx context.Context) (*DNSInformation, error) {
+	resp := new(GetDNSResponse)
+	if err := c.CallContext(ctx, &GetDNS{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.DNSInformation, nil
+}
+
+// SetDNS is an ONVIF SetDNS operation
+type SetDNS struct {
+	XMLName      xml.Name     `xml:"tds:SetDNS"`
+	FromDHCP     bool         `xml:"tds:FromDHCP"`
+	SearchDomain []string     `xml:"tds:SearchDomain"`
+	DNSManual    []*IPAddress `xml:"tds:DNSManual"`
+}
+
+// SetDNS sets the device's DNS configuration. If fromDHCP is true, DNS servers are taken from DHCP and servers is ignored
+func (c *Client) SetDNS(fromDHCP bool, searchDomains []string, servers []*IPAddress) error {
+	return c.SetDNSContext(context.Background(), fromDHCP, searchDomains, servers)
+}
+
+// SetDNSContext is like SetDNS, but ctx controls the request
+func (c *Client) SetDNSContext(ctx context.Context, fromDHCP bool, searchDomains []string, servers []*IPAddress) error {
+	return c.CallContext(ctx, &SetDNS{FromDHCP: fromDHCP, SearchDomain: searchDomains, DNSManual: servers}, nil)
+}
+
+// GetNTP is an ONVIF GetNTP operation
+type GetNTP struct {
+	XMLName xml.Name `xml:"tds:GetNTP"`
+}
+
+// NTPInformation is an ONVIF NTPInformation
+type NTPInformation struct {
+	FromDHCP    bool
+	NTPFromDHCP []*NetworkHost
+	NTPManual   []*NetworkHost
+}
+
+// GetNTPResponse is an ONVIF GetNTPResponse response
+type GetNTPResponse struct {
+	NTPInformation *NTPInformation
+}
+
+// GetNTP returns the device's NTP configuration
+func (c *Client) GetNTP() (*NTPInformation, error) {
+	return c.GetNTPContext(context.Background())
+}
+
+// GetNTPContext is like GetNTP, but ctx controls the request
+func (c *Client) GetNTPContext(ctx context.Context) (*NTPInformation, error) {
+	resp := new(GetNTPResponse)
+	if err := c.CallContext(ctx, &GetNTP{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.NTPInformation, nil
+}
+
+// SetNTP is an ONVIF SetNTP operation
+type SetNTP struct {
+	XMLName   xml.Name       `xml:"tds:SetNTP"`
+	FromDHCP  bool           `xml:"tds:FromDHCP"`
+	NTPManual []*NetworkHost `xml:"tds:NTPManual"`
+}
+
+// SetNTP sets the device's NTP servers. If fromDHCP is true, NTP servers are taken from DHCP and servers is ignored.
+// See SetSystemDateAndTime to enable NTP
+func (c *Client) SetNTP(fromDHCP bool, servers []*NetworkHost) error {
+	return c.SetNTPContext(context.Background(), fromDHCP, servers)
+}
+
+// SetNTPContext is like SetNTP, but ctx controls the request
+func (c *Client) SetNTPContext(ctx context.Context, fromDHCP bool, servers []*NetworkHost) error {
+	return c.CallContext(ctx, &SetNTP{FromDHCP: fromDHCP, NTPManual: servers}, nil)
+}
+
+// GetNetworkDefaultGateway is an ONVIF GetNetworkDefaultGateway operation
+type GetNetworkDefaultGateway struct {
+	XMLName xml.Name `xml:"tds:GetNetworkDefaultGateway"`
+}
+
+// NetworkGateway is an ONVIF NetworkGateway
+type NetworkGateway struct {
+	IPv4Address []string
+	IPv6Address []string
+}
+
+// GetNetworkDefaultGatewayResponse is an ONVIF GetNetworkDefaultGatewayResponse response
+type GetNetworkDefaultGatewayResponse struct {
+	NetworkGateway *NetworkGateway
+}
+
+// GetNetworkDefaultGateway returns the device's default gateways
+func (c *Client) GetNetworkDefaultGateway() (*NetworkGateway, error) {
+	return c.GetNetworkDefaultGatewayContext(context.Background())
+}
+
+// GetNetworkDefaultGatewayContext is like GetNetworkDefaultGateway, but ctx controls the request
+func (c *Client) GetNetworkDefaultGatewayContext(ctx context.Context) (*NetworkGateway, error) {
+	resp := new(GetNetworkDefaultGatewayResponse)
+	if err := c.CallContext(ctx, &GetNetworkDefaultGateway{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.NetworkGateway, nil
+}
+
+// SetNetworkDefaultGateway is an ONVIF SetNetworkDefaultGateway operation
+type SetNetworkDefaultGateway struct {
+	XMLName     xml.Name `xml:"tds:SetNetworkDefaultGateway"`
+	IPv4Address []string `xml:"tds:IPv4Address"`
+	IPv6Address []string `xml:"tds:IPv6Address"`
+}
+
+// SetNetworkDefaultGateway sets the device's default gateways
+func (c *Client) SetNetworkDefaultGateway(gateway *NetworkGateway) error {
+	return c.SetNetworkDefaultGatewayContext(context.Background(), gateway)
+}
+
+// SetNetworkDefaultGatewayContext is like SetNetworkDefaultGateway, but ctx controls the request
+func (c *Client) SetNetworkDefaultGatewayContext(ctx context.Context, gateway *NetworkGateway) error {
+	return c.CallContext(ctx, &SetNetworkDefaultGateway{IPv4Address: gateway.IPv4Address, IPv6Address: gateway.IPv6Address}, nil)
+}
+
+// GetHostname is an ONVIF GetHostname operation
+type GetHostname struct {
+	XMLName xml.Name `xml:"tds:GetHostname"`
+}
+
+// HostnameInformation is an ONVIF HostnameInformation
+type HostnameInformation struct {
+	FromDHCP bool
+	Name     string
+}
+
+// GetHostnameResponse is an ONVIF GetHostnameResponse response
+type GetHostnameResponse struct {
+	HostnameInformation *HostnameInformation
+}
+
+// GetHostname returns the device's hostname
+func (c *Client) GetHostname() (*HostnameInformation, error) {
+	return c.GetHostnameContext(context.Background())
+}
+
+// GetHostnameContext is like GetHostname, but ctx controls the request
+func (c *Client) GetHostnameContext(ctx context.Context) (*HostnameInformation, error) {
+	resp := new(GetHostnameResponse)
+	if err := c.CallContext(ctx, &GetHostname{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.HostnameInformation, nil
+}
+
+// SetHostname is an ONVIF SetHostname operation
+type SetHostname struct {
+	XMLName xml.Name `xml:"tds:SetHostname"`
+	Name    string   `xml:"tds:Name"`
+}
+
+// SetHostname sets the device's hostname
+func (c *Client) SetHostname(name string) error {
+	return c.SetHostnameContext(context.Background(), name)
+}
+
+// SetHostnameContext is like SetHostname, but ctx controls the request
+func (c *Client) SetHostnameContext(ctx context.Context, name string) error {
+	return c.CallContext(ctx, &SetHostname{Name: name}, nil)
+}
+
+// SetHostnameFromDHCP is an ONVIF SetHostnameFromDHCP operation
+type SetHostnameFromDHCP struct {
+	XMLName  xml.Name `xml:"tds:SetHostnameFromDHCP"`
+	FromDHCP bool     `xml:"tds:FromDHCP"`
+}
+
+// SetHostnameFromDHCPResponse is an ONVIF SetHostnameFromDHCPResponse response
+type SetHostnameFromDHCPResponse struct {
+	RebootNeeded bool
+}
+
+// SetHostnameFromDHCP sets whether the device's hostname is taken from DHCP.
+// It returns true if the device must be rebooted for the change to take effect
+func (c *Client) SetHostnameFromDHCP(fromDHCP bool) (bool, error) {
+	return c.SetHostnameFromDHCPContext(context.Background(), fromDHCP)
+}
+
+// SetHostnameFromDHCPContext is like SetHostnameFromDHCP, but ctx controls the request
+func (c *Client) SetHostnameFromDHCPContext(ctx context.Context, fromDHCP bool) (bool, error) {
+	resp := new(SetHostnameFromDHCPResponse)
+	if err := c.CallContext(ctx, &SetHostnameFromDHCP{FromDHCP: fromDHCP}, resp); err != nil {
+		return false, err
+	}
+	return resp.RebootNeeded, nil
+}
@@ -0,0 +1,151 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// ScopeDefinition is the type of a scope
+type ScopeDefinition string
+
+// ONVIF scope definitions
+const (
+	ScopeDefinitionFixed        ScopeDefinition = "Fixed"
+	ScopeDefinitionConfigurable ScopeDefinition = "Configurable"
+)
+
+// GetScopes is an ONVIF GetScopes operation
+type GetScopes struct {
+	XMLName xml.Name `xml:"tds:GetScopes"`
+}
+
+// Scope is a device scope URI, e.g. onvif://www.onvif.org/hardware/M1234
+type Scope struct {
+	ScopeDef  ScopeDefinition
+	ScopeItem string
+}
+
+// GetScopesResponse is an ONVIF GetScopesResponse response
+type GetScopesResponse struct {
+	Scopes []*Scope
+}
+
+// GetScopes returns the device's scopes
+func (c *Client) GetScopes() ([]*Scope, error) {
+	return c.GetScopesContext(context.Background())
+}
+
+// GetScopesContext is like GetScopes, but ctx controls the request
+func (c *Client) GetScopesContext(ctx context.Context) ([]*Scope, error) {
+	resp := new(GetScopesResponse)
+	if err := c.CallContext(ctx, &GetScopes{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Scopes, nil
+}
+
+// SetScopes is an ONVIF SetScopes operation
+type SetScopes struct {
+	XMLName xml.Name `xml:"tds:SetScopes"`
+	Scopes  []string `xml:"tds:Scopes"`
+}
+
+// SetScopes replaces the device's configurable scopes with scopes, e.g. onvif://www.onvif.org/location/building1
+func (c *Client) SetScopes(scopes ...string) error {
+	return c.SetScopesContext(context.Background(), scopes...)
+}
+
+// SetScopesContext is like SetScopes, but ctx controls the request
+func (c *Client) SetScopesContext(ctx context.Context, scopes ...string) error {
+	return c.CallContext(ctx, &SetScopes{Scopes: scopes}, nil)
+}
+
+// AddScopes is an ONVIF AddScopes operation
+type AddScopes struct {
+	XMLName   xml.Name `xml:"tds:AddScopes"`
+	ScopeItem []string `xml:"tds:ScopeItem"`
+}
+
+// AddScopes adds configurable scopes to the device
+func (c *Client) AddScopes(scopes ...string) error {
+	return c.AddScopesContext(context.Background(), scopes...)
+}
+
+// AddScopesContext is like AddScopes, but ctx controls the request
+func (c *Client) AddScopesContext(ctx context.Context, scopes ...string) error {
+	return c.CallContext(ctx, &AddScopes{ScopeItem: scopes}, nil)
+}
+
+// RemoveScopes is an ONVIF RemoveScopes operation
+type RemoveScopes struct {
+	XMLName   xml.Name `xml:"tds:RemoveScopes"`
+	ScopeItem []string `xml:"tds:ScopeItem"`
+}
+
+// RemoveScopesResponse is an ONVIF RemoveScopesResponse response
+type RemoveScopesResponse struct {
+	ScopeItem []string
+}
+
+// RemoveScopes removes configurable scopes from the device and returns the scopes that were removed
+func (c *Client) RemoveScopes(scopes ...string) ([]string, error) {
+	return c.RemoveScopesContext(context.Background(), scopes...)
+}
+
+// RemoveScopesContext is like RemoveScopes, but ctx controls the request
+func (c *Client) RemoveScopesContext(ctx context.Context, scopes ...string) ([]string, error) {
+	resp := new(RemoveScopesResponse)
+	if err := c.CallContext(ctx, &RemoveScopes{ScopeItem: scopes}, resp); err != nil {
+		return nil, err
+	}
+	return resp.ScopeItem, nil
+}
+
+// DiscoveryMode is an ONVIF DiscoveryMode
+type DiscoveryMode string
+
+// ONVIF discovery modes
+const (
+	DiscoveryModeDiscoverable    DiscoveryMode = "Discoverable"
+	DiscoveryModeNonDiscoverable DiscoveryMode = "NonDiscoverable"
+)
+
+// GetDiscoveryMode is an ONVIF GetDiscoveryMode operation
+type GetDiscoveryMode struct {
+	XMLName xml.Name `xml:"tds:GetDiscoveryMode"`
+}
+
+// GetDiscoveryModeResponse is an ONVIF GetDiscoveryModeResponse response
+type GetDiscoveryModeResponse struct {
+	DiscoveryMode DiscoveryMode
+}
+
+// GetDiscoveryMode returns whether the device responds to WS-Discovery probes
+func (c *Client) GetDiscoveryMode() (DiscoveryMode, error) {
+	return c.GetDiscoveryModeContext(context.Background())
+}
+
+// GetDiscoveryModeContext is like GetDiscoveryMode, but ctx controls the request
+func (c *Client) GetDiscoveryModeContext(ctx context.Context) (DiscoveryMode, error) {
+	resp := new(GetDiscoveryModeResponse)
+	if err := c.CallContext(ctx, &GetDiscoveryMode{}, resp); err != nil {
+		return "", err
+	}
+	return resp.DiscoveryMode, nil
+}
+
+// SetDiscoveryMode is an ONVIF SetDiscoveryMode operation
+type SetDiscoveryMode struct {
+	XMLName       xml.Name      `xml:"tds:SetDiscoveryMode"`
+	DiscoveryMode DiscoveryMode `xml:"tds:DiscoveryMode"`
+}
+
+// SetDiscoveryMode sets whether the device responds to WS-Discovery probes
+func (c *Client) SetDiscoveryMode(mode DiscoveryMode) error {
+	return c.SetDiscoveryModeContext(context.Background(), mode)
+}
+
+// SetDiscoveryModeContext is like SetDiscoveryMode, but ctx controls the request
+func (c *Client) SetDiscoveryModeContext(ctx context.Context, mode DiscoveryMode) error {
+	return c.CallContext(ctx, &SetDiscoveryMode{DiscoveryMode: mode}, nil)
+}
@@ -0,0 +1,63 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// StorageType is the type of a storage configuration
+type StorageType string
+
+// ONVIF storage types
+const (
+	StorageTypeNFS   StorageType = "NFS"
+	StorageTypeCIFS  StorageType = "CIFS"
+	StorageTypeCDMI  StorageType = "CDMI"
+	StorageTypeFTP   StorageType = "FTP"
+	StorageTypeLocal StorageType = "ONVIF://www.onvif.org/StorageType/Local"
+)
+
+// UserCredential is an ONVIF UserCredential type. Passwords aren't returned by devices
+type UserCredential struct {
+	UserName string
+}
+
+// StorageConfigurationData is an ONVIF StorageConfigurationData type
+type StorageConfigurationData struct {
+	Type StorageType `xml:"type,attr"`
+	// LocalPath is the local path of the storage, if it's mounted by the device
+	LocalPath  string
+	StorageURI string `xml:"StorageUri"`
+	User       *UserCredential
+	Region     string
+}
+
+// StorageConfiguration is an ONVIF StorageConfiguration type
+type StorageConfiguration struct {
+	Token string `xml:"token,attr"`
+	Data  *StorageConfigurationData
+}
+
+// GetStorageConfigurations is an ONVIF GetStorageConfigurations operation
+type GetStorageConfigurations struct {
+	XMLName xml.Name `xml:"tds:GetStorageConfigurations"`
+}
+
+// GetStorageConfigurationsResponse is an ONVIF GetStorageConfigurationsResponse response
+type GetStorageConfigurationsResponse struct {
+	StorageConfigurations []*StorageConfiguration
+}
+
+// GetStorageConfigurations returns the device's storage configurations. Devices advertise support with SystemCapabilities.StorageConfiguration
+func (c *Client) GetStorageConfigurations() ([]*StorageConfiguration, error) {
+	return c.GetStorageConfigurationsContext(context.Background())
+}
+
+// GetStorageConfigurationsContext is like GetStorageConfigurations, but ctx controls the request
+func (c *Client) GetStorageConfigurationsContext(ctx context.Context) ([]*StorageConfiguration, error) {
+	resp := new(GetStorageConfigurationsResponse)
+	if err := c.CallContext(ctx, &GetStorageConfigurations{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.StorageConfigurations, nil
+}
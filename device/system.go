@@ -0,0 +1,403 @@
+package device
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// ErrNoContent indicates the device did not return a download URI or inline content
+var ErrNoContent = errors.New("device did not return content")
+
+// SystemLogType is an ONVIF SystemLogType
+type SystemLogType string
+
+// ONVIF system log types
+const (
+	SystemLogTypeSystem SystemLogType = "System"
+	SystemLogTypeAccess SystemLogType = "Access"
+)
+
+// GetSystemUris is an ONVIF GetSystemUris operation
+type GetSystemUris struct {
+	XMLName xml.Name `xml:"tds:GetSystemUris"`
+}
+
+// SystemLogURI is an ONVIF SystemLogUri
+type SystemLogURI struct {
+	Type SystemLogType
+	URI  string `xml:"Uri"`
+}
+
+// GetSystemUrisResponse is an ONVIF GetSystemUrisResponse response
+type GetSystemUrisResponse struct {
+	SystemLogURIs   []*SystemLogURI `xml:"SystemLogUris>SystemLog"`
+	SupportInfoURI  string          `xml:"SupportInfoUri"`
+	SystemBackupURI string          `xml:"SystemBackupUri"`
+}
+
+// SystemLogURI returns the URI for the given log type or the empty string if it isn't found
+func (r *GetSystemUrisResponse) SystemLogURI(typ SystemLogType) string {
+	for _, l := range r.SystemLogURIs {
+		if l.Type == typ {
+			return l.URI
+		}
+	}
+	return ""
+}
+
+// GetSystemUris returns the URIs from which system logs, support information, and backups can be downloaded over HTTP
+func (c *Client) GetSystemUris() (*GetSystemUrisResponse, error) {
+	return c.GetSystemUrisContext(context.Background())
+}
+
+// GetSystemUrisContext is like GetSystemUris, but ctx controls the request
+func (c *Client) GetSystemUrisContext(ctx context.Context) (*GetSystemUrisResponse, error) {
+	resp := new(GetSystemUrisResponse)
+	if err := c.CallContext(ctx, &GetSystemUris{}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetSystemLog is an ONVIF GetSystemLog operation
+type GetSystemLog struct {
+	XMLName xml.Name      `xml:"tds:GetSystemLog"`
+	LogType SystemLogType `xml:"tds:LogType"`
+}
+
+// NamespaceXMLMime is the XML media type namespace, used for the content type of attachments
+const NamespaceXMLMime = "http://www.w3.org/2005/05/xmlmime"
+
+// AttachmentData is an ONVIF AttachmentData, a binary payload.
+// MTOM attachments are inlined by onvif.Client, so Data holds the content whether or not the device used MTOM
+type AttachmentData struct {
+	ContentType string
+	Data        []byte
+}
+
+// UnmarshalXML implements xml.Unmarshaler
+func (a *AttachmentData) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		ContentType string `xml:"contentType,attr"`
+		Content     string `xml:",chardata"`
+		Include     *struct {
+			Href    string `xml:"href,attr"`
+			Content string `xml:",chardata"`
+		}
+	}
+	if err := dec.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	content := v.Content
+	if v.Include != nil {
+		if strings.TrimSpace(v.Include.Content) == "" {
+			return fmt.Errorf("could not resolve attachment %q", v.Include.Href)
+		}
+		content = v.Include.Content
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+	if err != nil {
+		return fmt.Errorf("could not decode attachment: %w", err)
+	}
+
+	a.ContentType, a.Data = v.ContentType, data
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler. The content is sent inline as base64, since MTOM requests aren't supported
+func (a *AttachmentData) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:xmime"}, Value: NamespaceXMLMime})
+	if a.ContentType != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmime:contentType"}, Value: a.ContentType})
+	}
+	return enc.EncodeElement(base64.StdEncoding.EncodeToString(a.Data), start)
+}
+
+// SystemLog is an ONVIF SystemLog or SupportInformation, which has either binary or string content
+type SystemLog struct {
+	Binary *AttachmentData
+	String string
+}
+
+// Content returns the binary content if set, or the string content
+func (l *SystemLog) Content() []byte {
+	if l.Binary != nil {
+		return l.Binary.Data
+	}
+	return []byte(l.String)
+}
+
+// GetSystemLogResponse is an ONVIF GetSystemLogResponse response
+type GetSystemLogResponse struct {
+	SystemLog *SystemLog
+}
+
+// GetSystemLog returns the system log of the given type
+func (c *Client) GetSystemLog(typ SystemLogType) (*SystemLog, error) {
+	return c.GetSystemLogContext(context.Background(), typ)
+}
+
+// GetSystemLogContext is like GetSystemLog, but ctx controls the request
+func (c *Client) GetSystemLogContext(ctx context.Context, typ SystemLogType) (*SystemLog, error) {
+	resp := new(GetSystemLogResponse)
+	if err := c.CallContext(ctx, &GetSystemLog{LogType: typ}, resp); err != nil {
+		return nil, err
+	}
+	if resp.SystemLog == nil {
+		return nil, fmt.Errorf("could not get system log: %w", soap.ErrNoResponse)
+	}
+	return resp.SystemLog, nil
+}
+
+// GetSystemSupportInformation is an ONVIF GetSystemSupportInformation operation
+type GetSystemSupportInformation struct {
+	XMLName xml.Name `xml:"tds:GetSystemSupportInformation"`
+}
+
+// GetSystemSupportInformationResponse is an ONVIF GetSystemSupportInformationResponse response
+type GetSystemSupportInformationResponse struct {
+	SupportInformation *SystemLog
+}
+
+// GetSystemSupportInformation returns the device support information
+func (c *Client) GetSystemSupportInformation() (*SystemLog, error) {
+	return c.GetSystemSupportInformationContext(context.Background())
+}
+
+// GetSystemSupportInformationContext is like GetSystemSupportInformation, but ctx controls the request
+func (c *Client) GetSystemSupportInformationContext(ctx context.Context) (*SystemLog, error) {
+	resp := new(GetSystemSupportInformationResponse)
+	if err := c.CallContext(ctx, &GetSystemSupportInformation{}, resp); err != nil {
+		return nil, err
+	}
+	if resp.SupportInformation == nil {
+		return nil, fmt.Errorf("could not get support information: %w", soap.ErrNoResponse)
+	}
+	return resp.SupportInformation, nil
+}
+
+// systemUris returns the system URIs, or nil if the device doesn't support GetSystemUris,
+// i.e. it returns an ActionNotSupported or UnknownAction fault. Other faults, e.g. NotAuthorized, are returned
+func (c *Client) systemUris(ctx context.Context) (*GetSystemUrisResponse, error) {
+	uris, err := c.GetSystemUrisContext(ctx)
+	if errors.Is(err, soap.ErrActionNotSupported) || errors.Is(err, soap.ErrUnknownAction) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get system uris: %w", err)
+	}
+	return uris, nil
+}
+
+// DownloadSystemLog writes the system log of the given type to w.
+// If the device returns a URI from GetSystemUris, the log is downloaded from it. Otherwise GetSystemLog is used
+func (c *Client) DownloadSystemLog(typ SystemLogType, w io.Writer) (int64, error) {
+	return c.DownloadSystemLogContext(context.Background(), typ, w)
+}
+
+// DownloadSystemLogContext is like DownloadSystemLog, but ctx controls the request(s)
+func (c *Client) DownloadSystemLogContext(ctx context.Context, typ SystemLogType, w io.Writer) (int64, error) {
+	uris, err := c.systemUris(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if uris != nil {
+		if uri := uris.SystemLogURI(typ); uri != "" {
+			return c.DownloadContext(ctx, uri, w)
+		}
+	}
+
+	log, err := c.GetSystemLogContext(ctx, typ)
+	if err != nil {
+		return 0, fmt.Errorf("could not get system log: %w", err)
+	}
+
+	return copyContent(log.Content(), w)
+}
+
+// DownloadSupportInformation writes the device support information to w.
+// If the device returns a URI from GetSystemUris, the information is downloaded from it. Otherwise GetSystemSupportInformation is used
+func (c *Client) DownloadSupportInformation(w io.Writer) (int64, error) {
+	return c.DownloadSupportInformationContext(context.Background(), w)
+}
+
+// DownloadSupportInformationContext is like DownloadSupportInformation, but ctx controls the request(s)
+func (c *Client) DownloadSupportInformationContext(ctx context.Context, w io.Writer) (int64, error) {
+	uris, err := c.systemUris(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if uris != nil && uris.SupportInfoURI != "" {
+		return c.DownloadContext(ctx, uris.SupportInfoURI, w)
+	}
+
+	info, err := c.GetSystemSupportInformationContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not get support information: %w", err)
+	}
+
+	return copyContent(info.Content(), w)
+}
+
+// DownloadSystemBackup writes the device system backup to w.
+// If the device returns a URI from GetSystemUris, the backup is downloaded from it. Otherwise the first file from GetSystemBackup is used
+func (c *Client) DownloadSystemBackup(w io.Writer) (int64, error) {
+	return c.DownloadSystemBackupContext(context.Background(), w)
+}
+
+// DownloadSystemBackupContext is like DownloadSystemBackup, but ctx controls the request(s)
+func (c *Client) DownloadSystemBackupContext(ctx context.Context, w io.Writer) (int64, error) {
+	uris, err := c.systemUris(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if uris != nil && uris.SystemBackupURI != "" {
+		return c.DownloadContext(ctx, uris.SystemBackupURI, w)
+	}
+
+	files, err := c.GetSystemBackupContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not get system backup: %w", err)
+	}
+	if len(files) == 0 || files[0].Data == nil {
+		return 0, ErrNoContent
+	}
+
+	return copyContent(files[0].Data.Data, w)
+}
+
+// BackupFile is an ONVIF BackupFile
+type BackupFile struct {
+	Name string
+	Data *AttachmentData
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (f *BackupFile) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := enc.EncodeElement(f.Name, xml.StartElement{Name: xml.Name{Local: "tt:Name"}}); err != nil {
+		return err
+	}
+	if f.Data != nil {
+		if err := enc.EncodeElement(f.Data, xml.StartElement{Name: xml.Name{Local: "tt:Data"}}); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// GetSystemBackup is an ONVIF GetSystemBackup operation
+type GetSystemBackup struct {
+	XMLName xml.Name `xml:"tds:GetSystemBackup"`
+}
+
+// GetSystemBackupResponse is an ONVIF GetSystemBackupResponse response
+type GetSystemBackupResponse struct {
+	BackupFiles []*BackupFile
+}
+
+// GetSystemBackup returns the device's configuration backup files
+func (c *Client) GetSystemBackup() ([]*BackupFile, error) {
+	return c.GetSystemBackupContext(context.Background())
+}
+
+// GetSystemBackupContext is like GetSystemBackup, but ctx controls the request
+func (c *Client) GetSystemBackupContext(ctx context.Context) ([]*BackupFile, error) {
+	resp := new(GetSystemBackupResponse)
+	if err := c.CallContext(ctx, &GetSystemBackup{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.BackupFiles, nil
+}
+
+// RestoreSystem is an ONVIF RestoreSystem operation
+type RestoreSystem struct {
+	XMLName     xml.Name      `xml:"tds:RestoreSystem"`
+	BackupFiles []*BackupFile `xml:"tds:BackupFiles"`
+}
+
+// RestoreSystem restores the device's configuration from backup files returned by GetSystemBackup.
+// The files are sent inline as base64, so devices that only accept MTOM requests aren't supported
+func (c *Client) RestoreSystem(files ...*BackupFile) error {
+	return c.RestoreSystemContext(context.Background(), files...)
+}
+
+// RestoreSystemContext is like RestoreSystem, but ctx controls the request
+func (c *Client) RestoreSystemContext(ctx context.Context, files ...*BackupFile) error {
+	return c.CallContext(ctx, &RestoreSystem{BackupFiles: files}, nil)
+}
+
+// BackupContentType is the content type backups are uploaded with by RestoreSystemUpload
+const BackupContentType = "application/octet-stream"
+
+// StartSystemRestore is an ONVIF StartSystemRestore operation
+type StartSystemRestore struct {
+	XMLName xml.Name `xml:"tds:StartSystemRestore"`
+}
+
+// StartSystemRestoreResponse is an ONVIF StartSystemRestoreResponse response
+type StartSystemRestoreResponse struct {
+	// UploadURI is the URI the backup must be POSTed to
+	UploadURI string `xml:"UploadUri"`
+	// ExpectedDownTime is an xsd:duration. See soap.ParseDuration
+	ExpectedDownTime string
+}
+
+// StartSystemRestore prepares the device for a system restore and returns the upload URI. Most users should use RestoreSystemUpload instead
+func (c *Client) StartSystemRestore() (*StartSystemRestoreResponse, error) {
+	return c.StartSystemRestoreContext(context.Background())
+}
+
+// StartSystemRestoreContext is like StartSystemRestore, but ctx controls the request
+func (c *Client) StartSystemRestoreContext(ctx context.Context) (*StartSystemRestoreResponse, error) {
+	resp := new(StartSystemRestoreResponse)
+	if err := c.CallContext(ctx, &StartSystemRestore{}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RestoreSystemUpload restores the device's configuration with StartSystemRestore, uploading the backup from r.
+// progress (which may be nil) receives StageUploading as the backup is uploaded (see onvif.Client.Upload) and StageRestarting.
+// It returns how long the device expects to be unavailable while it applies the backup. See WaitOnline
+func (c *Client) RestoreSystemUpload(ctx context.Context, r io.ReadSeeker, progress onvif.Progress) (time.Duration, error) {
+	start, err := c.StartSystemRestoreContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not start system restore: %w", err)
+	}
+
+	downtime, err := c.upload(ctx, start.UploadURI, "", start.ExpectedDownTime, BackupContentType, r, progress)
+	if err != nil {
+		return 0, fmt.Errorf("could not upload backup: %w", err)
+	}
+	return downtime, nil
+}
+
+// copyContent writes buf to w, returning ErrNoContent if buf is empty
+func copyContent(buf []byte, w io.Writer) (int64, error) {
+	if len(buf) == 0 {
+		return 0, ErrNoContent
+	}
+	n, err := io.Copy(w, bytes.NewReader(buf))
+	if err != nil {
+		return n, fmt.Errorf("could not write content: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,41 @@
+package device_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+func TestDownloadSystemLog(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+	srv.Respond("GetSystemLog", `<tds:GetSystemLogResponse><tds:SystemLog><tt:String>log</tt:String></tds:SystemLog></tds:GetSystemLogResponse>`)
+
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{Username: "admin", Password: "password"}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	c, err := device.FromDevice(dev)
+	if err != nil {
+		t.Fatalf("could not create device client: %v", err)
+	}
+
+	// the server doesn't support GetSystemUris, so the inline log is used
+	buf := new(bytes.Buffer)
+	if _, err = c.DownloadSystemLog(device.SystemLogTypeSystem, buf); err != nil || buf.String() != "log" {
+		t.Errorf("expected inline log, got %q, %v", buf, err)
+	}
+
+	srv.Handle("GetSystemUris", func(*onviftest.Request) (string, error) {
+		return "", onviftest.Fault(soap.ErrNotAuthorized, "not authorized")
+	})
+	if _, err = c.DownloadSystemLog(device.SystemLogTypeSystem, new(bytes.Buffer)); !errors.Is(err, soap.ErrNotAuthorized) {
+		t.Errorf("expected not authorized error, got %v", err)
+	}
+}
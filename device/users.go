@@ -0,0 +1,106 @@
+package device
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// UserLevel is an ONVIF UserLevel
+type UserLevel string
+
+// ONVIF user levels
+const (
+	UserLevelAdministrator UserLevel = "Administrator"
+	UserLevelOperator      UserLevel = "Operator"
+	UserLevelUser          UserLevel = "User"
+	UserLevelAnonymous     UserLevel = "Anonymous"
+	UserLevelExtended      UserLevel = "Extended"
+)
+
+// User is an ONVIF User. Password is never returned by GetUsers
+type User struct {
+	Username  string
+	Password  string `xml:",omitempty"`
+	UserLevel UserLevel
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (u *User) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type user User
+	return soap.EncodeElementPrefixed(enc, (*user)(u), start, "tt")
+}
+
+// GetUsers is an ONVIF GetUsers operation
+type GetUsers struct {
+	XMLName xml.Name `xml:"tds:GetUsers"`
+}
+
+// GetUsersResponse is an ONVIF GetUsersResponse response
+type GetUsersResponse struct {
+	User []*User
+}
+
+// GetUsers returns the device's users, without their passwords
+func (c *Client) GetUsers() ([]*User, error) {
+	return c.GetUsersContext(context.Background())
+}
+
+// GetUsersContext is like GetUsers, but ctx controls the request
+func (c *Client) GetUsersContext(ctx context.Context) ([]*User, error) {
+	resp := new(GetUsersResponse)
+	if err := c.CallContext(ctx, &GetUsers{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+// CreateUsers is an ONVIF CreateUsers operation
+type CreateUsers struct {
+	XMLName xml.Name `xml:"tds:CreateUsers"`
+	User    []*User  `xml:"tds:User"`
+}
+
+// CreateUsers creates users on the device. Passwords are sent in plain text, so TLS should be used when possible
+func (c *Client) CreateUsers(users ...*User) error {
+	return c.CreateUsersContext(context.Background(), users...)
+}
+
+// CreateUsersContext is like CreateUsers, but ctx controls the request
+func (c *Client) CreateUsersContext(ctx context.Context, users ...*User) error {
+	return c.CallContext(ctx, &CreateUsers{User: users}, nil)
+}
+
+// SetUser is an ONVIF SetUser operation
+type SetUser struct {
+	XMLName xml.Name `xml:"tds:SetUser"`
+	User    []*User  `xml:"tds:User"`
+}
+
+// SetUser updates the passwords and levels of existing users on the device, e.g. to rotate credentials.
+// Passwords are sent in plain text, so TLS should be used when possible
+func (c *Client) SetUser(users ...*User) error {
+	return c.SetUserContext(context.Background(), users...)
+}
+
+// SetUserContext is like SetUser, but ctx controls the request
+func (c *Client) SetUserContext(ctx context.Context, users ...*User) error {
+	return c.CallContext(ctx, &SetUser{User: users}, nil)
+}
+
+// DeleteUsers is an ONVIF DeleteUsers operation
+type DeleteUsers struct {
+	XMLName  xml.Name `xml:"tds:DeleteUsers"`
+	Username []string `xml:"tds:Username"`
+}
+
+// DeleteUsers deletes the users with the given usernames from the device
+func (c *Client) DeleteUsers(usernames ...string) error {
+	return c.DeleteUsersContext(context.Background(), usernames...)
+}
+
+// DeleteUsersContext is like DeleteUsers, but ctx controls the request
+func (c *Client) DeleteUsersContext(ctx context.Context, usernames ...string) error {
+	return c.CallContext(ctx, &DeleteUsers{Username: usernames}, nil)
+}
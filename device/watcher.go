@@ -0,0 +1,276 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/discovery"
+)
+
+// ErrNoMACAddress is returned (wrapped) by Watcher.Add if the device's network interfaces have no hardware addresses
+var ErrNoMACAddress = errors.New("no MAC address")
+
+// Tracked is a device tracked by a Watcher
+type Tracked struct {
+	*onvif.Device
+	// MACs is the lower case hardware addresses of the device's network interfaces
+	MACs []string
+	// UUID is the device's normalized endpoint reference, or empty if it isn't known. See EndpointUUID
+	UUID string
+}
+
+// hasMAC returns true if any of macs is one of t's hardware addresses
+func (t *Tracked) hasMAC(macs []string) bool {
+	for _, mac := range macs {
+		for _, m := range t.MACs {
+			if mac == m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Watcher tracks devices by MAC address, so long-running integrations survive address changes (e.g. from DHCP).
+// Each Rescan probes the network with WS-Discovery and moves tracked devices that answer from a new address.
+// Discovered devices are matched by endpoint reference, or failing that, by querying the network interfaces at unknown addresses
+// with the Client of each tracked device that wasn't found. WS-Discovery Hello messages aren't listened for, so changes are only detected by Rescan.
+// The zero value is ready to use, and it is safe for concurrent use
+type Watcher struct {
+	// Options configures each Rescan's probe. It may be nil
+	Options *discovery.Options
+	// Probe, if set, is used by Rescan instead of discovery.Probe
+	Probe func(ctx context.Context, opts *discovery.Options) ([]*discovery.Device, error)
+	// OnChange, if set, is called by Rescan with the previous and current record of each device whose address changed.
+	// Service clients created from the previous Device should be recreated from the current one
+	OnChange func(prev, cur *Tracked)
+
+	mu      sync.Mutex
+	tracked []*Tracked
+}
+
+// MACAddresses returns the lower case hardware addresses of the device's network interfaces
+func (c *Client) MACAddresses(ctx context.Context) ([]string, error) {
+	ifaces, err := c.GetNetworkInterfacesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	macs := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		if iface.HwAddress != "" {
+			macs = append(macs, strings.ToLower(iface.HwAddress))
+		}
+	}
+	return macs, nil
+}
+
+// Add starts tracking dev, returning its record. dev's MAC addresses and endpoint reference are queried from the device.
+// An error is returned if the device has no MAC addresses
+func (w *Watcher) Add(ctx context.Context, dev *onvif.Device) (*Tracked, error) {
+	c, err := FromDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+
+	macs, err := c.MACAddresses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get MAC addresses: %w", err)
+	}
+	if len(macs) == 0 {
+		return nil, fmt.Errorf("could not get MAC addresses: %w", ErrNoMACAddress)
+	}
+
+	t := &Tracked{Device: dev, MACs: macs}
+	// the endpoint reference only speeds up matching, so devices without it are still tracked
+	if ref, err := c.GetEndpointReferenceContext(ctx); err == nil {
+		t.UUID = EndpointUUID(ref)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, prev := range w.tracked {
+		if prev.hasMAC(macs) {
+			w.tracked[i] = t
+			return t, nil
+		}
+	}
+	w.tracked = append(w.tracked, t)
+	return t, nil
+}
+
+// Remove stops tracking the device with the given MAC address
+func (w *Watcher) Remove(mac string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	mac = strings.ToLower(mac)
+	for i, t := range w.tracked {
+		if t.hasMAC([]string{mac}) {
+			w.tracked = append(w.tracked[:i], w.tracked[i+1:]...)
+			return
+		}
+	}
+}
+
+// Lookup returns the record of the device with the given MAC address, or nil if it isn't tracked
+func (w *Watcher) Lookup(mac string) *Tracked {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	mac = strings.ToLower(mac)
+	for _, t := range w.tracked {
+		if t.hasMAC([]string{mac}) {
+			return t
+		}
+	}
+	return nil
+}
+
+// Devices returns the records of the tracked devices
+func (w *Watcher) Devices() []*Tracked {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]*Tracked(nil), w.tracked...)
+}
+
+// Rescan probes the network and updates the records of tracked devices found at a new address,
+// returning the current records of the devices that changed. Records are replaced, not modified, so previously returned records stay valid.
+// Devices that aren't found are left unchanged
+func (w *Watcher) Rescan(ctx context.Context) ([]*Tracked, error) {
+	probe := w.Probe
+	if probe == nil {
+		probe = discovery.Probe
+	}
+	found, err := probe(ctx, w.Options)
+	if err != nil {
+		return nil, fmt.Errorf("could not probe: %w", err)
+	}
+
+	tracked := w.Devices()
+	// missing is the tracked devices that weren't found at their current address
+	missing := make(map[*Tracked]bool, len(tracked))
+	for _, t := range tracked {
+		missing[t] = true
+	}
+
+	var (
+		moved   []*discovery.Device
+		matched = make(map[*Tracked]*discovery.Device)
+	)
+	for _, d := range found {
+		if len(d.XAddrs) == 0 {
+			continue
+		}
+		if t := trackedAt(tracked, d.XAddrs); t != nil {
+			delete(missing, t)
+			continue
+		}
+		if t := trackedUUID(tracked, EndpointUUID(d.EndpointReference)); t != nil && missing[t] {
+			delete(missing, t)
+			matched[t] = d
+			continue
+		}
+		moved = append(moved, d)
+	}
+
+	// match the remaining devices by MAC address, using the credentials of each missing device
+	for _, d := range moved {
+		for t := range missing {
+			dev := &onvif.Device{Client: t.Client, Addr: d.XAddrs[0], Services: onvif.Services{{Namespace: onvif.NamespaceDevice, URL: d.XAddrs[0]}}}
+			c, err := FromDevice(dev)
+			if err != nil {
+				continue
+			}
+			macs, err := c.MACAddresses(ctx)
+			if err != nil || !t.hasMAC(macs) {
+				continue
+			}
+			delete(missing, t)
+			matched[t] = d
+			break
+		}
+	}
+
+	var changed []*Tracked
+	for prev, d := range matched {
+		dev, err := onvif.NewDevice(ctx, prev.Client, d.XAddrs[0])
+		if err != nil {
+			continue
+		}
+		cur := &Tracked{Device: dev, MACs: prev.MACs, UUID: prev.UUID}
+		if cur.UUID == "" {
+			cur.UUID = EndpointUUID(d.EndpointReference)
+		}
+
+		w.mu.Lock()
+		replaced := false
+		for i, t := range w.tracked {
+			if t == prev {
+				w.tracked[i] = cur
+				replaced = true
+				break
+			}
+		}
+		w.mu.Unlock()
+		if !replaced {
+			// removed or re-added during the rescan
+			continue
+		}
+
+		changed = append(changed, cur)
+		if w.OnChange != nil {
+			w.OnChange(prev, cur)
+		}
+	}
+
+	return changed, nil
+}
+
+// trackedAt returns the tracked device whose address has the same host and port as one of xaddrs, or nil
+func trackedAt(tracked []*Tracked, xaddrs []string) *Tracked {
+	for _, t := range tracked {
+		for _, addr := range xaddrs {
+			if sameHost(t.Addr, addr) {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// trackedUUID returns the tracked device with the given endpoint UUID, or nil
+func trackedUUID(tracked []*Tracked, uuid string) *Tracked {
+	if uuid == "" {
+		return nil
+	}
+	for _, t := range tracked {
+		if t.UUID == uuid {
+			return t
+		}
+	}
+	return nil
+}
+
+// sameHost returns true if the addresses (host:port pairs or URLs) have the same host and port
+func sameHost(a, b string) bool {
+	return addrHost(a) == addrHost(b)
+}
+
+// addrHost returns the host:port of addr, without the scheme's default port
+func addrHost(addr string) string {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return addr
+	}
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		return u.Hostname()
+	}
+	return u.Host
+}
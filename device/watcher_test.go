@@ -0,0 +1,107 @@
+package device_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/discovery"
+	"github.com/korylprince/go-onvif/onviftest"
+)
+
+// interfacesServer returns a Server whose network interface has the hardware address mac
+func interfacesServer(mac string) *onviftest.Server {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	srv.Respond("GetNetworkInterfaces", `<tds:GetNetworkInterfacesResponse><tds:NetworkInterfaces token="eth0">
+<tt:Enabled>true</tt:Enabled><tt:Info><tt:Name>eth0</tt:Name><tt:HwAddress>`+mac+`</tt:HwAddress><tt:MTU>1500</tt:MTU></tt:Info>
+</tds:NetworkInterfaces></tds:GetNetworkInterfacesResponse>`)
+	return srv
+}
+
+func TestWatcher(t *testing.T) {
+	old := interfacesServer("00:11:22:AA:BB:CC")
+	defer old.Close()
+	moved := interfacesServer("00:11:22:AA:BB:CC")
+	defer moved.Close()
+	other := interfacesServer("00:11:22:DD:EE:FF")
+	defer other.Close()
+
+	c := &onvif.Client{Username: "admin", Password: "password"}
+	dev, err := onvif.NewDevice(context.Background(), c, old.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+
+	var changes []*device.Tracked
+	w := &device.Watcher{
+		Probe: func(ctx context.Context, opts *discovery.Options) ([]*discovery.Device, error) {
+			return []*discovery.Device{
+				{EndpointReference: "urn:uuid:2", XAddrs: []string{other.URL + onviftest.PathDevice}},
+				{EndpointReference: "urn:uuid:1", XAddrs: []string{moved.URL + onviftest.PathDevice}},
+			}, nil
+		},
+		OnChange: func(prev, cur *device.Tracked) {
+			if prev.Device != dev {
+				t.Errorf("unexpected previous device: %#v", prev.Device)
+			}
+			changes = append(changes, cur)
+		},
+	}
+
+	tracked, err := w.Add(context.Background(), dev)
+	if err != nil {
+		t.Fatalf("could not add device: %v", err)
+	}
+	if len(tracked.MACs) != 1 || tracked.MACs[0] != "00:11:22:aa:bb:cc" {
+		t.Errorf("unexpected MAC addresses: %v", tracked.MACs)
+	}
+
+	changed, err := w.Rescan(context.Background())
+	if err != nil {
+		t.Fatalf("could not rescan: %v", err)
+	}
+	if len(changed) != 1 || len(changes) != 1 || changed[0] != changes[0] {
+		t.Fatalf("expected one change, got %v (OnChange: %v)", changed, changes)
+	}
+
+	cur := w.Lookup("00:11:22:AA:BB:CC")
+	if cur != changed[0] {
+		t.Errorf("expected current record, got %#v", cur)
+	}
+	if cur.Addr != moved.URL+onviftest.PathDevice {
+		t.Errorf("unexpected address: %q", cur.Addr)
+	}
+	if url := cur.Services.URL(onvif.NamespaceMedia); url != moved.URL+onviftest.PathMedia {
+		t.Errorf("unexpected media service url: %q", url)
+	}
+	if cur.UUID != "1" {
+		t.Errorf("expected endpoint reference from discovery, got %q", cur.UUID)
+	}
+
+	// the device is now found at its current address
+	if changed, err = w.Rescan(context.Background()); err != nil || len(changed) != 0 {
+		t.Errorf("expected no changes, got %v, %v", changed, err)
+	}
+
+	w.Remove("00:11:22:aa:bb:cc")
+	if len(w.Devices()) != 0 {
+		t.Errorf("expected no devices, got %v", w.Devices())
+	}
+}
+
+func TestWatcherNoMAC(t *testing.T) {
+	srv := interfacesServer("")
+	defer srv.Close()
+
+	c := &onvif.Client{Username: "admin", Password: "password"}
+	dev, err := onvif.NewDevice(context.Background(), c, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+
+	if _, err = new(device.Watcher).Add(context.Background(), dev); !errors.Is(err, device.ErrNoMACAddress) {
+		t.Errorf("expected no MAC address error, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package deviceio
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF device IO Capabilities type
+type Capabilities struct {
+	VideoSources        int  `xml:"VideoSources,attr"`
+	VideoOutputs        int  `xml:"VideoOutputs,attr"`
+	AudioSources        int  `xml:"AudioSources,attr"`
+	AudioOutputs        int  `xml:"AudioOutputs,attr"`
+	RelayOutputs        int  `xml:"RelayOutputs,attr"`
+	SerialPorts         int  `xml:"SerialPorts,attr"`
+	DigitalInputs       int  `xml:"DigitalInputs,attr"`
+	DigitalInputOptions bool `xml:"DigitalInputOptions,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tmd:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the device IO service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
@@ -0,0 +1,26 @@
+// Package deviceio implements typed operations for the ONVIF DeviceIO (ver10) service
+package deviceio
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF DeviceIO service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new DeviceIO service client using c to make requests to the DeviceIO service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceDeviceIO, soap.Namespaces{"tmd": onvif.NamespaceDeviceIO, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new DeviceIO service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
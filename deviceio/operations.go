@@ -0,0 +1,112 @@
+package deviceio
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetRelayOutputs is an ONVIF GetRelayOutputs operation
+type GetRelayOutputs struct {
+	XMLName xml.Name `xml:"tmd:GetRelayOutputs"`
+}
+
+// GetRelayOutputsResponse is an ONVIF GetRelayOutputsResponse response
+type GetRelayOutputsResponse struct {
+	RelayOutputs []*RelayOutput
+}
+
+// GetRelayOutputs returns the device's relay outputs
+func (c *Client) GetRelayOutputs() ([]*RelayOutput, error) {
+	return c.GetRelayOutputsContext(context.Background())
+}
+
+// GetRelayOutputsContext is like GetRelayOutputs, but ctx controls the request
+func (c *Client) GetRelayOutputsContext(ctx context.Context) ([]*RelayOutput, error) {
+	resp := new(GetRelayOutputsResponse)
+	if err := c.CallContext(ctx, &GetRelayOutputs{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.RelayOutputs, nil
+}
+
+// SetRelayOutputSettings is an ONVIF SetRelayOutputSettings operation
+type SetRelayOutputSettings struct {
+	XMLName     xml.Name     `xml:"tmd:SetRelayOutputSettings"`
+	RelayOutput *RelayOutput `xml:"tmd:RelayOutput"`
+}
+
+// SetRelayOutputSettings sets the properties of the relay output with output.Token
+func (c *Client) SetRelayOutputSettings(output *RelayOutput) error {
+	return c.SetRelayOutputSettingsContext(context.Background(), output)
+}
+
+// SetRelayOutputSettingsContext is like SetRelayOutputSettings, but ctx controls the request
+func (c *Client) SetRelayOutputSettingsContext(ctx context.Context, output *RelayOutput) error {
+	return c.CallContext(ctx, &SetRelayOutputSettings{RelayOutput: output}, nil)
+}
+
+// SetRelayOutputState is an ONVIF SetRelayOutputState operation
+type SetRelayOutputState struct {
+	XMLName          xml.Name          `xml:"tmd:SetRelayOutputState"`
+	RelayOutputToken string            `xml:"tmd:RelayOutputToken"`
+	LogicalState     RelayLogicalState `xml:"tmd:LogicalState"`
+}
+
+// SetRelayOutputState activates or deactivates the relay output with the given token.
+// A monostable relay returns to its idle state after its DelayTime
+func (c *Client) SetRelayOutputState(token string, state RelayLogicalState) error {
+	return c.SetRelayOutputStateContext(context.Background(), token, state)
+}
+
+// SetRelayOutputStateContext is like SetRelayOutputState, but ctx controls the request
+func (c *Client) SetRelayOutputStateContext(ctx context.Context, token string, state RelayLogicalState) error {
+	return c.CallContext(ctx, &SetRelayOutputState{RelayOutputToken: token, LogicalState: state}, nil)
+}
+
+// GetDigitalInputs is an ONVIF GetDigitalInputs operation
+type GetDigitalInputs struct {
+	XMLName xml.Name `xml:"tmd:GetDigitalInputs"`
+}
+
+// GetDigitalInputsResponse is an ONVIF GetDigitalInputsResponse response
+type GetDigitalInputsResponse struct {
+	DigitalInputs []*DigitalInput
+}
+
+// GetDigitalInputs returns the device's digital inputs. Input state changes are reported with events, e.g. tns1:Device/Trigger/DigitalInput
+func (c *Client) GetDigitalInputs() ([]*DigitalInput, error) {
+	return c.GetDigitalInputsContext(context.Background())
+}
+
+// GetDigitalInputsContext is like GetDigitalInputs, but ctx controls the request
+func (c *Client) GetDigitalInputsContext(ctx context.Context) ([]*DigitalInput, error) {
+	resp := new(GetDigitalInputsResponse)
+	if err := c.CallContext(ctx, &GetDigitalInputs{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.DigitalInputs, nil
+}
+
+// GetAudioOutputs is an ONVIF GetAudioOutputs operation
+type GetAudioOutputs struct {
+	XMLName xml.Name `xml:"tmd:GetAudioOutputs"`
+}
+
+// GetAudioOutputsResponse is an ONVIF GetAudioOutputsResponse response
+type GetAudioOutputsResponse struct {
+	Token []string
+}
+
+// GetAudioOutputs returns the tokens of the device's audio outputs
+func (c *Client) GetAudioOutputs() ([]string, error) {
+	return c.GetAudioOutputsContext(context.Background())
+}
+
+// GetAudioOutputsContext is like GetAudioOutputs, but ctx controls the request
+func (c *Client) GetAudioOutputsContext(ctx context.Context) ([]string, error) {
+	resp := new(GetAudioOutputsResponse)
+	if err := c.CallContext(ctx, &GetAudioOutputs{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Token, nil
+}
@@ -0,0 +1,71 @@
+package deviceio
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// RelayMode is an ONVIF RelayMode
+type RelayMode string
+
+// RelayModes
+const (
+	// RelayModeMonostable returns the relay to its idle state after DelayTime
+	RelayModeMonostable RelayMode = "Monostable"
+	// RelayModeBistable keeps the relay in its state until it's changed
+	RelayModeBistable RelayMode = "Bistable"
+)
+
+// RelayIdleState is an ONVIF RelayIdleState
+type RelayIdleState string
+
+// RelayIdleStates
+const (
+	RelayIdleStateClosed RelayIdleState = "closed"
+	RelayIdleStateOpen   RelayIdleState = "open"
+)
+
+// RelayLogicalState is an ONVIF RelayLogicalState
+type RelayLogicalState string
+
+// RelayLogicalStates
+const (
+	RelayLogicalStateActive   RelayLogicalState = "active"
+	RelayLogicalStateInactive RelayLogicalState = "inactive"
+)
+
+// RelayOutputSettings is an ONVIF RelayOutputSettings type
+type RelayOutputSettings struct {
+	Mode RelayMode
+	// DelayTime is the xsd:duration a monostable relay stays active, e.g. PT5S. See soap.FormatDuration
+	DelayTime string
+	IdleState RelayIdleState
+}
+
+// RelayOutput is an ONVIF RelayOutput type
+type RelayOutput struct {
+	Token      string `xml:"token,attr"`
+	Properties *RelayOutputSettings
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (r *RelayOutput) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type output RelayOutput
+	return soap.EncodeElementPrefixed(enc, (*output)(r), start, "tt")
+}
+
+// DigitalIdleState is an ONVIF DigitalIdleState
+type DigitalIdleState string
+
+// DigitalIdleStates
+const (
+	DigitalIdleStateClosed DigitalIdleState = "closed"
+	DigitalIdleStateOpen   DigitalIdleState = "open"
+)
+
+// DigitalInput is an ONVIF DigitalInput type
+type DigitalInput struct {
+	Token     string           `xml:"token,attr"`
+	IdleState DigitalIdleState `xml:"IdleState,attr"`
+}
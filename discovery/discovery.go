@@ -0,0 +1,259 @@
+// Package discovery finds ONVIF devices on the local network with WS-Discovery
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// WS-Discovery namespaces
+const (
+	NamespaceWSA       = "http://schemas.xmlsoap.org/ws/2004/08/addressing"
+	NamespaceDiscovery = "http://schemas.xmlsoap.org/ws/2005/04/discovery"
+	NamespaceNetwork   = "http://www.onvif.org/ver10/network/wsdl"
+)
+
+const (
+	// MulticastAddress is the WS-Discovery IPv4 multicast address
+	MulticastAddress = "239.255.255.250:3702"
+	// DefaultTimeout is how long Probe waits for responses if ctx has no deadline
+	DefaultTimeout = 3 * time.Second
+
+	actionProbe = "http://schemas.xmlsoap.org/ws/2005/04/discovery/Probe"
+	toDiscovery = "urn:schemas-xmlsoap-org:ws:2005:04:discovery"
+)
+
+// ProbeMessage is a WS-Discovery Probe message
+type ProbeMessage struct {
+	XMLName xml.Name `xml:"wsd:Probe"`
+	Types   string   `xml:"wsd:Types,omitempty"`
+	Scopes  string   `xml:"wsd:Scopes,omitempty"`
+}
+
+// ProbeMatch is a WS-Discovery ProbeMatch
+type ProbeMatch struct {
+	Address         string `xml:"EndpointReference>Address"`
+	Types           string
+	Scopes          string
+	XAddrs          string
+	MetadataVersion int
+}
+
+// ProbeMatches is a WS-Discovery ProbeMatches message
+type ProbeMatches struct {
+	ProbeMatch []*ProbeMatch
+}
+
+// Device is a device found by Probe
+type Device struct {
+	// EndpointReference is the device's endpoint reference address, e.g. urn:uuid:6b29fc40-ca47-1067-b31d-00dd010662da.
+	// It's stable across address changes
+	EndpointReference string
+	// Types is the device's types, e.g. dn:NetworkVideoTransmitter
+	Types []string
+	// Scopes is the device's scopes, e.g. onvif://www.onvif.org/hardware/M1234
+	Scopes []string
+	// XAddrs is the device service URLs
+	XAddrs []string
+	// MetadataVersion is incremented by the device when its metadata changes
+	MetadataVersion int
+}
+
+// Options configures Probe
+type Options struct {
+	// Interface, if set, sends the probe from this network interface (by binding to its first IPv4 address),
+	// for hosts with multiple networks
+	Interface *net.Interface
+	// Types is the device types to probe for. If empty, dn:NetworkVideoTransmitter is used
+	Types []string
+	// Scopes, if set, limits responses to devices with all of the scopes
+	Scopes []string
+	// Address is the address the probe is sent to. If empty, MulticastAddress is used
+	Address string
+}
+
+// Probe sends a WS-Discovery Probe and returns the devices that respond before ctx is done.
+// If ctx has no deadline, DefaultTimeout is used. opts may be nil.
+// Reaching the deadline is not an error; if ctx is canceled, the devices found so far are returned with ctx.Err()
+func Probe(ctx context.Context, opts *Options) ([]*Device, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+
+	var cancel context.CancelFunc
+	if _, ok := ctx.Deadline(); ok {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+	}
+	defer cancel()
+
+	addr := opts.Address
+	if addr == "" {
+		addr = MulticastAddress
+	}
+	raddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve address: %w", err)
+	}
+
+	laddr, err := localAddr(opts.Interface)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp4", laddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen: %w", err)
+	}
+	defer conn.Close()
+
+	msg, err := probeMessage(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.WriteTo(msg, raddr); err != nil {
+		return nil, fmt.Errorf("could not send probe: %w", err)
+	}
+
+	// unblock ReadFrom when ctx is done
+	go func() {
+		<-ctx.Done()
+		conn.SetReadDeadline(time.Now())
+	}()
+
+	var (
+		devices []*Device
+		seen    = make(map[string]bool)
+		buf     = make([]byte, 65535)
+	)
+
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return devices, nil
+				}
+				return devices, ctx.Err()
+			}
+			return devices, fmt.Errorf("could not read response: %w", err)
+		}
+
+		// ignore invalid responses from misbehaving devices
+		matches, err := parseProbeMatches(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		for _, m := range matches {
+			d := m.device()
+			if seen[d.EndpointReference] {
+				continue
+			}
+			seen[d.EndpointReference] = true
+			devices = append(devices, d)
+		}
+	}
+}
+
+// localAddr returns the address to bind to for iface, or nil for any address
+func localAddr(iface *net.Interface) (*net.UDPAddr, error) {
+	if iface == nil {
+		return nil, nil
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("could not get interface addresses: %w", err)
+	}
+
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			return &net.UDPAddr{IP: ipnet.IP}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s has no IPv4 address", iface.Name)
+}
+
+// probeMessage returns the marshaled Probe envelope
+func probeMessage(opts *Options) ([]byte, error) {
+	types := opts.Types
+	if len(types) == 0 {
+		types = []string{"dn:NetworkVideoTransmitter"}
+	}
+
+	body, err := xml.Marshal(&ProbeMessage{Types: strings.Join(types, " "), Scopes: strings.Join(opts.Scopes, " ")})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal probe: %w", err)
+	}
+
+	id, err := messageID()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &soap.Envelope{
+		Namespaces: soap.Namespaces{"wsa": NamespaceWSA, "wsd": NamespaceDiscovery, "dn": NamespaceNetwork},
+		Header:     &soap.Header{Addressing: &soap.Addressing{Namespace: NamespaceWSA, Action: actionProbe, To: toDiscovery, MessageID: id}},
+		Body:       &soap.Body{InnerXML: body},
+	}
+
+	buf := bytes.NewBufferString(xml.Header)
+	if err = xml.NewEncoder(buf).Encode(env); err != nil {
+		return nil, fmt.Errorf("could not marshal envelope: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// messageID returns a random urn:uuid message ID
+func messageID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate message id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// parseProbeMatches parses a ProbeMatches envelope
+func parseProbeMatches(buf []byte) ([]*ProbeMatch, error) {
+	env := &soap.Envelope{Strictness: soap.StrictnessWarn}
+	if err := xml.Unmarshal(buf, env); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	if env.Body.Fault != nil {
+		return nil, env.Body.Fault
+	}
+
+	matches := new(ProbeMatches)
+	if err := env.Body.Unmarshal(matches); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+	}
+
+	return matches.ProbeMatch, nil
+}
+
+func (m *ProbeMatch) device() *Device {
+	return &Device{
+		EndpointReference: strings.TrimSpace(m.Address),
+		Types:             strings.Fields(m.Types),
+		Scopes:            strings.Fields(m.Scopes),
+		XAddrs:            strings.Fields(m.XAddrs),
+		MetadataVersion:   m.MetadataVersion,
+	}
+}
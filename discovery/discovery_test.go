@@ -0,0 +1,64 @@
+package discovery_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif/discovery"
+)
+
+const probeMatches = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:wsa="http://schemas.xmlsoap.org/ws/2004/08/addressing" xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery" xmlns:dn="http://www.onvif.org/ver10/network/wsdl">
+<env:Header><wsa:Action>http://schemas.xmlsoap.org/ws/2005/04/discovery/ProbeMatches</wsa:Action></env:Header>
+<env:Body><d:ProbeMatches><d:ProbeMatch>
+<wsa:EndpointReference><wsa:Address>urn:uuid:6b29fc40-ca47-1067-b31d-00dd010662da</wsa:Address></wsa:EndpointReference>
+<d:Types>dn:NetworkVideoTransmitter</d:Types>
+<d:Scopes>onvif://www.onvif.org/hardware/M1234 onvif://www.onvif.org/name/Camera</d:Scopes>
+<d:XAddrs>http://192.168.0.64/onvif/device_service</d:XAddrs>
+<d:MetadataVersion>1</d:MetadataVersion>
+</d:ProbeMatch></d:ProbeMatches></env:Body>
+</env:Envelope>`
+
+func TestProbe(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 65535)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil || !bytes.Contains(buf[:n], []byte("<wsd:Types>dn:NetworkVideoTransmitter</wsd:Types>")) {
+			return
+		}
+		// respond twice to check duplicates are removed
+		conn.WriteTo([]byte(probeMatches), addr)
+		conn.WriteTo([]byte(probeMatches), addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	devices, err := discovery.Probe(ctx, &discovery.Options{Address: conn.LocalAddr().String()})
+	if err != nil {
+		t.Fatalf("could not probe: %v", err)
+	}
+
+	if len(devices) != 1 {
+		t.Fatalf("expected 1 device, got %d", len(devices))
+	}
+	d := devices[0]
+	if d.EndpointReference != "urn:uuid:6b29fc40-ca47-1067-b31d-00dd010662da" {
+		t.Errorf("unexpected endpoint reference: %q", d.EndpointReference)
+	}
+	if len(d.Scopes) != 2 {
+		t.Errorf("expected 2 scopes, got %d", len(d.Scopes))
+	}
+	if len(d.XAddrs) != 1 || d.XAddrs[0] != "http://192.168.0.64/onvif/device_service" {
+		t.Errorf("unexpected XAddrs: %v", d.XAddrs)
+	}
+}
@@ -0,0 +1,26 @@
+// Package display implements typed operations for the ONVIF Display (ver10) service
+package display
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Display service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Display service client using c to make requests to the Display service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceDisplay, soap.Namespaces{"tls": onvif.NamespaceDisplay, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Display service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
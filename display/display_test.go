@@ -0,0 +1,119 @@
+package display_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/display"
+)
+
+const responseEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tls="http://www.onvif.org/ver10/display/wsdl"
+xmlns:trv="http://www.onvif.org/ver10/receiver/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+const faultResponse = `<env:Fault><env:Code><env:Value>env:Sender</env:Value>
+<env:Subcode><env:Value>ter:InvalidArgVal</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">Invalid pane</env:Text></env:Reason></env:Fault>`
+
+func newServer(t *testing.T, failSet bool) (*httptest.Server, func() []string) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case bytes.Contains(buf, []byte("<tls:GetPaneConfiguration>")):
+			requests = append(requests, "GetPaneConfiguration")
+			fmt.Fprintf(w, responseEnvelope, `<tls:GetPaneConfigurationResponse><tls:PaneConfiguration><tt:PaneName>Pane 1</tt:PaneName>
+<tt:ReceiverToken>Receiver_0</tt:ReceiverToken><tt:Token>Pane_1</tt:Token></tls:PaneConfiguration></tls:GetPaneConfigurationResponse>`)
+		case bytes.Contains(buf, []byte("<trv:CreateReceiver>")):
+			if !bytes.Contains(buf, []byte("<trv:Configuration><tt:Mode>AlwaysConnect</tt:Mode><tt:MediaUri>rtsp://camera/stream1</tt:MediaUri>"+
+				"<tt:StreamSetup><tt:Stream>RTP-Unicast</tt:Stream><tt:Transport><tt:Protocol>RTSP</tt:Protocol></tt:Transport></tt:StreamSetup></trv:Configuration>")) {
+				t.Errorf("unexpected receiver configuration: %s", buf)
+			}
+			requests = append(requests, "CreateReceiver")
+			fmt.Fprintf(w, responseEnvelope, `<trv:CreateReceiverResponse><trv:Receiver><tt:Token>Receiver_1</tt:Token></trv:Receiver></trv:CreateReceiverResponse>`)
+		case bytes.Contains(buf, []byte("<tls:SetPaneConfiguration>")):
+			if !bytes.Contains(buf, []byte("<tls:VideoOutput>VideoOutput_1</tls:VideoOutput><tls:PaneConfiguration><tt:PaneName>Pane 1</tt:PaneName>")) ||
+				!bytes.Contains(buf, []byte("<tt:Token>Pane_1</tt:Token>")) {
+				t.Errorf("unexpected pane configuration: %s", buf)
+			}
+			start := bytes.Index(buf, []byte("<tt:ReceiverToken>")) + len("<tt:ReceiverToken>")
+			requests = append(requests, "SetPaneConfiguration "+string(buf[start:start+bytes.IndexByte(buf[start:], '<')]))
+			if failSet {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, responseEnvelope, faultResponse)
+				return
+			}
+			fmt.Fprintf(w, responseEnvelope, `<tls:SetPaneConfigurationResponse/>`)
+		case bytes.Contains(buf, []byte("<trv:DeleteReceiver>")):
+			if !bytes.Contains(buf, []byte("<trv:ReceiverToken>Receiver_1</trv:ReceiverToken>")) {
+				t.Errorf("unexpected receiver: %s", buf)
+			}
+			requests = append(requests, "DeleteReceiver")
+			fmt.Fprintf(w, responseEnvelope, `<trv:DeleteReceiverResponse/>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func newDevice(srv *httptest.Server) *onvif.Device {
+	return &onvif.Device{Client: &onvif.Client{}, Services: onvif.Services{
+		{Namespace: onvif.NamespaceDisplay, URL: srv.URL},
+		{Namespace: onvif.NamespaceReceiver, URL: srv.URL},
+	}}
+}
+
+func TestConnect(t *testing.T) {
+	srv, requests := newServer(t, false)
+	defer srv.Close()
+
+	c, err := display.Connect(context.Background(), newDevice(srv), "VideoOutput_1", "Pane_1", "rtsp://camera/stream1")
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	if c.ReceiverToken != "Receiver_1" {
+		t.Errorf("unexpected receiver token: %s", c.ReceiverToken)
+	}
+	if err = c.Disconnect(context.Background()); err != nil {
+		t.Errorf("could not disconnect: %v", err)
+	}
+
+	// the pane's previous receiver is restored before the created receiver is deleted
+	if r := fmt.Sprint(requests()); r != "[GetPaneConfiguration CreateReceiver SetPaneConfiguration Receiver_1 "+
+		"GetPaneConfiguration SetPaneConfiguration Receiver_0 DeleteReceiver]" {
+		t.Errorf("unexpected requests: %s", r)
+	}
+}
+
+func TestConnectFailure(t *testing.T) {
+	srv, requests := newServer(t, true)
+	defer srv.Close()
+
+	if _, err := display.Connect(context.Background(), newDevice(srv), "VideoOutput_1", "Pane_1", "rtsp://camera/stream1"); err == nil ||
+		!strings.Contains(err.Error(), "could not set pane configuration") {
+		t.Errorf("expected pane configuration error, got %v", err)
+	}
+	if r := fmt.Sprint(requests()); r != "[GetPaneConfiguration CreateReceiver SetPaneConfiguration Receiver_1 DeleteReceiver]" {
+		t.Errorf("unexpected requests: %s", r)
+	}
+}
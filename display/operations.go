@@ -0,0 +1,99 @@
+package display
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetLayout is an ONVIF GetLayout operation
+type GetLayout struct {
+	XMLName     xml.Name `xml:"tls:GetLayout"`
+	VideoOutput string   `xml:"tls:VideoOutput"`
+}
+
+// GetLayoutResponse is an ONVIF GetLayoutResponse response
+type GetLayoutResponse struct {
+	Layout *Layout
+}
+
+// GetLayout returns the pane layout of the video output with the given token. Video outputs are listed by the device IO service
+func (c *Client) GetLayout(videoOutputToken string) (*Layout, error) {
+	return c.GetLayoutContext(context.Background(), videoOutputToken)
+}
+
+// GetLayoutContext is like GetLayout, but ctx controls the request
+func (c *Client) GetLayoutContext(ctx context.Context, videoOutputToken string) (*Layout, error) {
+	resp := new(GetLayoutResponse)
+	if err := c.CallContext(ctx, &GetLayout{VideoOutput: videoOutputToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Layout, nil
+}
+
+// GetPaneConfigurations is an ONVIF GetPaneConfigurations operation
+type GetPaneConfigurations struct {
+	XMLName     xml.Name `xml:"tls:GetPaneConfigurations"`
+	VideoOutput string   `xml:"tls:VideoOutput"`
+}
+
+// GetPaneConfigurationsResponse is an ONVIF GetPaneConfigurationsResponse response
+type GetPaneConfigurationsResponse struct {
+	PaneConfiguration []*PaneConfiguration
+}
+
+// GetPaneConfigurations returns the configurations of the panes of the video output with the given token
+func (c *Client) GetPaneConfigurations(videoOutputToken string) ([]*PaneConfiguration, error) {
+	return c.GetPaneConfigurationsContext(context.Background(), videoOutputToken)
+}
+
+// GetPaneConfigurationsContext is like GetPaneConfigurations, but ctx controls the request
+func (c *Client) GetPaneConfigurationsContext(ctx context.Context, videoOutputToken string) ([]*PaneConfiguration, error) {
+	resp := new(GetPaneConfigurationsResponse)
+	if err := c.CallContext(ctx, &GetPaneConfigurations{VideoOutput: videoOutputToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.PaneConfiguration, nil
+}
+
+// GetPaneConfiguration is an ONVIF GetPaneConfiguration operation
+type GetPaneConfiguration struct {
+	XMLName     xml.Name `xml:"tls:GetPaneConfiguration"`
+	VideoOutput string   `xml:"tls:VideoOutput"`
+	Pane        string   `xml:"tls:Pane"`
+}
+
+// GetPaneConfigurationResponse is an ONVIF GetPaneConfigurationResponse response
+type GetPaneConfigurationResponse struct {
+	PaneConfiguration *PaneConfiguration
+}
+
+// GetPaneConfiguration returns the configuration of the pane with the given token on the video output with the given token
+func (c *Client) GetPaneConfiguration(videoOutputToken, paneToken string) (*PaneConfiguration, error) {
+	return c.GetPaneConfigurationContext(context.Background(), videoOutputToken, paneToken)
+}
+
+// GetPaneConfigurationContext is like GetPaneConfiguration, but ctx controls the request
+func (c *Client) GetPaneConfigurationContext(ctx context.Context, videoOutputToken, paneToken string) (*PaneConfiguration, error) {
+	resp := new(GetPaneConfigurationResponse)
+	if err := c.CallContext(ctx, &GetPaneConfiguration{VideoOutput: videoOutputToken, Pane: paneToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.PaneConfiguration, nil
+}
+
+// SetPaneConfiguration is an ONVIF SetPaneConfiguration operation
+type SetPaneConfiguration struct {
+	XMLName           xml.Name           `xml:"tls:SetPaneConfiguration"`
+	VideoOutput       string             `xml:"tls:VideoOutput"`
+	PaneConfiguration *PaneConfiguration `xml:"tls:PaneConfiguration"`
+}
+
+// SetPaneConfiguration sets the configuration of the pane with pane.Token on the video output with the given token
+func (c *Client) SetPaneConfiguration(videoOutputToken string, pane *PaneConfiguration) error {
+	return c.SetPaneConfigurationContext(context.Background(), videoOutputToken, pane)
+}
+
+// SetPaneConfigurationContext is like SetPaneConfiguration, but ctx controls the request
+func (c *Client) SetPaneConfigurationContext(ctx context.Context, videoOutputToken string, pane *PaneConfiguration) error {
+	return c.CallContext(ctx, &SetPaneConfiguration{VideoOutput: videoOutputToken, PaneConfiguration: pane}, nil)
+}
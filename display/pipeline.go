@@ -0,0 +1,89 @@
+package display
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/receiver"
+)
+
+// Connection is a camera stream shown on a display pane by Connect
+type Connection struct {
+	VideoOutputToken string
+	PaneToken        string
+	// ReceiverToken is the token of the receiver created for the stream
+	ReceiverToken string
+
+	// previous is the token of the pane's receiver before Connect
+	previous string
+	display  *Client
+	receiver *receiver.Client
+}
+
+// Connect creates a receiver on the decoder dev for the RTSP stream at uri (e.g. from media.Client.GetStreamUri),
+// and shows it on the pane with paneToken of the video output with videoOutputToken.
+// If the pane can't be configured, the receiver is deleted. Call Connection.Disconnect to tear the pipeline down
+func Connect(ctx context.Context, dev *onvif.Device, videoOutputToken, paneToken, uri string) (*Connection, error) {
+	dc, err := FromDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := receiver.FromDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+
+	pane, err := dc.GetPaneConfigurationContext(ctx, videoOutputToken, paneToken)
+	if err != nil {
+		return nil, fmt.Errorf("could not get pane configuration: %w", err)
+	}
+
+	r, err := rc.CreateReceiverContext(ctx, &receiver.Configuration{
+		Mode:        receiver.ModeAlwaysConnect,
+		MediaURI:    uri,
+		StreamSetup: &receiver.StreamSetup{Stream: media.StreamTypeUnicast, Protocol: media.TransportProtocolRTSP},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create receiver: %w", err)
+	}
+
+	c := &Connection{
+		VideoOutputToken: videoOutputToken,
+		PaneToken:        paneToken,
+		ReceiverToken:    r.Token,
+		previous:         pane.ReceiverToken,
+		display:          dc,
+		receiver:         rc,
+	}
+
+	pane.Token = paneToken
+	pane.ReceiverToken = r.Token
+	if err = dc.SetPaneConfigurationContext(ctx, videoOutputToken, pane); err != nil {
+		err = fmt.Errorf("could not set pane configuration: %w", err)
+		if derr := rc.DeleteReceiverContext(ctx, r.Token); derr != nil {
+			return nil, fmt.Errorf("%w (could not delete receiver: %v)", err, derr)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// Disconnect shows the pane's previous receiver, if any, and deletes the receiver created by Connect
+func (c *Connection) Disconnect(ctx context.Context) error {
+	pane, err := c.display.GetPaneConfigurationContext(ctx, c.VideoOutputToken, c.PaneToken)
+	if err != nil {
+		return fmt.Errorf("could not get pane configuration: %w", err)
+	}
+	pane.Token = c.PaneToken
+	pane.ReceiverToken = c.previous
+	if err = c.display.SetPaneConfigurationContext(ctx, c.VideoOutputToken, pane); err != nil {
+		return fmt.Errorf("could not set pane configuration: %w", err)
+	}
+
+	if err = c.receiver.DeleteReceiverContext(ctx, c.ReceiverToken); err != nil {
+		return fmt.Errorf("could not delete receiver: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package display
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Rectangle is an ONVIF Rectangle type. Coordinates are normalized to -1 to 1
+type Rectangle struct {
+	Bottom float64 `xml:"bottom,attr"`
+	Top    float64 `xml:"top,attr"`
+	Right  float64 `xml:"right,attr"`
+	Left   float64 `xml:"left,attr"`
+}
+
+// PaneLayout is an ONVIF PaneLayout type, the position of a pane on a video output
+type PaneLayout struct {
+	// Pane is the token of the pane
+	Pane string
+	Area *Rectangle
+}
+
+// Layout is an ONVIF Layout type
+type Layout struct {
+	PaneLayout []*PaneLayout
+}
+
+// PaneConfiguration is an ONVIF PaneConfiguration type
+type PaneConfiguration struct {
+	PaneName         string `xml:",omitempty"`
+	AudioOutputToken string `xml:",omitempty"`
+	AudioSourceToken string `xml:",omitempty"`
+	// ReceiverToken is the token of the receiver whose stream is shown on the pane. See receiver.Client
+	ReceiverToken string `xml:",omitempty"`
+	Token         string
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (p *PaneConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type pane PaneConfiguration
+	return soap.EncodeElementPrefixed(enc, (*pane)(p), start, "tt")
+}
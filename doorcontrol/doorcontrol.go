@@ -0,0 +1,26 @@
+// Package doorcontrol implements typed operations for the ONVIF Door Control (ver10) service
+package doorcontrol
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Door Control service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Door Control service client using c to make requests to the Door Control service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceDoorControl, soap.Namespaces{"tdc": onvif.NamespaceDoorControl, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Door Control service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
@@ -0,0 +1,166 @@
+package doorcontrol
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif"
+)
+
+// GetDoorInfoList is an ONVIF GetDoorInfoList operation
+type GetDoorInfoList struct {
+	XMLName xml.Name `xml:"tdc:GetDoorInfoList"`
+	// Limit is the maximum number of doors to return. If zero, the device's limit is used
+	Limit int `xml:"tdc:Limit,omitempty"`
+	// StartReference is the NextStartReference from a previous response. If empty, the first page is returned
+	StartReference string `xml:"tdc:StartReference,omitempty"`
+}
+
+// GetDoorInfoListResponse is an ONVIF GetDoorInfoListResponse response
+type GetDoorInfoListResponse struct {
+	// NextStartReference is empty if there are no more doors
+	NextStartReference string
+	DoorInfo           []*DoorInfo
+}
+
+// GetDoorInfoList returns a page of doors. See GetAllDoorInfo to get all pages
+func (c *Client) GetDoorInfoList(req *GetDoorInfoList) (*GetDoorInfoListResponse, error) {
+	return c.GetDoorInfoListContext(context.Background(), req)
+}
+
+// GetDoorInfoListContext is like GetDoorInfoList, but ctx controls the request
+func (c *Client) GetDoorInfoListContext(ctx context.Context, req *GetDoorInfoList) (*GetDoorInfoListResponse, error) {
+	resp := new(GetDoorInfoListResponse)
+	if err := c.CallContext(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetAllDoorInfo returns all of the device's doors, calling GetDoorInfoList until there are no more pages. See onvif.Pages
+func (c *Client) GetAllDoorInfo() ([]*DoorInfo, error) {
+	return c.GetAllDoorInfoContext(context.Background())
+}
+
+// GetAllDoorInfoContext is like GetAllDoorInfo, but ctx controls the requests
+func (c *Client) GetAllDoorInfoContext(ctx context.Context) ([]*DoorInfo, error) {
+	return onvif.Pages(ctx, func(ctx context.Context, start string) ([]*DoorInfo, string, error) {
+		resp, err := c.GetDoorInfoListContext(ctx, &GetDoorInfoList{StartReference: start})
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.DoorInfo, resp.NextStartReference, nil
+	})
+}
+
+// GetDoorState is an ONVIF GetDoorState operation
+type GetDoorState struct {
+	XMLName xml.Name `xml:"tdc:GetDoorState"`
+	Token   string   `xml:"tdc:Token"`
+}
+
+// GetDoorStateResponse is an ONVIF GetDoorStateResponse response
+type GetDoorStateResponse struct {
+	DoorState *DoorState
+}
+
+// GetDoorState returns the state of the door with the given token
+func (c *Client) GetDoorState(token string) (*DoorState, error) {
+	return c.GetDoorStateContext(context.Background(), token)
+}
+
+// GetDoorStateContext is like GetDoorState, but ctx controls the request
+func (c *Client) GetDoorStateContext(ctx context.Context, token string) (*DoorState, error) {
+	resp := new(GetDoorStateResponse)
+	if err := c.CallContext(ctx, &GetDoorState{Token: token}, resp); err != nil {
+		return nil, err
+	}
+	return resp.DoorState, nil
+}
+
+// AccessDoor is an ONVIF AccessDoor operation
+type AccessDoor struct {
+	XMLName xml.Name `xml:"tdc:AccessDoor"`
+	Token   string   `xml:"tdc:Token"`
+	// UseExtendedTime, if set, uses the door's extended access times, e.g. for disabled persons
+	UseExtendedTime *bool `xml:"tdc:UseExtendedTime,omitempty"`
+	// AccessTime, OpenTooLongTime, and PreAlarmTime are xsd:durations that override the door's configured times if the door supports AccessTimingOverride.
+	// See soap.FormatDuration
+	AccessTime      string `xml:"tdc:AccessTime,omitempty"`
+	OpenTooLongTime string `xml:"tdc:OpenTooLongTime,omitempty"`
+	PreAlarmTime    string `xml:"tdc:PreAlarmTime,omitempty"`
+}
+
+// AccessDoor momentarily unlocks the door with req.Token, e.g. to let a person through
+func (c *Client) AccessDoor(req *AccessDoor) error {
+	return c.AccessDoorContext(context.Background(), req)
+}
+
+// AccessDoorContext is like AccessDoor, but ctx controls the request
+func (c *Client) AccessDoorContext(ctx context.Context, req *AccessDoor) error {
+	return c.CallContext(ctx, req, nil)
+}
+
+// LockDoor is an ONVIF LockDoor operation
+type LockDoor struct {
+	XMLName xml.Name `xml:"tdc:LockDoor"`
+	Token   string   `xml:"tdc:Token"`
+}
+
+// LockDoor locks the door with the given token
+func (c *Client) LockDoor(token string) error {
+	return c.LockDoorContext(context.Background(), token)
+}
+
+// LockDoorContext is like LockDoor, but ctx controls the request
+func (c *Client) LockDoorContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &LockDoor{Token: token}, nil)
+}
+
+// UnlockDoor is an ONVIF UnlockDoor operation
+type UnlockDoor struct {
+	XMLName xml.Name `xml:"tdc:UnlockDoor"`
+	Token   string   `xml:"tdc:Token"`
+}
+
+// UnlockDoor unlocks the door with the given token until it's locked again
+func (c *Client) UnlockDoor(token string) error {
+	return c.UnlockDoorContext(context.Background(), token)
+}
+
+// UnlockDoorContext is like UnlockDoor, but ctx controls the request
+func (c *Client) UnlockDoorContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &UnlockDoor{Token: token}, nil)
+}
+
+// BlockDoor is an ONVIF BlockDoor operation
+type BlockDoor struct {
+	XMLName xml.Name `xml:"tdc:BlockDoor"`
+	Token   string   `xml:"tdc:Token"`
+}
+
+// BlockDoor locks the door with the given token and blocks access requests until it's unlocked or locked
+func (c *Client) BlockDoor(token string) error {
+	return c.BlockDoorContext(context.Background(), token)
+}
+
+// BlockDoorContext is like BlockDoor, but ctx controls the request
+func (c *Client) BlockDoorContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &BlockDoor{Token: token}, nil)
+}
+
+// DoubleLockDoor is an ONVIF DoubleLockDoor operation
+type DoubleLockDoor struct {
+	XMLName xml.Name `xml:"tdc:DoubleLockDoor"`
+	Token   string   `xml:"tdc:Token"`
+}
+
+// DoubleLockDoor locks the door with the given token with its extra (double) lock
+func (c *Client) DoubleLockDoor(token string) error {
+	return c.DoubleLockDoorContext(context.Background(), token)
+}
+
+// DoubleLockDoorContext is like DoubleLockDoor, but ctx controls the request
+func (c *Client) DoubleLockDoorContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &DoubleLockDoor{Token: token}, nil)
+}
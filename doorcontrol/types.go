@@ -0,0 +1,70 @@
+package doorcontrol
+
+// DoorCapabilities is an ONVIF DoorCapabilities type, which lists the operations and monitoring a door supports
+type DoorCapabilities struct {
+	Access               bool `xml:"Access,attr"`
+	AccessTimingOverride bool `xml:"AccessTimingOverride,attr"`
+	Lock                 bool `xml:"Lock,attr"`
+	Unlock               bool `xml:"Unlock,attr"`
+	Block                bool `xml:"Block,attr"`
+	DoubleLock           bool `xml:"DoubleLock,attr"`
+	LockDown             bool `xml:"LockDown,attr"`
+	LockOpen             bool `xml:"LockOpen,attr"`
+	DoorMonitor          bool `xml:"DoorMonitor,attr"`
+	LockMonitor          bool `xml:"LockMonitor,attr"`
+	DoubleLockMonitor    bool `xml:"DoubleLockMonitor,attr"`
+	Alarm                bool `xml:"Alarm,attr"`
+	Tamper               bool `xml:"Tamper,attr"`
+	Fault                bool `xml:"Fault,attr"`
+}
+
+// DoorInfo is an ONVIF DoorInfo type
+type DoorInfo struct {
+	Token        string `xml:"token,attr"`
+	Name         string
+	Description  string
+	Capabilities *DoorCapabilities
+}
+
+// DoorMode is an ONVIF DoorMode
+type DoorMode string
+
+// DoorModes
+const (
+	DoorModeUnknown      DoorMode = "Unknown"
+	DoorModeLocked       DoorMode = "Locked"
+	DoorModeUnlocked     DoorMode = "Unlocked"
+	DoorModeAccessed     DoorMode = "Accessed"
+	DoorModeBlocked      DoorMode = "Blocked"
+	DoorModeLockedDown   DoorMode = "LockedDown"
+	DoorModeLockedOpen   DoorMode = "LockedOpen"
+	DoorModeDoubleLocked DoorMode = "DoubleLocked"
+)
+
+// DoorTamper is an ONVIF DoorTamper type
+type DoorTamper struct {
+	Reason string
+	// State is Unknown, NotInTamper, or TamperDetected
+	State string
+}
+
+// DoorFault is an ONVIF DoorFault type
+type DoorFault struct {
+	Reason string
+	// State is Unknown, NotInFault, or FaultDetected
+	State string
+}
+
+// DoorState is an ONVIF DoorState type. Fields are empty if the door doesn't support monitoring them. See DoorCapabilities
+type DoorState struct {
+	// DoorPhysicalState is Unknown, Open, Closed, or Fault
+	DoorPhysicalState string
+	// LockPhysicalState and DoubleLockPhysicalState are Unknown, Locked, Unlocked, or Fault
+	LockPhysicalState       string
+	DoubleLockPhysicalState string
+	// Alarm is Normal, DoorForcedOpen, or DoorOpenTooLong
+	Alarm    string
+	Tamper   *DoorTamper
+	Fault    *DoorFault
+	DoorMode DoorMode
+}
@@ -0,0 +1,129 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/icholy/digest"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Download fetches uri (e.g. a URI returned by GetSystemUris) and writes the content to w, returning the number of bytes written.
+// The Client's HTTPClient and credentials are used. If the device requests HTTP digest or basic authentication, the request is retried with it.
+// If the Client's AuthMode is AuthModeDigest, digest authentication is used from the start
+func (c *Client) Download(uri string, w io.Writer) (int64, error) {
+	return c.DownloadContext(context.Background(), uri, w)
+}
+
+// DownloadContext is like Download, but ctx controls the request
+func (c *Client) DownloadContext(ctx context.Context, uri string, w io.Writer) (int64, error) {
+	if !c.acquire() {
+		return 0, ErrClientClosed
+	}
+	defer c.release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not create http request: %w", err)
+	}
+
+	client, req, cred, err := c.transferClient(req)
+	if err != nil {
+		return 0, err
+	}
+	defer cred.release()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("could not GET uri: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		cred, err := c.clientCredentials()
+		if err != nil {
+			resp.Body.Close()
+			return 0, err
+		}
+		defer cred.release()
+		if cred != nil {
+			resp.Body.Close()
+			resp, err = c.downloadAuth(req, cred, resp.Header.Values("WWW-Authenticate"))
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return 0, &soap.UnauthorizedError{Err: errors.New(resp.Status)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("could not read response body: %w", err)
+	}
+
+	return n, nil
+}
+
+// transferClient returns the *http.Client to use for req (a Download or Upload request).
+// If the Client is known to use AuthModeDigest, the Client's digest transport is used with req's credentials attached,
+// so the saved challenge is reused instead of negotiating digest authentication for each request.
+// The attached credentials are returned, or nil if none are attached. The caller should call release when the request is finished
+func (c *Client) transferClient(req *http.Request) (*http.Client, *http.Request, *credentials, error) {
+	if c.CurrentAuthMode() != AuthModeDigest {
+		return c.httpClient(AuthModeNone), req, nil, nil
+	}
+
+	cred, err := c.clientCredentials()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cred == nil {
+		return c.httpClient(AuthModeNone), req, nil, nil
+	}
+
+	return c.httpClient(AuthModeDigest), req.WithContext(context.WithValue(req.Context(), credentialsKey{}, cred)), cred, nil
+}
+
+// downloadAuth retries req (a Download or Upload request) with the authentication requested in the challenges
+func (c *Client) downloadAuth(req *http.Request, cred *credentials, challenges []string) (*http.Response, error) {
+	client := *c.httpClient(AuthModeNone)
+	req = req.Clone(context.WithValue(req.Context(), credentialsKey{}, cred))
+
+	var isDigest bool
+	for _, chal := range challenges {
+		if digest.IsDigest(chal) {
+			isDigest = true
+		}
+	}
+
+	switch {
+	case isDigest:
+		// don't wrap the digest transport again if it already failed
+		if _, ok := client.Transport.(*digest.Transport); !ok {
+			client.Transport = &digest.Transport{Transport: client.Transport, Digest: digestCredentials}
+		}
+	default:
+		for _, chal := range challenges {
+			if strings.HasPrefix(strings.ToLower(chal), "basic") {
+				req.SetBasicAuth(cred.username, string(cred.password))
+			}
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not %s uri: %w", req.Method, err)
+	}
+
+	return resp, nil
+}
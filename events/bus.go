@@ -0,0 +1,152 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Bus shares the notifications of one subscription (usually a pull point) between consumers in the same application,
+// each with its own topic filter applied client-side, so consumers don't each use one of the device's limited subscriptions.
+// Each consumer has a buffered channel; notifications for a consumer whose buffer is full are dropped rather than blocking the others.
+// The zero value is ready to use, and it is safe for concurrent use
+type Bus struct {
+	mu        sync.Mutex
+	consumers map[*Consumer]struct{}
+	closed    bool
+}
+
+// Consumer receives the notifications a Bus delivers to it. See Bus.Subscribe
+type Consumer struct {
+	c      chan *Notification
+	topics []string
+	match  func(n *Notification) bool
+
+	// mu protects dropped
+	mu      sync.Mutex
+	dropped int
+}
+
+// Notifications returns the channel notifications are delivered on. It's closed by Bus.Unsubscribe and Bus.Close
+func (c *Consumer) Notifications() <-chan *Notification {
+	return c.c
+}
+
+// Dropped returns the number of notifications dropped because the consumer's buffer was full
+func (c *Consumer) Dropped() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dropped
+}
+
+// matches returns true if n is one of c's topics (or a subtopic) and match returns true for it
+func (c *Consumer) matches(n *Notification) bool {
+	if len(c.topics) > 0 {
+		found := false
+		for _, t := range c.topics {
+			if n.Is(t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return c.match == nil || c.match(n)
+}
+
+// Subscribe adds a consumer that receives the notifications matching topics (without namespace prefixes, including subtopics; all topics if empty)
+// for which match (if not nil) returns true, with a channel with the given buffer size. If b is closed, the consumer's channel is already closed
+func (b *Bus) Subscribe(buffer int, match func(n *Notification) bool, topics ...string) *Consumer {
+	c := &Consumer{c: make(chan *Notification, buffer), topics: topics, match: match}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(c.c)
+		return c
+	}
+	if b.consumers == nil {
+		b.consumers = make(map[*Consumer]struct{})
+	}
+	b.consumers[c] = struct{}{}
+	return c
+}
+
+// Unsubscribe removes c from b and closes its channel
+func (b *Bus) Unsubscribe(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.consumers[c]; !ok {
+		return
+	}
+	delete(b.consumers, c)
+	close(c.c)
+}
+
+// Publish delivers n to the consumers it matches. It never blocks
+func (b *Bus) Publish(n *Notification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for c := range b.consumers {
+		if !c.matches(n) {
+			continue
+		}
+		select {
+		case c.c <- n:
+		default:
+			c.mu.Lock()
+			c.dropped++
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Close removes all consumers and closes their channels. Later consumers are closed when they're added
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for c := range b.consumers {
+		close(c.c)
+	}
+	b.consumers = nil
+}
+
+// Run publishes each notification from notifications (e.g. from a NotificationServer) until it's closed or ctx is done.
+// ctx's error is returned if ctx is done first
+func (b *Bus) Run(ctx context.Context, notifications <-chan *Notification) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n, ok := <-notifications:
+			if !ok {
+				return nil
+			}
+			b.Publish(n)
+		}
+	}
+}
+
+// Pull publishes the notifications pulled from the pull point subscription s until ctx is done or pulling fails. See Subscription.Pull.
+// The subscription isn't renewed; run Subscription.Maintain concurrently to keep it from terminating
+func (b *Bus) Pull(ctx context.Context, s *Subscription) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	notifications := make(chan *Notification)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Pull(ctx, notifications)
+	}()
+
+	for {
+		select {
+		case err := <-errc:
+			return err
+		case n := <-notifications:
+			b.Publish(n)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package events
+
+import "encoding/xml"
+
+// Capabilities is an ONVIF events Capabilities type
+type Capabilities struct {
+	WSSubscriptionPolicySupport                   bool `xml:"WSSubscriptionPolicySupport,attr"`
+	WSPullPointSupport                            bool `xml:"WSPullPointSupport,attr"`
+	WSPausableSubscriptionManagerInterfaceSupport bool `xml:"WSPausableSubscriptionManagerInterfaceSupport,attr"`
+	MaxNotificationProducers                      int  `xml:"MaxNotificationProducers,attr"`
+	MaxPullPoints                                 int  `xml:"MaxPullPoints,attr"`
+	PersistentNotificationStorage                 bool `xml:"PersistentNotificationStorage,attr"`
+	// EventBrokerProtocols is a space separated list of supported event broker protocols, e.g. mqtt
+	EventBrokerProtocols string `xml:"EventBrokerProtocols,attr"`
+	MaxEventBrokers      int    `xml:"MaxEventBrokers,attr"`
+	MetadataOverMQTT     bool   `xml:"MetadataOverMQTT,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tev:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the events service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.Call(&GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
@@ -0,0 +1,56 @@
+// Package events implements helpers for the ONVIF event service
+package events
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// WS-Notification and ONVIF event namespaces
+const (
+	NamespaceWSNT   = "http://docs.oasis-open.org/wsn/b-2"
+	NamespaceWSTOP  = "http://docs.oasis-open.org/wsn/t-1"
+	NamespaceTopics = "http://www.onvif.org/ver10/topics"
+	NamespaceWSA    = soap.NamespaceWSAddressing
+)
+
+// Filter dialects
+const (
+	DialectMessageContent = "http://www.onvif.org/ver10/tev/messageContentFilter/ItemFilter"
+)
+
+// WS-BaseNotification actions
+const (
+	ActionSubscribe   = "http://docs.oasis-open.org/wsn/bw-2/NotificationProducer/SubscribeRequest"
+	ActionRenew       = "http://docs.oasis-open.org/wsn/bw-2/SubscriptionManager/RenewRequest"
+	ActionUnsubscribe = "http://docs.oasis-open.org/wsn/bw-2/SubscriptionManager/UnsubscribeRequest"
+)
+
+// namespaces are the namespaces used by event requests
+var namespaces = soap.Namespaces{
+	"tev":  onvif.NamespaceEvents,
+	"wsnt": NamespaceWSNT,
+	"wsa":  NamespaceWSA,
+	"tt":   onvif.NamespaceONVIF,
+}
+
+// Client is an ONVIF Events service client
+type Client struct {
+	*onvif.ServiceClient
+	// Registry, if set, records the subscriptions created by the client, so they can be cleaned up after a crash. See Client.Cleanup
+	Registry Registry
+}
+
+// NewClient returns a new Events service client using c to make requests to the Events service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceEvents, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Events service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
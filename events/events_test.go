@@ -0,0 +1,709 @@
+package events_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+)
+
+func TestMessageContent(t *testing.T) {
+	for _, test := range []struct {
+		expr     events.MessageContent
+		expected string
+	}{
+		{events.SimpleItem("InputToken", "1"), `boolean(//tt:SimpleItem[@Name="InputToken" and @Value="1"])`},
+		{events.SourceItem("Token", `a"b`), `boolean(//tt:Source/tt:SimpleItem[@Name="Token" and @Value='a"b'])`},
+		{events.DataItem("Value", `a"b'c`), `boolean(//tt:Data/tt:SimpleItem[@Name="Value" and @Value=concat("a", '"', "b'c")])`},
+		{
+			events.And(events.SourceItem("VideoSourceToken", "1"), events.Not(events.HasItem("Rule"))),
+			`boolean((//tt:Source/tt:SimpleItem[@Name="VideoSourceToken" and @Value="1"]) and (not(//tt:SimpleItem[@Name="Rule"])))`,
+		},
+	} {
+		if s := test.expr.String(); s != test.expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", test.expected, s)
+		}
+	}
+
+	buf, err := xml.Marshal(events.SimpleItem("Name", "<&>").Filter())
+	if err != nil {
+		t.Fatalf("could not marshal filter: %v", err)
+	}
+
+	expected := `<wsnt:MessageContent Dialect="http://www.onvif.org/ver10/tev/messageContentFilter/ItemFilter" xmlns:tt="http://www.onvif.org/ver10/schema">` +
+		`boolean(//tt:SimpleItem[@Name=&#34;Name&#34; and @Value=&#34;&lt;&amp;&gt;&#34;])</wsnt:MessageContent>`
+	if string(buf) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, string(buf))
+	}
+}
+
+func TestSubscriptionTimes(t *testing.T) {
+	received := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		times    *events.SubscriptionTimes
+		expected time.Duration
+	}{
+		// device clock is a year behind
+		{&events.SubscriptionTimes{CurrentTime: "2019-01-01T00:00:00Z", TerminationTime: "2019-01-01T00:01:00Z"}, time.Minute},
+		{&events.SubscriptionTimes{TerminationTime: "2020-01-01T00:00:30"}, 30 * time.Second},
+		{&events.SubscriptionTimes{CurrentTime: "2019-01-01T00:00:00Z", TerminationTime: "PT60S"}, time.Minute},
+	} {
+		d, err := test.times.Remaining(received)
+		if err != nil {
+			t.Errorf("%#v: unexpected error: %v", test.times, err)
+		} else if d != test.expected {
+			t.Errorf("%#v: expected %v, got %v", test.times, test.expected, d)
+		}
+	}
+
+	if _, err := (&events.SubscriptionTimes{}).Remaining(received); err != events.ErrNoTerminationTime {
+		t.Errorf("expected ErrNoTerminationTime, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	const body = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:wsnt="http://docs.oasis-open.org/wsn/b-2"
+xmlns:wsa="http://www.w3.org/2005/08/addressing" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:tns1="http://www.onvif.org/ver10/topics">
+<env:Body><tev:PullMessagesResponse xmlns:tev="http://www.onvif.org/ver10/events/wsdl">
+<tev:CurrentTime>2020-01-01T00:00:00Z</tev:CurrentTime>
+<tev:TerminationTime>2020-01-01T00:01:00Z</tev:TerminationTime>
+<wsnt:NotificationMessage>
+<wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:RuleEngine/CellMotionDetector/Motion</wsnt:Topic>
+<wsnt:Message><tt:Message UtcTime="2020-01-01T00:00:00.5Z" PropertyOperation="Changed">
+<tt:Source><tt:SimpleItem Name="VideoSourceConfigurationToken" Value="VideoSourceToken"/><tt:SimpleItem Name="Rule" Value="MyMotionDetectorRule"/></tt:Source>
+<tt:Data><tt:SimpleItem Name="IsMotion" Value="true"/></tt:Data>
+</tt:Message></wsnt:Message>
+</wsnt:NotificationMessage>
+<wsnt:NotificationMessage>
+<wsnt:SubscriptionReference><wsa:Address>http://192.168.0.64/Subscription?Idx=1</wsa:Address></wsnt:SubscriptionReference>
+<wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:Device/tnsvendor:Trigger/DigitalInput</wsnt:Topic>
+<wsnt:Message><tt:Message UtcTime="2020-01-01T00:00:01Z" PropertyOperation="Initialized">
+<tt:Source><tt:SimpleItem Name="InputToken" Value="DIGIT_INPUT_000"/></tt:Source>
+<tt:Data><tt:SimpleItem Name="LogicalState" Value="false"/></tt:Data>
+</tt:Message></wsnt:Message>
+</wsnt:NotificationMessage>
+</tev:PullMessagesResponse></env:Body></env:Envelope>`
+
+	notifications, err := events.Decode(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("could not decode notifications: %v", err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifications))
+	}
+
+	motion := notifications[0]
+	if !motion.Is(events.TopicCellMotion) || !motion.Is("RuleEngine") {
+		t.Errorf("unexpected topic: %s", motion.Topic)
+	}
+	if motion.PropertyOperation != events.PropertyChanged {
+		t.Errorf("expected Changed, got %s", motion.PropertyOperation)
+	}
+	if !motion.Time.Equal(time.Date(2020, 1, 1, 0, 0, 0, 5e8, time.UTC)) {
+		t.Errorf("unexpected time: %v", motion.Time)
+	}
+	if !motion.Data.Bool("IsMotion") {
+		t.Errorf("expected IsMotion to be true")
+	}
+	if v, ok := motion.Item("Rule"); !ok || v != "MyMotionDetectorRule" {
+		t.Errorf("expected Rule=MyMotionDetectorRule, got %q, %v", v, ok)
+	}
+
+	input := notifications[1]
+	if p := input.TopicPath(); p != events.TopicDigitalInput {
+		t.Errorf("expected %s, got %s", events.TopicDigitalInput, p)
+	}
+	if input.SubscriptionReference != "http://192.168.0.64/Subscription?Idx=1" {
+		t.Errorf("unexpected subscription reference: %s", input.SubscriptionReference)
+	}
+	if v, _ := input.Source.Get("InputToken"); v != "DIGIT_INPUT_000" || input.Data.Bool("LogicalState") {
+		t.Errorf("unexpected items: %v, %v", input.Source, input.Data)
+	}
+}
+
+const responseEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:wsnt="http://docs.oasis-open.org/wsn/b-2"
+xmlns:wsa="http://www.w3.org/2005/08/addressing" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:tns1="http://www.onvif.org/ver10/topics">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+const notifyMessage = `<wsnt:Notify><wsnt:NotificationMessage>
+<wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:VideoSource/MotionAlarm</wsnt:Topic>
+<wsnt:Message><tt:Message UtcTime="2020-01-01T00:00:00Z" PropertyOperation="Changed">
+<tt:Source><tt:SimpleItem Name="Source" Value="VideoSource_1"/></tt:Source>
+<tt:Data><tt:SimpleItem Name="State" Value="true"/></tt:Data>
+</tt:Message></wsnt:Message>
+</wsnt:NotificationMessage></wsnt:Notify>`
+
+// fastClock is a Clock whose After fires after 10ms, recording the requested durations
+type fastClock struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *fastClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *fastClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	return time.After(10 * time.Millisecond)
+}
+
+var referenceRegexp = regexp.MustCompile(`<SubscriptionId xmlns="http://www.axis.com/2009/event" xmlns:wsa="` + regexp.QuoteMeta(events.NamespaceWSA) + `" wsa:IsReferenceParameter="true">1</SubscriptionId>`)
+
+func TestPushSubscription(t *testing.T) {
+	ns := events.NewNotificationServer(1)
+	consumer := httptest.NewServer(ns)
+	defer consumer.Close()
+
+	var (
+		mu         sync.Mutex
+		operations []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if (bytes.Contains(buf, []byte("<wsnt:Renew>")) || bytes.Contains(buf, []byte("<wsnt:Unsubscribe>"))) && !referenceRegexp.Match(buf) {
+			t.Errorf("expected reference parameter header: %s", buf)
+		}
+		switch {
+		case bytes.Contains(buf, []byte("<wsnt:Subscribe>")):
+			if !bytes.Contains(buf, []byte("<wsnt:ConsumerReference><wsa:Address>"+consumer.URL+"</wsa:Address></wsnt:ConsumerReference>")) {
+				t.Errorf("unexpected consumer reference: %s", buf)
+			}
+			if !bytes.Contains(buf, []byte("<wsnt:InitialTerminationTime>PT60S</wsnt:InitialTerminationTime>")) {
+				t.Errorf("unexpected termination time: %s", buf)
+			}
+			operations = append(operations, "Subscribe")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:SubscribeResponse>
+<wsnt:SubscriptionReference><wsa:Address>http://`+r.Host+`/subscription/1</wsa:Address>
+<wsa:ReferenceParameters><dom0:SubscriptionId xmlns:dom0="http://www.axis.com/2009/event">1</dom0:SubscriptionId></wsa:ReferenceParameters></wsnt:SubscriptionReference>
+<wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime><wsnt:TerminationTime>2020-01-01T00:00:00.05Z</wsnt:TerminationTime>
+</wsnt:SubscribeResponse>`)
+
+			go func() {
+				resp, err := http.Post(consumer.URL, "application/soap+xml", strings.NewReader(fmt.Sprintf(responseEnvelope, notifyMessage)))
+				if err != nil {
+					t.Errorf("could not send notification: %v", err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusAccepted {
+					t.Errorf("unexpected status: %s", resp.Status)
+				}
+			}()
+		case bytes.Contains(buf, []byte("<wsnt:Renew>")):
+			if r.URL.Path != "/subscription/1" {
+				t.Errorf("unexpected renew path: %s", r.URL.Path)
+			}
+			if !bytes.Contains(buf, []byte("<wsa:Action xmlns:wsa=\""+events.NamespaceWSA+"\">"+events.ActionRenew+"</wsa:Action>")) ||
+				!bytes.Contains(buf, []byte("<wsa:To xmlns:wsa=\""+events.NamespaceWSA+"\">http://"+r.Host+"/subscription/1</wsa:To>")) ||
+				!bytes.Contains(buf, []byte("<wsa:MessageID xmlns:wsa=\""+events.NamespaceWSA+"\">urn:uuid:")) {
+				t.Errorf("unexpected addressing headers: %s", buf)
+			}
+			operations = append(operations, "Renew")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:RenewResponse><wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime>
+<wsnt:TerminationTime>2020-01-01T00:00:00.05Z</wsnt:TerminationTime></wsnt:RenewResponse>`)
+		case bytes.Contains(buf, []byte("<wsnt:Unsubscribe>")):
+			operations = append(operations, "Unsubscribe")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:UnsubscribeResponse></wsnt:UnsubscribeResponse>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	clock := new(fastClock)
+	c, err := events.NewClient(&onvif.Client{Clock: clock}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := c.Subscribe(ctx, consumer.URL, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	n := <-ns.Notifications()
+	if !n.Is(events.TopicMotionAlarm) || !n.Data.Bool("State") {
+		t.Errorf("unexpected notification: %#v", n)
+	}
+
+	// renewals are due every 40ms, which is clamped to MinRenewInterval (shortened to 10ms by the clock)
+	time.AfterFunc(100*time.Millisecond, cancel)
+	if err = s.Maintain(ctx, time.Minute); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	clock.mu.Lock()
+	for _, d := range clock.waits {
+		if d != events.MinRenewInterval {
+			t.Errorf("expected renewal wait to be clamped, got %v", d)
+		}
+	}
+	clock.mu.Unlock()
+	if times, _ := s.Times(); times.TerminationTime == "" {
+		t.Error("expected subscription times")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(operations) < 3 || operations[0] != "Subscribe" || operations[1] != "Renew" || operations[len(operations)-1] != "Unsubscribe" {
+		t.Errorf("unexpected operations: %v", operations)
+	}
+
+	ns.Close()
+	if _, ok := <-ns.Notifications(); ok {
+		t.Error("expected closed channel")
+	}
+}
+
+func TestSubscriptionShutdown(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		operations []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case bytes.Contains(buf, []byte("<wsnt:Subscribe>")):
+			operations = append(operations, "Subscribe")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:SubscribeResponse>
+<wsnt:SubscriptionReference><wsa:Address>http://`+r.Host+`/subscription/1</wsa:Address></wsnt:SubscriptionReference>
+<wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime><wsnt:TerminationTime>2020-01-01T00:01:00Z</wsnt:TerminationTime>
+</wsnt:SubscribeResponse>`)
+		case bytes.Contains(buf, []byte("<wsnt:Unsubscribe>")):
+			operations = append(operations, "Unsubscribe")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:UnsubscribeResponse></wsnt:UnsubscribeResponse>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	oc := &onvif.Client{}
+	c, err := events.NewClient(oc, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	s, err := c.Subscribe(context.Background(), "http://127.0.0.1/notify", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	errc := make(chan error)
+	go func() {
+		errc <- s.Maintain(context.Background(), time.Minute)
+	}()
+
+	// Shutdown can run before or after Maintain starts; either way Maintain returns and the subscription is unsubscribed once
+	if err = oc.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected shutdown to succeed, got %v", err)
+	}
+
+	select {
+	case err = <-errc:
+		if !errors.Is(err, onvif.ErrClientClosed) {
+			t.Errorf("expected client closed error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Maintain to be stopped")
+	}
+
+	if err = s.Unsubscribe(context.Background()); err != nil {
+		t.Errorf("expected unsubscribed subscription to do nothing, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(operations) != 2 || operations[0] != "Subscribe" || operations[1] != "Unsubscribe" {
+		t.Errorf("unexpected operations: %v", operations)
+	}
+}
+
+func TestMaintainUnsubscribeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(buf, []byte("<wsnt:Subscribe>")):
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:SubscribeResponse>
+<wsnt:SubscriptionReference><wsa:Address>http://`+r.Host+`/subscription/1</wsa:Address></wsnt:SubscriptionReference>
+<wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime><wsnt:TerminationTime>2020-01-01T00:01:00Z</wsnt:TerminationTime>
+</wsnt:SubscribeResponse>`)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := events.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	s, err := c.Subscribe(context.Background(), "http://127.0.0.1/notify", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	// a failed unsubscribe doesn't hide the cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = s.Maintain(ctx, time.Second)
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "could not unsubscribe") {
+		t.Errorf("expected canceled error including unsubscribe error, got %v", err)
+	}
+}
+
+func TestTopicFilter(t *testing.T) {
+	filter := events.TopicFilter(events.Subtopics("tns1:RuleEngine"), events.TopicDigitalInput, "tnsaxis:Storage/Alert").
+		Namespace("tnsaxis", "http://www.axis.com/2009/event/topics").Filter()
+	filter.MessageContent = events.DataItem("IsMotion", "true").Filter()
+
+	buf, err := xml.Marshal(&events.Subscribe{ConsumerReference: "http://consumer", Filter: filter})
+	if err != nil {
+		t.Fatalf("could not marshal request: %v", err)
+	}
+
+	expected := `<wsnt:Subscribe><wsnt:ConsumerReference><wsa:Address>http://consumer</wsa:Address></wsnt:ConsumerReference><wsnt:Filter>` +
+		`<wsnt:TopicExpression Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet" xmlns:tns1="http://www.onvif.org/ver10/topics" ` +
+		`xmlns:tnsaxis="http://www.axis.com/2009/event/topics">tns1:RuleEngine//.|tns1:Device/Trigger/DigitalInput|tnsaxis:Storage/Alert</wsnt:TopicExpression>` +
+		`<wsnt:MessageContent Dialect="http://www.onvif.org/ver10/tev/messageContentFilter/ItemFilter" xmlns:tt="http://www.onvif.org/ver10/schema">` +
+		`boolean(//tt:Data/tt:SimpleItem[@Name=&#34;IsMotion&#34; and @Value=&#34;true&#34;])</wsnt:MessageContent></wsnt:Filter></wsnt:Subscribe>`
+	if string(buf) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, string(buf))
+	}
+}
+
+const pullMessagesResponse = `<tev:PullMessagesResponse xmlns:tev="http://www.onvif.org/ver10/events/wsdl">
+<tev:CurrentTime>2020-01-01T00:00:00Z</tev:CurrentTime><tev:TerminationTime>2020-01-01T00:01:00Z</tev:TerminationTime>
+<wsnt:NotificationMessage>
+<wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:VideoSource/MotionAlarm</wsnt:Topic>
+<wsnt:Message><tt:Message UtcTime="2020-01-01T00:00:00Z" PropertyOperation="Initialized">
+<tt:Source><tt:SimpleItem Name="Source" Value="VideoSource_1"/></tt:Source>
+<tt:Data><tt:SimpleItem Name="State" Value="true"/></tt:Data>
+</tt:Message></wsnt:Message>
+</wsnt:NotificationMessage></tev:PullMessagesResponse>`
+
+const faultResponse = `<env:Fault xmlns:ter="http://www.onvif.org/ver10/error"><env:Code><env:Value>env:Receiver</env:Value>
+<env:Subcode><env:Value>ter:Action</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">Maximum number of pull points reached</env:Text></env:Reason></env:Fault>`
+
+func TestPullPointSubscription(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		operations []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case bytes.Contains(buf, []byte("<tev:CreatePullPointSubscription>")):
+			if !bytes.Contains(buf, []byte("<tev:InitialTerminationTime>PT60S</tev:InitialTerminationTime>")) {
+				t.Errorf("unexpected termination time: %s", buf)
+			}
+			operations = append(operations, "CreatePullPointSubscription")
+			fmt.Fprintf(w, responseEnvelope, `<tev:CreatePullPointSubscriptionResponse xmlns:tev="http://www.onvif.org/ver10/events/wsdl">
+<tev:SubscriptionReference><wsa:Address>http://`+r.Host+`/pullpoint/1</wsa:Address></tev:SubscriptionReference>
+<wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime><wsnt:TerminationTime>2020-01-01T00:01:00Z</wsnt:TerminationTime>
+</tev:CreatePullPointSubscriptionResponse>`)
+		case bytes.Contains(buf, []byte("<tev:PullMessages>")):
+			if r.URL.Path != "/pullpoint/1" {
+				t.Errorf("unexpected pull path: %s", r.URL.Path)
+			}
+			if !bytes.Contains(buf, []byte("<tev:Timeout>PT5S</tev:Timeout><tev:MessageLimit>10</tev:MessageLimit>")) {
+				t.Errorf("unexpected pull request: %s", buf)
+			}
+			operations = append(operations, "PullMessages")
+			fmt.Fprintf(w, responseEnvelope, pullMessagesResponse)
+		case bytes.Contains(buf, []byte("<wsnt:Unsubscribe>")):
+			if r.URL.Path != "/pullpoint/1" {
+				t.Errorf("unexpected unsubscribe path: %s", r.URL.Path)
+			}
+			operations = append(operations, "Unsubscribe")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:UnsubscribeResponse></wsnt:UnsubscribeResponse>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := events.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	s, err := c.CreatePullPointSubscription(context.Background(), nil, time.Minute)
+	if err != nil {
+		t.Fatalf("could not create pull point subscription: %v", err)
+	}
+	if !s.PullPoint() {
+		t.Error("expected pull point subscription")
+	}
+
+	ns, err := s.PullMessages(context.Background(), 5*time.Second, 10)
+	if err != nil {
+		t.Fatalf("could not pull messages: %v", err)
+	}
+	if len(ns) != 1 || !ns[0].Is(events.TopicMotionAlarm) || ns[0].PropertyOperation != "Initialized" || !ns[0].Data.Bool("State") {
+		t.Errorf("unexpected notifications: %v", ns)
+	}
+
+	if err = s.Unsubscribe(context.Background()); err != nil {
+		t.Errorf("could not unsubscribe: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(operations) != "[CreatePullPointSubscription PullMessages Unsubscribe]" {
+		t.Errorf("unexpected operations: %v", operations)
+	}
+}
+
+func TestSubscribeAny(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		operations []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case bytes.Contains(buf, []byte("<tev:CreatePullPointSubscription>")):
+			operations = append(operations, "CreatePullPointSubscription")
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, responseEnvelope, faultResponse)
+		case bytes.Contains(buf, []byte("<wsnt:Subscribe>")):
+			operations = append(operations, "Subscribe")
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:SubscribeResponse>
+<wsnt:SubscriptionReference><wsa:Address>http://`+r.Host+`/subscription/1</wsa:Address></wsnt:SubscriptionReference>
+<wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime><wsnt:TerminationTime>2020-01-01T00:01:00Z</wsnt:TerminationTime>
+</wsnt:SubscribeResponse>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := events.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	// pull point subscriptions fail with a fault, so the push subscription is used
+	s, err := c.SubscribeAny(context.Background(), &events.SubscribeOptions{Consumer: "http://127.0.0.1/notify", Termination: time.Minute})
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+	if s.PullPoint() {
+		t.Error("expected push subscription")
+	}
+	if _, err = s.PullMessages(context.Background(), time.Second, 1); !errors.Is(err, events.ErrNotPullPoint) {
+		t.Errorf("expected not pull point error, got %v", err)
+	}
+
+	// without a consumer, the fault is returned
+	if _, err = c.SubscribeAny(context.Background(), &events.SubscribeOptions{}); !strings.Contains(err.Error(), "could not create pull point subscription") {
+		t.Errorf("expected pull point error, got %v", err)
+	}
+
+	if _, err = c.SubscribeAny(context.Background(), &events.SubscribeOptions{Modes: []events.SubscriptionMode{events.ModePush}}); !errors.Is(err, events.ErrNoSubscriptionMode) {
+		t.Errorf("expected no subscription mode error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(operations) != "[CreatePullPointSubscription Subscribe CreatePullPointSubscription]" {
+		t.Errorf("unexpected operations: %v", operations)
+	}
+}
+
+func TestRegistryCleanup(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		unsubscribed []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case bytes.Contains(buf, []byte("<wsnt:Subscribe>")):
+			fmt.Fprintf(w, responseEnvelope, `<wsnt:SubscribeResponse>
+<wsnt:SubscriptionReference><wsa:Address>http://`+r.Host+`/subscription/1</wsa:Address>
+<wsa:ReferenceParameters><dom0:SubscriptionId xmlns:dom0="http://www.axis.com/2009/event">1</dom0:SubscriptionId></wsa:ReferenceParameters></wsnt:SubscriptionReference>
+<wsnt:CurrentTime>2020-01-01T00:00:00Z</wsnt:CurrentTime><wsnt:TerminationTime>2020-01-01T00:01:00Z</wsnt:TerminationTime>
+</wsnt:SubscribeResponse>`)
+		case bytes.Contains(buf, []byte("<wsnt:Unsubscribe>")):
+			unsubscribed = append(unsubscribed, r.URL.Path)
+			switch r.URL.Path {
+			case "/subscription/1":
+				if !referenceRegexp.Match(buf) {
+					t.Errorf("expected reference parameter header: %s", buf)
+				}
+				fmt.Fprintf(w, responseEnvelope, `<wsnt:UnsubscribeResponse></wsnt:UnsubscribeResponse>`)
+			case "/subscription/2":
+				// already terminated
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, responseEnvelope, faultResponse)
+			default:
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			}
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	registry := &events.FileRegistry{Path: t.TempDir() + "/subscriptions.json"}
+	c, err := events.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	c.Registry = registry
+
+	s, err := c.Subscribe(context.Background(), "http://127.0.0.1/notify", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+	if records, err := registry.List(); err != nil || len(records) != 1 || records[0].Address != s.Address || records[0].Service != srv.URL {
+		t.Errorf("unexpected records: %v, %v", records, err)
+	}
+	if err = s.Unsubscribe(context.Background()); err != nil {
+		t.Fatalf("could not unsubscribe: %v", err)
+	}
+	if records, err := registry.List(); err != nil || len(records) != 0 {
+		t.Errorf("expected unsubscribed record to be removed: %v, %v", records, err)
+	}
+
+	// the subscription is orphaned by a crash, and left with others from a previous run and another device
+	if _, err = c.Subscribe(context.Background(), "http://127.0.0.1/notify", nil, time.Minute); err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+	for _, rec := range []*events.SubscriptionRecord{
+		{Service: srv.URL, Address: srv.URL + "/subscription/2"},
+		{Service: srv.URL, Address: srv.URL + "/subscription/3"},
+		{Service: "http://192.168.0.2/onvif/event_service", Address: "http://192.168.0.2/subscription/1"},
+	} {
+		if err = registry.Add(rec); err != nil {
+			t.Fatalf("could not add record: %v", err)
+		}
+	}
+
+	c, err = events.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	c.Registry = registry
+	removed, err := c.Cleanup(context.Background())
+	if removed != 2 || err == nil || !strings.Contains(err.Error(), "/subscription/3") {
+		t.Errorf("unexpected cleanup result: %d, %v", removed, err)
+	}
+
+	records, err := registry.List()
+	if err != nil || len(records) != 2 || records[0].Address != srv.URL+"/subscription/3" || records[1].Address != "http://192.168.0.2/subscription/1" {
+		t.Errorf("unexpected records after cleanup: %v, %v", records, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(unsubscribed) != "[/subscription/1 /subscription/1 /subscription/2 /subscription/3]" {
+		t.Errorf("unexpected unsubscribes: %v", unsubscribed)
+	}
+}
+
+func TestBus(t *testing.T) {
+	b := new(events.Bus)
+	motion := b.Subscribe(1, nil, events.TopicMotionAlarm)
+	inputs := b.Subscribe(2, func(n *events.Notification) bool { return n.Data.Bool("LogicalState") }, "Device/Trigger")
+	all := b.Subscribe(10, nil)
+
+	b.Publish(&events.Notification{Topic: "tns1:VideoSource/MotionAlarm"})
+	b.Publish(&events.Notification{Topic: "tns1:VideoSource/MotionAlarm"})
+	b.Publish(&events.Notification{Topic: "tns1:Device/Trigger/DigitalInput", Data: events.Items{"LogicalState": "true"}})
+	b.Publish(&events.Notification{Topic: "tns1:Device/Trigger/DigitalInput", Data: events.Items{"LogicalState": "false"}})
+
+	if len(motion.Notifications()) != 1 || motion.Dropped() != 1 {
+		t.Errorf("expected 1 motion notification and 1 dropped, got %d, %d", len(motion.Notifications()), motion.Dropped())
+	}
+	if n := <-inputs.Notifications(); len(inputs.Notifications()) != 0 || !n.Is(events.TopicDigitalInput) {
+		t.Errorf("unexpected input notifications: %v", n)
+	}
+	if len(all.Notifications()) != 4 {
+		t.Errorf("expected 4 notifications, got %d", len(all.Notifications()))
+	}
+
+	b.Unsubscribe(inputs)
+	if _, ok := <-inputs.Notifications(); ok {
+		t.Error("expected closed channel")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(buf, []byte("<tev:CreatePullPointSubscription>")):
+			fmt.Fprintf(w, responseEnvelope, `<tev:CreatePullPointSubscriptionResponse xmlns:tev="http://www.onvif.org/ver10/events/wsdl">
+<tev:SubscriptionReference><wsa:Address>http://`+r.Host+`/pullpoint/1</wsa:Address></tev:SubscriptionReference>
+</tev:CreatePullPointSubscriptionResponse>`)
+		case bytes.Contains(buf, []byte("<tev:PullMessages>")):
+			fmt.Fprintf(w, responseEnvelope, pullMessagesResponse)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := events.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceEvents, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	s, err := c.CreatePullPointSubscription(context.Background(), nil, time.Minute)
+	if err != nil {
+		t.Fatalf("could not create pull point subscription: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- b.Pull(ctx, s)
+	}()
+	<-motion.Notifications()
+	if n := <-motion.Notifications(); !n.Is(events.TopicMotionAlarm) || n.PropertyOperation != events.PropertyInitialized {
+		t.Errorf("unexpected pulled notification: %#v", n)
+	}
+	cancel()
+	if err = <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	b.Close()
+	for range all.Notifications() {
+	}
+	if _, ok := <-b.Subscribe(1, nil).Notifications(); ok {
+		t.Error("expected closed channel after Close")
+	}
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/korylprince/go-onvif"
+)
+
+// MessageContent is a message content filter expression in the ItemFilter dialect.
+// Expressions select notifications by their Source, Key, or Data SimpleItems, so devices only send matching events.
+//
+// Example: events.And(events.SourceItem("VideoSourceConfigurationToken", "1"), events.DataItem("IsMotion", "true"))
+type MessageContent string
+
+// quote returns s as an XPath string literal
+func quote(s string) string {
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	if !strings.Contains(s, `'`) {
+		return `'` + s + `'`
+	}
+
+	// XPath 1.0 has no escape sequences, so literals containing both quote types must be concatenated
+	parts := strings.Split(s, `"`)
+	for i, p := range parts {
+		parts[i] = `"` + p + `"`
+	}
+	return "concat(" + strings.Join(parts, `, '"', `) + ")"
+}
+
+func item(path, name, value string) MessageContent {
+	return MessageContent(fmt.Sprintf(`%stt:SimpleItem[@Name=%s and @Value=%s]`, path, quote(name), quote(value)))
+}
+
+// SimpleItem matches notifications with a Source, Key, or Data SimpleItem with the given name and value
+func SimpleItem(name, value string) MessageContent {
+	return item("//", name, value)
+}
+
+// SourceItem matches notifications with a Source SimpleItem with the given name and value, e.g. SourceItem("VideoSourceConfigurationToken", "1")
+func SourceItem(name, value string) MessageContent {
+	return item("//tt:Source/", name, value)
+}
+
+// KeyItem matches notifications with a Key SimpleItem with the given name and value
+func KeyItem(name, value string) MessageContent {
+	return item("//tt:Key/", name, value)
+}
+
+// DataItem matches notifications with a Data SimpleItem with the given name and value, e.g. DataItem("IsMotion", "true")
+func DataItem(name, value string) MessageContent {
+	return item("//tt:Data/", name, value)
+}
+
+// HasItem matches notifications with a SimpleItem with the given name, regardless of value
+func HasItem(name string) MessageContent {
+	return MessageContent(fmt.Sprintf(`//tt:SimpleItem[@Name=%s]`, quote(name)))
+}
+
+func join(op string, exprs []MessageContent) MessageContent {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = "(" + string(e) + ")"
+	}
+	return MessageContent(strings.Join(parts, " "+op+" "))
+}
+
+// And matches notifications matching all exprs
+func And(exprs ...MessageContent) MessageContent {
+	return join("and", exprs)
+}
+
+// Or matches notifications matching any of exprs
+func Or(exprs ...MessageContent) MessageContent {
+	return join("or", exprs)
+}
+
+// Not matches notifications not matching expr
+func Not(expr MessageContent) MessageContent {
+	return MessageContent("not(" + string(expr) + ")")
+}
+
+// String returns the full filter expression
+func (m MessageContent) String() string {
+	return "boolean(" + string(m) + ")"
+}
+
+// MessageContentFilter is a wsnt:MessageContent element. It should be placed in a wsnt:Filter element,
+// with the wsnt prefix declared as NamespaceWSNT
+type MessageContentFilter struct {
+	XMLName     xml.Name `xml:"wsnt:MessageContent"`
+	Dialect     string   `xml:"Dialect,attr"`
+	NamespaceTT string   `xml:"xmlns:tt,attr"`
+	Expression  string   `xml:",chardata"`
+}
+
+// Filter returns the wsnt:MessageContent element for m, with the dialect and namespaces set
+func (m MessageContent) Filter() *MessageContentFilter {
+	return &MessageContentFilter{
+		Dialect:     DialectMessageContent,
+		NamespaceTT: onvif.NamespaceONVIF,
+		Expression:  m.String(),
+	}
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// SubscriptionMode is a way of receiving notifications
+type SubscriptionMode int
+
+// SubscriptionModes
+const (
+	// ModePullPoint creates a pull point subscription. See Client.CreatePullPointSubscription
+	ModePullPoint SubscriptionMode = iota
+	// ModePush creates a push subscription. See Client.Subscribe
+	ModePush
+)
+
+func (m SubscriptionMode) String() string {
+	switch m {
+	case ModePullPoint:
+		return "pull point"
+	case ModePush:
+		return "push"
+	}
+	return "unknown"
+}
+
+// DefaultSubscriptionModes is the order SubscribeAny tries modes in if SubscribeOptions.Modes isn't set.
+// Pull points are preferred since they don't need the device to be able to connect to the client
+var DefaultSubscriptionModes = []SubscriptionMode{ModePullPoint, ModePush}
+
+// ErrNoSubscriptionMode is returned (wrapped) by SubscribeAny if none of the modes can be tried, e.g. only ModePush is allowed but there's no consumer
+var ErrNoSubscriptionMode = errors.New("no usable subscription mode")
+
+// SubscribeOptions configures SubscribeAny
+type SubscribeOptions struct {
+	// Modes are the modes to try, in order of preference. If empty, DefaultSubscriptionModes is used
+	Modes []SubscriptionMode
+	// Consumer is the URL push notifications are sent to, e.g. a URL served by a NotificationServer. ModePush is skipped if it's empty
+	Consumer string
+	// Filter, if set, limits the notifications sent
+	Filter *Filter
+	// Termination is the initial termination time of the subscription. See Subscription.Maintain
+	Termination time.Duration
+}
+
+// SubscribeAny creates a subscription using the first of opts.Modes the device accepts.
+// Some devices advertise pull points but reject CreatePullPointSubscription (e.g. when MaxPullPoints is reached) while accepting Subscribe, or the reverse.
+// If creating a subscription fails with a SOAP fault other than an authorization fault, the next mode is tried; other errors are returned immediately.
+// If every mode fails, the last error is returned. Subscription.PullPoint reports the mode used
+func (c *Client) SubscribeAny(ctx context.Context, opts *SubscribeOptions) (*Subscription, error) {
+	modes := opts.Modes
+	if len(modes) == 0 {
+		modes = DefaultSubscriptionModes
+	}
+
+	err := fmt.Errorf("could not subscribe: %w", ErrNoSubscriptionMode)
+	for _, mode := range modes {
+		var s *Subscription
+		switch mode {
+		case ModePullPoint:
+			s, err = c.CreatePullPointSubscription(ctx, opts.Filter, opts.Termination)
+		case ModePush:
+			if opts.Consumer == "" {
+				continue
+			}
+			s, err = c.Subscribe(ctx, opts.Consumer, opts.Filter, opts.Termination)
+		default:
+			continue
+		}
+		if err == nil {
+			return s, nil
+		}
+
+		var f *soap.Fault
+		if !errors.As(err, &f) || errors.Is(err, soap.ErrNotAuthorized) {
+			return nil, err
+		}
+		err = fmt.Errorf("could not create %s subscription: %w", mode, err)
+	}
+	return nil, err
+}
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// PropertyOperation is the PropertyOperation of a property event
+type PropertyOperation string
+
+// PropertyOperations
+const (
+	// PropertyInitialized is sent for the current state of a property when a subscription is created
+	PropertyInitialized PropertyOperation = "Initialized"
+	PropertyChanged     PropertyOperation = "Changed"
+	PropertyDeleted     PropertyOperation = "Deleted"
+)
+
+// Common topics, without namespace prefixes. See Notification.TopicPath
+const (
+	TopicCellMotion     = "RuleEngine/CellMotionDetector/Motion"
+	TopicMotionAlarm    = "VideoSource/MotionAlarm"
+	TopicLineCrossed    = "RuleEngine/LineDetector/Crossed"
+	TopicFieldDetector  = "RuleEngine/FieldDetector/ObjectsInside"
+	TopicDigitalInput   = "Device/Trigger/DigitalInput"
+	TopicRelay          = "Device/Trigger/Relay"
+	TopicTamper         = "VideoSource/GlobalSceneChange/ImagingService"
+	TopicStorageFailure = "Device/HardwareFailure/StorageFailure"
+)
+
+// MessageItem is an ONVIF SimpleItem in a notification message
+type MessageItem struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:"Value,attr"`
+}
+
+// Message is an ONVIF tt:Message, the content of a notification
+type Message struct {
+	// UtcTime is an xsd:dateTime. See ParseDateTime
+	UtcTime           string            `xml:"UtcTime,attr"`
+	PropertyOperation PropertyOperation `xml:"PropertyOperation,attr"`
+	Source            []*MessageItem    `xml:"Source>SimpleItem"`
+	Key               []*MessageItem    `xml:"Key>SimpleItem"`
+	Data              []*MessageItem    `xml:"Data>SimpleItem"`
+	ElementItem       []*MessageElement `xml:"Data>ElementItem"`
+}
+
+// MessageElement is an ONVIF ElementItem in a notification message, e.g. an analytics object
+type MessageElement struct {
+	Name     string `xml:"Name,attr"`
+	InnerXML []byte `xml:",innerxml"`
+}
+
+// Topic is a wsnt:Topic
+type Topic struct {
+	Dialect string `xml:"Dialect,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// NotificationMessage is a wsnt:NotificationMessage, as returned by PullMessages or sent in a Notify message
+type NotificationMessage struct {
+	SubscriptionReference string `xml:"SubscriptionReference>Address"`
+	Topic                 Topic
+	ProducerReference     string   `xml:"ProducerReference>Address"`
+	Message               *Message `xml:"Message>Message"`
+}
+
+// Items is a set of message SimpleItems, by name
+type Items map[string]string
+
+// Get returns the value of the item with the given name and true, or false if it's not found
+func (i Items) Get(name string) (string, bool) {
+	v, ok := i[name]
+	return v, ok
+}
+
+// Bool returns true if the item with the given name is "true" or "1"
+func (i Items) Bool(name string) bool {
+	v := strings.TrimSpace(i[name])
+	return v == "true" || v == "1"
+}
+
+func newItems(items []*MessageItem) Items {
+	m := make(Items, len(items))
+	for _, item := range items {
+		m[item.Name] = item.Value
+	}
+	return m
+}
+
+// Notification is a decoded event notification
+type Notification struct {
+	// Topic is the topic expression as sent, e.g. tns1:RuleEngine/CellMotionDetector/Motion
+	Topic string
+	// Time is the zero time if the message didn't have a UtcTime
+	Time              time.Time
+	PropertyOperation PropertyOperation
+	Source            Items
+	Key               Items
+	Data              Items
+	Elements          []*MessageElement
+	// SubscriptionReference and ProducerReference are the addresses from the message, if set
+	SubscriptionReference string
+	ProducerReference     string
+}
+
+// TopicPath returns the Topic with the namespace prefix removed from each part, e.g. RuleEngine/CellMotionDetector/Motion
+func (n *Notification) TopicPath() string {
+	parts := strings.Split(strings.TrimSpace(n.Topic), "/")
+	for i, p := range parts {
+		if idx := strings.IndexByte(p, ':'); idx != -1 {
+			parts[i] = p[idx+1:]
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Is returns true if the notification's TopicPath is topic or a subtopic of topic, e.g. Is("Device/Trigger")
+func (n *Notification) Is(topic string) bool {
+	path := n.TopicPath()
+	topic = strings.Trim(topic, "/")
+	return path == topic || strings.HasPrefix(path, topic+"/")
+}
+
+// Item returns the value of the Data, Source, or Key item with the given name (searched in that order) and true,
+// or false if it's not found
+func (n *Notification) Item(name string) (string, bool) {
+	for _, items := range []Items{n.Data, n.Source, n.Key} {
+		if v, ok := items[name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Notification returns the decoded notification
+func (m *NotificationMessage) Notification() (*Notification, error) {
+	n := &Notification{
+		Topic:                 strings.TrimSpace(m.Topic.Value),
+		SubscriptionReference: strings.TrimSpace(m.SubscriptionReference),
+		ProducerReference:     strings.TrimSpace(m.ProducerReference),
+	}
+
+	msg := m.Message
+	if msg == nil {
+		msg = new(Message)
+	}
+
+	if strings.TrimSpace(msg.UtcTime) != "" {
+		t, err := ParseDateTime(msg.UtcTime)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse message time: %w", err)
+		}
+		n.Time = t
+	}
+
+	n.PropertyOperation = msg.PropertyOperation
+	n.Source = newItems(msg.Source)
+	n.Key = newItems(msg.Key)
+	n.Data = newItems(msg.Data)
+	n.Elements = msg.ElementItem
+
+	return n, nil
+}
+
+// Decode decodes all wsnt:NotificationMessages in r, e.g. a PullMessagesResponse or Notify message, or a full SOAP envelope
+func Decode(r io.Reader) ([]*Notification, error) {
+	dec := xml.NewDecoder(r)
+	var notifications []*Notification
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return notifications, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read token: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "NotificationMessage" {
+			continue
+		}
+
+		msg := new(NotificationMessage)
+		if err = dec.DecodeElement(msg, &start); err != nil {
+			return nil, fmt.Errorf("could not decode notification message: %w", err)
+		}
+
+		n, err := msg.Notification()
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+}
+
+// DecodeElement decodes a notification message held in el, e.g. search.FindEventResult.Event
+func DecodeElement(el *soap.Element) (*Notification, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<NotificationMessage>")
+	buf.Write(el.InnerXML)
+	buf.WriteString("</NotificationMessage>")
+
+	msg := new(NotificationMessage)
+	if err := xml.NewDecoder(buf).Decode(msg); err != nil {
+		return nil, fmt.Errorf("could not decode notification message: %w", err)
+	}
+
+	return msg.Notification()
+}
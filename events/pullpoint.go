@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// ONVIF pull point actions
+const (
+	ActionCreatePullPointSubscription = "http://www.onvif.org/ver10/events/wsdl/EventPortType/CreatePullPointSubscriptionRequest"
+	ActionPullMessages                = "http://www.onvif.org/ver10/events/wsdl/PullPointSubscription/PullMessagesRequest"
+)
+
+// DefaultPullTimeout and DefaultPullLimit are the timeout and message limit Subscription.Pull uses for each PullMessages request
+const (
+	DefaultPullTimeout = 10 * time.Second
+	DefaultPullLimit   = 100
+)
+
+// CreatePullPointSubscription is an ONVIF CreatePullPointSubscription operation
+type CreatePullPointSubscription struct {
+	XMLName xml.Name `xml:"tev:CreatePullPointSubscription"`
+	Filter  *Filter  `xml:"tev:Filter,omitempty"`
+	// InitialTerminationTime is an xsd:duration or xsd:dateTime. See soap.FormatDuration
+	InitialTerminationTime string `xml:"tev:InitialTerminationTime,omitempty"`
+}
+
+// CreatePullPointSubscriptionResponse is an ONVIF CreatePullPointSubscriptionResponse response
+type CreatePullPointSubscriptionResponse struct {
+	// SubscriptionReference is the URL of the pull point
+	SubscriptionReference string `xml:"SubscriptionReference>Address"`
+	// ReferenceParameters is the subscription reference's wsa:ReferenceParameters, if any
+	ReferenceParameters *soap.ReferenceParameters `xml:"SubscriptionReference>ReferenceParameters"`
+	SubscriptionTimes
+}
+
+// PullMessages is an ONVIF PullMessages operation
+type PullMessages struct {
+	XMLName xml.Name `xml:"tev:PullMessages"`
+	// Timeout is an xsd:duration. See soap.FormatDuration
+	Timeout      string `xml:"tev:Timeout"`
+	MessageLimit int    `xml:"tev:MessageLimit"`
+}
+
+// PullMessagesResponse is an ONVIF PullMessagesResponse response
+type PullMessagesResponse struct {
+	CurrentTime         string
+	TerminationTime     string
+	NotificationMessage []*NotificationMessage
+}
+
+// CreatePullPointSubscription creates a pull point subscription for notifications matching filter (which may be nil).
+// Notifications are received with Subscription.PullMessages or Subscription.Pull.
+// The subscription terminates after termination, unless it's renewed. See Subscription.Maintain
+func (c *Client) CreatePullPointSubscription(ctx context.Context, filter *Filter, termination time.Duration) (*Subscription, error) {
+	req := &CreatePullPointSubscription{Filter: filter}
+	if termination > 0 {
+		req.InitialTerminationTime = soap.FormatDuration(termination)
+	}
+
+	resp := new(CreatePullPointSubscriptionResponse)
+	if err := call(ctx, c.Client, c.URL, nil, ActionCreatePullPointSubscription, req, resp); err != nil {
+		return nil, err
+	}
+
+	return c.newSubscription(ctx, resp.SubscriptionReference, resp.ReferenceParameters, resp.SubscriptionTimes, true)
+}
+
+// ErrNotPullPoint is returned (wrapped) by Subscription.PullMessages and Subscription.Pull for push subscriptions
+var ErrNotPullPoint = errors.New("not a pull point subscription")
+
+// PullMessages waits up to timeout for notifications from a pull point subscription, returning at most limit notifications.
+// It returns no notifications if none are sent before timeout
+func (s *Subscription) PullMessages(ctx context.Context, timeout time.Duration, limit int) ([]*Notification, error) {
+	if !s.pullPoint {
+		return nil, fmt.Errorf("could not pull messages: %w", ErrNotPullPoint)
+	}
+
+	resp := new(PullMessagesResponse)
+	req := &PullMessages{Timeout: soap.FormatDuration(timeout), MessageLimit: limit}
+	if err := call(ctx, s.client, s.Address, s.ReferenceParameters, ActionPullMessages, req, resp); err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*Notification, 0, len(resp.NotificationMessage))
+	for _, msg := range resp.NotificationMessage {
+		n, err := msg.Notification()
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// Pull calls PullMessages with DefaultPullTimeout and DefaultPullLimit repeatedly and sends the notifications to notifications,
+// until ctx is done or PullMessages fails. The subscription isn't renewed; run Maintain concurrently to keep it from terminating.
+// ctx's error is returned if ctx is done first
+func (s *Subscription) Pull(ctx context.Context, notifications chan<- *Notification) error {
+	for {
+		msgs, err := s.PullMessages(ctx, DefaultPullTimeout, DefaultPullLimit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("could not pull messages: %w", err)
+		}
+		for _, n := range msgs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case notifications <- n:
+			}
+		}
+	}
+}
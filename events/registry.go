@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// SubscriptionRecord is a subscription recorded in a Registry
+type SubscriptionRecord struct {
+	// Service is the URL of the Events service the subscription was created with
+	Service string
+	// Address is the URL of the subscription manager. See Subscription.Address
+	Address             string
+	ReferenceParameters *soap.ReferenceParameters `json:",omitempty"`
+	PullPoint           bool
+	// Created is the local time the subscription was created
+	Created time.Time
+}
+
+// Registry records the subscriptions created by a Client. Devices keep subscriptions orphaned by a crash until they terminate,
+// and they count against limits like MaxPullPoints in the meantime, so Client.Cleanup unsubscribes them on the next startup.
+// Implementations must be safe for concurrent use
+type Registry interface {
+	// Add records r
+	Add(r *SubscriptionRecord) error
+	// Remove removes the record with the given subscription manager address, if it exists
+	Remove(address string) error
+	// List returns the records
+	List() ([]*SubscriptionRecord, error)
+}
+
+// FileRegistry is a Registry stored as JSON in the file at Path, so it persists across restarts.
+// It's safe for concurrent use, but the file must not be shared between processes
+type FileRegistry struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// read returns the records in the file. A missing file has no records
+func (r *FileRegistry) read() ([]*SubscriptionRecord, error) {
+	buf, err := os.ReadFile(r.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read registry: %w", err)
+	}
+
+	var records []*SubscriptionRecord
+	if err = json.Unmarshal(buf, &records); err != nil {
+		return nil, fmt.Errorf("could not decode registry: %w", err)
+	}
+	return records, nil
+}
+
+// write replaces the file with records. The file is replaced atomically, so a crash doesn't leave a partial file
+func (r *FileRegistry) write(records []*SubscriptionRecord) error {
+	buf, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not encode registry: %w", err)
+	}
+
+	tmp := r.Path + ".tmp"
+	if err = os.WriteFile(tmp, buf, 0o600); err != nil {
+		return fmt.Errorf("could not write registry: %w", err)
+	}
+	if err = os.Rename(tmp, r.Path); err != nil {
+		return fmt.Errorf("could not write registry: %w", err)
+	}
+	return nil
+}
+
+// Add implements Registry
+func (r *FileRegistry) Add(rec *SubscriptionRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records, err := r.read()
+	if err != nil {
+		return err
+	}
+	return r.write(append(records, rec))
+}
+
+// Remove implements Registry
+func (r *FileRegistry) Remove(address string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records, err := r.read()
+	if err != nil {
+		return err
+	}
+	kept := records[:0]
+	for _, rec := range records {
+		if rec.Address != address {
+			kept = append(kept, rec)
+		}
+	}
+	if len(kept) == len(records) {
+		return nil
+	}
+	return r.write(kept)
+}
+
+// List implements Registry
+func (r *FileRegistry) List() ([]*SubscriptionRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.read()
+}
+
+// Cleanup unsubscribes the subscriptions in c.Registry that were created with c's Events service, e.g. by a previous run that crashed,
+// and removes them from the registry. It returns the number of records removed.
+// Subscriptions the device returns a fault for (usually because they already terminated) are removed too.
+// Other errors leave the subscription in the registry, and the first is returned after trying the rest.
+// Subscriptions created by this run of the application must not be in the registry yet, since they would be unsubscribed too
+func (c *Client) Cleanup(ctx context.Context) (int, error) {
+	if c.Registry == nil {
+		return 0, nil
+	}
+	records, err := c.Registry.List()
+	if err != nil {
+		return 0, fmt.Errorf("could not list subscriptions: %w", err)
+	}
+
+	var (
+		removed  int
+		firstErr error
+	)
+	for _, rec := range records {
+		if rec.Service != c.URL {
+			continue
+		}
+
+		err = call(ctx, c.Client, rec.Address, rec.ReferenceParameters, ActionUnsubscribe, &Unsubscribe{}, nil)
+		var f *soap.Fault
+		if err != nil && (!errors.As(err, &f) || errors.Is(err, soap.ErrNotAuthorized)) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("could not unsubscribe %s: %w", rec.Address, err)
+			}
+			continue
+		}
+
+		if err = c.Registry.Remove(rec.Address); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("could not remove subscription: %w", err)
+			}
+			continue
+		}
+		removed++
+	}
+	return removed, firstErr
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// MaxNotifySize is the maximum size of a Notify message accepted by a NotificationServer
+const MaxNotifySize = 1 << 20
+
+// NotificationServer is an http.Handler that receives Notify messages from devices with push subscriptions
+// and delivers the decoded notifications on a channel. See Client.Subscribe
+type NotificationServer struct {
+	// ErrorFunc, if set, is called with errors decoding Notify messages
+	ErrorFunc func(r *http.Request, err error)
+
+	c     chan *Notification
+	done  chan struct{}
+	once  sync.Once
+	mu    sync.RWMutex
+	ended bool
+}
+
+// NewNotificationServer returns a new NotificationServer with a notification channel with the given buffer size
+func NewNotificationServer(buffer int) *NotificationServer {
+	return &NotificationServer{
+		c:    make(chan *Notification, buffer),
+		done: make(chan struct{}),
+	}
+}
+
+// Notifications returns the channel notifications are delivered on. It's closed when the server is closed
+func (s *NotificationServer) Notifications() <-chan *Notification {
+	return s.c
+}
+
+// ServeHTTP implements http.Handler. Delivery blocks until the notification is received or the request is canceled
+func (s *NotificationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	notifications, err := Decode(io.LimitReader(r.Body, MaxNotifySize))
+	if err != nil {
+		if s.ErrorFunc != nil {
+			s.ErrorFunc(r, err)
+		}
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.ended {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, n := range notifications {
+		select {
+		case s.c <- n:
+		case <-s.done:
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Close stops delivering notifications and closes the notification channel. Later Notify messages are rejected
+func (s *NotificationServer) Close() {
+	s.once.Do(func() {
+		// unblock delivering handlers so the lock can be acquired
+		close(s.done)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.ended = true
+		close(s.c)
+	})
+}
@@ -0,0 +1,285 @@
+package events
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// DefaultRenewFraction is the fraction of a subscription's remaining time after which Subscription.Maintain renews it
+const DefaultRenewFraction = 0.8
+
+// MinRenewInterval is the minimum time Subscription.Maintain waits between renewals,
+// so a subscription the device reports as already terminated isn't renewed in a tight loop
+const MinRenewInterval = time.Second
+
+// Filter is a wsnt:Filter, which limits the notifications sent for a subscription.
+// Notifications must match both TopicExpression and MessageContent, if set
+type Filter struct {
+	TopicExpression *TopicExpressionFilter
+	MessageContent  *MessageContentFilter
+}
+
+// Subscribe is a WS-BaseNotification Subscribe operation
+type Subscribe struct {
+	XMLName xml.Name `xml:"wsnt:Subscribe"`
+	// ConsumerReference is the URL notifications are sent to
+	ConsumerReference string  `xml:"wsnt:ConsumerReference>wsa:Address"`
+	Filter            *Filter `xml:"wsnt:Filter,omitempty"`
+	// InitialTerminationTime is an xsd:duration or xsd:dateTime. See soap.FormatDuration
+	InitialTerminationTime string `xml:"wsnt:InitialTerminationTime,omitempty"`
+}
+
+// SubscribeResponse is a WS-BaseNotification SubscribeResponse response
+type SubscribeResponse struct {
+	// SubscriptionReference is the URL of the subscription manager
+	SubscriptionReference string `xml:"SubscriptionReference>Address"`
+	// ReferenceParameters is the subscription reference's wsa:ReferenceParameters, if any
+	ReferenceParameters *soap.ReferenceParameters `xml:"SubscriptionReference>ReferenceParameters"`
+	SubscriptionTimes
+}
+
+// Renew is a WS-BaseNotification Renew operation
+type Renew struct {
+	XMLName xml.Name `xml:"wsnt:Renew"`
+	// TerminationTime is an xsd:duration or xsd:dateTime. See soap.FormatDuration
+	TerminationTime string `xml:"wsnt:TerminationTime"`
+}
+
+// RenewResponse is a WS-BaseNotification RenewResponse response
+type RenewResponse struct {
+	SubscriptionTimes
+}
+
+// Unsubscribe is a WS-BaseNotification Unsubscribe operation
+type Unsubscribe struct {
+	XMLName xml.Name `xml:"wsnt:Unsubscribe"`
+}
+
+// Subscription is a push subscription created with Client.Subscribe, or a pull point subscription created with Client.CreatePullPointSubscription.
+// onvif.Client.Shutdown stops Maintain and unsubscribes the subscription if it hasn't been unsubscribed
+type Subscription struct {
+	client    *onvif.Client
+	pullPoint bool
+	// registry is the Registry s is recorded in, if any
+	registry Registry
+	// mu protects stop, stopped, unsubscribed, unregister, times, and received
+	mu sync.Mutex
+	// stop cancels a running Maintain
+	stop context.CancelFunc
+	// stopped is set when the subscription is stopped by onvif.Client.Shutdown
+	stopped      bool
+	unsubscribed bool
+	unregister   func()
+	// Address is the URL of the subscription manager
+	Address string
+	// ReferenceParameters is the subscription reference's wsa:ReferenceParameters, if any.
+	// They're sent as headers with each request to the subscription manager, since some devices use them to identify the subscription
+	ReferenceParameters *soap.ReferenceParameters
+	// times is the subscription times from the last Subscribe or Renew response
+	times SubscriptionTimes
+	// received is the local time the last Subscribe or Renew response was received
+	received time.Time
+}
+
+// Times returns the subscription times from the last Subscribe or Renew response and the local time it was received.
+// It's safe to call while Maintain is running
+func (s *Subscription) Times() (times SubscriptionTimes, received time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.times, s.received
+}
+
+// PullPoint returns true if s is a pull point subscription
+func (s *Subscription) PullPoint() bool {
+	return s.pullPoint
+}
+
+// clock returns the Clock of s's Client
+func (s *Subscription) clock() onvif.Clock {
+	if s.client.Clock != nil {
+		return s.client.Clock
+	}
+	return onvif.SystemClock
+}
+
+// call makes a request for body to url, unmarshaling the response into resp, if not nil.
+// WS-Addressing headers are sent with wsa:To set to url and params (which may be nil) as reference parameters,
+// since many devices route subscription requests with them
+func call(ctx context.Context, c *onvif.Client, url string, params *soap.ReferenceParameters, action string, body, resp interface{}) error {
+	a := &soap.Addressing{ReferenceParameters: params}
+	env, err := c.DoContext(ctx, &onvif.Request{URL: url, Namespaces: namespaces, Body: body, Action: action, Addressing: a})
+	if err != nil {
+		return fmt.Errorf("could not complete operation: %w", err)
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	if err := env.Body.Unmarshal(resp); err != nil {
+		return fmt.Errorf("could not unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe creates a push subscription that sends notifications matching filter (which may be nil) to consumer, e.g. a URL served by a NotificationServer.
+// The subscription terminates after termination, unless it's renewed. See Subscription.Maintain
+func (c *Client) Subscribe(ctx context.Context, consumer string, filter *Filter, termination time.Duration) (*Subscription, error) {
+	req := &Subscribe{ConsumerReference: consumer, Filter: filter}
+	if termination > 0 {
+		req.InitialTerminationTime = soap.FormatDuration(termination)
+	}
+
+	resp := new(SubscribeResponse)
+	if err := call(ctx, c.Client, c.URL, nil, ActionSubscribe, req, resp); err != nil {
+		return nil, err
+	}
+
+	return c.newSubscription(ctx, resp.SubscriptionReference, resp.ReferenceParameters, resp.SubscriptionTimes, false)
+}
+
+// newSubscription returns a Subscription for a subscription created by c, recording it in c.Registry, if set.
+// If it can't be recorded, it's unsubscribed so it isn't orphaned
+func (c *Client) newSubscription(ctx context.Context, address string, params *soap.ReferenceParameters, times SubscriptionTimes, pullPoint bool) (*Subscription, error) {
+	s := &Subscription{client: c.Client, pullPoint: pullPoint, Address: address, ReferenceParameters: params, times: times}
+	s.received = s.clock().Now()
+
+	if c.Registry != nil {
+		rec := &SubscriptionRecord{Service: c.URL, Address: address, ReferenceParameters: params, PullPoint: pullPoint, Created: s.received}
+		if err := c.Registry.Add(rec); err != nil {
+			err = fmt.Errorf("could not record subscription: %w", err)
+			if uerr := s.Unsubscribe(ctx); uerr != nil {
+				return nil, fmt.Errorf("%w (could not unsubscribe: %v)", err, uerr)
+			}
+			return nil, err
+		}
+		s.registry = c.Registry
+	}
+
+	s.unregister = c.Client.RegisterShutdown(s.shutdown)
+	return s, nil
+}
+
+// shutdown stops Maintain and unsubscribes s. It's registered with onvif.Client.RegisterShutdown
+func (s *Subscription) shutdown(ctx context.Context) {
+	s.mu.Lock()
+	s.stopped = true
+	stop := s.stop
+	s.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+
+	// errors are ignored since the device terminates the subscription eventually anyway
+	s.Unsubscribe(ctx) //nolint:errcheck
+}
+
+// Renew extends the subscription to terminate after termination
+func (s *Subscription) Renew(ctx context.Context, termination time.Duration) error {
+	resp := new(RenewResponse)
+	if err := call(ctx, s.client, s.Address, s.ReferenceParameters, ActionRenew, &Renew{TerminationTime: soap.FormatDuration(termination)}, resp); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.times = resp.SubscriptionTimes
+	s.received = s.clock().Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Unsubscribe terminates the subscription. Calling Unsubscribe after it succeeds does nothing
+func (s *Subscription) Unsubscribe(ctx context.Context) error {
+	s.mu.Lock()
+	unsubscribed := s.unsubscribed
+	s.mu.Unlock()
+	if unsubscribed {
+		return nil
+	}
+
+	if err := call(ctx, s.client, s.Address, s.ReferenceParameters, ActionUnsubscribe, &Unsubscribe{}, nil); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.unsubscribed = true
+	unregister := s.unregister
+	s.unregister = nil
+	s.mu.Unlock()
+	if unregister != nil {
+		unregister()
+	}
+	if s.registry != nil {
+		// the subscription is gone, so a record left by a failed Remove is removed by the next Client.Cleanup
+		s.registry.Remove(s.Address) //nolint:errcheck
+	}
+	return nil
+}
+
+// Maintain renews the subscription with termination each time DefaultRenewFraction of its remaining time passes
+// (but no more often than MinRenewInterval), until ctx is canceled or renewing fails.
+// The subscription is then unsubscribed (using a new context limited to termination) and the error that stopped it is always returned,
+// i.e. ctx.Err() or the renewal error (wrapped). If unsubscribing fails, its error is included in the message,
+// but errors.Is and errors.As still match the error that stopped Maintain.
+// If the subscription is stopped by onvif.Client.Shutdown, which unsubscribes it, onvif.ErrClientClosed is returned (wrapped)
+func (s *Subscription) Maintain(ctx context.Context, termination time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return fmt.Errorf("could not maintain subscription: %w", onvif.ErrClientClosed)
+	}
+	s.stop = cancel
+	s.mu.Unlock()
+
+	err := s.maintain(ctx, termination)
+
+	s.mu.Lock()
+	s.stop = nil
+	stopped := s.stopped
+	s.mu.Unlock()
+	if stopped {
+		return fmt.Errorf("could not maintain subscription: %w", onvif.ErrClientClosed)
+	}
+
+	uctx, cancel := context.WithTimeout(context.Background(), termination)
+	defer cancel()
+	if uerr := s.Unsubscribe(uctx); uerr != nil {
+		return fmt.Errorf("%w (could not unsubscribe: %v)", err, uerr)
+	}
+
+	return err
+}
+
+func (s *Subscription) maintain(ctx context.Context, termination time.Duration) error {
+	for {
+		times, received := s.Times()
+		wait, err := times.RenewAfter(received, DefaultRenewFraction)
+		if err != nil {
+			// without a termination time, renew using the requested termination
+			wait = time.Duration(float64(termination) * DefaultRenewFraction)
+		}
+		if wait < MinRenewInterval {
+			wait = MinRenewInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock().After(wait):
+		}
+
+		if err := s.Renew(ctx, termination); err != nil {
+			return fmt.Errorf("could not renew subscription: %w", err)
+		}
+	}
+}
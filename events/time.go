@@ -0,0 +1,75 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// ErrNoTerminationTime indicates a subscription doesn't have a termination time, i.e. it doesn't expire
+var ErrNoTerminationTime = errors.New("no termination time")
+
+// ParseDateTime parses an xsd:dateTime. Times without a time zone are assumed to be UTC
+func ParseDateTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05.999999999", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date time: %q", s)
+}
+
+// SubscriptionTimes is the CurrentTime and TerminationTime returned by subscription operations, e.g. SubscribeResponse or RenewResponse
+type SubscriptionTimes struct {
+	// CurrentTime is the device's time when the response was sent. It's optional
+	CurrentTime string
+	// TerminationTime is when the subscription expires. Most devices return an xsd:dateTime, but some return a relative xsd:duration
+	TerminationTime string
+}
+
+// Remaining returns how long until the subscription terminates.
+// The time is measured on the device's clock (TerminationTime - CurrentTime), so it's correct even if the device's clock is wrong.
+// If CurrentTime isn't set, received (the local time the response was received) is used instead, without skew compensation.
+// ErrNoTerminationTime is returned if TerminationTime isn't set
+func (t *SubscriptionTimes) Remaining(received time.Time) (time.Duration, error) {
+	term := strings.TrimSpace(t.TerminationTime)
+	if term == "" {
+		return 0, ErrNoTerminationTime
+	}
+
+	if strings.HasPrefix(term, "P") || strings.HasPrefix(term, "-P") {
+		return soap.ParseDuration(term)
+	}
+
+	termination, err := ParseDateTime(term)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse termination time: %w", err)
+	}
+
+	current := received
+	if strings.TrimSpace(t.CurrentTime) != "" {
+		if current, err = ParseDateTime(t.CurrentTime); err != nil {
+			return 0, fmt.Errorf("could not parse current time: %w", err)
+		}
+	}
+
+	return termination.Sub(current), nil
+}
+
+// RenewAfter returns how long to wait before renewing the subscription, as fraction (e.g. 0.8) of the remaining time.
+// See Remaining
+func (t *SubscriptionTimes) RenewAfter(received time.Time, fraction float64) (time.Duration, error) {
+	d, err := t.Remaining(received)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, nil
+	}
+	return time.Duration(float64(d) * fraction), nil
+}
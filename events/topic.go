@@ -0,0 +1,72 @@
+package events
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Topic expression dialects
+const (
+	DialectTopicConcreteSet = "http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet"
+	DialectTopicConcrete    = "http://docs.oasis-open.org/wsn/t-1/TopicExpression/Concrete"
+	DialectTopicSimple      = "http://docs.oasis-open.org/wsn/t-1/TopicExpression/Simple"
+)
+
+// PrefixTopics is the namespace prefix TopicFilter adds to topics without a prefix. It's declared as NamespaceTopics
+const PrefixTopics = "tns1"
+
+// TopicExpressionFilter is a wsnt:TopicExpression element. It should be placed in a wsnt:Filter element,
+// with the wsnt prefix declared as NamespaceWSNT. See Filter
+type TopicExpressionFilter struct {
+	XMLName       xml.Name `xml:"wsnt:TopicExpression"`
+	Dialect       string   `xml:"Dialect,attr"`
+	NamespaceTNS1 string   `xml:"xmlns:tns1,attr"`
+	// Namespaces is additional namespace declarations, e.g. for vendor topics. See Namespace
+	Namespaces []xml.Attr `xml:",any,attr"`
+	Expression string     `xml:",chardata"`
+}
+
+// Subtopics returns a ConcreteSet expression matching topic and all topics below it, e.g. Subtopics("tns1:RuleEngine") returns tns1:RuleEngine//.
+func Subtopics(topic string) string {
+	return strings.TrimRight(topic, "/") + "//."
+}
+
+// prefixTopic adds PrefixTopics to the root of topic if it doesn't have a prefix
+func prefixTopic(topic string) string {
+	topic = strings.TrimLeft(strings.TrimSpace(topic), "/")
+	root := topic
+	if idx := strings.IndexByte(topic, '/'); idx != -1 {
+		root = topic[:idx]
+	}
+	if root == "" || strings.Contains(root, ":") {
+		return topic
+	}
+	return PrefixTopics + ":" + topic
+}
+
+// TopicFilter returns a wsnt:TopicExpression in the ConcreteSet dialect matching any of topics, e.g. TopicFilter("tns1:RuleEngine//.", TopicDigitalInput).
+// Topics without a namespace prefix, like the Topic constants, are prefixed with PrefixTopics.
+// Vendor topic prefixes must be declared with Namespace
+func TopicFilter(topics ...string) *TopicExpressionFilter {
+	exprs := make([]string, len(topics))
+	for i, t := range topics {
+		exprs[i] = prefixTopic(t)
+	}
+
+	return &TopicExpressionFilter{
+		Dialect:       DialectTopicConcreteSet,
+		NamespaceTNS1: NamespaceTopics,
+		Expression:    strings.Join(exprs, "|"),
+	}
+}
+
+// Namespace declares prefix as namespace on the expression and returns f, e.g. TopicFilter("tnsaxis:CameraApplicationPlatform//.").Namespace("tnsaxis", "http://www.axis.com/2009/event/topics")
+func (f *TopicExpressionFilter) Namespace(prefix, namespace string) *TopicExpressionFilter {
+	f.Namespaces = append(f.Namespaces, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: namespace})
+	return f
+}
+
+// Filter returns a Filter with f as the topic expression
+func (f *TopicExpressionFilter) Filter() *Filter {
+	return &Filter{TopicExpression: f}
+}
@@ -61,25 +61,16 @@ func Example() {
 		panic(err)
 	}
 
-	var (
-		mediaNS  string
-		mediaURL string
-	)
-
 	// check if media service is supported
-	if s := services.URL(onvif.NamespaceMedia); s != "" {
-		mediaNS = onvif.NamespaceMedia
-		mediaURL = s
-	}
-
-	if mediaNS == "" {
+	media := services.Find(onvif.NamespaceMedia)
+	if media == nil {
 		// handle media service not supported
 		panic("media service not supported")
 	}
 
 	r := &onvif.Request{
-		URL:        mediaURL,
-		Namespaces: soap.Namespaces{"trt": mediaNS},
+		URL:        media.URL,
+		Namespaces: soap.Namespaces{"trt": media.Namespace},
 		Body:       &GetVideoSources{},
 	}
 
@@ -95,18 +86,15 @@ func Example() {
 		panic(err)
 	}
 
-	// check if media ver20 service is supported so newer encodings will be returned
-	if s := services.URL(onvif.NamespaceMedia2); s != "" {
-		mediaNS = onvif.NamespaceMedia2
-		mediaURL = s
-	}
+	// prefer the media ver20 service if it's supported so newer encodings will be returned
+	media = services.Best(onvif.NamespaceMedia2, onvif.NamespaceMedia)
 
 	for _, source := range resp.VideoSources {
 		fmt.Printf("Found video source \"%s\": (%dx%d@%0.2f)\n", source.Token, source.Width, source.Height, source.Framerate)
 
 		r := &onvif.Request{
-			URL:        mediaURL,
-			Namespaces: soap.Namespaces{"trt": mediaNS},
+			URL:        media.URL,
+			Namespaces: soap.Namespaces{"trt": media.Namespace},
 			Body:       &GetVideoSourceModes{Token: source.Token},
 		}
 
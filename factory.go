@@ -0,0 +1,143 @@
+package onvif
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Factory creates Clients for many devices that share a single HTTP transport and connection budget,
+// so managing thousands of devices doesn't require a transport (and its idle connections) per Client.
+// It is safe for concurrent use
+type Factory struct {
+	transport http.RoundTripper
+	template  *Client
+}
+
+// NewFactory returns a Factory whose Clients share transport, with at most maxConns requests in flight across all Clients.
+// If transport is nil, a clone of http.DefaultTransport with at most maxConns idle connections is used. If maxConns is less than 1, requests aren't limited.
+// template's exported fields (e.g. Debug, RetryPolicy, EnvelopeHook, and HTTPClient.Timeout) are copied to each Client, so common behavior is configured once.
+// Middleware, OperationBudgets, RetryPolicy, Hedge, and Quirks are deep copied, so customizing one Client doesn't affect the template or other Clients.
+// Per-device state (Username, Password, Secrets, and TimeOffset) isn't copied. template should not be used to make requests. template may be nil.
+// Since the transport is shared, template's TLSConfig and InsecureSkipVerify aren't used and per-device TLS settings aren't supported;
+// configure TLS on transport instead
+func NewFactory(transport http.RoundTripper, maxConns int, template *Client) *Factory {
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if maxConns > 0 {
+			t.MaxIdleConns = maxConns
+		}
+		transport = t
+	}
+
+	if maxConns > 0 {
+		transport = &limitTransport{RoundTripper: transport, sem: make(chan struct{}, maxConns)}
+	}
+
+	if template == nil {
+		template = new(Client)
+	}
+
+	return &Factory{transport: transport, template: template}
+}
+
+// NewClient returns a new Client using the shared transport and the given credentials
+func (f *Factory) NewClient(username, password string) *Client {
+	c := new(Client)
+	copyExported(c, f.template)
+
+	c.Username, c.Password = username, password
+	c.Secrets = nil
+	c.TimeOffset = 0
+	c.HTTPClient = &http.Client{Transport: f.transport}
+	if f.template.HTTPClient != nil {
+		c.HTTPClient.Timeout = f.template.HTTPClient.Timeout
+	}
+	cloneOptions(c)
+
+	return c
+}
+
+// cloneOptions replaces c's slices, maps, and policies with copies, so they aren't shared with the Client they were copied from
+func cloneOptions(c *Client) {
+	c.Middleware = append([]Middleware(nil), c.Middleware...)
+
+	if c.OperationBudgets != nil {
+		budgets := make(map[string]time.Duration, len(c.OperationBudgets))
+		for op, d := range c.OperationBudgets {
+			budgets[op] = d
+		}
+		c.OperationBudgets = budgets
+	}
+
+	if c.RetryPolicy != nil {
+		p := *c.RetryPolicy
+		p.Faults = make([]*FaultRetry, len(c.RetryPolicy.Faults))
+		for i, fr := range c.RetryPolicy.Faults {
+			f := *fr
+			p.Faults[i] = &f
+		}
+		c.RetryPolicy = &p
+	}
+
+	if c.Hedge != nil {
+		h := *c.Hedge
+		h.Operations = append([]string(nil), c.Hedge.Operations...)
+		c.Hedge = &h
+	}
+
+	if c.Quirks != nil {
+		q := *c.Quirks
+		c.Quirks = &q
+	}
+}
+
+// copyExported copies the exported fields of src to dst.
+// Unexported fields hold per-Client state (e.g. locks, detected authentication modes, and cached security headers), so they're left unset
+func copyExported(dst, src *Client) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if s.Type().Field(i).IsExported() {
+			d.Field(i).Set(s.Field(i))
+		}
+	}
+}
+
+// limitTransport limits the number of requests in flight. A request holds its slot until its response body is closed
+type limitTransport struct {
+	http.RoundTripper
+	sem chan struct{}
+}
+
+func (t *limitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+
+	resp, err := t.RoundTripper.RoundTrip(r)
+	if err != nil {
+		<-t.sem
+		return nil, err
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { <-t.sem }}
+
+	return resp, nil
+}
+
+// releaseBody calls release once when it's closed
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
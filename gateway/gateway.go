@@ -0,0 +1,303 @@
+// Package gateway implements an HTTP server with a JSON API for common operations on ONVIF devices
+// (listing devices, snapshots, PTZ, and events as server-sent events), so services in other languages can use this library as a sidecar.
+//
+// The API is:
+//
+//	GET  /devices                      the devices, as a JSON list of {ID, Addr, Services}
+//	GET  /devices/{id}/profiles        the device's media profiles
+//	GET  /devices/{id}/snapshot        a JPEG snapshot of the profile given by the profile query parameter (default: the first profile)
+//	POST /devices/{id}/ptz/move        start a continuous move. The body is a JSON MoveRequest
+//	POST /devices/{id}/ptz/stop        stop moving. The body is a JSON StopRequest
+//	GET  /devices/{id}/events          a text/event-stream of JSON notifications, limited to the topic query parameters, if any
+//
+// Errors are returned as a JSON {Error} object
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/ptz"
+)
+
+// DefaultTimeout and DefaultEventBuffer are used by Server if its Timeout or EventBuffer isn't set
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultEventBuffer = 100
+)
+
+// MaxRequestSize is the maximum size of a request body accepted by a Server
+const MaxRequestSize = 1 << 16
+
+// errNotFound is returned by Server.device for unknown devices
+var errNotFound = errors.New("not found")
+
+// DeviceInfo is a device listed by GET /devices
+type DeviceInfo struct {
+	ID       string
+	Addr     string
+	Services onvif.Services
+}
+
+// MoveRequest is the body of POST /devices/{id}/ptz/move. See ptz.Client.ContinuousMove
+type MoveRequest struct {
+	Profile  string
+	Velocity *ptz.PTZSpeed
+	// Timeout is an xsd:duration, e.g. PT5S. If empty, the device's default timeout is used
+	Timeout string
+}
+
+// StopRequest is the body of POST /devices/{id}/ptz/stop. Both pan/tilt and zoom are stopped
+type StopRequest struct {
+	Profile string
+}
+
+// Server is an http.Handler serving the API for its devices. The zero value is ready to use, and it is safe for concurrent use
+type Server struct {
+	// Timeout limits the device requests made for each API request. If zero, DefaultTimeout is used
+	Timeout time.Duration
+	// EventBuffer is the notification buffer of each event stream. Notifications for a slow client are dropped when it's full. If zero, DefaultEventBuffer is used
+	EventBuffer int
+
+	mu      sync.RWMutex
+	devices map[string]*entry
+}
+
+type entry struct {
+	dev *onvif.Device
+	bus *events.Bus
+}
+
+// Add serves dev with the given ID, replacing any device with the same ID.
+// Event streams are served from bus, which may be nil if the device's events aren't served. See events.Bus.Pull to feed it from a pull point
+func (s *Server) Add(id string, dev *onvif.Device, bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.devices == nil {
+		s.devices = make(map[string]*entry)
+	}
+	s.devices[id] = &entry{dev: dev, bus: bus}
+}
+
+// Remove stops serving the device with the given ID. Open event streams aren't closed; close the device's bus to end them
+func (s *Server) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.devices, id)
+}
+
+func (s *Server) device(id string) (*entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.devices[id]
+	if !ok {
+		return nil, fmt.Errorf("could not find device %s: %w", id, errNotFound)
+	}
+	return e, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct{ Error string }{err.Error()})
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "devices" {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.New(http.StatusText(http.StatusMethodNotAllowed)))
+			return
+		}
+		s.serveDevices(w)
+		return
+	}
+
+	e, err := s.device(parts[1])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	route := r.Method + " " + strings.Join(parts[2:], "/")
+	if route == "GET events" {
+		s.serveEvents(w, r, e)
+		return
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	switch route {
+	case "GET profiles":
+		s.serveProfiles(ctx, w, e)
+	case "GET snapshot":
+		s.serveSnapshot(ctx, w, r, e)
+	case "POST ptz/move", "POST ptz/stop":
+		s.servePTZ(ctx, w, r, e, parts[3])
+	default:
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+}
+
+func (s *Server) serveDevices(w http.ResponseWriter) {
+	s.mu.RLock()
+	devices := make([]*DeviceInfo, 0, len(s.devices))
+	for id, e := range s.devices {
+		devices = append(devices, &DeviceInfo{ID: id, Addr: e.dev.Addr, Services: e.dev.Services})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	writeJSON(w, http.StatusOK, devices)
+}
+
+func (s *Server) serveProfiles(ctx context.Context, w http.ResponseWriter, e *entry) {
+	m, err := media.FromDevice(e.dev)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	profiles, err := m.GetProfilesContext(ctx)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("could not get profiles: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+func (s *Server) serveSnapshot(ctx context.Context, w http.ResponseWriter, r *http.Request, e *entry) {
+	m, err := media.FromDevice(e.dev)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	profile := r.URL.Query().Get("profile")
+	if profile == "" {
+		profiles, err := m.GetProfilesContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, fmt.Errorf("could not get profiles: %w", err))
+			return
+		}
+		if len(profiles) == 0 {
+			writeError(w, http.StatusNotFound, errors.New("device has no profiles"))
+			return
+		}
+		profile = profiles[0].Token
+	}
+
+	uri, err := m.GetSnapshotUriContext(ctx, profile)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("could not get snapshot URI: %w", err))
+		return
+	}
+	buf, err := e.dev.GetSnapshot(ctx, uri.URI)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("could not get snapshot: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Write(buf) //nolint:errcheck
+}
+
+func (s *Server) servePTZ(ctx context.Context, w http.ResponseWriter, r *http.Request, e *entry, op string) {
+	c, err := ptz.FromDevice(e.dev)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+	if op == "move" {
+		req := new(MoveRequest)
+		if err = dec.Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid move request: %w", err))
+			return
+		}
+		if req.Velocity == nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid move request: missing Velocity"))
+			return
+		}
+		err = c.ContinuousMoveContext(ctx, req.Profile, req.Velocity, req.Timeout)
+	} else {
+		req := new(StopRequest)
+		if err = dec.Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid stop request: %w", err))
+			return
+		}
+		err = c.StopContext(ctx, req.Profile, true, true)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("could not %s: %w", op, err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveEvents streams the device's notifications as server-sent events until the client disconnects or the bus is closed
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request, e *entry) {
+	flusher, ok := w.(http.Flusher)
+	if e.bus == nil || !ok {
+		writeError(w, http.StatusNotFound, errors.New("events not available"))
+		return
+	}
+
+	buffer := s.EventBuffer
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	consumer := e.bus.Subscribe(buffer, nil, r.URL.Query()["topic"]...)
+	defer e.bus.Unsubscribe(consumer)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// a comment lets the client know the stream is open
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case n, ok := <-consumer.Notifications():
+			if !ok {
+				return
+			}
+			buf, err := json.Marshal(n)
+			if err != nil {
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "event: notification\ndata: %s\n\n", buf); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
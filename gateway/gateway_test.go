@@ -0,0 +1,140 @@
+package gateway_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/gateway"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/ptz"
+)
+
+func TestServer(t *testing.T) {
+	srv := onviftest.NewServer("", "", onviftest.AuthNone)
+	defer srv.Close()
+
+	dev, err := onvif.NewDevice(context.Background(), new(onvif.Client), srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+
+	g := new(gateway.Server)
+	g.Add("cam1", dev, nil)
+	api := httptest.NewServer(g)
+	defer api.Close()
+
+	resp, err := http.Get(api.URL + "/devices")
+	if err != nil {
+		t.Fatalf("could not list devices: %v", err)
+	}
+	var devices []*gateway.DeviceInfo
+	err = json.NewDecoder(resp.Body).Decode(&devices)
+	resp.Body.Close()
+	if err != nil || len(devices) != 1 || devices[0].ID != "cam1" || devices[0].Addr != dev.Addr || len(devices[0].Services) == 0 {
+		t.Errorf("unexpected devices: %v, %v", devices, err)
+	}
+
+	resp, err = http.Get(api.URL + "/devices/cam1/snapshot")
+	if err != nil {
+		t.Fatalf("could not get snapshot: %v", err)
+	}
+	buf, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/jpeg" || !bytes.Equal(buf, srv.Snapshot) {
+		t.Errorf("unexpected snapshot: %d %s", resp.StatusCode, buf)
+	}
+
+	move, _ := json.Marshal(&gateway.MoveRequest{Profile: onviftest.ProfileToken, Velocity: &ptz.PTZSpeed{PanTilt: &ptz.Vector2D{X: 0.5}}})
+	for _, test := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/devices/cam1/ptz/move", string(move), http.StatusNoContent},
+		{"/devices/cam1/ptz/stop", `{"Profile":"` + onviftest.ProfileToken + `"}`, http.StatusNoContent},
+		{"/devices/cam1/ptz/stop", `{"Profile":"missing"}`, http.StatusBadGateway},
+		{"/devices/cam1/ptz/move", `{"Profile":"` + onviftest.ProfileToken + `"}`, http.StatusBadRequest},
+		{"/devices/cam2/ptz/stop", `{}`, http.StatusNotFound},
+	} {
+		resp, err = http.Post(api.URL+test.path, "application/json", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatalf("could not post %s: %v", test.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.path, test.body, test.status, resp.StatusCode)
+		}
+	}
+
+	var ops []string
+	for _, r := range srv.Requests() {
+		if r.Namespace == onvif.NamespacePTZ {
+			ops = append(ops, r.Operation)
+		}
+	}
+	if strings.Join(ops, ",") != "ContinuousMove,Stop,Stop" {
+		t.Errorf("unexpected PTZ requests: %v", ops)
+	}
+
+	resp, err = http.Get(api.URL + "/devices/cam1/events")
+	if err != nil {
+		t.Fatalf("could not get events: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected events without a bus to be not found, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	bus := new(events.Bus)
+	g := new(gateway.Server)
+	g.Add("cam1", &onvif.Device{Client: new(onvif.Client)}, bus)
+	api := httptest.NewServer(g)
+	defer api.Close()
+
+	resp, err := http.Get(api.URL + "/devices/cam1/events?topic=" + events.TopicMotionAlarm)
+	if err != nil {
+		t.Fatalf("could not get events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	// the stream is subscribed once the opening comment is sent
+	if line, err := r.ReadString('\n'); err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("unexpected opening: %q, %v", line, err)
+	}
+
+	bus.Publish(&events.Notification{Topic: "tns1:Device/Trigger/DigitalInput"})
+	bus.Publish(&events.Notification{Topic: "tns1:VideoSource/MotionAlarm", Data: events.Items{"State": "true"}})
+	bus.Close()
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("could not read events: %v", err)
+	}
+	var data []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	if len(data) != 1 {
+		t.Fatalf("expected 1 event, got %q", buf)
+	}
+	n := new(events.Notification)
+	if err = json.Unmarshal([]byte(data[0]), n); err != nil || !n.Is(events.TopicMotionAlarm) || !n.Data.Bool("State") {
+		t.Errorf("unexpected event: %s, %v", data[0], err)
+	}
+}
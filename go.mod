@@ -1,5 +1,5 @@
 module github.com/korylprince/go-onvif
 
-go 1.17
+go 1.18
 
 require github.com/icholy/digest v0.1.15
@@ -0,0 +1,126 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// HedgePolicy sends a second, identical request if the first hasn't completed within Delay, and uses whichever response arrives first.
+// This works around device SOAP stacks that randomly stall, at the cost of extra requests.
+// Only operations listed in Operations are hedged, since the device may execute both requests
+type HedgePolicy struct {
+	// Delay is how long to wait for a response before sending the hedged request
+	Delay time.Duration
+	// Operations is the list of operations that are safe to execute more than once, e.g. "Stop" or "GetStatus".
+	// An operation matches either the body element name or the full SOAP action, e.g. http://www.onvif.org/ver20/ptz/wsdl/Stop.
+	// See DefaultHedgeOperations
+	Operations []string
+}
+
+// DefaultHedgeOperations is a list of common operations that are safe to execute more than once
+var DefaultHedgeOperations = []string{
+	"http://www.onvif.org/ver20/ptz/wsdl/Stop",
+	"http://www.onvif.org/ver20/ptz/wsdl/GetStatus",
+	"http://www.onvif.org/ver20/ptz/wsdl/GotoPreset",
+	"http://www.onvif.org/ver20/ptz/wsdl/GotoHomePosition",
+	"http://www.onvif.org/ver20/ptz/wsdl/AbsoluteMove",
+	"http://www.onvif.org/ver20/imaging/wsdl/Stop",
+	"http://www.onvif.org/ver10/device/wsdl/GetSystemDateAndTime",
+	"http://www.onvif.org/ver10/device/wsdl/GetDeviceInformation",
+	"http://www.onvif.org/ver10/media/wsdl/GetSnapshotUri",
+	"http://www.onvif.org/ver10/media/wsdl/GetStreamUri",
+}
+
+// match returns true if the operation is whitelisted
+func (p *HedgePolicy) match(op operation) bool {
+	if p == nil || p.Delay <= 0 {
+		return false
+	}
+	for _, o := range p.Operations {
+		if o == op.name || o == op.action {
+			return true
+		}
+	}
+	return false
+}
+
+// operation identifies the operation of a request
+type operation struct {
+	// name is the body element name, e.g. GetServices
+	name string
+	// action is the SOAP action, e.g. http://www.onvif.org/ver10/device/wsdl/GetServices
+	action string
+}
+
+// requestOperation returns the operation of r
+func requestOperation(r *Request) (operation, error) {
+	buf, err := xml.Marshal(r.Body)
+	if err != nil {
+		return operation{}, err
+	}
+	name, err := bodyName(buf, r.Namespaces)
+	if err != nil {
+		return operation{}, err
+	}
+
+	op := operation{name: name.Local, action: r.Action}
+	if op.action == "" && name.Space != "" {
+		op.action = strings.TrimSuffix(name.Space, "/") + "/" + name.Local
+	}
+	return op, nil
+}
+
+// budget returns the deadline budget for op and true if one is configured
+func (c *Client) budget(op operation) (time.Duration, bool) {
+	if d, ok := c.OperationBudgets[op.action]; ok && op.action != "" {
+		return d, true
+	}
+	d, ok := c.OperationBudgets[op.name]
+	return d, ok
+}
+
+// doHedged is like do, but sends a second request if the first hasn't completed within Client.Hedge.Delay
+func (c *Client) doHedged(ctx context.Context, r *Request, id string) (*soap.Envelope, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// abort the losing request
+	defer cancel()
+
+	type result struct {
+		env *soap.Envelope
+		err error
+	}
+	results := make(chan result, 2)
+	send := func() {
+		env, err := c.send(ctx, r, id)
+		results <- result{env, err}
+	}
+
+	go send()
+	hedge := c.clock().After(c.Hedge.Delay)
+	pending := 1
+	var first error
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			pending++
+			go send()
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				return res.env, nil
+			}
+			if first == nil {
+				first = res.err
+			}
+			// only wait for the hedged request if it's already been sent
+			if pending == 0 {
+				return nil, first
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package imaging
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// AbsoluteFocus is an ONVIF AbsoluteFocus type
+type AbsoluteFocus struct {
+	Position float64
+	Speed    *float64 `xml:",omitempty"`
+}
+
+// RelativeFocus is an ONVIF RelativeFocus type
+type RelativeFocus struct {
+	Distance float64
+	Speed    *float64 `xml:",omitempty"`
+}
+
+// ContinuousFocus is an ONVIF ContinuousFocus type
+type ContinuousFocus struct {
+	Speed float64
+}
+
+// FocusMove is an ONVIF FocusMove type. Exactly one of the fields should be set
+type FocusMove struct {
+	Absolute   *AbsoluteFocus   `xml:",omitempty"`
+	Relative   *RelativeFocus   `xml:",omitempty"`
+	Continuous *ContinuousFocus `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (m *FocusMove) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type move FocusMove
+	return soap.EncodeElementPrefixed(enc, (*move)(m), start, "tt")
+}
+
+// Move is an ONVIF Move operation
+type Move struct {
+	XMLName          xml.Name   `xml:"timg:Move"`
+	VideoSourceToken string     `xml:"timg:VideoSourceToken"`
+	Focus            *FocusMove `xml:"timg:Focus"`
+}
+
+// Move moves the focus lens of the video source with the given token.
+// A continuous move runs until Stop is called
+func (c *Client) Move(videoSourceToken string, focus *FocusMove) error {
+	return c.MoveContext(context.Background(), videoSourceToken, focus)
+}
+
+// MoveContext is like Move, but ctx controls the request
+func (c *Client) MoveContext(ctx context.Context, videoSourceToken string, focus *FocusMove) error {
+	return c.CallContext(ctx, &Move{VideoSourceToken: videoSourceToken, Focus: focus}, nil)
+}
+
+// Stop is an ONVIF Stop operation
+type Stop struct {
+	XMLName          xml.Name `xml:"timg:Stop"`
+	VideoSourceToken string   `xml:"timg:VideoSourceToken"`
+}
+
+// Stop stops any focus movement of the video source with the given token
+func (c *Client) Stop(videoSourceToken string) error {
+	return c.StopContext(context.Background(), videoSourceToken)
+}
+
+// StopContext is like Stop, but ctx controls the request
+func (c *Client) StopContext(ctx context.Context, videoSourceToken string) error {
+	return c.CallContext(ctx, &Stop{VideoSourceToken: videoSourceToken}, nil)
+}
+
+// AbsoluteFocusOptions is an ONVIF AbsoluteFocusOptions type
+type AbsoluteFocusOptions struct {
+	Position FloatRange
+	Speed    *FloatRange
+}
+
+// RelativeFocusOptions is an ONVIF RelativeFocusOptions20 type
+type RelativeFocusOptions struct {
+	Distance FloatRange
+	Speed    *FloatRange
+}
+
+// ContinuousFocusOptions is an ONVIF ContinuousFocusOptions type
+type ContinuousFocusOptions struct {
+	Speed FloatRange
+}
+
+// MoveOptions is an ONVIF MoveOptions20 type. Fields are nil if the move type isn't supported
+type MoveOptions struct {
+	Absolute   *AbsoluteFocusOptions
+	Relative   *RelativeFocusOptions
+	Continuous *ContinuousFocusOptions
+}
+
+// GetMoveOptions is an ONVIF GetMoveOptions operation
+type GetMoveOptions struct {
+	XMLName          xml.Name `xml:"timg:GetMoveOptions"`
+	VideoSourceToken string   `xml:"timg:VideoSourceToken"`
+}
+
+// GetMoveOptionsResponse is an ONVIF GetMoveOptionsResponse response
+type GetMoveOptionsResponse struct {
+	MoveOptions *MoveOptions
+}
+
+// GetMoveOptions returns the supported focus moves of the video source with the given token
+func (c *Client) GetMoveOptions(videoSourceToken string) (*MoveOptions, error) {
+	return c.GetMoveOptionsContext(context.Background(), videoSourceToken)
+}
+
+// GetMoveOptionsContext is like GetMoveOptions, but ctx controls the request
+func (c *Client) GetMoveOptionsContext(ctx context.Context, videoSourceToken string) (*MoveOptions, error) {
+	resp := new(GetMoveOptionsResponse)
+	if err := c.CallContext(ctx, &GetMoveOptions{VideoSourceToken: videoSourceToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.MoveOptions, nil
+}
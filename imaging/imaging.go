@@ -0,0 +1,26 @@
+// Package imaging implements typed operations for the ONVIF Imaging (ver20) service
+package imaging
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Imaging service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Imaging service client using c to make requests to the Imaging service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceImaging, soap.Namespaces{"timg": onvif.NamespaceImaging, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Imaging service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
@@ -0,0 +1,75 @@
+package imaging
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetImagingSettings is an ONVIF GetImagingSettings operation
+type GetImagingSettings struct {
+	XMLName          xml.Name `xml:"timg:GetImagingSettings"`
+	VideoSourceToken string   `xml:"timg:VideoSourceToken"`
+}
+
+// GetImagingSettingsResponse is an ONVIF GetImagingSettingsResponse response
+type GetImagingSettingsResponse struct {
+	ImagingSettings *ImagingSettings
+}
+
+// GetImagingSettings returns the imaging settings of the video source with the given token
+func (c *Client) GetImagingSettings(videoSourceToken string) (*ImagingSettings, error) {
+	return c.GetImagingSettingsContext(context.Background(), videoSourceToken)
+}
+
+// GetImagingSettingsContext is like GetImagingSettings, but ctx controls the request
+func (c *Client) GetImagingSettingsContext(ctx context.Context, videoSourceToken string) (*ImagingSettings, error) {
+	resp := new(GetImagingSettingsResponse)
+	if err := c.CallContext(ctx, &GetImagingSettings{VideoSourceToken: videoSourceToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.ImagingSettings, nil
+}
+
+// SetImagingSettings is an ONVIF SetImagingSettings operation
+type SetImagingSettings struct {
+	XMLName          xml.Name         `xml:"timg:SetImagingSettings"`
+	VideoSourceToken string           `xml:"timg:VideoSourceToken"`
+	ImagingSettings  *ImagingSettings `xml:"timg:ImagingSettings"`
+	ForcePersistence bool             `xml:"timg:ForcePersistence"`
+}
+
+// SetImagingSettings sets the imaging settings of the video source with the given token.
+// Only the non-nil fields of settings are sent. If persist is true, the settings survive a reboot
+func (c *Client) SetImagingSettings(videoSourceToken string, settings *ImagingSettings, persist bool) error {
+	return c.SetImagingSettingsContext(context.Background(), videoSourceToken, settings, persist)
+}
+
+// SetImagingSettingsContext is like SetImagingSettings, but ctx controls the request
+func (c *Client) SetImagingSettingsContext(ctx context.Context, videoSourceToken string, settings *ImagingSettings, persist bool) error {
+	return c.CallContext(ctx, &SetImagingSettings{VideoSourceToken: videoSourceToken, ImagingSettings: settings, ForcePersistence: persist}, nil)
+}
+
+// GetOptions is an ONVIF GetOptions operation
+type GetOptions struct {
+	XMLName          xml.Name `xml:"timg:GetOptions"`
+	VideoSourceToken string   `xml:"timg:VideoSourceToken"`
+}
+
+// GetOptionsResponse is an ONVIF GetOptionsResponse response
+type GetOptionsResponse struct {
+	ImagingOptions *ImagingOptions
+}
+
+// GetOptions returns the valid imaging settings ranges of the video source with the given token
+func (c *Client) GetOptions(videoSourceToken string) (*ImagingOptions, error) {
+	return c.GetOptionsContext(context.Background(), videoSourceToken)
+}
+
+// GetOptionsContext is like GetOptions, but ctx controls the request
+func (c *Client) GetOptionsContext(ctx context.Context, videoSourceToken string) (*ImagingOptions, error) {
+	resp := new(GetOptionsResponse)
+	if err := c.CallContext(ctx, &GetOptions{VideoSourceToken: videoSourceToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.ImagingOptions, nil
+}
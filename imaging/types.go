@@ -0,0 +1,149 @@
+package imaging
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// IrCutFilterMode is an ONVIF IrCutFilterMode
+type IrCutFilterMode string
+
+// IrCutFilterModes
+const (
+	IrCutFilterOn   IrCutFilterMode = "ON"
+	IrCutFilterOff  IrCutFilterMode = "OFF"
+	IrCutFilterAuto IrCutFilterMode = "AUTO"
+)
+
+// BacklightCompensation is an ONVIF BacklightCompensation20 type
+type BacklightCompensation struct {
+	// Mode is ON or OFF
+	Mode  string
+	Level *float64 `xml:",omitempty"`
+}
+
+// Exposure is an ONVIF Exposure20 type. Fields that are nil are left unchanged when setting
+type Exposure struct {
+	// Mode is AUTO or MANUAL
+	Mode string
+	// Priority is LowNoise or FrameRate
+	Priority        string   `xml:",omitempty"`
+	MinExposureTime *float64 `xml:",omitempty"`
+	MaxExposureTime *float64 `xml:",omitempty"`
+	MinGain         *float64 `xml:",omitempty"`
+	MaxGain         *float64 `xml:",omitempty"`
+	MinIris         *float64 `xml:",omitempty"`
+	MaxIris         *float64 `xml:",omitempty"`
+	ExposureTime    *float64 `xml:",omitempty"`
+	Gain            *float64 `xml:",omitempty"`
+	Iris            *float64 `xml:",omitempty"`
+}
+
+// FocusConfiguration is an ONVIF FocusConfiguration20 type
+type FocusConfiguration struct {
+	// AutoFocusMode is AUTO or MANUAL
+	AutoFocusMode string
+	DefaultSpeed  *float64 `xml:",omitempty"`
+	NearLimit     *float64 `xml:",omitempty"`
+	FarLimit      *float64 `xml:",omitempty"`
+}
+
+// WideDynamicRange is an ONVIF WideDynamicRange20 type
+type WideDynamicRange struct {
+	// Mode is ON or OFF
+	Mode  string
+	Level *float64 `xml:",omitempty"`
+}
+
+// WhiteBalance is an ONVIF WhiteBalance20 type
+type WhiteBalance struct {
+	// Mode is AUTO or MANUAL
+	Mode   string
+	CrGain *float64 `xml:",omitempty"`
+	CbGain *float64 `xml:",omitempty"`
+}
+
+// ImagingSettings is an ONVIF ImagingSettings20 type. Fields that are nil or empty are omitted, so when setting,
+// a device keeps its current value for them
+type ImagingSettings struct {
+	BacklightCompensation *BacklightCompensation `xml:",omitempty"`
+	Brightness            *float64               `xml:",omitempty"`
+	ColorSaturation       *float64               `xml:",omitempty"`
+	Contrast              *float64               `xml:",omitempty"`
+	Exposure              *Exposure              `xml:",omitempty"`
+	Focus                 *FocusConfiguration    `xml:",omitempty"`
+	IrCutFilter           IrCutFilterMode        `xml:",omitempty"`
+	Sharpness             *float64               `xml:",omitempty"`
+	WideDynamicRange      *WideDynamicRange      `xml:",omitempty"`
+	WhiteBalance          *WhiteBalance          `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (s *ImagingSettings) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type settings ImagingSettings
+	return soap.EncodeElementPrefixed(enc, (*settings)(s), start, "tt")
+}
+
+// FloatRange is an ONVIF FloatRange type
+type FloatRange struct {
+	Min float64
+	Max float64
+}
+
+// BacklightCompensationOptions is an ONVIF BacklightCompensationOptions20 type
+type BacklightCompensationOptions struct {
+	Mode  []string
+	Level *FloatRange
+}
+
+// ExposureOptions is an ONVIF ExposureOptions20 type
+type ExposureOptions struct {
+	Mode            []string
+	Priority        []string
+	MinExposureTime *FloatRange
+	MaxExposureTime *FloatRange
+	MinGain         *FloatRange
+	MaxGain         *FloatRange
+	MinIris         *FloatRange
+	MaxIris         *FloatRange
+	ExposureTime    *FloatRange
+	Gain            *FloatRange
+	Iris            *FloatRange
+}
+
+// FocusOptions is an ONVIF FocusOptions20 type
+type FocusOptions struct {
+	AutoFocusModes []string
+	DefaultSpeed   *FloatRange
+	NearLimit      *FloatRange
+	FarLimit       *FloatRange
+}
+
+// WideDynamicRangeOptions is an ONVIF WideDynamicRangeOptions20 type
+type WideDynamicRangeOptions struct {
+	Mode  []string
+	Level *FloatRange
+}
+
+// WhiteBalanceOptions is an ONVIF WhiteBalanceOptions20 type
+type WhiteBalanceOptions struct {
+	Mode   []string
+	YrGain *FloatRange
+	YbGain *FloatRange
+}
+
+// ImagingOptions is an ONVIF ImagingOptions20 type, which describes the valid ranges of ImagingSettings.
+// Fields are nil if the setting isn't supported
+type ImagingOptions struct {
+	BacklightCompensation *BacklightCompensationOptions
+	Brightness            *FloatRange
+	ColorSaturation       *FloatRange
+	Contrast              *FloatRange
+	Exposure              *ExposureOptions
+	Focus                 *FocusOptions
+	IrCutFilterModes      []IrCutFilterMode
+	Sharpness             *FloatRange
+	WideDynamicRange      *WideDynamicRangeOptions
+	WhiteBalance          *WhiteBalanceOptions
+}
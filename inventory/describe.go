@@ -0,0 +1,86 @@
+// Package inventory describes ONVIF devices for inventory systems (e.g. CMDBs and asset inventories),
+// scans networks for them, and persists them in a versioned file format
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Description is a JSON-serializable description of a device. See Describe
+type Description struct {
+	// Addr is the address of the device. See onvif.Device.Addr
+	Addr              string
+	Info              *device.GetDeviceInformationResponse
+	Hostname          *device.HostnameInformation
+	NetworkInterfaces []*device.NetworkInterface
+	DNS               *device.DNSInformation
+	NTP               *device.NTPInformation
+	DefaultGateway    *device.NetworkGateway
+	Services          onvif.Services
+	Capabilities      *onvif.Capabilities
+	// Profiles is nil if the device doesn't have the media service
+	Profiles []*media.Profile
+	// Errors are the faults returned for the operations the device doesn't support, keyed by operation, e.g. GetNTP.
+	// The fields filled by those operations are nil
+	Errors map[string]string
+}
+
+// isFault returns true if err is a SOAP fault other than an authorization fault, i.e. the device doesn't support the operation
+func isFault(err error) bool {
+	var f *soap.Fault
+	return errors.As(err, &f) && !errors.Is(err, soap.ErrNotAuthorized)
+}
+
+// Describe returns a Description of dev with its device information, network configuration, services, capabilities, and media profiles.
+// If an operation other than GetDeviceInformation faults, it's recorded in Description.Errors and the rest of the description is still returned.
+// Other errors (e.g. network or authorization errors) are returned.
+// It isn't an onvif.Device method since the service packages import the onvif package
+func Describe(ctx context.Context, dev *onvif.Device) (*Description, error) {
+	c, err := device.FromDevice(dev)
+	if err != nil {
+		return nil, fmt.Errorf("could not create device client: %w", err)
+	}
+
+	d := &Description{Addr: dev.Addr, Services: dev.Services, Errors: make(map[string]string)}
+	if d.Info, err = c.GetDeviceInformationContext(ctx); err != nil {
+		return nil, fmt.Errorf("could not get device information: %w", err)
+	}
+
+	for _, part := range []struct {
+		operation string
+		get       func() error
+	}{
+		{"GetHostname", func() (err error) { d.Hostname, err = c.GetHostnameContext(ctx); return }},
+		{"GetNetworkInterfaces", func() (err error) { d.NetworkInterfaces, err = c.GetNetworkInterfacesContext(ctx); return }},
+		{"GetDNS", func() (err error) { d.DNS, err = c.GetDNSContext(ctx); return }},
+		{"GetNTP", func() (err error) { d.NTP, err = c.GetNTPContext(ctx); return }},
+		{"GetNetworkDefaultGateway", func() (err error) { d.DefaultGateway, err = c.GetNetworkDefaultGatewayContext(ctx); return }},
+		{"GetCapabilities", func() (err error) { d.Capabilities, err = dev.GetAllCapabilitiesContext(ctx, dev.Addr); return }},
+		{"GetProfiles", func() error {
+			if !dev.HasService(onvif.NamespaceMedia) {
+				return nil
+			}
+			m, err := media.FromDevice(dev)
+			if err != nil {
+				return err
+			}
+			d.Profiles, err = m.GetProfilesContext(ctx)
+			return err
+		}},
+	} {
+		if err := part.get(); isFault(err) {
+			d.Errors[part.operation] = err.Error()
+		} else if err != nil {
+			return nil, fmt.Errorf("could not describe device: %s: %w", part.operation, err)
+		}
+	}
+
+	return d, nil
+}
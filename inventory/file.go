@@ -0,0 +1,134 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/media"
+)
+
+// FormatVersion is the version of the inventory format written by Write. Read accepts inventories up to this version
+const FormatVersion = 1
+
+// ErrUnsupportedVersion is returned (wrapped) by Read for inventories with an unknown format version, e.g. written by a newer version of this package
+var ErrUnsupportedVersion = errors.New("unsupported inventory format version")
+
+// ErrUnknownAuthMode is returned (wrapped) by Entry.Device if the entry's AuthMode isn't known
+var ErrUnknownAuthMode = errors.New("unknown auth mode")
+
+// authModes are the names of the auth modes in the inventory format. Names are used instead of the onvif.AuthMode values so the format is stable
+var authModes = map[onvif.AuthMode]string{
+	onvif.AuthModeNone:           "None",
+	onvif.AuthModeDigest:         "Digest",
+	onvif.AuthModeWSSecurity:     "WSSecurity",
+	onvif.AuthModeWSSecurityText: "WSSecurityText",
+}
+
+// Inventory is a versioned record of devices, so discovery results can be persisted across restarts and shared between tools. See Write and Read
+type Inventory struct {
+	// Version is the format version. See FormatVersion
+	Version int
+	Devices []*Entry
+}
+
+// Entry is a device in an Inventory. Credentials aren't recorded
+type Entry struct {
+	// Addr is the address of the device. See onvif.Device.Addr
+	Addr string
+	// AuthMode is the device's authentication mode: None, Digest, WSSecurity, or WSSecurityText. See onvif.Client.CurrentAuthMode
+	AuthMode string
+	Services onvif.Services
+	// Profiles is nil if the device doesn't have the media service
+	Profiles []*media.Profile `json:",omitempty"`
+	// Fingerprint is nil if the device doesn't support the operations it's made from, and FingerprintHash is its Hash
+	Fingerprint     *device.Fingerprint `json:",omitempty"`
+	FingerprintHash string              `json:",omitempty"`
+}
+
+// NewEntry returns an Entry for dev with its services and current auth mode, and its profiles and fingerprint queried from the device.
+// Faults returned for the profiles or fingerprint leave them unset; other errors are returned
+func NewEntry(ctx context.Context, dev *onvif.Device) (*Entry, error) {
+	e := &Entry{Addr: dev.Addr, AuthMode: authModes[dev.CurrentAuthMode()], Services: dev.Services}
+
+	if dev.HasService(onvif.NamespaceMedia) {
+		m, err := media.FromDevice(dev)
+		if err != nil {
+			return nil, err
+		}
+		if e.Profiles, err = m.GetProfilesContext(ctx); err != nil && !isFault(err) {
+			return nil, fmt.Errorf("could not get profiles: %w", err)
+		}
+	}
+
+	c, err := device.FromDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+	if e.Fingerprint, err = c.FingerprintContext(ctx); err != nil && !isFault(err) {
+		return nil, fmt.Errorf("could not get fingerprint: %w", err)
+	}
+	if e.Fingerprint != nil {
+		e.FingerprintHash = e.Fingerprint.Hash()
+	}
+	return e, nil
+}
+
+// Export returns an Inventory of devices, with an Entry for each from NewEntry
+func Export(ctx context.Context, devices []*onvif.Device) (*Inventory, error) {
+	inv := &Inventory{Version: FormatVersion, Devices: make([]*Entry, 0, len(devices))}
+	for _, dev := range devices {
+		e, err := NewEntry(ctx, dev)
+		if err != nil {
+			return nil, fmt.Errorf("could not export %s: %w", dev.Addr, err)
+		}
+		inv.Devices = append(inv.Devices, e)
+	}
+	return inv, nil
+}
+
+// Write writes inv to w as indented JSON with Version set to FormatVersion
+func Write(w io.Writer, inv *Inventory) error {
+	v := *inv
+	v.Version = FormatVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(&v); err != nil {
+		return fmt.Errorf("could not encode inventory: %w", err)
+	}
+	return nil
+}
+
+// Read reads an inventory written by Write from r
+func Read(r io.Reader) (*Inventory, error) {
+	inv := new(Inventory)
+	if err := json.NewDecoder(r).Decode(inv); err != nil {
+		return nil, fmt.Errorf("could not decode inventory: %w", err)
+	}
+	if inv.Version < 1 || inv.Version > FormatVersion {
+		return nil, fmt.Errorf("could not read inventory version %d: %w", inv.Version, ErrUnsupportedVersion)
+	}
+	return inv, nil
+}
+
+// Device returns a Device for the entry using c, without making any requests. If c's AuthMode isn't set, it's set to the entry's AuthMode.
+// c should have the device's credentials, which aren't recorded
+func (e *Entry) Device(c *onvif.Client) (*onvif.Device, error) {
+	if c.AuthMode == onvif.AuthModeNone {
+		found := false
+		for mode, name := range authModes {
+			if name == e.AuthMode {
+				c.AuthMode, found = mode, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("could not set auth mode %q: %w", e.AuthMode, ErrUnknownAuthMode)
+		}
+	}
+	return &onvif.Device{Client: c, Addr: e.Addr, Services: e.Services}, nil
+}
@@ -0,0 +1,162 @@
+package inventory_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/inventory"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/onviftest"
+)
+
+func TestDescribe(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+	srv.Respond("GetNTP", `<tds:GetNTPResponse><tds:NTPInformation><tt:FromDHCP>false</tt:FromDHCP>
+<tt:NTPManual><tt:Type>DNS</tt:Type><tt:DNSname>pool.ntp.org</tt:DNSname></tt:NTPManual></tds:NTPInformation></tds:GetNTPResponse>`)
+
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{Username: "admin", Password: "password"}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+
+	d, err := inventory.Describe(context.Background(), dev)
+	if err != nil {
+		t.Fatalf("could not describe device: %v", err)
+	}
+	if d.Info.Model != onviftest.Model || d.Info.SerialNumber != onviftest.SerialNumber {
+		t.Errorf("unexpected device information: %#v", d.Info)
+	}
+	if d.NTP == nil || len(d.NTP.NTPManual) != 1 || d.NTP.NTPManual[0].DNSname != "pool.ntp.org" {
+		t.Errorf("unexpected NTP information: %#v", d.NTP)
+	}
+	if len(d.Profiles) != 1 || d.Profiles[0].Token != onviftest.ProfileToken || d.Capabilities == nil || len(d.Services) == 0 {
+		t.Errorf("expected profiles, capabilities, and services: %#v", d)
+	}
+	// the server doesn't support the other network operations
+	if _, ok := d.Errors["GetDNS"]; !ok || d.DNS != nil || len(d.Errors) != 4 {
+		t.Errorf("unexpected errors: %v", d.Errors)
+	}
+
+	if _, err = json.Marshal(d); err != nil {
+		t.Errorf("could not marshal description: %v", err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	baselines := []*inventory.Baseline{
+		{Manufacturer: regexp.MustCompile(`^Other`), MinFirmware: "0.1"},
+		{Model: regexp.MustCompile(`^Fake`), MinFirmware: "V1.2 build 1"},
+	}
+	// 127.0.0.2 doesn't have a server, so it isn't included
+	results, err := inventory.Scan(context.Background(), &inventory.ScanOptions{
+		Username: "admin", Password: "password", CIDRs: []string{"127.0.0.0/30"}, Ports: []int{port}, DisableDiscovery: true, Baselines: baselines,
+	})
+	if err != nil {
+		t.Fatalf("could not scan: %v", err)
+	}
+	if len(results) != 1 || results[0].Addr != u.Host || results[0].Err != nil {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if r := results[0]; r.Info.SerialNumber != onviftest.SerialNumber || r.Baseline != baselines[1] || !r.BelowBaseline {
+		t.Errorf("expected device below baseline: %#v", r)
+	}
+
+	results, err = inventory.Scan(context.Background(), &inventory.ScanOptions{
+		Username: "admin", Password: "wrong", CIDRs: []string{u.Hostname() + "/32"}, Ports: []int{port}, DisableDiscovery: true,
+	})
+	if err != nil || len(results) != 1 || results[0].Err == nil {
+		t.Errorf("expected authorization error result, got %#v, %v", results, err)
+	}
+
+	if _, err = inventory.Scan(context.Background(), &inventory.ScanOptions{CIDRs: []string{"10.0.0.0/8"}, DisableDiscovery: true}); err == nil {
+		t.Error("expected too many addresses error")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0", 0},
+		{"1.0.1", "1.0", 1},
+		{"V5.5.3 build 180214", "V5.5.10", -1},
+		{"2.10", "2.9", 1},
+		{"", "1", -1},
+	} {
+		if c := inventory.CompareVersions(test.a, test.b); c != test.expected {
+			t.Errorf("%q vs %q: expected %d, got %d", test.a, test.b, test.expected, c)
+		}
+	}
+}
+
+func TestExportRead(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{Username: "admin", Password: "password"}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+
+	inv, err := inventory.Export(context.Background(), []*onvif.Device{dev})
+	if err != nil {
+		t.Fatalf("could not export: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err = inventory.Write(buf, inv); err != nil {
+		t.Fatalf("could not write inventory: %v", err)
+	}
+
+	inv, err = inventory.Read(buf)
+	if err != nil {
+		t.Fatalf("could not read inventory: %v", err)
+	}
+	if inv.Version != inventory.FormatVersion || len(inv.Devices) != 1 {
+		t.Fatalf("unexpected inventory: %#v", inv)
+	}
+	e := inv.Devices[0]
+	if e.Addr != srv.URL || e.AuthMode != "WSSecurity" || len(e.Services) != len(dev.Services) || len(e.Profiles) != 1 ||
+		e.Fingerprint == nil || e.Fingerprint.SerialNumber != onviftest.SerialNumber || e.FingerprintHash != e.Fingerprint.Hash() {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+
+	// the device is recreated without requests, and authenticates with the recorded mode
+	c := &onvif.Client{Username: "admin", Password: "password"}
+	requests := len(srv.Requests())
+	restored, err := e.Device(c)
+	if err != nil {
+		t.Fatalf("could not restore device: %v", err)
+	}
+	if len(srv.Requests()) != requests || c.AuthMode != onvif.AuthModeWSSecurity {
+		t.Errorf("unexpected requests or auth mode: %d, %v", len(srv.Requests())-requests, c.AuthMode)
+	}
+	m, err := media.FromDevice(restored)
+	if err != nil {
+		t.Fatalf("could not create media client: %v", err)
+	}
+	if _, err = m.GetProfiles(); err != nil {
+		t.Errorf("could not get profiles: %v", err)
+	}
+
+	if _, err = inventory.Read(strings.NewReader(`{"Version": 2}`)); !errors.Is(err, inventory.ErrUnsupportedVersion) {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+	if _, err = (&inventory.Entry{AuthMode: "Kerberos"}).Device(&onvif.Client{}); !errors.Is(err, inventory.ErrUnknownAuthMode) {
+		t.Errorf("expected unknown auth mode error, got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/discovery"
+)
+
+// DefaultScanPorts are the ports Scan probes on each address of ScanOptions.CIDRs if ScanOptions.Ports is empty
+var DefaultScanPorts = []int{80}
+
+// DefaultScanTimeout limits the requests to each address if ScanOptions.Timeout isn't set
+const DefaultScanTimeout = 5 * time.Second
+
+// MaxScanAddresses is the maximum number of addresses Scan probes directly, so a mistyped CIDR doesn't start a scan of millions of addresses
+const MaxScanAddresses = 1 << 16
+
+// Baseline is the minimum firmware version for devices matching Manufacturer and Model
+type Baseline struct {
+	// Manufacturer and Model, if set, must match the device's manufacturer and model
+	Manufacturer *regexp.Regexp
+	Model        *regexp.Regexp
+	// MinFirmware is the minimum firmware version. See CompareVersions
+	MinFirmware string
+}
+
+// Matches returns true if info's manufacturer and model match b
+func (b *Baseline) Matches(info *device.GetDeviceInformationResponse) bool {
+	return (b.Manufacturer == nil || b.Manufacturer.MatchString(info.Manufacturer)) && (b.Model == nil || b.Model.MatchString(info.Model))
+}
+
+// ScanOptions configures Scan
+type ScanOptions struct {
+	// Username and Password are used for each device
+	Username string
+	Password string
+	// Factory, if set, creates the Client for each device. Otherwise Clients share a Factory with a default transport
+	Factory *onvif.Factory
+	// CIDRs are the IPv4 networks (e.g. 192.168.1.0/24) whose addresses are probed directly, for devices that don't respond to WS-Discovery
+	// (e.g. on other subnets). The network and broadcast addresses are skipped
+	CIDRs []string
+	// Ports are the ports probed on each CIDR address. If empty, DefaultScanPorts is used
+	Ports []int
+	// DisableDiscovery disables the WS-Discovery probe, e.g. to only scan CIDRs
+	DisableDiscovery bool
+	// Discovery configures the WS-Discovery probe. It may be nil
+	Discovery *discovery.Options
+	// Baselines are checked in order, and the first that matches a device is used. See ScanResult.BelowBaseline
+	Baselines []*Baseline
+	// Parallelism is the maximum number of addresses probed concurrently. If less than 1, onvif.DefaultBroadcastParallelism is used
+	Parallelism int
+	// Timeout limits the requests to each address. If zero, DefaultScanTimeout is used
+	Timeout time.Duration
+}
+
+// ScanResult is a device found by Scan
+type ScanResult struct {
+	// Addr is the device service URL for discovered devices, or the host:port pair for devices found by probing CIDRs
+	Addr string
+	// EndpointReference is the WS-Discovery endpoint reference, or empty if the device was only found by probing CIDRs
+	EndpointReference string
+	// Info is the device information, or nil if Err is set
+	Info *device.GetDeviceInformationResponse
+	// Baseline is the first of ScanOptions.Baselines that matches the device, or nil if none match
+	Baseline *Baseline
+	// BelowBaseline is true if the device's firmware is older than Baseline.MinFirmware
+	BelowBaseline bool
+	// Err is the error getting the device information, e.g. an authorization error
+	Err error
+}
+
+// errNotONVIF indicates a probed address doesn't respond to GetServices, so it's not included in the scan results
+var errNotONVIF = errors.New("not an ONVIF device")
+
+// Scan finds devices with WS-Discovery and by probing the addresses of opts.CIDRs, then collects their device information and
+// checks their firmware against opts.Baselines. Probed addresses that don't respond to GetServices are ignored.
+// Devices found both ways are returned once, with their discovery address. Results are ordered by discovery, then by CIDR address
+func Scan(ctx context.Context, opts *ScanOptions) ([]*ScanResult, error) {
+	if opts == nil {
+		opts = new(ScanOptions)
+	}
+
+	var (
+		addrs  []string
+		refs   = make(map[string]string)
+		hosts  = make(map[string]bool)
+		ports  = opts.Ports
+		expand []string
+	)
+
+	if !opts.DisableDiscovery {
+		devices, err := discovery.Probe(ctx, opts.Discovery)
+		if err != nil {
+			return nil, fmt.Errorf("could not probe for devices: %w", err)
+		}
+		for _, d := range devices {
+			if len(d.XAddrs) == 0 || hosts[hostPort(d.XAddrs[0])] {
+				continue
+			}
+			hosts[hostPort(d.XAddrs[0])] = true
+			addrs = append(addrs, d.XAddrs[0])
+			refs[d.XAddrs[0]] = d.EndpointReference
+		}
+	}
+
+	if len(ports) == 0 {
+		ports = DefaultScanPorts
+	}
+	for _, cidr := range opts.CIDRs {
+		ips, err := cidrAddresses(cidr, MaxScanAddresses-len(expand))
+		if err != nil {
+			return nil, err
+		}
+		expand = append(expand, ips...)
+	}
+	for _, ip := range expand {
+		for _, port := range ports {
+			addr := net.JoinHostPort(ip, strconv.Itoa(port))
+			if !hosts[addr] {
+				hosts[addr] = true
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	factory := opts.Factory
+	if factory == nil {
+		factory = onvif.NewFactory(nil, 0, nil)
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultScanTimeout
+	}
+
+	report := onvif.Broadcast(ctx, addrs, &onvif.BroadcastOptions{Parallelism: opts.Parallelism, Timeout: timeout},
+		func(ctx context.Context, addr string) (*ScanResult, error) {
+			c := factory.NewClient(opts.Username, opts.Password)
+			dev, err := onvif.NewDevice(ctx, c, addr)
+			if err != nil {
+				if _, discovered := refs[addr]; !discovered {
+					return nil, errNotONVIF
+				}
+				return nil, err
+			}
+			dc, err := device.FromDevice(dev)
+			if err != nil {
+				return nil, err
+			}
+			return check(ctx, addr, dc, opts.Baselines), nil
+		})
+
+	var results []*ScanResult
+	for _, res := range report.Results {
+		if errors.Is(res.Err, errNotONVIF) {
+			continue
+		}
+		r := res.Value
+		if res.Err != nil {
+			r = &ScanResult{Addr: res.Device, Err: res.Err}
+		}
+		r.EndpointReference = refs[res.Device]
+		results = append(results, r)
+	}
+
+	return results, ctx.Err()
+}
+
+// check returns the ScanResult for the device at addr
+func check(ctx context.Context, addr string, c *device.Client, baselines []*Baseline) *ScanResult {
+	r := &ScanResult{Addr: addr}
+	if r.Info, r.Err = c.GetDeviceInformationContext(ctx); r.Err != nil {
+		return r
+	}
+	for _, b := range baselines {
+		if b.Matches(r.Info) {
+			r.Baseline = b
+			r.BelowBaseline = CompareVersions(r.Info.FirmwareVersion, b.MinFirmware) < 0
+			break
+		}
+	}
+	return r
+}
+
+// hostPort returns the host:port pair of the URL u, with the scheme's default port if it doesn't have one
+func hostPort(u string) string {
+	p, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	if p.Port() != "" {
+		return p.Host
+	}
+	if p.Scheme == "https" {
+		return net.JoinHostPort(p.Hostname(), "443")
+	}
+	return net.JoinHostPort(p.Hostname(), "80")
+}
+
+// cidrAddresses returns the host addresses of the IPv4 network cidr, without the network and broadcast addresses (for networks larger than /31).
+// An error is returned if there are more than max addresses
+func cidrAddresses(cidr string, max int) ([]string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse CIDR: %w", err)
+	}
+	ip := network.IP.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("could not scan %s: only IPv4 networks are supported", cidr)
+	}
+
+	ones, bits := network.Mask.Size()
+	size := 1 << (bits - ones)
+	first, last := 0, size
+	if size > 2 {
+		first, last = 1, size-1
+	}
+	if last-first > max {
+		return nil, fmt.Errorf("could not scan %s: more than %d addresses", cidr, MaxScanAddresses)
+	}
+
+	start := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+	addrs := make([]string, 0, last-first)
+	for i := first; i < last; i++ {
+		n := start + uint32(i)
+		addrs = append(addrs, net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).String())
+	}
+	return addrs, nil
+}
+
+// versionRegexp matches the numeric parts of a version
+var versionRegexp = regexp.MustCompile(`\d+`)
+
+// CompareVersions compares the numeric parts of firmware versions a and b in order (e.g. V5.5.3 build 180214 is 5, 5, 3, 180214),
+// returning -1 if a is older than b, 1 if a is newer, or 0 if they're equal. Missing parts are treated as zero
+func CompareVersions(a, b string) int {
+	pa, pb := versionRegexp.FindAllString(a, -1), versionRegexp.FindAllString(b, -1)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb uint64
+		if i < len(pa) {
+			na, _ = strconv.ParseUint(pa[i], 10, 64)
+		}
+		if i < len(pb) {
+			nb, _ = strconv.ParseUint(pb[i], 10, 64)
+		}
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+	}
+	return 0
+}
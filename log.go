@@ -0,0 +1,44 @@
+package onvif
+
+import "time"
+
+// LogEntry describes a single HTTP request made by a Client.
+// A call to Client.Do may make several HTTP requests, e.g. during authentication mode detection or retries
+type LogEntry struct {
+	// CorrelationID is the request's correlation ID. See Request.CorrelationID
+	CorrelationID string
+	URL           string
+	// Action is the SOAP action of the request. It may be empty if it couldn't be determined
+	Action string
+	// StatusCode is the HTTP response status code, or 0 if no response was received
+	StatusCode int
+	// Duration is the time from sending the request until the response headers were received,
+	// or until the response body was read if it's included
+	Duration time.Duration
+	// Err is the error that occurred sending the request or reading the response, if any.
+	// SOAP faults aren't included; they're returned by Client.Do
+	Err error
+	// Request and Response are the redacted request and response bodies if Client.LogBodies is true
+	Request  []byte
+	Response []byte
+}
+
+// Logger receives log entries from a Client. Log may be called concurrently
+type Logger interface {
+	Log(e *LogEntry)
+}
+
+// LoggerFunc is a function that implements Logger
+type LoggerFunc func(e *LogEntry)
+
+// Log implements Logger
+func (f LoggerFunc) Log(e *LogEntry) {
+	f(e)
+}
+
+// log sends e to Client.Logger if it's set
+func (c *Client) log(e *LogEntry) {
+	if c.Logger != nil {
+		c.Logger.Log(e)
+	}
+}
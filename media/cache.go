@@ -0,0 +1,91 @@
+package media
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// uriKey identifies a cached stream URI
+type uriKey struct {
+	profileToken string
+	setup        StreamSetup
+}
+
+// cachedURI is a cached stream URI and when it expires. expires is the zero time if it doesn't expire
+type cachedURI struct {
+	uri     *MediaURI
+	expires time.Time
+}
+
+// uriCache caches stream URIs. See Client.CachedStreamUri
+type uriCache struct {
+	mu      sync.Mutex
+	entries map[uriKey]*cachedURI
+}
+
+// CachedStreamUri is like GetStreamUriContext, but returns a cached URI if one was returned for the same profile and setup and it's still valid.
+// URIs marked InvalidAfterConnect aren't cached, and URIs with a non-zero Timeout expire after it. Others are cached until invalidated.
+// The cache is invalidated by SetVideoEncoderConfiguration, ApplyVideoEncoderConfiguration, and AddVideoEncoderConfiguration, and can be invalidated with InvalidateStreamUris.
+// Changes made by other Clients or applications aren't detected
+func (c *Client) CachedStreamUri(ctx context.Context, profileToken string, setup *StreamSetup) (*MediaURI, error) {
+	if setup == nil {
+		setup = &StreamSetup{Stream: StreamTypeUnicast, Protocol: TransportProtocolRTSP}
+	}
+	key := uriKey{profileToken: profileToken, setup: *setup}
+	now := c.clock().Now()
+
+	c.uris.mu.Lock()
+	if e, ok := c.uris.entries[key]; ok {
+		if e.expires.IsZero() || now.Before(e.expires) {
+			c.uris.mu.Unlock()
+			uri := *e.uri
+			return &uri, nil
+		}
+		delete(c.uris.entries, key)
+	}
+	c.uris.mu.Unlock()
+
+	uri, err := c.GetStreamUriContext(ctx, profileToken, setup)
+	if err != nil || uri == nil || uri.InvalidAfterConnect {
+		return uri, err
+	}
+
+	e := &cachedURI{uri: uri}
+	if timeout, err := soap.ParseDuration(uri.Timeout); err == nil && timeout > 0 {
+		e.expires = now.Add(timeout)
+	}
+
+	c.uris.mu.Lock()
+	if c.uris.entries == nil {
+		c.uris.entries = make(map[uriKey]*cachedURI)
+	}
+	c.uris.entries[key] = e
+	c.uris.mu.Unlock()
+
+	cached := *uri
+	return &cached, nil
+}
+
+// InvalidateStreamUris removes the cached stream URIs for the profile with the given token, or all cached stream URIs if profileToken is empty.
+// It should be called after changing a profile's configuration outside of this Client
+func (c *Client) InvalidateStreamUris(profileToken string) {
+	c.uris.mu.Lock()
+	defer c.uris.mu.Unlock()
+	for key := range c.uris.entries {
+		if profileToken == "" || key.profileToken == profileToken {
+			delete(c.uris.entries, key)
+		}
+	}
+}
+
+// clock returns the Clock of c's onvif.Client
+func (c *Client) clock() onvif.Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return onvif.SystemClock
+}
@@ -0,0 +1,122 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+)
+
+// manualClock is a Clock whose time only changes when it's advanced
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestCachedStreamUri(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		// uriOptions are added to each GetStreamUri response
+		uriOptions string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if bytes.Contains(buf, []byte("SetVideoEncoderConfiguration")) {
+			fmt.Fprintf(w, responseEnvelope, "<SetVideoEncoderConfigurationResponse/>")
+			return
+		}
+		requests++
+		fmt.Fprintf(w, responseEnvelope, fmt.Sprintf(`<GetStreamUriResponse><MediaUri><tt:Uri>rtsp://camera/%d</tt:Uri>%s</MediaUri></GetStreamUriResponse>`, requests, uriOptions))
+	}))
+	defer srv.Close()
+
+	clock := &manualClock{now: time.Now()}
+	c, err := media.NewClient(&onvif.Client{Clock: clock}, onvif.Services{{Namespace: onvif.NamespaceMedia, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	get := func(token string, setup *media.StreamSetup) string {
+		t.Helper()
+		uri, err := c.CachedStreamUri(context.Background(), token, setup)
+		if err != nil {
+			t.Fatalf("could not get stream uri: %v", err)
+		}
+		return uri.URI
+	}
+
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/1" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/1" {
+		t.Errorf("expected cached uri, got %s", uri)
+	}
+	if uri := get("Profile_1", &media.StreamSetup{Stream: media.StreamTypeUnicast, Protocol: media.TransportProtocolHTTP}); uri != "rtsp://camera/2" {
+		t.Errorf("expected uri for different setup, got %s", uri)
+	}
+
+	// changing an encoder configuration invalidates all profiles
+	if err = c.SetVideoEncoderConfiguration(encoderConfig()); err != nil {
+		t.Fatalf("could not set configuration: %v", err)
+	}
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/3" {
+		t.Errorf("expected invalidated uri, got %s", uri)
+	}
+
+	c.InvalidateStreamUris("Profile_2")
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/3" {
+		t.Errorf("expected other profile to stay cached, got %s", uri)
+	}
+	c.InvalidateStreamUris("Profile_1")
+
+	mu.Lock()
+	uriOptions = "<tt:Timeout>PT60S</tt:Timeout>"
+	mu.Unlock()
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/4" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+	clock.advance(30 * time.Second)
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/4" {
+		t.Errorf("expected cached uri before timeout, got %s", uri)
+	}
+	clock.advance(30 * time.Second)
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/5" {
+		t.Errorf("expected expired uri, got %s", uri)
+	}
+
+	mu.Lock()
+	uriOptions = "<tt:InvalidAfterConnect>true</tt:InvalidAfterConnect>"
+	mu.Unlock()
+	c.InvalidateStreamUris("")
+	get("Profile_1", nil)
+	if uri := get("Profile_1", nil); uri != "rtsp://camera/7" {
+		t.Errorf("expected uri valid for one connection not to be cached, got %s", uri)
+	}
+}
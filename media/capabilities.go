@@ -0,0 +1,54 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// StreamingCapabilities is an ONVIF media StreamingCapabilities type
+type StreamingCapabilities struct {
+	RTPMulticast bool `xml:"RTPMulticast,attr"`
+	RTPTCP       bool `xml:"RTP_TCP,attr"`
+	RTPRTSPTCP   bool `xml:"RTP_RTSP_TCP,attr"`
+	// NonAggregateControl is true if the device supports non aggregate RTSP control
+	NonAggregateControl bool `xml:"NonAggregateControl,attr"`
+}
+
+// ProfileCapabilities is an ONVIF media ProfileCapabilities type
+type ProfileCapabilities struct {
+	MaximumNumberOfProfiles int `xml:"MaximumNumberOfProfiles,attr"`
+}
+
+// Capabilities is an ONVIF media Capabilities type
+type Capabilities struct {
+	SnapshotUri           bool `xml:"SnapshotUri,attr"`
+	Rotation              bool `xml:"Rotation,attr"`
+	VideoSourceMode       bool `xml:"VideoSourceMode,attr"`
+	OSD                   bool `xml:"OSD,attr"`
+	ProfileCapabilities   *ProfileCapabilities
+	StreamingCapabilities *StreamingCapabilities
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"trt:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the media service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
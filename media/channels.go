@@ -0,0 +1,104 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// VideoSource is an ONVIF VideoSource type
+type VideoSource struct {
+	Token      string `xml:"token,attr"`
+	Framerate  float64
+	Resolution *VideoResolution
+}
+
+// GetVideoSources is an ONVIF GetVideoSources operation
+type GetVideoSources struct {
+	XMLName xml.Name `xml:"trt:GetVideoSources"`
+}
+
+// GetVideoSourcesResponse is an ONVIF GetVideoSourcesResponse response
+type GetVideoSourcesResponse struct {
+	VideoSources []*VideoSource
+}
+
+// GetVideoSources returns the device's video sources, e.g. one per input of an encoder
+func (c *Client) GetVideoSources() ([]*VideoSource, error) {
+	return c.GetVideoSourcesContext(context.Background())
+}
+
+// GetVideoSourcesContext is like GetVideoSources, but ctx controls the request
+func (c *Client) GetVideoSourcesContext(ctx context.Context) ([]*VideoSource, error) {
+	resp := new(GetVideoSourcesResponse)
+	if err := c.CallContext(ctx, &GetVideoSources{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.VideoSources, nil
+}
+
+// Channel is a video source of a device with the profiles that use it. See Client.Channels
+type Channel struct {
+	// Index is the position of the channel, starting at zero. It's stable as long as the device's video sources don't change
+	Index int
+	// VideoSource is the video source, or nil if it's only referenced by profiles and not returned by GetVideoSources
+	VideoSource      *VideoSource
+	VideoSourceToken string
+	Profiles         []*Profile
+	// StreamURIs are the unicast RTSP stream URIs of Profiles, in the same order. A URI is empty if GetStreamUri faults for its profile
+	StreamURIs []string
+}
+
+// Channels groups the device's profiles and their stream URIs by video source, for multichannel devices like encoders and NVRs.
+// Channels are ordered by GetVideoSources, followed by video sources only referenced by profiles in the order they're first referenced.
+// Profiles without a video source configuration aren't included. Stream URIs are returned with CachedStreamUri
+func (c *Client) Channels(ctx context.Context) ([]*Channel, error) {
+	profiles, err := c.GetProfilesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get profiles: %w", err)
+	}
+
+	sources, err := c.GetVideoSourcesContext(ctx)
+	if err != nil && !isFault(err) {
+		return nil, fmt.Errorf("could not get video sources: %w", err)
+	}
+
+	var channels []*Channel
+	byToken := make(map[string]*Channel)
+	add := func(token string, source *VideoSource) *Channel {
+		ch := &Channel{Index: len(channels), VideoSource: source, VideoSourceToken: token}
+		channels = append(channels, ch)
+		byToken[token] = ch
+		return ch
+	}
+
+	for _, s := range sources {
+		if _, ok := byToken[s.Token]; !ok {
+			add(s.Token, s)
+		}
+	}
+
+	for _, p := range profiles {
+		if p.VideoSourceConfiguration == nil {
+			continue
+		}
+		token := p.VideoSourceConfiguration.SourceToken
+		ch, ok := byToken[token]
+		if !ok {
+			ch = add(token, nil)
+		}
+
+		var uri string
+		u, err := c.CachedStreamUri(ctx, p.Token, nil)
+		if err != nil && !isFault(err) {
+			return nil, fmt.Errorf("could not get stream uri for %s: %w", p.Token, err)
+		}
+		if u != nil {
+			uri = u.URI
+		}
+		ch.Profiles = append(ch.Profiles, p)
+		ch.StreamURIs = append(ch.StreamURIs, uri)
+	}
+
+	return channels, nil
+}
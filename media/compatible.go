@@ -0,0 +1,81 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetCompatibleMetadataConfigurations is an ONVIF GetCompatibleMetadataConfigurations operation
+type GetCompatibleMetadataConfigurations struct {
+	XMLName      xml.Name `xml:"trt:GetCompatibleMetadataConfigurations"`
+	ProfileToken string   `xml:"trt:ProfileToken"`
+}
+
+// GetCompatibleMetadataConfigurationsResponse is an ONVIF GetCompatibleMetadataConfigurationsResponse response
+type GetCompatibleMetadataConfigurationsResponse struct {
+	Configurations []*MetadataConfiguration
+}
+
+// GetCompatibleMetadataConfigurations returns the metadata configurations that can be added to the profile with the given token
+func (c *Client) GetCompatibleMetadataConfigurations(profileToken string) ([]*MetadataConfiguration, error) {
+	return c.GetCompatibleMetadataConfigurationsContext(context.Background(), profileToken)
+}
+
+// GetCompatibleMetadataConfigurationsContext is like GetCompatibleMetadataConfigurations, but ctx controls the request
+func (c *Client) GetCompatibleMetadataConfigurationsContext(ctx context.Context, profileToken string) ([]*MetadataConfiguration, error) {
+	resp := new(GetCompatibleMetadataConfigurationsResponse)
+	if err := c.CallContext(ctx, &GetCompatibleMetadataConfigurations{ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Configurations, nil
+}
+
+// GetCompatibleAudioSourceConfigurations is an ONVIF GetCompatibleAudioSourceConfigurations operation
+type GetCompatibleAudioSourceConfigurations struct {
+	XMLName      xml.Name `xml:"trt:GetCompatibleAudioSourceConfigurations"`
+	ProfileToken string   `xml:"trt:ProfileToken"`
+}
+
+// GetCompatibleAudioSourceConfigurationsResponse is an ONVIF GetCompatibleAudioSourceConfigurationsResponse response
+type GetCompatibleAudioSourceConfigurationsResponse struct {
+	Configurations []*AudioSourceConfiguration
+}
+
+// GetCompatibleAudioSourceConfigurations returns the audio source configurations that can be added to the profile with the given token
+func (c *Client) GetCompatibleAudioSourceConfigurations(profileToken string) ([]*AudioSourceConfiguration, error) {
+	return c.GetCompatibleAudioSourceConfigurationsContext(context.Background(), profileToken)
+}
+
+// GetCompatibleAudioSourceConfigurationsContext is like GetCompatibleAudioSourceConfigurations, but ctx controls the request
+func (c *Client) GetCompatibleAudioSourceConfigurationsContext(ctx context.Context, profileToken string) ([]*AudioSourceConfiguration, error) {
+	resp := new(GetCompatibleAudioSourceConfigurationsResponse)
+	if err := c.CallContext(ctx, &GetCompatibleAudioSourceConfigurations{ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Configurations, nil
+}
+
+// GetCompatibleAudioOutputConfigurations is an ONVIF GetCompatibleAudioOutputConfigurations operation
+type GetCompatibleAudioOutputConfigurations struct {
+	XMLName      xml.Name `xml:"trt:GetCompatibleAudioOutputConfigurations"`
+	ProfileToken string   `xml:"trt:ProfileToken"`
+}
+
+// GetCompatibleAudioOutputConfigurationsResponse is an ONVIF GetCompatibleAudioOutputConfigurationsResponse response
+type GetCompatibleAudioOutputConfigurationsResponse struct {
+	Configurations []*AudioOutputConfiguration
+}
+
+// GetCompatibleAudioOutputConfigurations returns the audio output configurations that can be added to the profile with the given token
+func (c *Client) GetCompatibleAudioOutputConfigurations(profileToken string) ([]*AudioOutputConfiguration, error) {
+	return c.GetCompatibleAudioOutputConfigurationsContext(context.Background(), profileToken)
+}
+
+// GetCompatibleAudioOutputConfigurationsContext is like GetCompatibleAudioOutputConfigurations, but ctx controls the request
+func (c *Client) GetCompatibleAudioOutputConfigurationsContext(ctx context.Context, profileToken string) ([]*AudioOutputConfiguration, error) {
+	resp := new(GetCompatibleAudioOutputConfigurationsResponse)
+	if err := c.CallContext(ctx, &GetCompatibleAudioOutputConfigurations{ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Configurations, nil
+}
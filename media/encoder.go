@@ -0,0 +1,248 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+	"math"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+)
+
+// EncoderPollInterval is how often ApplyVideoEncoderConfiguration reads back the configuration
+const EncoderPollInterval = 500 * time.Millisecond
+
+// EncoderMaxPolls is how many times ApplyVideoEncoderConfiguration reads back the configuration if its context has no deadline
+const EncoderMaxPolls = 20
+
+// qualityTolerance is the largest difference between a requested and applied quality that's considered a match,
+// since devices may round the quality when storing it
+const qualityTolerance = 0.01
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (v *VideoEncoderConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type resolution struct {
+		Width  int `xml:"tt:Width"`
+		Height int `xml:"tt:Height"`
+	}
+	type rateControl struct {
+		FrameRateLimit   int `xml:"tt:FrameRateLimit"`
+		EncodingInterval int `xml:"tt:EncodingInterval"`
+		BitrateLimit     int `xml:"tt:BitrateLimit"`
+	}
+	type mpeg4 struct {
+		GovLength    int    `xml:"tt:GovLength"`
+		Mpeg4Profile string `xml:"tt:Mpeg4Profile"`
+	}
+	type h264 struct {
+		GovLength   int    `xml:"tt:GovLength"`
+		H264Profile string `xml:"tt:H264Profile"`
+	}
+	type address struct {
+		Type        string `xml:"tt:Type"`
+		IPv4Address string `xml:"tt:IPv4Address,omitempty"`
+		IPv6Address string `xml:"tt:IPv6Address,omitempty"`
+	}
+	type multicast struct {
+		Address   *address `xml:"tt:Address"`
+		Port      int      `xml:"tt:Port"`
+		TTL       int      `xml:"tt:TTL"`
+		AutoStart bool     `xml:"tt:AutoStart"`
+	}
+	c := struct {
+		Token          string       `xml:"token,attr"`
+		Name           string       `xml:"tt:Name"`
+		UseCount       int          `xml:"tt:UseCount"`
+		Encoding       string       `xml:"tt:Encoding"`
+		Resolution     *resolution  `xml:"tt:Resolution,omitempty"`
+		Quality        float64      `xml:"tt:Quality"`
+		RateControl    *rateControl `xml:"tt:RateControl,omitempty"`
+		MPEG4          *mpeg4       `xml:"tt:MPEG4,omitempty"`
+		H264           *h264        `xml:"tt:H264,omitempty"`
+		Multicast      *multicast   `xml:"tt:Multicast,omitempty"`
+		SessionTimeout string       `xml:"tt:SessionTimeout,omitempty"`
+	}{
+		Token:          v.Token,
+		Name:           v.Name,
+		UseCount:       v.UseCount,
+		Encoding:       v.Encoding,
+		Quality:        v.Quality,
+		SessionTimeout: v.SessionTimeout,
+	}
+	if v.Resolution != nil {
+		c.Resolution = &resolution{Width: v.Resolution.Width, Height: v.Resolution.Height}
+	}
+	if v.RateControl != nil {
+		c.RateControl = &rateControl{FrameRateLimit: v.RateControl.FrameRateLimit, EncodingInterval: v.RateControl.EncodingInterval, BitrateLimit: v.RateControl.BitrateLimit}
+	}
+	if v.MPEG4 != nil {
+		c.MPEG4 = &mpeg4{GovLength: v.MPEG4.GovLength, Mpeg4Profile: v.MPEG4.Mpeg4Profile}
+	}
+	if v.H264 != nil {
+		c.H264 = &h264{GovLength: v.H264.GovLength, H264Profile: v.H264.H264Profile}
+	}
+	if v.Multicast != nil {
+		c.Multicast = &multicast{Port: v.Multicast.Port, TTL: v.Multicast.TTL, AutoStart: v.Multicast.AutoStart}
+		if a := v.Multicast.Address; a != nil {
+			c.Multicast.Address = &address{Type: a.Type, IPv4Address: a.IPv4Address, IPv6Address: a.IPv6Address}
+		}
+	}
+	return enc.EncodeElement(c, start)
+}
+
+// GetVideoEncoderConfiguration is an ONVIF GetVideoEncoderConfiguration operation
+type GetVideoEncoderConfiguration struct {
+	XMLName            xml.Name `xml:"trt:GetVideoEncoderConfiguration"`
+	ConfigurationToken string   `xml:"trt:ConfigurationToken"`
+}
+
+// GetVideoEncoderConfigurationResponse is an ONVIF GetVideoEncoderConfigurationResponse response
+type GetVideoEncoderConfigurationResponse struct {
+	Configuration *VideoEncoderConfiguration
+}
+
+// GetVideoEncoderConfiguration returns the video encoder configuration with the given token
+func (c *Client) GetVideoEncoderConfiguration(token string) (*VideoEncoderConfiguration, error) {
+	return c.GetVideoEncoderConfigurationContext(context.Background(), token)
+}
+
+// GetVideoEncoderConfigurationContext is like GetVideoEncoderConfiguration, but ctx controls the request
+func (c *Client) GetVideoEncoderConfigurationContext(ctx context.Context, token string) (*VideoEncoderConfiguration, error) {
+	resp := new(GetVideoEncoderConfigurationResponse)
+	if err := c.CallContext(ctx, &GetVideoEncoderConfiguration{ConfigurationToken: token}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Configuration, nil
+}
+
+// SetVideoEncoderConfiguration is an ONVIF SetVideoEncoderConfiguration operation
+type SetVideoEncoderConfiguration struct {
+	XMLName       xml.Name                   `xml:"trt:SetVideoEncoderConfiguration"`
+	Configuration *VideoEncoderConfiguration `xml:"trt:Configuration"`
+	// ForcePersistence is obsolete and should always be true
+	ForcePersistence bool `xml:"trt:ForcePersistence"`
+}
+
+// SetVideoEncoderConfiguration sets the video encoder configuration. ForcePersistence is always sent as true, since it's obsolete and some devices reject false.
+// Cached stream URIs are invalidated. See CachedStreamUri
+func (c *Client) SetVideoEncoderConfiguration(config *VideoEncoderConfiguration) error {
+	return c.SetVideoEncoderConfigurationContext(context.Background(), config)
+}
+
+// SetVideoEncoderConfigurationContext is like SetVideoEncoderConfiguration, but ctx controls the request
+func (c *Client) SetVideoEncoderConfigurationContext(ctx context.Context, config *VideoEncoderConfiguration) error {
+	// the configuration may be used by any profile, so all cached stream URIs are invalidated
+	defer c.InvalidateStreamUris("")
+	return c.CallContext(ctx, &SetVideoEncoderConfiguration{Configuration: config, ForcePersistence: true}, nil)
+}
+
+// EncoderChange is the result of ApplyVideoEncoderConfiguration
+type EncoderChange struct {
+	// Previous is the configuration before the change
+	Previous *VideoEncoderConfiguration
+	// Applied is the configuration last read back from the device
+	Applied *VideoEncoderConfiguration
+	// Matched is true if Applied matches the requested encoding, resolution, quality, rate control, MPEG4, and H264 settings
+	Matched bool
+	// RefetchStreamURIs is true if the encoding, resolution, or multicast settings changed.
+	// Stream URIs of profiles using the configuration should be fetched again and active sessions restarted
+	RefetchStreamURIs bool
+}
+
+// ApplyVideoEncoderConfiguration sets config, then reads it back until the change takes effect or ctx is done.
+// If ctx is done first, the change is returned with Matched false, along with ctx.Err().
+// Devices may clamp or normalize settings (e.g. the quality or bitrate), so if the device applies a change but the read-back doesn't match
+// and stops changing, the change is returned with Matched false. If ctx has no deadline, the configuration is read back at most EncoderMaxPolls times
+func (c *Client) ApplyVideoEncoderConfiguration(ctx context.Context, config *VideoEncoderConfiguration) (*EncoderChange, error) {
+	prev, err := c.GetVideoEncoderConfigurationContext(ctx, config.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.SetVideoEncoderConfigurationContext(ctx, config); err != nil {
+		return nil, err
+	}
+
+	change := &EncoderChange{Previous: prev, RefetchStreamURIs: streamChanged(prev, config)}
+
+	clock := c.Clock
+	if clock == nil {
+		clock = onvif.SystemClock
+	}
+
+	_, bounded := ctx.Deadline()
+	for polls := 1; ; polls++ {
+		last := change.Applied
+		if change.Applied, err = c.GetVideoEncoderConfigurationContext(ctx, config.Token); err != nil {
+			return nil, err
+		}
+		if change.Matched = encoderMatches(config, change.Applied); change.Matched {
+			return change, nil
+		}
+		// the device applied a change, but not the requested one
+		if last != nil && !sameEncoder(prev, change.Applied) && sameEncoder(last, change.Applied) {
+			return change, nil
+		}
+		if !bounded && polls >= EncoderMaxPolls {
+			return change, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return change, ctx.Err()
+		case <-clock.After(EncoderPollInterval):
+		}
+	}
+}
+
+// encoderMatches returns true if the settings requested in want are applied in got
+func encoderMatches(want, got *VideoEncoderConfiguration) bool {
+	if got == nil || want.Encoding != got.Encoding || math.Abs(want.Quality-got.Quality) > qualityTolerance {
+		return false
+	}
+	if want.Resolution != nil && (got.Resolution == nil || *want.Resolution != *got.Resolution) {
+		return false
+	}
+	if want.RateControl != nil && (got.RateControl == nil || *want.RateControl != *got.RateControl) {
+		return false
+	}
+	if want.MPEG4 != nil && (got.MPEG4 == nil || *want.MPEG4 != *got.MPEG4) {
+		return false
+	}
+	if want.H264 != nil && (got.H264 == nil || *want.H264 != *got.H264) {
+		return false
+	}
+	return true
+}
+
+// sameEncoder returns true if a and b have the same settings compared by encoderMatches
+func sameEncoder(a, b *VideoEncoderConfiguration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return encoderMatches(a, b) && encoderMatches(b, a)
+}
+
+// streamChanged returns true if the change from prev to next requires stream URIs to be fetched again
+func streamChanged(prev, next *VideoEncoderConfiguration) bool {
+	if prev == nil {
+		return true
+	}
+	if prev.Encoding != next.Encoding {
+		return true
+	}
+	if (prev.Resolution == nil) != (next.Resolution == nil) || (prev.Resolution != nil && *prev.Resolution != *next.Resolution) {
+		return true
+	}
+	if (prev.Multicast == nil) != (next.Multicast == nil) {
+		return true
+	}
+	if prev.Multicast != nil {
+		pm, nm := prev.Multicast, next.Multicast
+		if pm.Port != nm.Port || pm.TTL != nm.TTL || pm.AutoStart != nm.AutoStart ||
+			(pm.Address == nil) != (nm.Address == nil) || (pm.Address != nil && *pm.Address != *nm.Address) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+const responseEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+// testClock is a Clock whose After fires immediately
+type testClock struct {
+	mu    sync.Mutex
+	waits int
+}
+
+func (c *testClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits++
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+// encoderDevice is a media service storing a single video encoder configuration
+type encoderDevice struct {
+	mu      sync.Mutex
+	current *media.VideoEncoderConfiguration
+	pending *media.VideoEncoderConfiguration
+	// delay is the number of reads before a set configuration is applied
+	delay int
+	// ignore discards set configurations
+	ignore bool
+	// normalize, if set, modifies set configurations, e.g. to clamp values
+	normalize func(*media.VideoEncoderConfiguration)
+	set       bool
+	// reads is the number of reads after the configuration is set
+	reads int
+}
+
+func (d *encoderDevice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	env := new(soap.Envelope)
+	if err := xml.NewDecoder(r.Body).Decode(env); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if bytes.Contains(env.Body.InnerXML, []byte("SetVideoEncoderConfiguration")) {
+		req := new(struct {
+			Configuration *media.VideoEncoderConfiguration
+		})
+		if err := env.Body.Unmarshal(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if d.normalize != nil {
+			d.normalize(req.Configuration)
+		}
+		if !d.ignore {
+			d.pending = req.Configuration
+		}
+		d.set = true
+		fmt.Fprintf(w, responseEnvelope, "<SetVideoEncoderConfigurationResponse/>")
+		return
+	}
+
+	if d.set {
+		d.reads++
+	}
+	if d.pending != nil && d.reads > d.delay {
+		d.current, d.pending = d.pending, nil
+	}
+
+	buf, err := xml.Marshal(struct {
+		XMLName       xml.Name `xml:"GetVideoEncoderConfigurationResponse"`
+		Configuration *media.VideoEncoderConfiguration
+	}{Configuration: d.current})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, responseEnvelope, buf)
+}
+
+func encoderConfig() *media.VideoEncoderConfiguration {
+	return &media.VideoEncoderConfiguration{
+		Token:       "VideoEncoder_1",
+		Name:        "VideoEncoder_1",
+		UseCount:    1,
+		Encoding:    "H264",
+		Resolution:  &media.VideoResolution{Width: 1920, Height: 1080},
+		Quality:     5,
+		RateControl: &media.VideoRateControl{FrameRateLimit: 30, EncodingInterval: 1, BitrateLimit: 8192},
+		H264:        &media.H264Configuration{GovLength: 30, H264Profile: "Main"},
+		Multicast:   &media.MulticastConfiguration{Address: &media.IPAddress{Type: "IPv4", IPv4Address: "239.0.0.1"}, Port: 5000, TTL: 1},
+	}
+}
+
+func TestVideoEncoderConfigurationMarshal(t *testing.T) {
+	config := encoderConfig()
+	config.Encoding, config.H264 = "MPEG4", nil
+	config.MPEG4 = &media.Mpeg4Configuration{GovLength: 15, Mpeg4Profile: "SP"}
+
+	buf, err := xml.Marshal(&media.SetVideoEncoderConfiguration{Configuration: config, ForcePersistence: true})
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+	if !bytes.Contains(buf, []byte("<tt:MPEG4><tt:GovLength>15</tt:GovLength><tt:Mpeg4Profile>SP</tt:Mpeg4Profile></tt:MPEG4>")) {
+		t.Errorf("expected MPEG4 configuration: %s", buf)
+	}
+	if bytes.Contains(buf, []byte("SessionTimeout")) || bytes.Contains(buf, []byte("H264")) {
+		t.Errorf("expected empty SessionTimeout and H264 to be omitted: %s", buf)
+	}
+
+	config.SessionTimeout = "PT60S"
+	if buf, err = xml.Marshal(config); err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+	if !bytes.Contains(buf, []byte("<tt:SessionTimeout>PT60S</tt:SessionTimeout>")) {
+		t.Errorf("expected SessionTimeout: %s", buf)
+	}
+}
+
+func TestApplyVideoEncoderConfiguration(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		delay     int
+		ignore    bool
+		normalize func(*media.VideoEncoderConfiguration)
+		change    func(*media.VideoEncoderConfiguration)
+		matched   bool
+		refetch   bool
+		// reads is the number of times the configuration is read back
+		reads int
+	}{
+		{name: "applied", change: func(c *media.VideoEncoderConfiguration) { c.Quality = 4 }, matched: true, reads: 1},
+		{name: "delayed", delay: 2, change: func(c *media.VideoEncoderConfiguration) { c.Quality = 4 }, matched: true, reads: 3},
+		{
+			name:      "rounded quality",
+			normalize: func(c *media.VideoEncoderConfiguration) { c.Quality = 3.999 },
+			change:    func(c *media.VideoEncoderConfiguration) { c.Quality = 4 },
+			matched:   true, reads: 1,
+		},
+		{
+			name:      "clamped bitrate",
+			normalize: func(c *media.VideoEncoderConfiguration) { c.RateControl.BitrateLimit = 4096 },
+			change:    func(c *media.VideoEncoderConfiguration) { c.RateControl.BitrateLimit = 16384 },
+			matched:   false, reads: 2,
+		},
+		{name: "ignored", ignore: true, change: func(c *media.VideoEncoderConfiguration) { c.Quality = 4 }, matched: false, reads: media.EncoderMaxPolls},
+		{
+			name: "resolution",
+			change: func(c *media.VideoEncoderConfiguration) {
+				c.Resolution = &media.VideoResolution{Width: 1280, Height: 720}
+			},
+			matched: true, refetch: true, reads: 1,
+		},
+		{
+			name: "encoding",
+			change: func(c *media.VideoEncoderConfiguration) {
+				c.Encoding, c.H264, c.MPEG4 = "MPEG4", nil, &media.Mpeg4Configuration{GovLength: 15, Mpeg4Profile: "SP"}
+			},
+			matched: true, refetch: true, reads: 1,
+		},
+		{name: "multicast", change: func(c *media.VideoEncoderConfiguration) { c.Multicast.Port = 5002 }, matched: true, refetch: true, reads: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			d := &encoderDevice{current: encoderConfig(), delay: test.delay, ignore: test.ignore, normalize: test.normalize}
+			srv := httptest.NewServer(d)
+			defer srv.Close()
+
+			clock := new(testClock)
+			c, err := media.NewClient(&onvif.Client{Clock: clock}, onvif.Services{{Namespace: onvif.NamespaceMedia, URL: srv.URL}})
+			if err != nil {
+				t.Fatalf("could not create client: %v", err)
+			}
+
+			config := encoderConfig()
+			test.change(config)
+			change, err := c.ApplyVideoEncoderConfiguration(context.Background(), config)
+			if err != nil {
+				t.Fatalf("could not apply configuration: %v", err)
+			}
+
+			if change.Matched != test.matched {
+				t.Errorf("expected matched %v, got %v", test.matched, change.Matched)
+			}
+			if change.RefetchStreamURIs != test.refetch {
+				t.Errorf("expected refetch %v, got %v", test.refetch, change.RefetchStreamURIs)
+			}
+			if change.Previous == nil || change.Previous.Quality != 5 {
+				t.Errorf("unexpected previous configuration: %#v", change.Previous)
+			}
+			if d.reads != test.reads {
+				t.Errorf("expected %d reads, got %d", test.reads, d.reads)
+			}
+			if clock.waits != test.reads-1 {
+				t.Errorf("expected %d waits, got %d", test.reads-1, clock.waits)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package media
+
+import (
+	"context"
+	"errors"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// StreamInfo describes the video stream of a profile. See Client.StreamMatrix
+type StreamInfo struct {
+	// Profile is the media profile. For Media2 profiles, only its token, name, and video encoder configuration are set
+	Profile *Profile
+	// Media2 is true if the profile is only reported by the Media2 service, e.g. an H265 profile
+	Media2 bool
+	// Encoding is JPEG, MPEG4, or H264 (or a Media2 encoding, e.g. H265), or empty if the profile has no video encoder
+	Encoding   string
+	Resolution *VideoResolution
+	// FrameRate is the frame rate limit in frames per second, or zero if unknown
+	FrameRate int
+	// Bitrate is the bitrate limit in kbps, or zero if unknown
+	Bitrate int
+	// StreamURI is the profile's unicast RTSP stream URI, or empty if the device doesn't return one
+	StreamURI string
+	// Snapshot is true if the device supports snapshots for the profile
+	Snapshot bool
+	// Multicast is true if the device supports RTP multicast and the profile's encoder has a multicast configuration
+	Multicast bool
+	// Options are the valid video encoder settings for the profile, or nil if the device doesn't return them. They aren't queried for Media2 profiles
+	Options *VideoEncoderConfigurationOptions
+}
+
+// StreamMatrix returns a StreamInfo for each of the device's profiles, combining the profiles, stream URIs,
+// video encoder options, and media service capabilities. If the device supports the Media2 service, profiles only it reports are included.
+// Devices that don't support GetServiceCapabilities are checked for snapshot support with GetSnapshotUri.
+// Profiles whose stream URI can't be returned (i.e. GetStreamUri faults) are included without one
+func (c *Client) StreamMatrix(ctx context.Context) ([]*StreamInfo, error) {
+	profiles, err := c.GetProfilesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// caps is nil if the device doesn't support GetServiceCapabilities
+	caps, err := c.GetServiceCapabilitiesContext(ctx)
+	if err != nil && !isFault(err) {
+		return nil, err
+	}
+
+	infos := make([]*StreamInfo, 0, len(profiles))
+	tokens := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		tokens[p.Token] = true
+		info, err := c.streamInfo(ctx, p, false, caps)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	if c.media2 == nil {
+		return infos, nil
+	}
+
+	profiles2, err := c.getProfiles2(ctx)
+	if err != nil && !isFault(err) {
+		return nil, err
+	}
+	for _, p := range profiles2 {
+		if tokens[p.Token] {
+			continue
+		}
+		info, err := c.streamInfo(ctx, p.profile(), true, caps)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// streamInfo returns the StreamInfo for p. media2 is true if p is only reported by the Media2 service. caps may be nil
+func (c *Client) streamInfo(ctx context.Context, p *Profile, media2 bool, caps *Capabilities) (*StreamInfo, error) {
+	info := &StreamInfo{Profile: p, Media2: media2}
+	if v := p.VideoEncoderConfiguration; v != nil {
+		info.Encoding = v.Encoding
+		info.Resolution = v.Resolution
+		if v.RateControl != nil {
+			info.FrameRate = v.RateControl.FrameRateLimit
+			info.Bitrate = v.RateControl.BitrateLimit
+		}
+		info.Multicast = v.Multicast != nil && caps != nil &&
+			caps.StreamingCapabilities != nil && caps.StreamingCapabilities.RTPMulticast
+
+		if !media2 {
+			opts, err := c.GetVideoEncoderConfigurationOptionsContext(ctx, v.Token, p.Token)
+			if err != nil && !isFault(err) {
+				return nil, err
+			}
+			info.Options = opts
+		}
+	}
+
+	var err error
+	if media2 {
+		resp := new(GetStreamUri2Response)
+		err = c.media2.CallContext(ctx, &GetStreamUri2{Protocol: StreamProtocolRTSPUnicast, ProfileToken: p.Token}, resp)
+		info.StreamURI = resp.URI
+	} else {
+		var uri *MediaURI
+		if uri, err = c.GetStreamUriContext(ctx, p.Token, nil); uri != nil {
+			info.StreamURI = uri.URI
+		}
+	}
+	if err != nil && !isFault(err) {
+		return nil, err
+	}
+
+	if caps != nil {
+		info.Snapshot = caps.SnapshotUri
+		return info, nil
+	}
+
+	if media2 {
+		err = c.media2.CallContext(ctx, &GetSnapshotUri2{ProfileToken: p.Token}, new(GetSnapshotUri2Response))
+	} else {
+		_, err = c.GetSnapshotUriContext(ctx, p.Token)
+	}
+	if err != nil && !isFault(err) {
+		return nil, err
+	}
+	info.Snapshot = err == nil
+
+	return info, nil
+}
+
+// isFault returns true if err is a SOAP fault, i.e. the device doesn't support the operation or arguments
+func isFault(err error) bool {
+	var f *soap.Fault
+	return errors.As(err, &f)
+}
@@ -0,0 +1,238 @@
+package media_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+const matrixEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tt="http://www.onvif.org/ver10/schema"
+xmlns:trt="http://www.onvif.org/ver10/media/wsdl" xmlns:tr2="http://www.onvif.org/ver20/media/wsdl" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+const matrixFault = `<env:Fault>
+<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:InvalidArgVal</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">Not supported</env:Text></env:Reason>
+</env:Fault>`
+
+var operationRegexp = regexp.MustCompile(`^\s*<\w+:(\w+)[\s>/]`)
+
+// matrixDevice serves the responses for the Media (/media) and Media2 (/media2) services, keyed by path and operation, e.g. "/media GetProfiles".
+// If a key includes a profile token (e.g. "/media GetStreamUri Profile_1"), it's used for requests for that profile.
+// Other requests are sent faults
+type matrixDevice map[string]string
+
+func (d matrixDevice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	env := new(soap.Envelope)
+	if err := xml.NewDecoder(r.Body).Decode(env); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m := operationRegexp.FindSubmatch(env.Body.InnerXML)
+	if m == nil {
+		http.Error(w, "no operation", http.StatusBadRequest)
+		return
+	}
+	req := new(struct {
+		ProfileToken string
+	})
+	if err := env.Body.Unmarshal(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key := r.URL.Path + " " + string(m[1])
+	body, ok := d[key+" "+req.ProfileToken]
+	if !ok {
+		body, ok = d[key]
+	}
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, matrixEnvelope, matrixFault)
+		return
+	}
+	fmt.Fprintf(w, matrixEnvelope, body)
+}
+
+const (
+	matrixProfiles = `<trt:GetProfilesResponse>
+<trt:Profiles token="Profile_1" fixed="true"><tt:Name>MainStream</tt:Name>
+<tt:VideoEncoderConfiguration token="VideoEncoder_1"><tt:Name>VideoEncoder_1</tt:Name><tt:UseCount>1</tt:UseCount><tt:Encoding>H264</tt:Encoding>
+<tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution><tt:Quality>5</tt:Quality>
+<tt:RateControl><tt:FrameRateLimit>30</tt:FrameRateLimit><tt:EncodingInterval>1</tt:EncodingInterval><tt:BitrateLimit>4096</tt:BitrateLimit></tt:RateControl>
+<tt:Multicast><tt:Address><tt:Type>IPv4</tt:Type><tt:IPv4Address>239.0.0.1</tt:IPv4Address></tt:Address><tt:Port>5000</tt:Port><tt:TTL>1</tt:TTL><tt:AutoStart>false</tt:AutoStart></tt:Multicast>
+</tt:VideoEncoderConfiguration></trt:Profiles>
+<trt:Profiles token="Profile_2" fixed="true"><tt:Name>SubStream</tt:Name>
+<tt:VideoEncoderConfiguration token="VideoEncoder_2"><tt:Name>VideoEncoder_2</tt:Name><tt:UseCount>1</tt:UseCount><tt:Encoding>JPEG</tt:Encoding>
+<tt:Resolution><tt:Width>640</tt:Width><tt:Height>480</tt:Height></tt:Resolution><tt:Quality>3</tt:Quality></tt:VideoEncoderConfiguration></trt:Profiles>
+</trt:GetProfilesResponse>`
+	matrixProfiles2 = `<tr2:GetProfilesResponse>
+<tr2:Profiles token="Profile_1" fixed="true"><tr2:Name>MainStream</tr2:Name><tr2:Configurations>
+<tr2:VideoEncoder token="VideoEncoder_1"><tt:Name>VideoEncoder_1</tt:Name><tt:UseCount>1</tt:UseCount><tt:Encoding>H264</tt:Encoding>
+<tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution></tr2:VideoEncoder></tr2:Configurations></tr2:Profiles>
+<tr2:Profiles token="Profile_3" fixed="false"><tr2:Name>H265Stream</tr2:Name><tr2:Configurations>
+<tr2:VideoEncoder token="VideoEncoder_3"><tt:Name>VideoEncoder_3</tt:Name><tt:UseCount>1</tt:UseCount><tt:Encoding>H265</tt:Encoding>
+<tt:Resolution><tt:Width>2560</tt:Width><tt:Height>1440</tt:Height></tt:Resolution>
+<tt:RateControl ConstantBitRate="true"><tt:FrameRateLimit>25.0</tt:FrameRateLimit><tt:BitrateLimit>6144</tt:BitrateLimit></tt:RateControl>
+<tt:Quality>4</tt:Quality></tr2:VideoEncoder></tr2:Configurations></tr2:Profiles>
+</tr2:GetProfilesResponse>`
+	matrixCapabilities = `<trt:GetServiceCapabilitiesResponse><trt:Capabilities SnapshotUri="true">
+<trt:StreamingCapabilities RTPMulticast="true"/></trt:Capabilities></trt:GetServiceCapabilitiesResponse>`
+	matrixOptions = `<trt:GetVideoEncoderConfigurationOptionsResponse><trt:Options>
+<tt:QualityRange><tt:Min>1</tt:Min><tt:Max>10</tt:Max></tt:QualityRange></trt:Options></trt:GetVideoEncoderConfigurationOptionsResponse>`
+)
+
+// streamRow is the expected StreamInfo for a profile
+type streamRow struct {
+	token     string
+	media2    bool
+	encoding  string
+	width     int
+	frameRate int
+	bitrate   int
+	streamURI string
+	snapshot  bool
+	multicast bool
+	options   bool
+}
+
+func TestStreamMatrix(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		media2    bool
+		responses matrixDevice
+		rows      []streamRow
+	}{
+		{
+			name: "media",
+			responses: matrixDevice{
+				"/media GetProfiles":                                   matrixProfiles,
+				"/media GetServiceCapabilities":                        matrixCapabilities,
+				"/media GetVideoEncoderConfigurationOptions Profile_1": matrixOptions,
+				"/media GetStreamUri Profile_1":                        `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://camera/1</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`,
+			},
+			rows: []streamRow{
+				{token: "Profile_1", encoding: "H264", width: 1920, frameRate: 30, bitrate: 4096, streamURI: "rtsp://camera/1", snapshot: true, multicast: true, options: true},
+				// GetStreamUri and GetVideoEncoderConfigurationOptions fail
+				{token: "Profile_2", encoding: "JPEG", width: 640, snapshot: true},
+			},
+		},
+		{
+			name:   "mixed",
+			media2: true,
+			responses: matrixDevice{
+				"/media GetProfiles": matrixProfiles,
+				"/media GetVideoEncoderConfigurationOptions Profile_1": matrixOptions,
+				"/media GetStreamUri Profile_1":                        `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://camera/1</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`,
+				"/media GetStreamUri Profile_2":                        `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://camera/2</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`,
+				"/media GetSnapshotUri Profile_1":                      `<trt:GetSnapshotUriResponse><trt:MediaUri><tt:Uri>http://camera/1.jpg</tt:Uri></trt:MediaUri></trt:GetSnapshotUriResponse>`,
+				"/media2 GetProfiles":                                  matrixProfiles2,
+				"/media2 GetStreamUri Profile_3":                       `<tr2:GetStreamUriResponse><tr2:Uri>rtsp://camera/3</tr2:Uri></tr2:GetStreamUriResponse>`,
+				"/media2 GetSnapshotUri Profile_3":                     `<tr2:GetSnapshotUriResponse><tr2:Uri>http://camera/3.jpg</tr2:Uri></tr2:GetSnapshotUriResponse>`,
+			},
+			rows: []streamRow{
+				// without capabilities, snapshots and multicast aren't known to be supported unless GetSnapshotUri succeeds
+				{token: "Profile_1", encoding: "H264", width: 1920, frameRate: 30, bitrate: 4096, streamURI: "rtsp://camera/1", snapshot: true, options: true},
+				{token: "Profile_2", encoding: "JPEG", width: 640, streamURI: "rtsp://camera/2"},
+				{token: "Profile_3", media2: true, encoding: "H265", width: 2560, frameRate: 25, bitrate: 6144, streamURI: "rtsp://camera/3", snapshot: true},
+			},
+		},
+		{
+			name:   "media2 fault",
+			media2: true,
+			responses: matrixDevice{
+				"/media GetProfiles":            matrixProfiles,
+				"/media GetServiceCapabilities": matrixCapabilities,
+			},
+			rows: []streamRow{
+				{token: "Profile_1", encoding: "H264", width: 1920, frameRate: 30, bitrate: 4096, snapshot: true, multicast: true},
+				{token: "Profile_2", encoding: "JPEG", width: 640, snapshot: true},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(test.responses)
+			defer srv.Close()
+
+			services := onvif.Services{{Namespace: onvif.NamespaceMedia, URL: srv.URL + "/media"}}
+			if test.media2 {
+				services = append(services, &onvif.Service{Namespace: onvif.NamespaceMedia2, URL: srv.URL + "/media2"})
+			}
+			c, err := media.NewClient(&onvif.Client{}, services)
+			if err != nil {
+				t.Fatalf("could not create client: %v", err)
+			}
+
+			infos, err := c.StreamMatrix(context.Background())
+			if err != nil {
+				t.Fatalf("could not get stream matrix: %v", err)
+			}
+			if len(infos) != len(test.rows) {
+				t.Fatalf("expected %d profiles, got %d", len(test.rows), len(infos))
+			}
+
+			for i, info := range infos {
+				row := streamRow{
+					token: info.Profile.Token, media2: info.Media2, encoding: info.Encoding, frameRate: info.FrameRate, bitrate: info.Bitrate,
+					streamURI: info.StreamURI, snapshot: info.Snapshot, multicast: info.Multicast, options: info.Options != nil,
+				}
+				if info.Resolution != nil {
+					row.width = info.Resolution.Width
+				}
+				if row != test.rows[i] {
+					t.Errorf("profile %d: expected %+v, got %+v", i, test.rows[i], row)
+				}
+			}
+		})
+	}
+}
+
+func TestChannels(t *testing.T) {
+	profile := func(token, source string) string {
+		return fmt.Sprintf(`<trt:Profiles token="%s"><tt:Name>%[1]s</tt:Name>
+<tt:VideoSourceConfiguration token="vsc_%[2]s"><tt:SourceToken>%[2]s</tt:SourceToken></tt:VideoSourceConfiguration></trt:Profiles>`, token, source)
+	}
+	srv := httptest.NewServer(matrixDevice{
+		"/media GetProfiles": `<trt:GetProfilesResponse>` + profile("ch2_main", "source_2") + profile("ch1_main", "source_1") +
+			profile("ch1_sub", "source_1") + profile("extra", "source_3") + `<trt:Profiles token="audio"><tt:Name>audio</tt:Name></trt:Profiles></trt:GetProfilesResponse>`,
+		"/media GetVideoSources": `<trt:GetVideoSourcesResponse><trt:VideoSources token="source_1"><tt:Framerate>30</tt:Framerate></trt:VideoSources>
+<trt:VideoSources token="source_2"><tt:Framerate>25</tt:Framerate></trt:VideoSources></trt:GetVideoSourcesResponse>`,
+		"/media GetStreamUri ch1_main": `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://nvr/1/main</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`,
+		"/media GetStreamUri ch1_sub":  `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://nvr/1/sub</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`,
+		"/media GetStreamUri ch2_main": `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://nvr/2/main</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`,
+	})
+	defer srv.Close()
+
+	c, err := media.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceMedia, URL: srv.URL + "/media"}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	channels, err := c.Channels(context.Background())
+	if err != nil {
+		t.Fatalf("could not get channels: %v", err)
+	}
+
+	var out []string
+	for _, ch := range channels {
+		s := fmt.Sprintf("%d %s %v", ch.Index, ch.VideoSourceToken, ch.VideoSource != nil)
+		for i, p := range ch.Profiles {
+			s += fmt.Sprintf(" %s=%s", p.Token, ch.StreamURIs[i])
+		}
+		out = append(out, s)
+	}
+	expected := "[0 source_1 true ch1_main=rtsp://nvr/1/main ch1_sub=rtsp://nvr/1/sub 1 source_2 true ch2_main=rtsp://nvr/2/main 2 source_3 false extra=]"
+	if s := fmt.Sprint(out); s != expected {
+		t.Errorf("expected channels:\n%s\ngot:\n%s", expected, s)
+	}
+}
@@ -0,0 +1,35 @@
+// Package media implements typed operations for the ONVIF media (ver10) service, and the Media2 (ver20) operations used by Client.StreamMatrix
+package media
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF media service client
+type Client struct {
+	*onvif.ServiceClient
+	// media2 is the Media2 (ver20) service client, or nil if the device doesn't support it. See StreamMatrix
+	media2 *onvif.ServiceClient
+	// uris is the stream URI cache. See CachedStreamUri
+	uris uriCache
+}
+
+// NewClient returns a new media service client using c to make requests to the media service URL in services.
+// If services includes the Media2 service, it's used by StreamMatrix for profiles only the Media2 service reports
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceMedia, soap.Namespaces{"trt": onvif.NamespaceMedia, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{ServiceClient: s}
+	if s2, err := onvif.NewServiceClient(c, services, onvif.NamespaceMedia2, soap.Namespaces{"tr2": onvif.NamespaceMedia2, "tt": onvif.NamespaceONVIF}); err == nil {
+		client.media2 = s2
+	}
+	return client, nil
+}
+
+// FromDevice returns a new media service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
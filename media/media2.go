@@ -0,0 +1,102 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Media2 (ver20) profile types. Only the parts used by Client.StreamMatrix are included
+
+// Media2 stream protocols
+const (
+	StreamProtocolRTSPUnicast   = "RtspUnicast"
+	StreamProtocolRTSPMulticast = "RtspMulticast"
+)
+
+// GetProfiles2 is an ONVIF Media2 GetProfiles operation
+type GetProfiles2 struct {
+	XMLName xml.Name `xml:"tr2:GetProfiles"`
+	// Type is the configuration types to include, e.g. VideoEncoder or All. If empty, no configurations are included
+	Type []string `xml:"tr2:Type,omitempty"`
+}
+
+// GetProfiles2Response is an ONVIF Media2 GetProfilesResponse response
+type GetProfiles2Response struct {
+	Profiles []*Profile2
+}
+
+// Profile2 is an ONVIF Media2 MediaProfile. Only the video encoder configuration is included
+type Profile2 struct {
+	Token        string `xml:"token,attr"`
+	Fixed        bool   `xml:"fixed,attr"`
+	Name         string
+	VideoEncoder *VideoEncoder2Configuration `xml:"Configurations>VideoEncoder"`
+}
+
+// VideoEncoder2Configuration is an ONVIF VideoEncoder2Configuration type
+type VideoEncoder2Configuration struct {
+	Token    string `xml:"token,attr"`
+	Name     string
+	UseCount int
+	// Encoding is a media subtype, e.g. JPEG, MPV4-ES, H264, or H265
+	Encoding    string
+	Resolution  *VideoResolution
+	RateControl *VideoRateControl2
+	Multicast   *MulticastConfiguration
+	Quality     float64
+}
+
+// VideoRateControl2 is an ONVIF VideoRateControl2 type
+type VideoRateControl2 struct {
+	ConstantBitRate bool `xml:"ConstantBitRate,attr"`
+	FrameRateLimit  float64
+	BitrateLimit    int
+}
+
+// GetStreamUri2 is an ONVIF Media2 GetStreamUri operation
+type GetStreamUri2 struct {
+	XMLName xml.Name `xml:"tr2:GetStreamUri"`
+	// Protocol is e.g. StreamProtocolRTSPUnicast
+	Protocol     string `xml:"tr2:Protocol"`
+	ProfileToken string `xml:"tr2:ProfileToken"`
+}
+
+// GetStreamUri2Response is an ONVIF Media2 GetStreamUriResponse response
+type GetStreamUri2Response struct {
+	URI string `xml:"Uri"`
+}
+
+// GetSnapshotUri2 is an ONVIF Media2 GetSnapshotUri operation
+type GetSnapshotUri2 struct {
+	XMLName      xml.Name `xml:"tr2:GetSnapshotUri"`
+	ProfileToken string   `xml:"tr2:ProfileToken"`
+}
+
+// GetSnapshotUri2Response is an ONVIF Media2 GetSnapshotUriResponse response
+type GetSnapshotUri2Response struct {
+	URI string `xml:"Uri"`
+}
+
+// profile converts p to a Media (ver10) Profile with its token, name, and video encoder configuration
+func (p *Profile2) profile() *Profile {
+	profile := &Profile{Token: p.Token, Fixed: p.Fixed, Name: p.Name}
+	if v := p.VideoEncoder; v != nil {
+		profile.VideoEncoderConfiguration = &VideoEncoderConfiguration{
+			Token: v.Token, Name: v.Name, UseCount: v.UseCount, Encoding: v.Encoding,
+			Resolution: v.Resolution, Quality: v.Quality, Multicast: v.Multicast,
+		}
+		if r := v.RateControl; r != nil {
+			profile.VideoEncoderConfiguration.RateControl = &VideoRateControl{FrameRateLimit: int(r.FrameRateLimit), BitrateLimit: r.BitrateLimit}
+		}
+	}
+	return profile
+}
+
+// getProfiles2 returns the Media2 service's profiles with their video encoder configurations
+func (c *Client) getProfiles2(ctx context.Context) ([]*Profile2, error) {
+	resp := new(GetProfiles2Response)
+	if err := c.media2.CallContext(ctx, &GetProfiles2{Type: []string{"VideoEncoder"}}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Profiles, nil
+}
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// IntRange is an ONVIF IntRange type
+type IntRange struct {
+	Min int
+	Max int
+}
+
+// JpegOptions is an ONVIF JpegOptions type
+type JpegOptions struct {
+	ResolutionsAvailable  []*VideoResolution
+	FrameRateRange        *IntRange
+	EncodingIntervalRange *IntRange
+}
+
+// Mpeg4Options is an ONVIF Mpeg4Options type
+type Mpeg4Options struct {
+	ResolutionsAvailable   []*VideoResolution
+	GovLengthRange         *IntRange
+	FrameRateRange         *IntRange
+	EncodingIntervalRange  *IntRange
+	Mpeg4ProfilesSupported []string
+}
+
+// H264Options is an ONVIF H264Options type
+type H264Options struct {
+	ResolutionsAvailable  []*VideoResolution
+	GovLengthRange        *IntRange
+	FrameRateRange        *IntRange
+	EncodingIntervalRange *IntRange
+	H264ProfilesSupported []string
+}
+
+// VideoEncoderConfigurationOptions is an ONVIF VideoEncoderConfigurationOptions type.
+// Options for unsupported encodings are nil
+type VideoEncoderConfigurationOptions struct {
+	QualityRange *IntRange
+	JPEG         *JpegOptions
+	MPEG4        *Mpeg4Options
+	H264         *H264Options
+	// JPEGBitrateRange, MPEG4BitrateRange, and H264BitrateRange are only returned by some devices
+	JPEGBitrateRange  *IntRange `xml:"Extension>JPEG>BitrateRange"`
+	MPEG4BitrateRange *IntRange `xml:"Extension>MPEG4>BitrateRange"`
+	H264BitrateRange  *IntRange `xml:"Extension>H264>BitrateRange"`
+}
+
+// GetVideoEncoderConfigurationOptions is an ONVIF GetVideoEncoderConfigurationOptions operation
+type GetVideoEncoderConfigurationOptions struct {
+	XMLName            xml.Name `xml:"trt:GetVideoEncoderConfigurationOptions"`
+	ConfigurationToken string   `xml:"trt:ConfigurationToken,omitempty"`
+	ProfileToken       string   `xml:"trt:ProfileToken,omitempty"`
+}
+
+// GetVideoEncoderConfigurationOptionsResponse is an ONVIF GetVideoEncoderConfigurationOptionsResponse response
+type GetVideoEncoderConfigurationOptionsResponse struct {
+	Options *VideoEncoderConfigurationOptions
+}
+
+// GetVideoEncoderConfigurationOptions returns the valid settings for the video encoder configuration and profile with the given tokens.
+// Either token may be empty
+func (c *Client) GetVideoEncoderConfigurationOptions(configurationToken, profileToken string) (*VideoEncoderConfigurationOptions, error) {
+	return c.GetVideoEncoderConfigurationOptionsContext(context.Background(), configurationToken, profileToken)
+}
+
+// GetVideoEncoderConfigurationOptionsContext is like GetVideoEncoderConfigurationOptions, but ctx controls the request
+func (c *Client) GetVideoEncoderConfigurationOptionsContext(ctx context.Context, configurationToken, profileToken string) (*VideoEncoderConfigurationOptions, error) {
+	resp := new(GetVideoEncoderConfigurationOptionsResponse)
+	if err := c.CallContext(ctx, &GetVideoEncoderConfigurationOptions{ConfigurationToken: configurationToken, ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Options, nil
+}
@@ -0,0 +1,127 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// OSD types
+const (
+	OSDTypeText  = "Text"
+	OSDTypeImage = "Image"
+)
+
+// OSD text types
+const (
+	OSDTextPlain       = "Plain"
+	OSDTextDate        = "Date"
+	OSDTextTime        = "Time"
+	OSDTextDateAndTime = "DateAndTime"
+)
+
+// OSDVector is the position of an OSD with the Custom position type, in normalized coordinates
+type OSDVector struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+}
+
+// OSDPosConfiguration is an ONVIF OSDPosConfiguration type
+type OSDPosConfiguration struct {
+	// Type is UpperLeft, UpperRight, LowerLeft, LowerRight, or Custom
+	Type string
+	Pos  *OSDVector `xml:",omitempty"`
+}
+
+// Color is an ONVIF Color type
+type Color struct {
+	X          float64 `xml:"X,attr"`
+	Y          float64 `xml:"Y,attr"`
+	Z          float64 `xml:"Z,attr"`
+	Colorspace string  `xml:"Colorspace,attr,omitempty"`
+}
+
+// OSDColor is an ONVIF OSDColor type
+type OSDColor struct {
+	Transparent *int `xml:"Transparent,attr"`
+	Color       *Color
+}
+
+// OSDTextConfiguration is an ONVIF OSDTextConfiguration type
+type OSDTextConfiguration struct {
+	IsPersistentText *bool `xml:"IsPersistentText,attr"`
+	// Type is one of the OSD text types, e.g. OSDTextPlain
+	Type string
+	// DateFormat and TimeFormat are the formats of Date and Time texts, e.g. yyyy-MM-dd and HH:mm:ss
+	DateFormat      string    `xml:",omitempty"`
+	TimeFormat      string    `xml:",omitempty"`
+	FontSize        *int      `xml:",omitempty"`
+	FontColor       *OSDColor `xml:",omitempty"`
+	BackgroundColor *OSDColor `xml:",omitempty"`
+	PlainText       string    `xml:",omitempty"`
+}
+
+// OSDImgConfiguration is an ONVIF OSDImgConfiguration type
+type OSDImgConfiguration struct {
+	ImgPath string
+}
+
+// OSDConfiguration is an ONVIF OSDConfiguration type
+type OSDConfiguration struct {
+	Token                         string `xml:"token,attr"`
+	VideoSourceConfigurationToken string
+	// Type is OSDTypeText or OSDTypeImage
+	Type       string
+	Position   *OSDPosConfiguration
+	TextString *OSDTextConfiguration `xml:",omitempty"`
+	Image      *OSDImgConfiguration  `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (o *OSDConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type osd OSDConfiguration
+	return soap.EncodeElementPrefixed(enc, (*osd)(o), start, "tt")
+}
+
+// GetOSDs is an ONVIF GetOSDs operation
+type GetOSDs struct {
+	XMLName            xml.Name `xml:"trt:GetOSDs"`
+	ConfigurationToken string   `xml:"trt:ConfigurationToken,omitempty"`
+}
+
+// GetOSDsResponse is an ONVIF GetOSDsResponse response
+type GetOSDsResponse struct {
+	OSDs []*OSDConfiguration
+}
+
+// GetOSDs returns the OSDs of the video source configuration with the given token, or all OSDs if configurationToken is empty.
+// Check Capabilities.OSD before using OSD operations
+func (c *Client) GetOSDs(configurationToken string) ([]*OSDConfiguration, error) {
+	return c.GetOSDsContext(context.Background(), configurationToken)
+}
+
+// GetOSDsContext is like GetOSDs, but ctx controls the request
+func (c *Client) GetOSDsContext(ctx context.Context, configurationToken string) ([]*OSDConfiguration, error) {
+	resp := new(GetOSDsResponse)
+	if err := c.CallContext(ctx, &GetOSDs{ConfigurationToken: configurationToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.OSDs, nil
+}
+
+// SetOSD is an ONVIF SetOSD operation
+type SetOSD struct {
+	XMLName xml.Name          `xml:"trt:SetOSD"`
+	OSD     *OSDConfiguration `xml:"trt:OSD"`
+}
+
+// SetOSD changes the OSD with osd's token
+func (c *Client) SetOSD(osd *OSDConfiguration) error {
+	return c.SetOSDContext(context.Background(), osd)
+}
+
+// SetOSDContext is like SetOSD, but ctx controls the request
+func (c *Client) SetOSDContext(ctx context.Context, osd *OSDConfiguration) error {
+	return c.CallContext(ctx, &SetOSD{OSD: osd}, nil)
+}
@@ -0,0 +1,91 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetProfiles is an ONVIF GetProfiles operation
+type GetProfiles struct {
+	XMLName xml.Name `xml:"trt:GetProfiles"`
+}
+
+// GetProfilesResponse is an ONVIF GetProfilesResponse response
+type GetProfilesResponse struct {
+	Profiles []*Profile
+}
+
+// GetProfiles returns the device's media profiles
+func (c *Client) GetProfiles() ([]*Profile, error) {
+	return c.GetProfilesContext(context.Background())
+}
+
+// GetProfilesContext is like GetProfiles, but ctx controls the request
+func (c *Client) GetProfilesContext(ctx context.Context) ([]*Profile, error) {
+	resp := new(GetProfilesResponse)
+	if err := c.CallContext(ctx, &GetProfiles{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Profiles, nil
+}
+
+// GetVideoEncoderConfigurations is an ONVIF GetVideoEncoderConfigurations operation
+type GetVideoEncoderConfigurations struct {
+	XMLName xml.Name `xml:"trt:GetVideoEncoderConfigurations"`
+}
+
+// GetVideoEncoderConfigurationsResponse is an ONVIF GetVideoEncoderConfigurationsResponse response
+type GetVideoEncoderConfigurationsResponse struct {
+	Configurations []*VideoEncoderConfiguration
+}
+
+// GetVideoEncoderConfigurations returns all of the device's video encoder configurations
+func (c *Client) GetVideoEncoderConfigurations() ([]*VideoEncoderConfiguration, error) {
+	return c.GetVideoEncoderConfigurationsContext(context.Background())
+}
+
+// GetVideoEncoderConfigurationsContext is like GetVideoEncoderConfigurations, but ctx controls the request
+func (c *Client) GetVideoEncoderConfigurationsContext(ctx context.Context) ([]*VideoEncoderConfiguration, error) {
+	resp := new(GetVideoEncoderConfigurationsResponse)
+	if err := c.CallContext(ctx, &GetVideoEncoderConfigurations{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Configurations, nil
+}
+
+// AddVideoEncoderConfiguration is an ONVIF AddVideoEncoderConfiguration operation
+type AddVideoEncoderConfiguration struct {
+	XMLName            xml.Name `xml:"trt:AddVideoEncoderConfiguration"`
+	ProfileToken       string   `xml:"trt:ProfileToken"`
+	ConfigurationToken string   `xml:"trt:ConfigurationToken"`
+}
+
+// AddVideoEncoderConfiguration makes the profile with the given token use the video encoder configuration with the given token,
+// replacing its current one. Cached stream URIs for the profile are invalidated
+func (c *Client) AddVideoEncoderConfiguration(profileToken, configurationToken string) error {
+	return c.AddVideoEncoderConfigurationContext(context.Background(), profileToken, configurationToken)
+}
+
+// AddVideoEncoderConfigurationContext is like AddVideoEncoderConfiguration, but ctx controls the request
+func (c *Client) AddVideoEncoderConfigurationContext(ctx context.Context, profileToken, configurationToken string) error {
+	defer c.InvalidateStreamUris(profileToken)
+	return c.CallContext(ctx, &AddVideoEncoderConfiguration{ProfileToken: profileToken, ConfigurationToken: configurationToken}, nil)
+}
+
+// AddMetadataConfiguration is an ONVIF AddMetadataConfiguration operation
+type AddMetadataConfiguration struct {
+	XMLName            xml.Name `xml:"trt:AddMetadataConfiguration"`
+	ProfileToken       string   `xml:"trt:ProfileToken"`
+	ConfigurationToken string   `xml:"trt:ConfigurationToken"`
+}
+
+// AddMetadataConfiguration makes the profile with the given token use the metadata configuration with the given token,
+// replacing its current one. See GetCompatibleMetadataConfigurations
+func (c *Client) AddMetadataConfiguration(profileToken, configurationToken string) error {
+	return c.AddMetadataConfigurationContext(context.Background(), profileToken, configurationToken)
+}
+
+// AddMetadataConfigurationContext is like AddMetadataConfiguration, but ctx controls the request
+func (c *Client) AddMetadataConfigurationContext(ctx context.Context, profileToken, configurationToken string) error {
+	return c.CallContext(ctx, &AddMetadataConfiguration{ProfileToken: profileToken, ConfigurationToken: configurationToken}, nil)
+}
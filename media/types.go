@@ -0,0 +1,157 @@
+package media
+
+// IPAddress is an ONVIF IPAddress type
+type IPAddress struct {
+	Type        string
+	IPv4Address string
+	IPv6Address string
+}
+
+// MulticastConfiguration is an ONVIF MulticastConfiguration type
+type MulticastConfiguration struct {
+	Address   *IPAddress
+	Port      int
+	TTL       int
+	AutoStart bool
+}
+
+// PTZFilter is an ONVIF PTZFilter type
+type PTZFilter struct {
+	Status   bool
+	Position bool
+}
+
+// MetadataConfiguration is an ONVIF MetadataConfiguration type
+type MetadataConfiguration struct {
+	Token           string `xml:"token,attr"`
+	CompressionType string `xml:"CompressionType,attr"`
+	Name            string
+	UseCount        int
+	PTZStatus       *PTZFilter
+	Analytics       bool
+	Multicast       *MulticastConfiguration
+	// SessionTimeout is an xsd:duration, e.g. PT60S
+	SessionTimeout string
+}
+
+// AudioSourceConfiguration is an ONVIF AudioSourceConfiguration type
+type AudioSourceConfiguration struct {
+	Token       string `xml:"token,attr"`
+	Name        string
+	UseCount    int
+	SourceToken string
+}
+
+// AudioOutputConfiguration is an ONVIF AudioOutputConfiguration type
+type AudioOutputConfiguration struct {
+	Token       string `xml:"token,attr"`
+	Name        string
+	UseCount    int
+	OutputToken string
+	SendPrimacy string
+	OutputLevel int
+}
+
+// IntRectangle is an ONVIF IntRectangle type
+type IntRectangle struct {
+	X      int `xml:"x,attr"`
+	Y      int `xml:"y,attr"`
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+}
+
+// VideoSourceConfiguration is an ONVIF VideoSourceConfiguration type
+type VideoSourceConfiguration struct {
+	Token       string `xml:"token,attr"`
+	Name        string
+	UseCount    int
+	SourceToken string
+	Bounds      *IntRectangle
+}
+
+// VideoResolution is an ONVIF VideoResolution type
+type VideoResolution struct {
+	Width  int
+	Height int
+}
+
+// VideoRateControl is an ONVIF VideoRateControl type
+type VideoRateControl struct {
+	FrameRateLimit   int
+	EncodingInterval int
+	BitrateLimit     int
+}
+
+// H264Configuration is an ONVIF H264Configuration type
+type H264Configuration struct {
+	GovLength   int
+	H264Profile string
+}
+
+// Mpeg4Configuration is an ONVIF Mpeg4Configuration type
+type Mpeg4Configuration struct {
+	GovLength    int
+	Mpeg4Profile string
+}
+
+// VideoEncoderConfiguration is an ONVIF VideoEncoderConfiguration type
+type VideoEncoderConfiguration struct {
+	Token    string `xml:"token,attr"`
+	Name     string
+	UseCount int
+	// Encoding is JPEG, MPEG4, or H264
+	Encoding    string
+	Resolution  *VideoResolution
+	Quality     float64
+	RateControl *VideoRateControl
+	MPEG4       *Mpeg4Configuration
+	H264        *H264Configuration
+	Multicast   *MulticastConfiguration
+	// SessionTimeout is an xsd:duration, e.g. PT60S
+	SessionTimeout string
+}
+
+// AudioEncoderConfiguration is an ONVIF AudioEncoderConfiguration type
+type AudioEncoderConfiguration struct {
+	Token    string `xml:"token,attr"`
+	Name     string
+	UseCount int
+	// Encoding is G711, G726, or AAC
+	Encoding   string
+	Bitrate    int
+	SampleRate int
+	Multicast  *MulticastConfiguration
+	// SessionTimeout is an xsd:duration, e.g. PT60S
+	SessionTimeout string
+}
+
+// PTZConfiguration is an ONVIF PTZConfiguration type. Only the identifying fields are included
+type PTZConfiguration struct {
+	Token     string `xml:"token,attr"`
+	Name      string
+	UseCount  int
+	NodeToken string
+}
+
+// Profile is an ONVIF media Profile type
+type Profile struct {
+	Token                     string `xml:"token,attr"`
+	Fixed                     bool   `xml:"fixed,attr"`
+	Name                      string
+	VideoSourceConfiguration  *VideoSourceConfiguration
+	AudioSourceConfiguration  *AudioSourceConfiguration
+	VideoEncoderConfiguration *VideoEncoderConfiguration
+	AudioEncoderConfiguration *AudioEncoderConfiguration
+	PTZConfiguration          *PTZConfiguration
+	MetadataConfiguration     *MetadataConfiguration
+	AudioOutputConfiguration  *AudioOutputConfiguration `xml:"Extension>AudioOutputConfiguration"`
+}
+
+// MediaURI is an ONVIF MediaUri type
+type MediaURI struct {
+	URI                 string `xml:"Uri"`
+	InvalidAfterConnect bool
+	InvalidAfterReboot  bool
+	// Timeout is an xsd:duration, e.g. PT0S
+	Timeout string
+}
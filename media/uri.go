@@ -0,0 +1,88 @@
+package media
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// StreamType is an ONVIF StreamType
+type StreamType string
+
+// ONVIF stream types
+const (
+	StreamTypeUnicast   StreamType = "RTP-Unicast"
+	StreamTypeMulticast StreamType = "RTP-Multicast"
+)
+
+// TransportProtocol is an ONVIF TransportProtocol
+type TransportProtocol string
+
+// ONVIF transport protocols
+const (
+	TransportProtocolUDP  TransportProtocol = "UDP"
+	TransportProtocolTCP  TransportProtocol = "TCP"
+	TransportProtocolRTSP TransportProtocol = "RTSP"
+	TransportProtocolHTTP TransportProtocol = "HTTP"
+)
+
+// StreamSetup is an ONVIF StreamSetup type
+type StreamSetup struct {
+	Stream   StreamType        `xml:"tt:Stream"`
+	Protocol TransportProtocol `xml:"tt:Transport>tt:Protocol"`
+}
+
+// GetStreamUri is an ONVIF GetStreamUri operation
+type GetStreamUri struct {
+	XMLName      xml.Name     `xml:"trt:GetStreamUri"`
+	StreamSetup  *StreamSetup `xml:"trt:StreamSetup"`
+	ProfileToken string       `xml:"trt:ProfileToken"`
+}
+
+// GetStreamUriResponse is an ONVIF GetStreamUriResponse response
+type GetStreamUriResponse struct {
+	MediaURI *MediaURI `xml:"MediaUri"`
+}
+
+// GetStreamUri returns the stream URI for the profile with the given token.
+// If setup is nil, a unicast RTSP stream is requested
+func (c *Client) GetStreamUri(profileToken string, setup *StreamSetup) (*MediaURI, error) {
+	return c.GetStreamUriContext(context.Background(), profileToken, setup)
+}
+
+// GetStreamUriContext is like GetStreamUri, but ctx controls the request
+func (c *Client) GetStreamUriContext(ctx context.Context, profileToken string, setup *StreamSetup) (*MediaURI, error) {
+	if setup == nil {
+		setup = &StreamSetup{Stream: StreamTypeUnicast, Protocol: TransportProtocolRTSP}
+	}
+
+	resp := new(GetStreamUriResponse)
+	if err := c.CallContext(ctx, &GetStreamUri{StreamSetup: setup, ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.MediaURI, nil
+}
+
+// GetSnapshotUri is an ONVIF GetSnapshotUri operation
+type GetSnapshotUri struct {
+	XMLName      xml.Name `xml:"trt:GetSnapshotUri"`
+	ProfileToken string   `xml:"trt:ProfileToken"`
+}
+
+// GetSnapshotUriResponse is an ONVIF GetSnapshotUriResponse response
+type GetSnapshotUriResponse struct {
+	MediaURI *MediaURI `xml:"MediaUri"`
+}
+
+// GetSnapshotUri returns the JPEG snapshot URI for the profile with the given token. The snapshot can be fetched with onvif.Client.Download
+func (c *Client) GetSnapshotUri(profileToken string) (*MediaURI, error) {
+	return c.GetSnapshotUriContext(context.Background(), profileToken)
+}
+
+// GetSnapshotUriContext is like GetSnapshotUri, but ctx controls the request
+func (c *Client) GetSnapshotUriContext(ctx context.Context, profileToken string) (*MediaURI, error) {
+	resp := new(GetSnapshotUriResponse)
+	if err := c.CallContext(ctx, &GetSnapshotUri{ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.MediaURI, nil
+}
@@ -0,0 +1,54 @@
+package onvif
+
+import (
+	"context"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Doer executes ONVIF requests. Client implements Doer
+type Doer interface {
+	DoContext(ctx context.Context, r *Request) (*soap.Envelope, error)
+}
+
+// DoerFunc is a function that implements Doer
+type DoerFunc func(ctx context.Context, r *Request) (*soap.Envelope, error)
+
+// DoContext implements Doer
+func (f DoerFunc) DoContext(ctx context.Context, r *Request) (*soap.Envelope, error) {
+	return f(ctx, r)
+}
+
+// Middleware wraps a Doer, e.g. to record metrics, add tracing, or modify requests.
+// next executes a single attempt of the request, including authentication mode detection, so middleware sees each retry and hedged request.
+// Changes to the Request are seen by later attempts. Use Client.EnvelopeHook to modify the marshaled envelope
+type Middleware func(next Doer) Doer
+
+type correlationKey struct{}
+
+// CorrelationID returns the correlation ID of the request in middleware's ctx, or the empty string if ctx isn't a request context
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// Use appends m to c.Middleware. The first middleware added is the outermost. It must be called before the first request
+func (c *Client) Use(m ...Middleware) {
+	c.Middleware = append(c.Middleware, m...)
+}
+
+// send executes a single attempt of r through c.Middleware
+func (c *Client) send(ctx context.Context, r *Request, id string) (*soap.Envelope, error) {
+	if len(c.Middleware) == 0 {
+		return c.do(ctx, r, id)
+	}
+
+	var d Doer = DoerFunc(func(ctx context.Context, r *Request) (*soap.Envelope, error) {
+		return c.do(ctx, r, id)
+	})
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		d = c.Middleware[i](d)
+	}
+
+	return d.DoContext(context.WithValue(ctx, correlationKey{}, id), r)
+}
@@ -0,0 +1,90 @@
+package onvif
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var includeRegexp = regexp.MustCompile(`<(?:[\w-]+:)?Include\b[^>]*\bhref=["']cid:([^"']+)["'][^>]*?(?:/>|>\s*</(?:[\w-]+:)?Include>)`)
+
+// contentID returns the Content-ID header of a MIME part without its angle brackets
+func contentID(h string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(h), "<"), ">")
+}
+
+// decodeMTOM replaces resp.Body with the root XML part if the response is an MTOM (multipart/related) message.
+// Attachments referenced with xop:Include elements are inlined as base64 content, so binary payloads can be unmarshaled as base64 data
+func decodeMTOM(resp *http.Response) error {
+	typ, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.EqualFold(typ, "multipart/related") {
+		return nil
+	}
+
+	var (
+		root  []byte
+		parts = make(map[string][]byte)
+		start = contentID(params["start"])
+		r     = multipart.NewReader(resp.Body, params["boundary"])
+	)
+	for {
+		p, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("could not read MTOM part: %w", err)
+		}
+
+		buf, err := io.ReadAll(p)
+		if err != nil {
+			return fmt.Errorf("could not read MTOM part: %w", err)
+		}
+
+		id := contentID(p.Header.Get("Content-ID"))
+		if root == nil && (start == "" || id == start) {
+			root = buf
+			continue
+		}
+		parts[id] = buf
+	}
+
+	if root == nil {
+		return errors.New("could not find MTOM root part")
+	}
+
+	var missing error
+	root = includeRegexp.ReplaceAllFunc(root, func(include []byte) []byte {
+		id := string(includeRegexp.FindSubmatch(include)[1])
+		if unescaped, err := url.PathUnescape(id); err == nil {
+			id = unescaped
+		}
+		part, ok := parts[id]
+		if !ok {
+			missing = fmt.Errorf("could not find MTOM attachment %q", id)
+			return include
+		}
+		return []byte(base64.StdEncoding.EncodeToString(part))
+	})
+	if missing != nil {
+		return missing
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(root), resp.Body}
+	resp.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = int64(len(root))
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package onviftest
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/icholy/digest"
+)
+
+const realm = "onviftest"
+
+// newNonce returns a random digest nonce
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// challenge writes a digest authentication challenge
+func (s *Server) challenge(w http.ResponseWriter) {
+	chal := &digest.Challenge{Realm: realm, Nonce: s.nonce, QOP: []string{"auth"}}
+	w.Header().Set("WWW-Authenticate", chal.String())
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// checkDigest returns the username and true if r has valid digest credentials
+func (s *Server) checkDigest(r *http.Request) (string, bool) {
+	cred, err := digest.ParseCredentials(r.Header.Get("Authorization"))
+	if err != nil || cred.Username != s.Username || cred.Nonce != s.nonce {
+		return "", false
+	}
+
+	chal := &digest.Challenge{Realm: realm, Nonce: s.nonce, Algorithm: cred.Algorithm}
+	if cred.QOP != "" {
+		chal.QOP = []string{cred.QOP}
+	}
+	expected, err := digest.Digest(chal, digest.Options{
+		Method:   r.Method,
+		URI:      cred.URI,
+		Count:    cred.Nc,
+		Username: s.Username,
+		Password: s.Password,
+		Cnonce:   cred.Cnonce,
+	})
+	if err != nil || subtle.ConstantTimeCompare([]byte(expected.Response), []byte(cred.Response)) != 1 {
+		return "", false
+	}
+
+	return cred.Username, true
+}
+
+// checkWSSecurity returns the username and true if env has a valid WS-Security UsernameToken
+func (s *Server) checkWSSecurity(env *requestEnvelope) (string, bool) {
+	if env.Security == nil {
+		return "", false
+	}
+	token := env.Security.UsernameToken
+	if token.Username != s.Username {
+		return "", false
+	}
+
+	password := strings.TrimSpace(token.Password.Value)
+	if strings.HasSuffix(token.Password.Type, "#PasswordText") {
+		return token.Username, subtle.ConstantTimeCompare([]byte(password), []byte(s.Password)) == 1
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token.Nonce))
+	if err != nil {
+		return "", false
+	}
+	hash := sha1.New()
+	hash.Write(nonce)
+	hash.Write([]byte(strings.TrimSpace(token.Created)))
+	hash.Write([]byte(s.Password))
+	expected := base64.StdEncoding.EncodeToString(hash.Sum(nil))
+
+	return token.Username, subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1
+}
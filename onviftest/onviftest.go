@@ -0,0 +1,298 @@
+// Package onviftest implements a fake ONVIF device for testing ONVIF clients without real cameras.
+//
+// A Server serves canned device, media, and PTZ responses, enforces the configured authentication,
+// and can be configured to return custom responses or faults for any operation:
+//
+//	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+//	defer srv.Close()
+//	srv.Fail("GetProfiles", onviftest.Fault(soap.ErrNoProfile, "No such profile"))
+//
+//	c := &onvif.Client{Username: "admin", Password: "password"}
+//	dev, err := onvif.NewDevice(ctx, c, srv.URL)
+package onviftest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Service paths of a Server
+const (
+	PathDevice   = "/onvif/device_service"
+	PathMedia    = "/onvif/media_service"
+	PathPTZ      = "/onvif/ptz_service"
+	PathSnapshot = "/onvif/snapshot.jpg"
+)
+
+// Auth is the authentication a Server requires
+type Auth int
+
+// Server authentication modes
+const (
+	AuthNone Auth = iota
+	// AuthWSSecurity requires a WS-Security UsernameToken with a digest or plain text password
+	AuthWSSecurity
+	// AuthDigest requires HTTP digest authentication
+	AuthDigest
+)
+
+// preAuth is the operations that don't require authentication, as allowed by the ONVIF Core Specification
+var preAuth = map[string]bool{
+	"GetSystemDateAndTime": true,
+	"GetServices":          true,
+	"GetCapabilities":      true,
+	"GetWsdlUrl":           true,
+}
+
+// Request is an ONVIF request received by a Server
+type Request struct {
+	// Operation is the local name of the body element, e.g. GetProfiles, and Namespace is its namespace
+	Operation string
+	Namespace string
+	// Path is the URL path the request was sent to, e.g. PathMedia
+	Path string
+	// Username is the authenticated username, or the empty string if the request wasn't authenticated
+	Username string
+	Header   http.Header
+	// Body is the raw body element
+	Body []byte
+}
+
+// Decode unmarshals the body element into v. The element's namespace prefixes aren't resolved,
+// so v's field tags should be unprefixed, like response types
+func (r *Request) Decode(v interface{}) error {
+	if err := xml.Unmarshal(r.Body, v); err != nil {
+		return fmt.Errorf("could not unmarshal request: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the XML body contents of the response to r, e.g. <trt:GetProfilesResponse>...</trt:GetProfilesResponse>.
+// The response envelope declares the tt, tds, trt, tptz, and ter prefixes.
+// If a *soap.Fault (see Fault) is returned, it's sent as a fault. Other errors are sent as receiver faults
+type Handler func(r *Request) (string, error)
+
+// Server is a fake ONVIF device. It is safe for concurrent use
+type Server struct {
+	*httptest.Server
+	// Username, Password, and Auth are the credentials and authentication required for operations.
+	// They should be set before the first request
+	Username string
+	Password string
+	Auth     Auth
+	// Snapshot is served at PathSnapshot, which is returned by GetSnapshotUri
+	Snapshot []byte
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	requests []*Request
+	nonce    string
+}
+
+// NewServer returns a started Server requiring the credentials with auth. The caller should call Close when finished
+func NewServer(username, password string, auth Auth) *Server {
+	s := newServer(username, password, auth)
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewTLSServer is like NewServer, but the Server uses TLS. Use Server.Client to get an *http.Client that trusts it
+func NewTLSServer(username, password string, auth Auth) *Server {
+	s := newServer(username, password, auth)
+	s.Server = httptest.NewTLSServer(s)
+	return s
+}
+
+func newServer(username, password string, auth Auth) *Server {
+	s := &Server{Username: username, Password: password, Auth: auth, Snapshot: snapshotJPEG, handlers: make(map[string]Handler), nonce: newNonce()}
+	s.handleDefaults()
+	return s
+}
+
+// Handle sets the handler for operation, e.g. GetProfiles, replacing any existing handler
+func (s *Server) Handle(operation string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[operation] = h
+}
+
+// Respond sets a handler for operation that always returns body. See Handler
+func (s *Server) Respond(operation, body string) {
+	s.Handle(operation, func(*Request) (string, error) {
+		return body, nil
+	})
+}
+
+// Fail sets a handler for operation that always returns f. See Fault
+func (s *Server) Fail(operation string, f *soap.Fault) {
+	s.Handle(operation, func(*Request) (string, error) {
+		return "", f
+	})
+}
+
+// Requests returns the requests received so far, in order, including unauthenticated requests
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// Fault returns a sender fault with the given subcode (in the ter namespace) and reason, e.g. Fault(soap.ErrNoProfile, "No such profile")
+func Fault(code soap.ErrorCode, reason string) *soap.Fault {
+	return &soap.Fault{Code: "env:Sender", SubCode: "ter:" + string(code), Reason: reason}
+}
+
+// requestEnvelope is the parts of a request envelope a Server uses
+type requestEnvelope struct {
+	Security *struct {
+		UsernameToken struct {
+			Username string
+			Password struct {
+				Type  string `xml:",attr"`
+				Value string `xml:",chardata"`
+			}
+			Nonce   string
+			Created string
+		}
+	} `xml:"Header>Security"`
+	Body struct {
+		Element struct {
+			XMLName xml.Name
+		} `xml:",any"`
+		InnerXML []byte `xml:",innerxml"`
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == PathSnapshot {
+		s.serveSnapshot(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	buf, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "could not read request", http.StatusBadRequest)
+		return
+	}
+
+	env := new(requestEnvelope)
+	if err = xml.Unmarshal(buf, env); err != nil || env.Body.Element.XMLName.Local == "" {
+		s.writeFault(w, Fault(soap.ErrWellFormed, "Malformed request"))
+		return
+	}
+
+	req := &Request{
+		Operation: env.Body.Element.XMLName.Local,
+		Namespace: env.Body.Element.XMLName.Space,
+		Path:      r.URL.Path,
+		Header:    r.Header.Clone(),
+		Body:      bytes.TrimSpace(env.Body.InnerXML),
+	}
+
+	authorized := preAuth[req.Operation]
+	switch s.Auth {
+	case AuthNone:
+		authorized = true
+	case AuthDigest:
+		if username, ok := s.checkDigest(r); ok {
+			req.Username, authorized = username, true
+		} else if !authorized {
+			s.record(req)
+			s.challenge(w)
+			return
+		}
+	case AuthWSSecurity:
+		if username, ok := s.checkWSSecurity(env); ok {
+			req.Username, authorized = username, true
+		}
+	}
+	s.record(req)
+
+	if !authorized {
+		s.writeFault(w, Fault(soap.ErrNotAuthorized, "Sender not Authorized"))
+		return
+	}
+
+	s.mu.Lock()
+	h, ok := s.handlers[req.Operation]
+	s.mu.Unlock()
+	if !ok {
+		s.writeFault(w, Fault(soap.ErrActionNotSupported, "Unknown action: "+req.Operation))
+		return
+	}
+
+	body, err := h(req)
+	if err != nil {
+		var f *soap.Fault
+		if !errors.As(err, &f) {
+			f = &soap.Fault{Code: "env:Receiver", SubCode: "ter:Action", Reason: err.Error()}
+		}
+		s.writeFault(w, f)
+		return
+	}
+
+	s.write(w, http.StatusOK, &soap.Body{InnerXML: []byte(body)})
+}
+
+func (s *Server) record(r *Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+}
+
+// responseNamespaces are the namespaces declared on response envelopes
+var responseNamespaces = soap.Namespaces{
+	"tt":   onvif.NamespaceONVIF,
+	"tds":  onvif.NamespaceDevice,
+	"trt":  onvif.NamespaceMedia,
+	"tptz": onvif.NamespacePTZ,
+	"ter":  soap.NamespaceONVIFError,
+}
+
+// writeFault writes f with the HTTP status code required by the SOAP 1.2 HTTP binding
+func (s *Server) writeFault(w http.ResponseWriter, f *soap.Fault) {
+	status := http.StatusInternalServerError
+	if f.Code == "env:Sender" {
+		status = http.StatusBadRequest
+	}
+	s.write(w, status, &soap.Body{Fault: f})
+}
+
+func (s *Server) write(w http.ResponseWriter, status int, body *soap.Body) {
+	buf := bytes.NewBufferString(xml.Header)
+	if err := xml.NewEncoder(buf).Encode(&soap.Envelope{Namespaces: responseNamespaces, Body: body}); err != nil {
+		http.Error(w, "could not marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func (s *Server) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	if s.Auth == AuthDigest {
+		if _, ok := s.checkDigest(r); !ok {
+			s.challenge(w)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Write(s.Snapshot)
+}
@@ -0,0 +1,121 @@
+package onviftest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/ptz"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+func TestServer(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		auth onviftest.Auth
+		mode onvif.AuthMode
+	}{
+		{"none", onviftest.AuthNone, onvif.AuthModeNone},
+		{"ws-security", onviftest.AuthWSSecurity, onvif.AuthModeWSSecurity},
+		{"digest", onviftest.AuthDigest, onvif.AuthModeDigest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			srv := onviftest.NewServer("admin", "password", test.auth)
+			defer srv.Close()
+
+			c := &onvif.Client{Username: "admin", Password: "password"}
+			dev, err := onvif.NewDevice(context.Background(), c, srv.URL)
+			if err != nil {
+				t.Fatalf("could not create device: %v", err)
+			}
+
+			dc, err := device.FromDevice(dev)
+			if err != nil {
+				t.Fatalf("could not create device client: %v", err)
+			}
+			info, err := dc.GetDeviceInformation()
+			if err != nil {
+				t.Fatalf("could not get device information: %v", err)
+			}
+			if info.Manufacturer != onviftest.Manufacturer || info.Model != onviftest.Model {
+				t.Errorf("unexpected device information: %#v", info)
+			}
+			if mode := c.CurrentAuthMode(); mode != test.mode {
+				t.Errorf("expected auth mode %d, got %d", test.mode, mode)
+			}
+
+			mc, err := media.NewClient(c, dev.Services)
+			if err != nil {
+				t.Fatalf("could not create media client: %v", err)
+			}
+			profiles, err := mc.GetProfiles()
+			if err != nil || len(profiles) != 1 || profiles[0].Token != onviftest.ProfileToken {
+				t.Fatalf("unexpected profiles: %v, %v", profiles, err)
+			}
+			uri, err := mc.GetStreamUri(onviftest.ProfileToken, nil)
+			if err != nil || !strings.HasPrefix(uri.URI, "rtsp://") {
+				t.Errorf("unexpected stream uri: %v, %v", uri, err)
+			}
+			if uri, err = mc.GetSnapshotUri(onviftest.ProfileToken); err != nil {
+				t.Fatalf("could not get snapshot uri: %v", err)
+			}
+			buf := new(bytes.Buffer)
+			if _, err = c.Download(uri.URI, buf); err != nil || !bytes.Equal(buf.Bytes(), srv.Snapshot) {
+				t.Errorf("unexpected snapshot: %v, %v", buf.Bytes(), err)
+			}
+
+			pc, err := ptz.NewClient(c, dev.Services)
+			if err != nil {
+				t.Fatalf("could not create ptz client: %v", err)
+			}
+			if err = pc.Stop(onviftest.ProfileToken, true, true); err != nil {
+				t.Errorf("could not stop: %v", err)
+			}
+			if _, err = pc.GetStatus("invalid"); !errors.Is(err, soap.ErrNoProfile) {
+				t.Errorf("expected ErrNoProfile, got %v", err)
+			}
+		})
+	}
+}
+
+func TestServerCustom(t *testing.T) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	defer srv.Close()
+
+	srv.Fail("GetProfiles", onviftest.Fault(soap.ErrOperationProhibited, "Prohibited"))
+	srv.Respond("GetDeviceInformation", "<tds:GetDeviceInformationResponse><tds:Model>Custom</tds:Model></tds:GetDeviceInformationResponse>")
+
+	c := &onvif.Client{Username: "admin", Password: "wrong"}
+	services := onvif.Services{
+		{Namespace: onvif.NamespaceDevice, URL: srv.URL + onviftest.PathDevice},
+		{Namespace: onvif.NamespaceMedia, URL: srv.URL + onviftest.PathMedia},
+	}
+	dc, _ := device.NewClient(c, services)
+	mc, _ := media.NewClient(c, services)
+
+	var uerr *soap.UnauthorizedError
+	if _, err := dc.GetDeviceInformation(); !errors.As(err, &uerr) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+
+	c.Password = "password"
+	info, err := dc.GetDeviceInformation()
+	if err != nil || info.Model != "Custom" {
+		t.Errorf("unexpected device information: %v, %v", info, err)
+	}
+	if _, err = mc.GetProfiles(); !errors.Is(err, soap.ErrOperationProhibited) {
+		t.Errorf("expected ErrOperationProhibited, got %v", err)
+	}
+
+	requests := srv.Requests()
+	last := requests[len(requests)-1]
+	if last.Operation != "GetProfiles" || last.Namespace != onvif.NamespaceMedia || last.Path != onviftest.PathMedia || last.Username != "admin" {
+		t.Errorf("unexpected request: %#v", last)
+	}
+}
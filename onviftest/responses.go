@@ -0,0 +1,155 @@
+package onviftest
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Canned device information returned by a Server
+const (
+	Manufacturer    = "onviftest"
+	Model           = "Fake Camera"
+	FirmwareVersion = "1.0.0"
+	SerialNumber    = "00000001"
+	HardwareID      = "1"
+)
+
+// ProfileToken is the token of the media profile returned by a Server
+const ProfileToken = "Profile_1"
+
+// snapshotJPEG is the default Server.Snapshot, the smallest JPEG: start of image, then end of image
+var snapshotJPEG = []byte{0xff, 0xd8, 0xff, 0xd9}
+
+// handleDefaults sets the canned handlers
+func (s *Server) handleDefaults() {
+	s.handlers["GetSystemDateAndTime"] = s.getSystemDateAndTime
+	s.handlers["GetServices"] = s.getServices
+	s.handlers["GetCapabilities"] = s.getCapabilities
+	s.handlers["GetDeviceInformation"] = getDeviceInformation
+	s.handlers["GetProfiles"] = getProfiles
+	s.handlers["GetStreamUri"] = s.getStreamURI
+	s.handlers["GetSnapshotUri"] = s.getSnapshotURI
+	s.handlers["GetStatus"] = getStatus
+	for _, op := range []string{"ContinuousMove", "AbsoluteMove", "RelativeMove", "Stop"} {
+		op := op
+		s.handlers[op] = func(r *Request) (string, error) {
+			return fmt.Sprintf("<tptz:%sResponse/>", op), profileToken(r)
+		}
+	}
+}
+
+// profileToken returns a fault if r's ProfileToken isn't ProfileToken
+func profileToken(r *Request) error {
+	var req struct {
+		ProfileToken string
+	}
+	if err := r.Decode(&req); err != nil {
+		return Fault(soap.ErrInvalidArgVal, err.Error())
+	}
+	if strings.TrimSpace(req.ProfileToken) != ProfileToken {
+		return Fault(soap.ErrNoProfile, "The requested profile token does not exist")
+	}
+	return nil
+}
+
+func (s *Server) getSystemDateAndTime(*Request) (string, error) {
+	now := time.Now().UTC()
+	return fmt.Sprintf(`<tds:GetSystemDateAndTimeResponse><tds:SystemDateAndTime>
+<tt:DateTimeType>Manual</tt:DateTimeType><tt:DaylightSavings>false</tt:DaylightSavings>
+<tt:UTCDateTime><tt:Time><tt:Hour>%d</tt:Hour><tt:Minute>%d</tt:Minute><tt:Second>%d</tt:Second></tt:Time>
+<tt:Date><tt:Year>%d</tt:Year><tt:Month>%d</tt:Month><tt:Day>%d</tt:Day></tt:Date></tt:UTCDateTime>
+</tds:SystemDateAndTime></tds:GetSystemDateAndTimeResponse>`, now.Hour(), now.Minute(), now.Second(), now.Year(), now.Month(), now.Day()), nil
+}
+
+func (s *Server) getServices(r *Request) (string, error) {
+	var req struct {
+		IncludeCapability bool
+	}
+	if err := r.Decode(&req); err != nil {
+		return "", Fault(soap.ErrInvalidArgVal, err.Error())
+	}
+
+	services := []struct {
+		namespace, path, capabilities string
+	}{
+		{onvif.NamespaceDevice, PathDevice, `<tds:Capabilities><tds:Network ZeroConfiguration="false"/><tds:Security UsernameToken="true" HttpDigest="true"/><tds:System DiscoveryResolve="true"/></tds:Capabilities>`},
+		{onvif.NamespaceMedia, PathMedia, `<trt:Capabilities SnapshotUri="true"><trt:ProfileCapabilities MaximumNumberOfProfiles="1"/><trt:StreamingCapabilities RTP_RTSP_TCP="true"/></trt:Capabilities>`},
+		{onvif.NamespacePTZ, PathPTZ, `<tptz:Capabilities MoveStatus="true" StatusPosition="true"/>`},
+	}
+
+	b := new(strings.Builder)
+	b.WriteString("<tds:GetServicesResponse>")
+	for _, svc := range services {
+		fmt.Fprintf(b, "<tds:Service><tds:Namespace>%s</tds:Namespace><tds:XAddr>%s</tds:XAddr>", svc.namespace, s.URL+svc.path)
+		if req.IncludeCapability {
+			fmt.Fprintf(b, "<tds:Capabilities>%s</tds:Capabilities>", svc.capabilities)
+		}
+		b.WriteString("<tds:Version><tt:Major>2</tt:Major><tt:Minor>60</tt:Minor></tds:Version></tds:Service>")
+	}
+	b.WriteString("</tds:GetServicesResponse>")
+
+	return b.String(), nil
+}
+
+func (s *Server) getCapabilities(*Request) (string, error) {
+	return fmt.Sprintf(`<tds:GetCapabilitiesResponse><tds:Capabilities>
+<tt:Device><tt:XAddr>%s</tt:XAddr></tt:Device>
+<tt:Media><tt:XAddr>%s</tt:XAddr><tt:StreamingCapabilities><tt:RTPMulticast>false</tt:RTPMulticast><tt:RTP_TCP>false</tt:RTP_TCP><tt:RTP_RTSP_TCP>true</tt:RTP_RTSP_TCP></tt:StreamingCapabilities></tt:Media>
+<tt:PTZ><tt:XAddr>%s</tt:XAddr></tt:PTZ>
+</tds:Capabilities></tds:GetCapabilitiesResponse>`, s.URL+PathDevice, s.URL+PathMedia, s.URL+PathPTZ), nil
+}
+
+func getDeviceInformation(*Request) (string, error) {
+	return fmt.Sprintf(`<tds:GetDeviceInformationResponse><tds:Manufacturer>%s</tds:Manufacturer><tds:Model>%s</tds:Model>
+<tds:FirmwareVersion>%s</tds:FirmwareVersion><tds:SerialNumber>%s</tds:SerialNumber><tds:HardwareId>%s</tds:HardwareId>
+</tds:GetDeviceInformationResponse>`, Manufacturer, Model, FirmwareVersion, SerialNumber, HardwareID), nil
+}
+
+func getProfiles(*Request) (string, error) {
+	return `<trt:GetProfilesResponse><trt:Profiles token="` + ProfileToken + `" fixed="true"><tt:Name>MainStream</tt:Name>
+<tt:VideoEncoderConfiguration token="VideoEncoder_1"><tt:Name>VideoEncoder_1</tt:Name><tt:UseCount>1</tt:UseCount><tt:Encoding>H264</tt:Encoding>
+<tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution><tt:Quality>5</tt:Quality></tt:VideoEncoderConfiguration>
+<tt:PTZConfiguration token="PTZ_1"><tt:Name>PTZ_1</tt:Name><tt:UseCount>1</tt:UseCount><tt:NodeToken>PTZNode_1</tt:NodeToken></tt:PTZConfiguration>
+</trt:Profiles></trt:GetProfilesResponse>`, nil
+}
+
+func (s *Server) getStreamURI(r *Request) (string, error) {
+	if err := profileToken(r); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return "", err
+	}
+	return `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>rtsp://` + u.Hostname() + `:554/stream1</tt:Uri>
+<tt:InvalidAfterConnect>false</tt:InvalidAfterConnect><tt:InvalidAfterReboot>false</tt:InvalidAfterReboot><tt:Timeout>PT0S</tt:Timeout>
+</trt:MediaUri></trt:GetStreamUriResponse>`, nil
+}
+
+func (s *Server) getSnapshotURI(r *Request) (string, error) {
+	if err := profileToken(r); err != nil {
+		return "", err
+	}
+
+	return `<trt:GetSnapshotUriResponse><trt:MediaUri><tt:Uri>` + s.URL + PathSnapshot + `</tt:Uri>
+<tt:InvalidAfterConnect>false</tt:InvalidAfterConnect><tt:InvalidAfterReboot>false</tt:InvalidAfterReboot><tt:Timeout>PT0S</tt:Timeout>
+</trt:MediaUri></trt:GetSnapshotUriResponse>`, nil
+}
+
+func getStatus(r *Request) (string, error) {
+	if err := profileToken(r); err != nil {
+		return "", err
+	}
+
+	return `<tptz:GetStatusResponse><tptz:PTZStatus>
+<tt:Position><tt:PanTilt x="0" y="0"/><tt:Zoom x="0"/></tt:Position>
+<tt:MoveStatus><tt:PanTilt>IDLE</tt:PanTilt><tt:Zoom>IDLE</tt:Zoom></tt:MoveStatus>
+<tt:UtcTime>` + time.Now().UTC().Format(time.RFC3339) + `</tt:UtcTime>
+</tptz:PTZStatus></tptz:GetStatusResponse>`, nil
+}
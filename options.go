@@ -0,0 +1,68 @@
+package onvif
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client created with NewClient
+type Option func(*Client)
+
+// NewClient returns a new Client configured with opts, applied in order.
+// It's equivalent to creating a Client and setting its fields, so options can be mixed with setting fields before the first request
+func NewClient(opts ...Option) *Client {
+	c := new(Client)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithCredentials sets the Client's Username and Password
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.Username, c.Password = username, password
+	}
+}
+
+// WithAuthMode sets the Client's AuthMode, skipping authentication mode detection
+func WithAuthMode(mode AuthMode) Option {
+	return func(c *Client) {
+		c.AuthMode = mode
+	}
+}
+
+// WithHTTPClient sets the Client's HTTPClient
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = hc
+	}
+}
+
+// WithTimeout limits each attempt of a request to d. It sets the Timeout of a copy of the Client's RetryPolicy, creating one if needed
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		p := new(RetryPolicy)
+		if c.RetryPolicy != nil {
+			*p = *c.RetryPolicy
+		}
+		p.Timeout = d
+		c.RetryPolicy = p
+	}
+}
+
+// WithLogger sets the Client's Logger
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.Logger = l
+	}
+}
+
+// WithTLSConfig sets the Client's TLSConfig, used if HTTPClient isn't set, and uses HTTPS for device addresses without a scheme
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.TLSConfig = config
+		c.UseTLS = true
+	}
+}
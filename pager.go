@@ -0,0 +1,36 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrRepeatedStartReference is returned (wrapped) by Pages if the device returns a start reference it already returned, which would otherwise page forever
+var ErrRepeatedStartReference = errors.New("repeated start reference")
+
+// Pages returns the items of all pages of a StartReference/NextStartReference paged list, e.g. the PACS services' Get...InfoList operations.
+// fetch is called with the start reference of each page, starting with the empty string, and returns the page's items and the next start reference.
+// Paging stops when the next start reference is empty
+func Pages[T any](ctx context.Context, fetch func(ctx context.Context, start string) ([]T, string, error)) ([]T, error) {
+	var (
+		items []T
+		start string
+		seen  = make(map[string]bool)
+	)
+	for {
+		page, next, err := fetch(ctx, start)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if next == "" {
+			return items, nil
+		}
+		if seen[next] {
+			return nil, fmt.Errorf("could not get page %q: %w", next, ErrRepeatedStartReference)
+		}
+		seen[next] = true
+		start = next
+	}
+}
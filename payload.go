@@ -0,0 +1,71 @@
+package onvif
+
+import (
+	"bytes"
+	"errors"
+)
+
+// MaxPayloadSize is the maximum number of bytes of each body kept by PayloadError
+const MaxPayloadSize = 64 << 10
+
+// PayloadError wraps a response decoding error or SOAP fault with the request and response bodies,
+// e.g. to attach the exact payloads to a vendor support ticket.
+// WS-Security passwords and nonces are redacted, and bodies are truncated to MaxPayloadSize. See Payloads
+type PayloadError struct {
+	Err      error
+	Request  []byte
+	Response []byte
+	// Truncated is true if either body was longer than MaxPayloadSize
+	Truncated bool
+}
+
+func (e *PayloadError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows PayloadError to be used with errors.Is and errors.As
+func (e *PayloadError) Unwrap() error {
+	return e.Err
+}
+
+// Payloads returns the redacted request and response bodies from err, or nil if err doesn't contain them
+func Payloads(err error) (request, response []byte) {
+	var p *PayloadError
+	if !errors.As(err, &p) {
+		return nil, nil
+	}
+	return p.Request, p.Response
+}
+
+// payloadBuffer keeps the first MaxPayloadSize bytes written to it
+type payloadBuffer struct {
+	buf       bytes.Buffer
+	truncated bool
+}
+
+// Write implements io.Writer. It never returns an error
+func (b *payloadBuffer) Write(p []byte) (int, error) {
+	if n := MaxPayloadSize - b.buf.Len(); n < len(p) {
+		b.truncated = true
+		if n > 0 {
+			b.buf.Write(p[:n])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// newPayloadError returns a *PayloadError wrapping err with the request body and the response body read into resp
+func newPayloadError(err error, request []byte, resp *payloadBuffer) *PayloadError {
+	truncated := resp.truncated
+	if len(request) > MaxPayloadSize {
+		request = request[:MaxPayloadSize]
+		truncated = true
+	}
+	return &PayloadError{
+		Err:       err,
+		Request:   redact(request),
+		Response:  redact(resp.buf.Bytes()),
+		Truncated: truncated,
+	}
+}
@@ -0,0 +1,112 @@
+package onvif
+
+import (
+	"context"
+	"time"
+)
+
+// Stage is a stage of a long-running operation, e.g. a firmware upgrade or system restore
+type Stage int
+
+// Stages
+const (
+	// StageWaiting is a delay requested by the device before the operation continues, e.g. a firmware upgrade's UploadDelay
+	StageWaiting Stage = iota
+	// StageUploading is uploading content to the device
+	StageUploading
+	// StageRestarting is the time the device is expected to be unavailable while it applies the operation, e.g. ExpectedDownTime
+	StageRestarting
+	// StageDone indicates the operation completed and the device is available
+	StageDone
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageWaiting:
+		return "waiting"
+	case StageUploading:
+		return "uploading"
+	case StageRestarting:
+		return "restarting"
+	case StageDone:
+		return "done"
+	}
+	return "unknown"
+}
+
+// Status is the status of a long-running operation
+type Status struct {
+	Stage Stage
+	// Sent and Total are the number of bytes uploaded and the size of the upload during StageUploading
+	Sent  int64
+	Total int64
+	// Remaining is how long the device said the stage will take during StageWaiting and StageRestarting, or zero if it's unknown
+	Remaining time.Duration
+}
+
+// Progress receives the status of long-running operations.
+// Update is called on the goroutine running the operation, so it shouldn't block
+type Progress interface {
+	Update(status Status)
+}
+
+// ProgressFunc is a function that implements Progress
+type ProgressFunc func(status Status)
+
+// Update implements Progress
+func (f ProgressFunc) Update(status Status) {
+	f(status)
+}
+
+// Report sends status to p. p may be nil
+func Report(p Progress, status Status) {
+	if p != nil {
+		p.Update(status)
+	}
+}
+
+// UploadProgress returns a progress function for Client.Upload that reports StageUploading to p, or nil if p is nil
+func UploadProgress(p Progress) func(sent, total int64) {
+	if p == nil {
+		return nil
+	}
+	return func(sent, total int64) {
+		p.Update(Status{Stage: StageUploading, Sent: sent, Total: total})
+	}
+}
+
+// Wait waits for d using the Client's Clock, returning ctx's error if ctx is done first
+func (c *Client) Wait(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil || d <= 0 {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.clock().After(d):
+		return nil
+	}
+}
+
+// WaitStage reports stage with d remaining to p (which may be nil), then waits for d. See Client.Wait
+func (c *Client) WaitStage(ctx context.Context, p Progress, stage Stage, d time.Duration) error {
+	Report(p, Status{Stage: stage, Remaining: d})
+	return c.Wait(ctx, d)
+}
+
+// Poll calls check immediately and then every interval until it returns true or an error, or ctx is done.
+// ctx's error is returned if ctx is done first
+func (c *Client) Poll(ctx context.Context, interval time.Duration, check func(ctx context.Context) (bool, error)) error {
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if err = c.Wait(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
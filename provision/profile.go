@@ -0,0 +1,113 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+)
+
+// ErrProfileNotFound is returned (wrapped) by Diff and Converge if the device doesn't have the spec's profile
+var ErrProfileNotFound = errors.New("profile not found")
+
+// ProfileSpec is the desired configuration of a media profile. Unset settings aren't checked. See Converge
+type ProfileSpec struct {
+	// Token is the token of the profile
+	Token string
+	// VideoEncoderToken and MetadataToken are the tokens of the video encoder and metadata configurations the profile should use
+	VideoEncoderToken string
+	MetadataToken     string
+	// VideoEncoder is applied to the profile's video encoder configuration
+	VideoEncoder *VideoEncoder
+	// OSD is applied to the text OSDs of the profile's video source configuration
+	OSD *OSD
+}
+
+// Diff returns the changes Converge would make to dev, i.e. how the profile has drifted from spec
+func Diff(ctx context.Context, dev *onvif.Device, spec *ProfileSpec) ([]*Change, error) {
+	return converge(ctx, dev, spec, true)
+}
+
+// Converge makes the Add and Set requests needed to make dev's profile match spec, and returns the changes made.
+// Configurations that already match aren't changed. If it fails, the changes made before the error are returned with it
+func Converge(ctx context.Context, dev *onvif.Device, spec *ProfileSpec) ([]*Change, error) {
+	return converge(ctx, dev, spec, false)
+}
+
+func converge(ctx context.Context, dev *onvif.Device, spec *ProfileSpec, dryRun bool) ([]*Change, error) {
+	mc, err := media.FromDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+	a := &applier{dryRun: dryRun}
+	err = a.applyProfile(ctx, mc, spec)
+	return a.changes, err
+}
+
+func (a *applier) applyProfile(ctx context.Context, mc *media.Client, spec *ProfileSpec) error {
+	profiles, err := mc.GetProfilesContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get profiles: %w", err)
+	}
+	var profile *media.Profile
+	for _, p := range profiles {
+		if p.Token == spec.Token {
+			profile = p
+			break
+		}
+	}
+	if profile == nil {
+		return fmt.Errorf("could not find profile %s: %w", spec.Token, ErrProfileNotFound)
+	}
+	setting := "Profile " + profile.Token
+
+	encoder := profile.VideoEncoderConfiguration
+	if spec.VideoEncoderToken != "" && (encoder == nil || encoder.Token != spec.VideoEncoderToken) {
+		var cur string
+		if encoder != nil {
+			cur = encoder.Token
+		}
+		if a.change(setting+" VideoEncoderConfiguration", cur, spec.VideoEncoderToken) {
+			if err = mc.AddVideoEncoderConfigurationContext(ctx, profile.Token, spec.VideoEncoderToken); err != nil {
+				return fmt.Errorf("could not add video encoder configuration: %w", err)
+			}
+		}
+		if spec.VideoEncoder != nil {
+			if encoder, err = mc.GetVideoEncoderConfigurationContext(ctx, spec.VideoEncoderToken); err != nil {
+				return fmt.Errorf("could not get video encoder configuration: %w", err)
+			}
+		}
+	}
+	if spec.VideoEncoder != nil {
+		if encoder == nil {
+			return fmt.Errorf("profile %s has no video encoder configuration", profile.Token)
+		}
+		if err = a.applyEncoderConfig(ctx, mc, spec.VideoEncoder, encoder); err != nil {
+			return err
+		}
+	}
+
+	if metadata := profile.MetadataConfiguration; spec.MetadataToken != "" && (metadata == nil || metadata.Token != spec.MetadataToken) {
+		var cur string
+		if metadata != nil {
+			cur = metadata.Token
+		}
+		if a.change(setting+" MetadataConfiguration", cur, spec.MetadataToken) {
+			if err = mc.AddMetadataConfigurationContext(ctx, profile.Token, spec.MetadataToken); err != nil {
+				return fmt.Errorf("could not add metadata configuration: %w", err)
+			}
+		}
+	}
+
+	if spec.OSD != nil {
+		if profile.VideoSourceConfiguration == nil {
+			return fmt.Errorf("profile %s has no video source configuration", profile.Token)
+		}
+		if err = a.applyOSD(ctx, mc, spec.OSD, profile.VideoSourceConfiguration.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+// Package provision applies configuration templates (NTP, DNS, users, video encoder, OSD, and media profile settings) to many ONVIF devices concurrently
+package provision
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/media"
+)
+
+// NTP is the NTP configuration of a Template
+type NTP struct {
+	FromDHCP bool
+	// Servers are the manual NTP servers. They're ignored if FromDHCP is true
+	Servers []*device.NetworkHost
+}
+
+// DNS is the DNS configuration of a Template
+type DNS struct {
+	FromDHCP      bool
+	SearchDomains []string
+	// Servers are the manual DNS servers. They're ignored if FromDHCP is true
+	Servers []*device.IPAddress
+}
+
+// VideoEncoder is the video encoder settings of a Template. They're applied to each of the device's video encoder configurations.
+// Unset fields aren't changed
+type VideoEncoder struct {
+	// Encoding is JPEG, MPEG4, or H264
+	Encoding       string
+	Resolution     *media.VideoResolution
+	Quality        *float64
+	FrameRateLimit *int
+	BitrateLimit   *int
+	// GovLength is applied to the MPEG4 or H264 settings
+	GovLength *int
+}
+
+// OSD is the OSD settings of a Template. They're applied to each of the device's text OSDs. Unset fields aren't changed
+type OSD struct {
+	// PlainText is the text of OSDs with the OSDTextPlain type
+	PlainText string
+	// DateFormat and TimeFormat are the formats of OSDs showing the date or time, e.g. yyyy-MM-dd and HH:mm:ss
+	DateFormat string
+	TimeFormat string
+}
+
+// Template is the configuration applied to each device by Apply. Nil settings aren't changed
+type Template struct {
+	NTP *NTP
+	DNS *DNS
+	// Users are created if they don't exist. Existing users are updated if their level differs or Password is set,
+	// since passwords can't be read back. Changing the password of the user the devices are accessed with will cause later requests to fail
+	Users        []*device.User
+	VideoEncoder *VideoEncoder
+	OSD          *OSD
+	// Profiles are applied after the other settings. See Converge
+	Profiles []*ProfileSpec
+}
+
+// Change is a setting changed on a device, or that would be changed in a dry run
+type Change struct {
+	// Setting is the changed setting, e.g. NTP, User admin, or VideoEncoder VideoEncoder_1
+	Setting string
+	// From and To describe the setting before and after the change. From is empty for created settings, e.g. a new user
+	From string
+	To   string
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Setting, c.From, c.To)
+}
+
+// Options configures Apply
+type Options struct {
+	// DryRun reports the changes that would be made without making them
+	DryRun bool
+	// Parallelism is the maximum number of devices configured concurrently. If less than 1, onvif.DefaultBroadcastParallelism is used
+	Parallelism int
+	// Timeout, if greater than zero, limits the time spent configuring each device
+	Timeout time.Duration
+}
+
+// Apply applies t to each of devices concurrently, and returns the changes made to each device.
+// If a device fails, its result holds the changes made before the error. opts may be nil
+func Apply(ctx context.Context, devices []*onvif.Device, t *Template, opts *Options) *onvif.BroadcastReport[*onvif.Device, []*Change] {
+	if opts == nil {
+		opts = new(Options)
+	}
+	return onvif.Broadcast(ctx, devices, &onvif.BroadcastOptions{Parallelism: opts.Parallelism, Timeout: opts.Timeout},
+		func(ctx context.Context, dev *onvif.Device) ([]*Change, error) {
+			a := &applier{dryRun: opts.DryRun}
+			err := a.apply(ctx, dev, t)
+			return a.changes, err
+		})
+}
+
+// applier applies a Template to a single device, recording the changes
+type applier struct {
+	dryRun  bool
+	changes []*Change
+}
+
+// change records the change and returns true if it should be made
+func (a *applier) change(setting, from, to string) bool {
+	a.changes = append(a.changes, &Change{Setting: setting, From: from, To: to})
+	return !a.dryRun
+}
+
+func (a *applier) apply(ctx context.Context, dev *onvif.Device, t *Template) error {
+	dc, err := device.FromDevice(dev)
+	if err != nil {
+		return err
+	}
+	if t.NTP != nil {
+		if err = a.applyNTP(ctx, dc, t.NTP); err != nil {
+			return err
+		}
+	}
+	if t.DNS != nil {
+		if err = a.applyDNS(ctx, dc, t.DNS); err != nil {
+			return err
+		}
+	}
+	if len(t.Users) > 0 {
+		if err = a.applyUsers(ctx, dc, t.Users); err != nil {
+			return err
+		}
+	}
+
+	if t.VideoEncoder == nil && t.OSD == nil && len(t.Profiles) == 0 {
+		return nil
+	}
+	mc, err := media.FromDevice(dev)
+	if err != nil {
+		return err
+	}
+	if t.VideoEncoder != nil {
+		if err = a.applyVideoEncoder(ctx, mc, t.VideoEncoder); err != nil {
+			return err
+		}
+	}
+	if t.OSD != nil {
+		if err = a.applyOSD(ctx, mc, t.OSD, ""); err != nil {
+			return err
+		}
+	}
+	for _, spec := range t.Profiles {
+		if err = a.applyProfile(ctx, mc, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeHosts returns "DHCP" if fromDHCP is true, or the list of hosts otherwise
+func describeHosts(fromDHCP bool, hosts []string) string {
+	if fromDHCP {
+		return "DHCP"
+	}
+	return "[" + strings.Join(hosts, " ") + "]"
+}
+
+func ntpHosts(hosts []*device.NetworkHost) []string {
+	s := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		switch {
+		case h.DNSname != "":
+			s = append(s, h.DNSname)
+		case h.IPv4Address != "":
+			s = append(s, h.IPv4Address)
+		default:
+			s = append(s, h.IPv6Address)
+		}
+	}
+	return s
+}
+
+func (a *applier) applyNTP(ctx context.Context, dc *device.Client, ntp *NTP) error {
+	cur, err := dc.GetNTPContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get NTP: %w", err)
+	}
+	if cur == nil {
+		cur = new(device.NTPInformation)
+	}
+
+	from := describeHosts(cur.FromDHCP, ntpHosts(cur.NTPManual))
+	to := describeHosts(ntp.FromDHCP, ntpHosts(ntp.Servers))
+	if from == to || !a.change("NTP", from, to) {
+		return nil
+	}
+	if err = dc.SetNTPContext(ctx, ntp.FromDHCP, ntp.Servers); err != nil {
+		return fmt.Errorf("could not set NTP: %w", err)
+	}
+	return nil
+}
+
+func dnsServers(addrs []*device.IPAddress) []string {
+	s := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr.IPv4Address != "" {
+			s = append(s, addr.IPv4Address)
+		} else {
+			s = append(s, addr.IPv6Address)
+		}
+	}
+	return s
+}
+
+func (a *applier) applyDNS(ctx context.Context, dc *device.Client, dns *DNS) error {
+	cur, err := dc.GetDNSContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get DNS: %w", err)
+	}
+	if cur == nil {
+		cur = new(device.DNSInformation)
+	}
+
+	from := fmt.Sprintf("%s search %v", describeHosts(cur.FromDHCP, dnsServers(cur.DNSManual)), cur.SearchDomain)
+	to := fmt.Sprintf("%s search %v", describeHosts(dns.FromDHCP, dnsServers(dns.Servers)), dns.SearchDomains)
+	if from == to || !a.change("DNS", from, to) {
+		return nil
+	}
+	if err = dc.SetDNSContext(ctx, dns.FromDHCP, dns.SearchDomains, dns.Servers); err != nil {
+		return fmt.Errorf("could not set DNS: %w", err)
+	}
+	return nil
+}
+
+func (a *applier) applyUsers(ctx context.Context, dc *device.Client, users []*device.User) error {
+	cur, err := dc.GetUsersContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get users: %w", err)
+	}
+	levels := make(map[string]device.UserLevel, len(cur))
+	for _, u := range cur {
+		levels[u.Username] = u.UserLevel
+	}
+
+	for _, u := range users {
+		setting := "User " + u.Username
+		level, ok := levels[u.Username]
+		if !ok {
+			if a.change(setting, "", string(u.UserLevel)) {
+				if err = dc.CreateUsersContext(ctx, u); err != nil {
+					return fmt.Errorf("could not create user %s: %w", u.Username, err)
+				}
+			}
+			continue
+		}
+
+		if level == u.UserLevel && u.Password == "" {
+			continue
+		}
+		to := string(u.UserLevel)
+		if u.Password != "" {
+			to += " (password changed)"
+		}
+		if a.change(setting, string(level), to) {
+			if err = dc.SetUserContext(ctx, u); err != nil {
+				return fmt.Errorf("could not set user %s: %w", u.Username, err)
+			}
+		}
+	}
+	return nil
+}
+
+// describeEncoder returns a description of the settings VideoEncoder changes
+func describeEncoder(c *media.VideoEncoderConfiguration) string {
+	s := fmt.Sprintf("%s quality %g", c.Encoding, c.Quality)
+	if c.Resolution != nil {
+		s += fmt.Sprintf(" %dx%d", c.Resolution.Width, c.Resolution.Height)
+	}
+	if c.RateControl != nil {
+		s += fmt.Sprintf(" %dfps %dkbps", c.RateControl.FrameRateLimit, c.RateControl.BitrateLimit)
+	}
+	if c.H264 != nil {
+		s += fmt.Sprintf(" gov %d", c.H264.GovLength)
+	} else if c.MPEG4 != nil {
+		s += fmt.Sprintf(" gov %d", c.MPEG4.GovLength)
+	}
+	return s
+}
+
+// encoderConfig returns a copy of c with the template's settings applied
+func (v *VideoEncoder) encoderConfig(c *media.VideoEncoderConfiguration) *media.VideoEncoderConfiguration {
+	next := *c
+	if v.Encoding != "" {
+		next.Encoding = v.Encoding
+	}
+	if v.Resolution != nil {
+		res := *v.Resolution
+		next.Resolution = &res
+	}
+	if v.Quality != nil {
+		next.Quality = *v.Quality
+	}
+	if v.FrameRateLimit != nil || v.BitrateLimit != nil {
+		rc := new(media.VideoRateControl)
+		if c.RateControl != nil {
+			*rc = *c.RateControl
+		}
+		if v.FrameRateLimit != nil {
+			rc.FrameRateLimit = *v.FrameRateLimit
+		}
+		if v.BitrateLimit != nil {
+			rc.BitrateLimit = *v.BitrateLimit
+		}
+		next.RateControl = rc
+	}
+	if v.GovLength != nil {
+		switch next.Encoding {
+		case "H264":
+			h264 := new(media.H264Configuration)
+			if c.H264 != nil {
+				*h264 = *c.H264
+			}
+			h264.GovLength = *v.GovLength
+			next.H264 = h264
+		case "MPEG4":
+			mpeg4 := new(media.Mpeg4Configuration)
+			if c.MPEG4 != nil {
+				*mpeg4 = *c.MPEG4
+			}
+			mpeg4.GovLength = *v.GovLength
+			next.MPEG4 = mpeg4
+		}
+	}
+	return &next
+}
+
+func (a *applier) applyVideoEncoder(ctx context.Context, mc *media.Client, v *VideoEncoder) error {
+	configs, err := mc.GetVideoEncoderConfigurationsContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get video encoder configurations: %w", err)
+	}
+
+	for _, c := range configs {
+		if err = a.applyEncoderConfig(ctx, mc, v, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEncoderConfig applies v to the video encoder configuration c
+func (a *applier) applyEncoderConfig(ctx context.Context, mc *media.Client, v *VideoEncoder, c *media.VideoEncoderConfiguration) error {
+	next := v.encoderConfig(c)
+	from, to := describeEncoder(c), describeEncoder(next)
+	if from == to || !a.change("VideoEncoder "+c.Token, from, to) {
+		return nil
+	}
+	if err := mc.SetVideoEncoderConfigurationContext(ctx, next); err != nil {
+		return fmt.Errorf("could not set video encoder configuration %s: %w", c.Token, err)
+	}
+	return nil
+}
+
+// osdText returns a copy of text with the template's settings applied, or nil if text isn't changed
+func (o *OSD) osdText(text *media.OSDTextConfiguration) *media.OSDTextConfiguration {
+	next := *text
+	switch text.Type {
+	case media.OSDTextPlain:
+		if o.PlainText != "" {
+			next.PlainText = o.PlainText
+		}
+	case media.OSDTextDate, media.OSDTextTime, media.OSDTextDateAndTime:
+		if o.DateFormat != "" && text.Type != media.OSDTextTime {
+			next.DateFormat = o.DateFormat
+		}
+		if o.TimeFormat != "" && text.Type != media.OSDTextDate {
+			next.TimeFormat = o.TimeFormat
+		}
+	}
+	if next.PlainText == text.PlainText && next.DateFormat == text.DateFormat && next.TimeFormat == text.TimeFormat {
+		return nil
+	}
+	return &next
+}
+
+// describeOSD returns a description of the settings OSD changes
+func describeOSD(text *media.OSDTextConfiguration) string {
+	switch text.Type {
+	case media.OSDTextPlain:
+		return text.PlainText
+	case media.OSDTextDate:
+		return text.DateFormat
+	case media.OSDTextTime:
+		return text.TimeFormat
+	}
+	return strings.TrimSpace(text.DateFormat + " " + text.TimeFormat)
+}
+
+// applyOSD applies o to the OSDs of the video source configuration with the given token, or all OSDs if configurationToken is empty
+func (a *applier) applyOSD(ctx context.Context, mc *media.Client, o *OSD, configurationToken string) error {
+	osds, err := mc.GetOSDsContext(ctx, configurationToken)
+	if err != nil {
+		return fmt.Errorf("could not get OSDs: %w", err)
+	}
+
+	for _, osd := range osds {
+		if osd.Type != media.OSDTypeText || osd.TextString == nil {
+			continue
+		}
+		text := o.osdText(osd.TextString)
+		if text == nil || !a.change("OSD "+osd.Token, describeOSD(osd.TextString), describeOSD(text)) {
+			continue
+		}
+		next := *osd
+		next.TextString = text
+		if err = mc.SetOSDContext(ctx, &next); err != nil {
+			return fmt.Errorf("could not set OSD %s: %w", osd.Token, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package provision_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/onviftest"
+	"github.com/korylprince/go-onvif/provision"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+func newServer(t *testing.T) (*onviftest.Server, *onvif.Device) {
+	srv := onviftest.NewServer("admin", "password", onviftest.AuthWSSecurity)
+	srv.Respond("GetNTP", `<tds:GetNTPResponse><tds:NTPInformation><tt:FromDHCP>true</tt:FromDHCP></tds:NTPInformation></tds:GetNTPResponse>`)
+	srv.Respond("SetNTP", `<tds:SetNTPResponse/>`)
+	srv.Respond("GetDNS", `<tds:GetDNSResponse><tds:DNSInformation><tt:FromDHCP>false</tt:FromDHCP><tt:SearchDomain>example.com</tt:SearchDomain>
+<tt:DNSManual><tt:Type>IPv4</tt:Type><tt:IPv4Address>10.0.0.1</tt:IPv4Address></tt:DNSManual></tds:DNSInformation></tds:GetDNSResponse>`)
+	srv.Respond("GetUsers", `<tds:GetUsersResponse><tds:User><tt:Username>admin</tt:Username><tt:UserLevel>Administrator</tt:UserLevel></tds:User>
+<tds:User><tt:Username>viewer</tt:Username><tt:UserLevel>Operator</tt:UserLevel></tds:User></tds:GetUsersResponse>`)
+	srv.Respond("CreateUsers", `<tds:CreateUsersResponse/>`)
+	srv.Respond("SetUser", `<tds:SetUserResponse/>`)
+	srv.Respond("GetVideoEncoderConfigurations", `<trt:GetVideoEncoderConfigurationsResponse><trt:Configurations token="VE_1">
+<tt:Name>Main</tt:Name><tt:Encoding>H264</tt:Encoding><tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution>
+<tt:Quality>5</tt:Quality><tt:RateControl><tt:FrameRateLimit>30</tt:FrameRateLimit><tt:EncodingInterval>1</tt:EncodingInterval><tt:BitrateLimit>4096</tt:BitrateLimit></tt:RateControl>
+<tt:H264><tt:GovLength>30</tt:GovLength><tt:H264Profile>Main</tt:H264Profile></tt:H264></trt:Configurations></trt:GetVideoEncoderConfigurationsResponse>`)
+	srv.Respond("SetVideoEncoderConfiguration", `<trt:SetVideoEncoderConfigurationResponse/>`)
+	srv.Respond("GetOSDs", `<trt:GetOSDsResponse><trt:OSDs token="OSD_1"><tt:VideoSourceConfigurationToken>VSC_1</tt:VideoSourceConfigurationToken>
+<tt:Type>Text</tt:Type><tt:Position><tt:Type>UpperLeft</tt:Type></tt:Position><tt:TextString><tt:Type>Plain</tt:Type><tt:FontSize>32</tt:FontSize>
+<tt:PlainText>Camera</tt:PlainText></tt:TextString></trt:OSDs><trt:OSDs token="OSD_2"><tt:VideoSourceConfigurationToken>VSC_1</tt:VideoSourceConfigurationToken>
+<tt:Type>Image</tt:Type><tt:Position><tt:Type>LowerRight</tt:Type></tt:Position><tt:Image><tt:ImgPath>logo.png</tt:ImgPath></tt:Image></trt:OSDs></trt:GetOSDsResponse>`)
+	srv.Respond("SetOSD", `<trt:SetOSDResponse/>`)
+
+	dev, err := onvif.NewDevice(context.Background(), &onvif.Client{Username: "admin", Password: "password"}, srv.URL)
+	if err != nil {
+		t.Fatalf("could not create device: %v", err)
+	}
+	return srv, dev
+}
+
+// sets returns the operations of the Set and Create requests made to srv
+func sets(srv *onviftest.Server) []string {
+	var ops []string
+	for _, r := range srv.Requests() {
+		if strings.HasPrefix(r.Operation, "Set") || strings.HasPrefix(r.Operation, "Create") {
+			ops = append(ops, r.Operation)
+		}
+	}
+	return ops
+}
+
+func TestApply(t *testing.T) {
+	srv1, dev1 := newServer(t)
+	defer srv1.Close()
+	srv2, dev2 := newServer(t)
+	defer srv2.Close()
+	srv2.Fail("GetUsers", onviftest.Fault("ter:NotAuthorized", "not allowed"))
+
+	quality, gov := 4.0, 60
+	template := &provision.Template{
+		NTP: &provision.NTP{Servers: []*device.NetworkHost{{Type: device.NetworkHostTypeDNS, DNSname: "pool.ntp.org"}}},
+		// the DNS settings already match
+		DNS:          &provision.DNS{SearchDomains: []string{"example.com"}, Servers: []*device.IPAddress{{Type: device.IPTypeIPv4, IPv4Address: "10.0.0.1"}}},
+		Users:        []*device.User{{Username: "viewer", UserLevel: device.UserLevelOperator}, {Username: "ops", Password: "secret", UserLevel: device.UserLevelUser}},
+		VideoEncoder: &provision.VideoEncoder{Quality: &quality, GovLength: &gov},
+		OSD:          &provision.OSD{PlainText: "Lobby"},
+	}
+
+	expected := `[NTP: "DHCP" -> "[pool.ntp.org]" User ops: "" -> "User" ` +
+		`VideoEncoder VE_1: "H264 quality 5 1920x1080 30fps 4096kbps gov 30" -> "H264 quality 4 1920x1080 30fps 4096kbps gov 60" OSD OSD_1: "Camera" -> "Lobby"]`
+
+	report := provision.Apply(context.Background(), []*onvif.Device{dev1, dev2}, template, &provision.Options{DryRun: true})
+	if res := report.Results[0]; res.Err != nil || fmt.Sprint(res.Value) != expected {
+		t.Errorf("unexpected dry run result: %v, %v", res.Value, res.Err)
+	}
+	if ops := append(sets(srv1), sets(srv2)...); len(ops) != 0 {
+		t.Errorf("expected no changes in dry run, got %v", ops)
+	}
+
+	report = provision.Apply(context.Background(), []*onvif.Device{dev1, dev2}, template, nil)
+	if res := report.Results[0]; res.Err != nil || fmt.Sprint(res.Value) != expected {
+		t.Errorf("unexpected result: %v, %v", res.Value, res.Err)
+	}
+	if ops := fmt.Sprint(sets(srv1)); ops != "[SetNTP CreateUsers SetVideoEncoderConfiguration SetOSD]" {
+		t.Errorf("unexpected changes: %s", ops)
+	}
+
+	// the second device fails after its NTP settings are changed
+	res := report.Results[1]
+	if !errors.Is(res.Err, soap.ErrNotAuthorized) || len(res.Value) != 1 || res.Value[0].Setting != "NTP" {
+		t.Errorf("unexpected failed result: %v, %v", res.Value, res.Err)
+	}
+	if ops := fmt.Sprint(sets(srv2)); ops != "[SetNTP]" {
+		t.Errorf("unexpected changes: %s", ops)
+	}
+
+	for _, r := range srv1.Requests() {
+		switch r.Operation {
+		case "SetVideoEncoderConfiguration":
+			req := new(struct {
+				Configuration *media.VideoEncoderConfiguration
+			})
+			if err := r.Decode(req); err != nil || req.Configuration.Quality != 4 || req.Configuration.H264.GovLength != 60 ||
+				req.Configuration.H264.H264Profile != "Main" || req.Configuration.RateControl.BitrateLimit != 4096 {
+				t.Errorf("unexpected encoder configuration: %s", r.Body)
+			}
+		case "SetOSD":
+			req := new(struct {
+				OSD *media.OSDConfiguration
+			})
+			if err := r.Decode(req); err != nil || req.OSD.Token != "OSD_1" || req.OSD.TextString.PlainText != "Lobby" ||
+				req.OSD.TextString.FontSize == nil || *req.OSD.TextString.FontSize != 32 || req.OSD.Position.Type != "UpperLeft" {
+				t.Errorf("unexpected OSD: %s", r.Body)
+			}
+		}
+	}
+}
+
+func TestConverge(t *testing.T) {
+	srv, dev := newServer(t)
+	defer srv.Close()
+	srv.Respond("GetProfiles", `<trt:GetProfilesResponse><trt:Profiles token="Profile_1"><tt:Name>Main</tt:Name>
+<tt:VideoSourceConfiguration token="VSC_1"><tt:Name>VSC_1</tt:Name><tt:SourceToken>VideoSource_1</tt:SourceToken></tt:VideoSourceConfiguration>
+<tt:VideoEncoderConfiguration token="VE_1"><tt:Encoding>H264</tt:Encoding><tt:Quality>5</tt:Quality></tt:VideoEncoderConfiguration>
+<tt:MetadataConfiguration token="Metadata_1"><tt:Name>Metadata_1</tt:Name></tt:MetadataConfiguration></trt:Profiles></trt:GetProfilesResponse>`)
+	srv.Respond("GetVideoEncoderConfiguration", `<trt:GetVideoEncoderConfigurationResponse><trt:Configuration token="VE_2">
+<tt:Encoding>H264</tt:Encoding><tt:Quality>3</tt:Quality></trt:Configuration></trt:GetVideoEncoderConfigurationResponse>`)
+	srv.Respond("AddVideoEncoderConfiguration", `<trt:AddVideoEncoderConfigurationResponse/>`)
+
+	quality := 3.0
+	spec := &provision.ProfileSpec{
+		Token:             "Profile_1",
+		VideoEncoderToken: "VE_2",
+		MetadataToken:     "Metadata_1",
+		VideoEncoder:      &provision.VideoEncoder{Quality: &quality},
+		OSD:               &provision.OSD{PlainText: "Lobby"},
+	}
+
+	// the new encoder configuration already has the requested quality and the metadata configuration matches
+	expected := `[Profile Profile_1 VideoEncoderConfiguration: "VE_1" -> "VE_2" OSD OSD_1: "Camera" -> "Lobby"]`
+	changes, err := provision.Diff(context.Background(), dev, spec)
+	if err != nil || fmt.Sprint(changes) != expected {
+		t.Errorf("unexpected diff: %v, %v", changes, err)
+	}
+	if ops := sets(srv); len(ops) != 0 {
+		t.Errorf("expected no changes for diff, got %v", ops)
+	}
+
+	changes, err = provision.Converge(context.Background(), dev, spec)
+	if err != nil || fmt.Sprint(changes) != expected {
+		t.Errorf("unexpected changes: %v, %v", changes, err)
+	}
+	var ops []string
+	for _, r := range srv.Requests() {
+		if strings.HasPrefix(r.Operation, "Add") || strings.HasPrefix(r.Operation, "Set") {
+			ops = append(ops, r.Operation)
+		}
+		if r.Operation == "GetOSDs" && !strings.Contains(string(r.Body), "VSC_1") {
+			t.Errorf("expected OSDs of the profile's video source configuration: %s", r.Body)
+		}
+	}
+	if fmt.Sprint(ops) != "[AddVideoEncoderConfiguration SetOSD]" {
+		t.Errorf("unexpected requests: %v", ops)
+	}
+
+	if _, err = provision.Diff(context.Background(), dev, &provision.ProfileSpec{Token: "Profile_2"}); !errors.Is(err, provision.ErrProfileNotFound) {
+		t.Errorf("expected profile not found error, got %v", err)
+	}
+}
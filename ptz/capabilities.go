@@ -0,0 +1,41 @@
+package ptz
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF PTZ Capabilities type
+type Capabilities struct {
+	EFlip                       bool `xml:"EFlip,attr"`
+	Reverse                     bool `xml:"Reverse,attr"`
+	GetCompatibleConfigurations bool `xml:"GetCompatibleConfigurations,attr"`
+	MoveStatus                  bool `xml:"MoveStatus,attr"`
+	StatusPosition              bool `xml:"StatusPosition,attr"`
+	// MoveAndTrack is a space separated list of supported MoveAndTrack methods
+	MoveAndTrack string `xml:"MoveAndTrack,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tptz:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the PTZ service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
@@ -0,0 +1,75 @@
+package ptz
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// FloatRange is an ONVIF FloatRange type
+type FloatRange struct {
+	Min float64
+	Max float64
+}
+
+// DurationRange is an ONVIF DurationRange type. Min and Max are xsd:durations. See soap.ParseDuration
+type DurationRange struct {
+	Min string
+	Max string
+}
+
+// Space2DDescription is an ONVIF Space2DDescription type
+type Space2DDescription struct {
+	URI    string
+	XRange FloatRange
+	YRange FloatRange
+}
+
+// Space1DDescription is an ONVIF Space1DDescription type
+type Space1DDescription struct {
+	URI    string
+	XRange FloatRange
+}
+
+// PTZSpaces is an ONVIF PTZSpaces type. The first space of each kind is usually the device's default, generic space
+type PTZSpaces struct {
+	AbsolutePanTiltPositionSpace    []*Space2DDescription
+	AbsoluteZoomPositionSpace       []*Space1DDescription
+	RelativePanTiltTranslationSpace []*Space2DDescription
+	RelativeZoomTranslationSpace    []*Space1DDescription
+	ContinuousPanTiltVelocitySpace  []*Space2DDescription
+	ContinuousZoomVelocitySpace     []*Space1DDescription
+	PanTiltSpeedSpace               []*Space1DDescription
+	ZoomSpeedSpace                  []*Space1DDescription
+}
+
+// PTZConfigurationOptions is an ONVIF PTZConfigurationOptions type
+type PTZConfigurationOptions struct {
+	Spaces *PTZSpaces
+	// PTZTimeout is the range of ContinuousMove timeouts
+	PTZTimeout *DurationRange
+}
+
+// GetConfigurationOptions is an ONVIF GetConfigurationOptions operation
+type GetConfigurationOptions struct {
+	XMLName            xml.Name `xml:"tptz:GetConfigurationOptions"`
+	ConfigurationToken string   `xml:"tptz:ConfigurationToken"`
+}
+
+// GetConfigurationOptionsResponse is an ONVIF GetConfigurationOptionsResponse response
+type GetConfigurationOptionsResponse struct {
+	PTZConfigurationOptions *PTZConfigurationOptions
+}
+
+// GetConfigurationOptions returns the supported coordinate spaces and timeouts of the PTZ configuration with the given token
+func (c *Client) GetConfigurationOptions(configurationToken string) (*PTZConfigurationOptions, error) {
+	return c.GetConfigurationOptionsContext(context.Background(), configurationToken)
+}
+
+// GetConfigurationOptionsContext is like GetConfigurationOptions, but ctx controls the request
+func (c *Client) GetConfigurationOptionsContext(ctx context.Context, configurationToken string) (*PTZConfigurationOptions, error) {
+	resp := new(GetConfigurationOptionsResponse)
+	if err := c.CallContext(ctx, &GetConfigurationOptions{ConfigurationToken: configurationToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.PTZConfigurationOptions, nil
+}
@@ -0,0 +1,121 @@
+package ptz
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// ContinuousMove is an ONVIF ContinuousMove operation
+type ContinuousMove struct {
+	XMLName      xml.Name  `xml:"tptz:ContinuousMove"`
+	ProfileToken string    `xml:"tptz:ProfileToken"`
+	Velocity     *PTZSpeed `xml:"tptz:Velocity"`
+	Timeout      string    `xml:"tptz:Timeout,omitempty"`
+}
+
+// ContinuousMove starts moving the PTZ unit of the profile with the given token at velocity until Stop is called or timeout passes.
+// timeout is an xsd:duration, e.g. PT5S. If empty, the device's default timeout is used
+func (c *Client) ContinuousMove(profileToken string, velocity *PTZSpeed, timeout string) error {
+	return c.ContinuousMoveContext(context.Background(), profileToken, velocity, timeout)
+}
+
+// ContinuousMoveContext is like ContinuousMove, but ctx controls the request
+func (c *Client) ContinuousMoveContext(ctx context.Context, profileToken string, velocity *PTZSpeed, timeout string) error {
+	return c.CallContext(ctx, &ContinuousMove{ProfileToken: profileToken, Velocity: velocity, Timeout: timeout}, nil)
+}
+
+// AbsoluteMove is an ONVIF AbsoluteMove operation
+type AbsoluteMove struct {
+	XMLName      xml.Name   `xml:"tptz:AbsoluteMove"`
+	ProfileToken string     `xml:"tptz:ProfileToken"`
+	Position     *PTZVector `xml:"tptz:Position"`
+	Speed        *PTZSpeed  `xml:"tptz:Speed,omitempty"`
+}
+
+// AbsoluteMove moves the PTZ unit of the profile with the given token to position. If speed is nil, the default speed is used
+func (c *Client) AbsoluteMove(profileToken string, position *PTZVector, speed *PTZSpeed) error {
+	return c.AbsoluteMoveContext(context.Background(), profileToken, position, speed)
+}
+
+// AbsoluteMoveContext is like AbsoluteMove, but ctx controls the request
+func (c *Client) AbsoluteMoveContext(ctx context.Context, profileToken string, position *PTZVector, speed *PTZSpeed) error {
+	return c.CallContext(ctx, &AbsoluteMove{ProfileToken: profileToken, Position: position, Speed: speed}, nil)
+}
+
+// RelativeMove is an ONVIF RelativeMove operation
+type RelativeMove struct {
+	XMLName      xml.Name   `xml:"tptz:RelativeMove"`
+	ProfileToken string     `xml:"tptz:ProfileToken"`
+	Translation  *PTZVector `xml:"tptz:Translation"`
+	Speed        *PTZSpeed  `xml:"tptz:Speed,omitempty"`
+}
+
+// RelativeMove moves the PTZ unit of the profile with the given token by translation. If speed is nil, the default speed is used
+func (c *Client) RelativeMove(profileToken string, translation *PTZVector, speed *PTZSpeed) error {
+	return c.RelativeMoveContext(context.Background(), profileToken, translation, speed)
+}
+
+// RelativeMoveContext is like RelativeMove, but ctx controls the request
+func (c *Client) RelativeMoveContext(ctx context.Context, profileToken string, translation *PTZVector, speed *PTZSpeed) error {
+	return c.CallContext(ctx, &RelativeMove{ProfileToken: profileToken, Translation: translation, Speed: speed}, nil)
+}
+
+// Stop is an ONVIF Stop operation
+type Stop struct {
+	XMLName      xml.Name `xml:"tptz:Stop"`
+	ProfileToken string   `xml:"tptz:ProfileToken"`
+	PanTilt      bool     `xml:"tptz:PanTilt"`
+	Zoom         bool     `xml:"tptz:Zoom"`
+}
+
+// Stop stops pan/tilt and/or zoom movement of the PTZ unit of the profile with the given token
+func (c *Client) Stop(profileToken string, panTilt, zoom bool) error {
+	return c.StopContext(context.Background(), profileToken, panTilt, zoom)
+}
+
+// StopContext is like Stop, but ctx controls the request
+func (c *Client) StopContext(ctx context.Context, profileToken string, panTilt, zoom bool) error {
+	return c.CallContext(ctx, &Stop{ProfileToken: profileToken, PanTilt: panTilt, Zoom: zoom}, nil)
+}
+
+// GetStatus is an ONVIF GetStatus operation
+type GetStatus struct {
+	XMLName      xml.Name `xml:"tptz:GetStatus"`
+	ProfileToken string   `xml:"tptz:ProfileToken"`
+}
+
+// GetStatusResponse is an ONVIF GetStatusResponse response
+type GetStatusResponse struct {
+	PTZStatus *PTZStatus
+}
+
+// GetStatus returns the position and move status of the PTZ unit of the profile with the given token
+func (c *Client) GetStatus(profileToken string) (*PTZStatus, error) {
+	return c.GetStatusContext(context.Background(), profileToken)
+}
+
+// GetStatusContext is like GetStatus, but ctx controls the request
+func (c *Client) GetStatusContext(ctx context.Context, profileToken string) (*PTZStatus, error) {
+	resp := new(GetStatusResponse)
+	if err := c.CallContext(ctx, &GetStatus{ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.PTZStatus, nil
+}
+
+// GotoHomePosition is an ONVIF GotoHomePosition operation
+type GotoHomePosition struct {
+	XMLName      xml.Name  `xml:"tptz:GotoHomePosition"`
+	ProfileToken string    `xml:"tptz:ProfileToken"`
+	Speed        *PTZSpeed `xml:"tptz:Speed,omitempty"`
+}
+
+// GotoHomePosition moves the PTZ unit of the profile with the given token to its home position. If speed is nil, the default speed is used
+func (c *Client) GotoHomePosition(profileToken string, speed *PTZSpeed) error {
+	return c.GotoHomePositionContext(context.Background(), profileToken, speed)
+}
+
+// GotoHomePositionContext is like GotoHomePosition, but ctx controls the request
+func (c *Client) GotoHomePositionContext(ctx context.Context, profileToken string, speed *PTZSpeed) error {
+	return c.CallContext(ctx, &GotoHomePosition{ProfileToken: profileToken, Speed: speed}, nil)
+}
@@ -0,0 +1,214 @@
+package ptz
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+)
+
+// DefaultPatrolCheckInterval is how often a patrol outside its active times checks if it should resume
+const DefaultPatrolCheckInterval = time.Minute
+
+// PatrolStop is a position a patrol moves to
+type PatrolStop struct {
+	// PresetToken, if set, is the preset to move to. Otherwise Position is used
+	PresetToken string
+	Position    *PTZVector
+	// Speed, if set, is the speed to move at
+	Speed *PTZSpeed
+	// Dwell is how long to stay at the stop before moving to the next one
+	Dwell time.Duration
+}
+
+// Patrol drives a PTZ unit through a list of stops in a loop, for devices without native tour support.
+// Pause and Resume can be called concurrently with Run, e.g. when a manual operation is detected.
+// See PauseOn to pause automatically on manual operation events
+type Patrol struct {
+	Client       *Client
+	ProfileToken string
+	Stops        []*PatrolStop
+	// Active, if set, restricts the patrol to times it returns true for, e.g. business hours
+	Active func(t time.Time) bool
+	// CheckInterval is how often Active is checked while inactive. If zero, DefaultPatrolCheckInterval is used
+	CheckInterval time.Duration
+	// OnError, if set, is called with errors moving to a stop and the patrol continues. Otherwise Run returns the error
+	OnError func(stop *PatrolStop, err error)
+	// Clock is used for dwell times and pauses. If nil, onvif.SystemClock is used
+	Clock onvif.Clock
+
+	mu            sync.Mutex
+	pausedUntil   time.Time
+	pausedForever bool
+	wake          chan struct{}
+	// moving is true while Run is sending a move request
+	moving bool
+}
+
+// Pause pauses the patrol for d, or until Resume is called if d is less than or equal to zero.
+// A patrol dwelling at a stop moves to the next stop when it's resumed
+func (p *Patrol) Pause(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if d <= 0 {
+		p.pausedForever = true
+	} else if until := p.clock().Now().Add(d); until.After(p.pausedUntil) {
+		p.pausedUntil = until
+	}
+	p.wakeLocked()
+}
+
+// Resume resumes a paused patrol
+func (p *Patrol) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pausedForever = false
+	p.pausedUntil = time.Time{}
+	p.wakeLocked()
+}
+
+// PauseOn pauses the patrol for d (see Pause) for each notification from notifications that match returns true for, so the patrol yields to manual operation.
+// notifications is usually from an events.NotificationServer subscribed to the device's PTZ or operator topics. If match is nil, all notifications match.
+// Notifications received while the patrol is sending a move are ignored, since they're likely caused by the patrol itself.
+// It returns when notifications is closed or ctx is done, so it's usually run in its own goroutine alongside Run
+func (p *Patrol) PauseOn(ctx context.Context, notifications <-chan *events.Notification, match func(n *events.Notification) bool, d time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if match != nil && !match(n) {
+				continue
+			}
+			p.mu.Lock()
+			moving := p.moving
+			p.mu.Unlock()
+			if !moving {
+				p.Pause(d)
+			}
+		}
+	}
+}
+
+// Paused returns true if the patrol is paused
+func (p *Patrol) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pausedForever || p.clock().Now().Before(p.pausedUntil)
+}
+
+// wakeLocked wakes a waiting Run. p.mu must be held
+func (p *Patrol) wakeLocked() {
+	if p.wake != nil {
+		close(p.wake)
+	}
+	p.wake = make(chan struct{})
+}
+
+// wakeChan returns a channel that is closed when the patrol is paused or resumed
+func (p *Patrol) wakeChan() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.wake == nil {
+		p.wake = make(chan struct{})
+	}
+	return p.wake
+}
+
+func (p *Patrol) clock() onvif.Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+	return onvif.SystemClock
+}
+
+// Run runs the patrol until ctx is done or an error occurs
+func (p *Patrol) Run(ctx context.Context) error {
+	if len(p.Stops) == 0 {
+		return errors.New("patrol has no stops")
+	}
+
+	for i := 0; ; i = (i + 1) % len(p.Stops) {
+		if err := p.waitReady(ctx); err != nil {
+			return err
+		}
+
+		stop := p.Stops[i]
+		if err := p.move(ctx, stop); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if p.OnError == nil {
+				return err
+			}
+			p.OnError(stop, err)
+		}
+
+		wake := p.wakeChan()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock().After(stop.Dwell):
+		case <-wake:
+		}
+	}
+}
+
+// waitReady waits until the patrol isn't paused and is active
+func (p *Patrol) waitReady(ctx context.Context) error {
+	for {
+		wake := p.wakeChan()
+
+		p.mu.Lock()
+		now := p.clock().Now()
+		forever, until := p.pausedForever, p.pausedUntil
+		p.mu.Unlock()
+
+		var after <-chan time.Time
+		switch {
+		case forever:
+		case now.Before(until):
+			after = p.clock().After(until.Sub(now))
+		case p.Active != nil && !p.Active(now):
+			interval := p.CheckInterval
+			if interval <= 0 {
+				interval = DefaultPatrolCheckInterval
+			}
+			after = p.clock().After(interval)
+		default:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-after:
+		}
+	}
+}
+
+// move moves to stop. ctx controls the request
+func (p *Patrol) move(ctx context.Context, stop *PatrolStop) error {
+	p.mu.Lock()
+	p.moving = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.moving = false
+		p.mu.Unlock()
+	}()
+
+	if stop.PresetToken != "" {
+		return p.Client.GotoPresetContext(ctx, p.ProfileToken, stop.PresetToken, stop.Speed)
+	}
+	return p.Client.AbsoluteMoveContext(ctx, p.ProfileToken, stop.Position, stop.Speed)
+}
@@ -0,0 +1,162 @@
+package ptz_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/ptz"
+)
+
+const responseEmpty = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+<env:Body><GotoPresetResponse/></env:Body>
+</env:Envelope>`
+
+var presetRegexp = regexp.MustCompile(`<tptz:PresetToken>([^<]*)</tptz:PresetToken>`)
+
+func TestPatrol(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		presets []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		if m := presetRegexp.FindSubmatch(buf); m != nil {
+			mu.Lock()
+			presets = append(presets, string(m[1]))
+			mu.Unlock()
+		}
+		w.Write([]byte(responseEmpty))
+	}))
+	defer srv.Close()
+
+	c, err := ptz.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespacePTZ, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	p := &ptz.Patrol{
+		Client:       c,
+		ProfileToken: "profile",
+		Stops: []*ptz.PatrolStop{
+			{PresetToken: "1", Dwell: 10 * time.Millisecond},
+			{PresetToken: "2", Dwell: 10 * time.Millisecond},
+		},
+	}
+
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(presets)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+
+	time.Sleep(50 * time.Millisecond)
+	p.Pause(0)
+	time.Sleep(20 * time.Millisecond)
+	paused := count()
+	time.Sleep(50 * time.Millisecond)
+	if n := count(); n != paused {
+		t.Errorf("expected no moves while paused, got %d", n-paused)
+	}
+
+	p.Resume()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(presets) <= paused {
+		t.Error("expected moves after resume")
+	}
+	for i, preset := range presets[:2] {
+		if expected := []string{"1", "2"}[i]; preset != expected {
+			t.Errorf("expected preset %s, got %s", expected, preset)
+		}
+	}
+}
+
+func TestPatrolCancelMove(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the device hangs until the request is canceled
+		close(started)
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c, err := ptz.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespacePTZ, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	var onError bool
+	p := &ptz.Patrol{
+		Client:       c,
+		ProfileToken: "profile",
+		Stops:        []*ptz.PatrolStop{{PresetToken: "1"}},
+		OnError:      func(*ptz.PatrolStop, error) { onError = true },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- p.Run(ctx) }()
+
+	<-started
+	cancel()
+	select {
+	case err = <-errc:
+		if err != context.Canceled {
+			t.Errorf("expected context canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected in-progress move to be canceled")
+	}
+	if onError {
+		t.Error("expected OnError not to be called for a canceled move")
+	}
+}
+
+func TestPatrolPauseOn(t *testing.T) {
+	p := new(ptz.Patrol)
+	notifications := make(chan *events.Notification)
+	done := make(chan struct{})
+	go func() {
+		p.PauseOn(context.Background(), notifications, func(n *events.Notification) bool {
+			return n.Is("PTZController/PTZPresets")
+		}, time.Minute)
+		close(done)
+	}()
+
+	notifications <- &events.Notification{Topic: "tns1:RuleEngine/CellMotionDetector/Motion"}
+	notifications <- &events.Notification{Topic: "tns1:RuleEngine/CellMotionDetector/Motion"}
+	if p.Paused() {
+		t.Error("expected patrol not to be paused by unmatched notification")
+	}
+
+	notifications <- &events.Notification{Topic: "tns1:PTZController/PTZPresets/Invoked"}
+	close(notifications)
+	<-done
+	if !p.Paused() {
+		t.Error("expected patrol to be paused by matched notification")
+	}
+}
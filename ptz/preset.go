@@ -0,0 +1,94 @@
+package ptz
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetPresets is an ONVIF GetPresets operation
+type GetPresets struct {
+	XMLName      xml.Name `xml:"tptz:GetPresets"`
+	ProfileToken string   `xml:"tptz:ProfileToken"`
+}
+
+// GetPresetsResponse is an ONVIF GetPresetsResponse response
+type GetPresetsResponse struct {
+	Preset []*PTZPreset
+}
+
+// GetPresets returns the presets of the profile with the given token
+func (c *Client) GetPresets(profileToken string) ([]*PTZPreset, error) {
+	return c.GetPresetsContext(context.Background(), profileToken)
+}
+
+// GetPresetsContext is like GetPresets, but ctx controls the request
+func (c *Client) GetPresetsContext(ctx context.Context, profileToken string) ([]*PTZPreset, error) {
+	resp := new(GetPresetsResponse)
+	if err := c.CallContext(ctx, &GetPresets{ProfileToken: profileToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Preset, nil
+}
+
+// SetPreset is an ONVIF SetPreset operation
+type SetPreset struct {
+	XMLName      xml.Name `xml:"tptz:SetPreset"`
+	ProfileToken string   `xml:"tptz:ProfileToken"`
+	PresetName   string   `xml:"tptz:PresetName,omitempty"`
+	PresetToken  string   `xml:"tptz:PresetToken,omitempty"`
+}
+
+// SetPresetResponse is an ONVIF SetPresetResponse response
+type SetPresetResponse struct {
+	PresetToken string
+}
+
+// SetPreset saves the current position of the profile with the given token as a preset, returning the preset token.
+// If presetToken is set, the existing preset is overwritten. name and presetToken may be empty
+func (c *Client) SetPreset(profileToken, name, presetToken string) (string, error) {
+	return c.SetPresetContext(context.Background(), profileToken, name, presetToken)
+}
+
+// SetPresetContext is like SetPreset, but ctx controls the request
+func (c *Client) SetPresetContext(ctx context.Context, profileToken, name, presetToken string) (string, error) {
+	resp := new(SetPresetResponse)
+	if err := c.CallContext(ctx, &SetPreset{ProfileToken: profileToken, PresetName: name, PresetToken: presetToken}, resp); err != nil {
+		return "", err
+	}
+	return resp.PresetToken, nil
+}
+
+// GotoPreset is an ONVIF GotoPreset operation
+type GotoPreset struct {
+	XMLName      xml.Name  `xml:"tptz:GotoPreset"`
+	ProfileToken string    `xml:"tptz:ProfileToken"`
+	PresetToken  string    `xml:"tptz:PresetToken"`
+	Speed        *PTZSpeed `xml:"tptz:Speed,omitempty"`
+}
+
+// GotoPreset moves the PTZ unit of the profile with the given token to the preset. If speed is nil, the default speed is used
+func (c *Client) GotoPreset(profileToken, presetToken string, speed *PTZSpeed) error {
+	return c.GotoPresetContext(context.Background(), profileToken, presetToken, speed)
+}
+
+// GotoPresetContext is like GotoPreset, but ctx controls the request
+func (c *Client) GotoPresetContext(ctx context.Context, profileToken, presetToken string, speed *PTZSpeed) error {
+	return c.CallContext(ctx, &GotoPreset{ProfileToken: profileToken, PresetToken: presetToken, Speed: speed}, nil)
+}
+
+// RemovePreset is an ONVIF RemovePreset operation
+type RemovePreset struct {
+	XMLName      xml.Name `xml:"tptz:RemovePreset"`
+	ProfileToken string   `xml:"tptz:ProfileToken"`
+	PresetToken  string   `xml:"tptz:PresetToken"`
+}
+
+// RemovePreset removes the preset from the profile with the given token
+func (c *Client) RemovePreset(profileToken, presetToken string) error {
+	return c.RemovePresetContext(context.Background(), profileToken, presetToken)
+}
+
+// RemovePresetContext is like RemovePreset, but ctx controls the request
+func (c *Client) RemovePresetContext(ctx context.Context, profileToken, presetToken string) error {
+	return c.CallContext(ctx, &RemovePreset{ProfileToken: profileToken, PresetToken: presetToken}, nil)
+}
@@ -0,0 +1,26 @@
+// Package ptz implements typed operations for the ONVIF PTZ (ver20) service
+package ptz
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF PTZ service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new PTZ service client using c to make requests to the PTZ service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespacePTZ, soap.Namespaces{"tptz": onvif.NamespacePTZ, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new PTZ service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
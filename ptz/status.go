@@ -0,0 +1,90 @@
+package ptz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+)
+
+// StatusPollInterval is how often WaitForIdle polls GetStatus
+const StatusPollInterval = 250 * time.Millisecond
+
+// PTZ event topics. WaitForIdle checks the status when it receives them
+const (
+	TopicMoveStatus    = "PTZController/MoveStatus"
+	TopicPresetReached = "PTZController/PTZPresets/Reached"
+)
+
+// ErrNoMoveStatus is returned (wrapped) by WaitForIdle if the device doesn't report the move status,
+// i.e. the pan/tilt and zoom statuses are both missing or UNKNOWN. Check Capabilities.MoveStatus before relying on WaitForIdle
+var ErrNoMoveStatus = errors.New("move status not reported")
+
+// Idle returns true if the PTZ unit isn't moving, i.e. each reported status is IDLE. It returns an error wrapping ErrNoMoveStatus
+// if neither status is reported
+func (s *PTZStatus) Idle() (bool, error) {
+	var idle bool
+	for _, status := range []MoveStatus{s.PanTiltStatus, s.ZoomStatus} {
+		switch status {
+		case MoveStatusMoving:
+			return false, nil
+		case MoveStatusIdle:
+			idle = true
+		}
+	}
+	if !idle {
+		return false, fmt.Errorf("could not check status: %w", ErrNoMoveStatus)
+	}
+	return true, nil
+}
+
+// WaitForIdle polls GetStatus every StatusPollInterval until the PTZ unit of the profile with the given token is idle, or ctx is done.
+// notifications may be nil, or a channel of notifications (e.g. from an events.NotificationServer) that cause the status to be checked immediately
+// if they're a TopicMoveStatus or TopicPresetReached event, so sequences like "go to preset, wait, take snapshot" don't wait for the next poll.
+// Some devices report IDLE briefly after a move request before they start moving
+func (c *Client) WaitForIdle(ctx context.Context, profileToken string, notifications <-chan *events.Notification) error {
+	for {
+		status, err := c.GetStatusContext(ctx, profileToken)
+		if err != nil {
+			return fmt.Errorf("could not get status: %w", err)
+		}
+		if status == nil {
+			return fmt.Errorf("could not check status: %w", ErrNoMoveStatus)
+		}
+		if idle, err := status.Idle(); err != nil || idle {
+			return err
+		}
+
+		if err = c.waitStatus(ctx, notifications); err != nil {
+			return err
+		}
+	}
+}
+
+// waitStatus waits for StatusPollInterval, or until a PTZ move notification is received from notifications, which may be nil
+func (c *Client) waitStatus(ctx context.Context, notifications <-chan *events.Notification) error {
+	clock := c.Clock
+	if clock == nil {
+		clock = onvif.SystemClock
+	}
+	after := clock.After(StatusPollInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-after:
+			return nil
+		case n, ok := <-notifications:
+			if !ok {
+				notifications = nil
+				continue
+			}
+			if n.Is(TopicMoveStatus) || n.Is(TopicPresetReached) {
+				return nil
+			}
+		}
+	}
+}
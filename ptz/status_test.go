@@ -0,0 +1,81 @@
+package ptz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/ptz"
+)
+
+// blockingClock is a Clock whose After never fires
+type blockingClock struct{}
+
+func (blockingClock) Now() time.Time {
+	return time.Now()
+}
+
+func (blockingClock) After(time.Duration) <-chan time.Time {
+	return nil
+}
+
+func TestWaitForIdle(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		statuses []string
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		requests++
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body><GetStatusResponse><PTZStatus>%s</PTZStatus></GetStatusResponse></env:Body>
+</env:Envelope>`, status)
+	}))
+	defer srv.Close()
+
+	// polls never fire, so only notifications cause the status to be checked again
+	c, err := ptz.NewClient(&onvif.Client{Clock: blockingClock{}}, onvif.Services{{Namespace: onvif.NamespacePTZ, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	statuses = []string{
+		"<tt:MoveStatus><tt:PanTilt>MOVING</tt:PanTilt><tt:Zoom>IDLE</tt:Zoom></tt:MoveStatus>",
+		"<tt:MoveStatus><tt:PanTilt>IDLE</tt:PanTilt></tt:MoveStatus>",
+	}
+	notifications := make(chan *events.Notification, 2)
+	notifications <- &events.Notification{Topic: "tns1:RuleEngine/CellMotionDetector/Motion"}
+	notifications <- &events.Notification{Topic: "tns1:PTZController/PTZPresets/Reached"}
+	if err = c.WaitForIdle(context.Background(), "profile", notifications); err != nil {
+		t.Errorf("could not wait for idle: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 status requests, got %d", requests)
+	}
+
+	statuses = []string{"<tt:MoveStatus><tt:PanTilt>UNKNOWN</tt:PanTilt></tt:MoveStatus>"}
+	if err = c.WaitForIdle(context.Background(), "profile", nil); !errors.Is(err, ptz.ErrNoMoveStatus) {
+		t.Errorf("expected no move status error, got %v", err)
+	}
+
+	statuses = []string{"<tt:MoveStatus><tt:PanTilt>MOVING</tt:PanTilt></tt:MoveStatus>"}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err = c.WaitForIdle(ctx, "profile", nil); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package ptz
+
+import "encoding/xml"
+
+// Vector2D is an ONVIF Vector2D type
+type Vector2D struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+	// Space is the coordinate space URI. If empty, the device's default space is used
+	Space string `xml:"space,attr,omitempty"`
+}
+
+// Vector1D is an ONVIF Vector1D type
+type Vector1D struct {
+	X float64 `xml:"x,attr"`
+	// Space is the coordinate space URI. If empty, the device's default space is used
+	Space string `xml:"space,attr,omitempty"`
+}
+
+// PTZVector is an ONVIF PTZVector type. Either PanTilt or Zoom may be nil
+type PTZVector struct {
+	PanTilt *Vector2D
+	Zoom    *Vector1D
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (v *PTZVector) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	p := struct {
+		PanTilt *Vector2D `xml:"tt:PanTilt,omitempty"`
+		Zoom    *Vector1D `xml:"tt:Zoom,omitempty"`
+	}{v.PanTilt, v.Zoom}
+	return enc.EncodeElement(p, start)
+}
+
+// PTZSpeed is an ONVIF PTZSpeed type, which has the same structure as PTZVector
+type PTZSpeed = PTZVector
+
+// MoveStatus is an ONVIF MoveStatus
+type MoveStatus string
+
+// ONVIF move statuses
+const (
+	MoveStatusIdle    MoveStatus = "IDLE"
+	MoveStatusMoving  MoveStatus = "MOVING"
+	MoveStatusUnknown MoveStatus = "UNKNOWN"
+)
+
+// PTZStatus is an ONVIF PTZStatus type
+type PTZStatus struct {
+	Position      *PTZVector
+	PanTiltStatus MoveStatus `xml:"MoveStatus>PanTilt"`
+	ZoomStatus    MoveStatus `xml:"MoveStatus>Zoom"`
+	Error         string
+	// UTCTime is an xsd:dateTime
+	UTCTime string `xml:"UtcTime"`
+}
+
+// PTZPreset is an ONVIF PTZPreset type
+type PTZPreset struct {
+	Token       string `xml:"token,attr"`
+	Name        string
+	PTZPosition *PTZVector
+}
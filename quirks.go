@@ -0,0 +1,97 @@
+package onvif
+
+import (
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// Quirks adjusts Client behavior for non-conformant devices. See Client.ApplyQuirks
+type Quirks struct {
+	// ContentType, if set, overrides the request Content-Type, e.g. "text/xml; charset=utf-8"
+	ContentType string
+	// AuthMode, if not AuthModeNone, forces the authentication mode instead of detecting it
+	AuthMode AuthMode
+	// DisableKeepAlives closes the connection after each request, for devices with broken keep-alive handling
+	DisableKeepAlives bool
+	// RewriteXAddrHost replaces the host and port of service URLs returned by GetServices and GetCapabilities with the address used to reach the device,
+	// for devices that report internal or incorrect addresses
+	RewriteXAddrHost bool
+}
+
+// QuirksProfile is a set of Quirks for devices matching a manufacturer and model
+type QuirksProfile struct {
+	// Manufacturer matches the device manufacturer returned by GetDeviceInformation. If nil, any manufacturer matches
+	Manufacturer *regexp.Regexp
+	// Model matches the device model returned by GetDeviceInformation. If nil, any model matches
+	Model  *regexp.Regexp
+	Quirks *Quirks
+}
+
+var (
+	quirksMu sync.RWMutex
+	// quirksRegistry is the list of profiles, in order of precedence
+	quirksRegistry = []*QuirksProfile{
+		{Manufacturer: regexp.MustCompile(`(?i)^hikvision`), Quirks: &Quirks{AuthMode: AuthModeWSSecurity}},
+		{Manufacturer: regexp.MustCompile(`(?i)^dahua`), Quirks: &Quirks{AuthMode: AuthModeWSSecurity}},
+		{Manufacturer: regexp.MustCompile(`(?i)^(xiongmai|xm$)`), Quirks: &Quirks{DisableKeepAlives: true, RewriteXAddrHost: true}},
+	}
+)
+
+// RegisterQuirks registers a quirks profile. Registered profiles take precedence over built-in and previously registered profiles
+func RegisterQuirks(p *QuirksProfile) {
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+	quirksRegistry = append([]*QuirksProfile{p}, quirksRegistry...)
+}
+
+// LookupQuirks returns the Quirks of the first registered profile matching the manufacturer and model, or nil if none match
+func LookupQuirks(manufacturer, model string) *Quirks {
+	quirksMu.RLock()
+	defer quirksMu.RUnlock()
+
+	for _, p := range quirksRegistry {
+		if p.Manufacturer != nil && !p.Manufacturer.MatchString(manufacturer) {
+			continue
+		}
+		if p.Model != nil && !p.Model.MatchString(model) {
+			continue
+		}
+		return p.Quirks
+	}
+
+	return nil
+}
+
+// ApplyQuirks sets Client.Quirks to q and applies any forced AuthMode. q may be nil to clear quirks.
+// It's safe to call while requests are in flight
+func (c *Client) ApplyQuirks(q *Quirks) {
+	c.versionMu.Lock()
+	c.Quirks = q
+	c.versionMu.Unlock()
+
+	if q != nil && q.AuthMode != AuthModeNone {
+		c.authMu.Lock()
+		c.AuthMode = q.AuthMode
+		c.authMu.Unlock()
+	}
+}
+
+// CurrentQuirks returns Client.Quirks. Use it instead of reading the field while requests are in flight, since ApplyQuirks updates it
+func (c *Client) CurrentQuirks() *Quirks {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.Quirks
+}
+
+// rewriteHost replaces the host of each service URL with addr
+func (s Services) rewriteHost(addr string) {
+	for _, svc := range s {
+		u, err := url.Parse(svc.URL)
+		if err != nil {
+			continue
+		}
+		u.Host = addr
+		svc.URL = u.String()
+	}
+}
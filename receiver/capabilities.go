@@ -0,0 +1,39 @@
+package receiver
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF receiver Capabilities type
+type Capabilities struct {
+	RTPMulticast         bool `xml:"RTP_Multicast,attr"`
+	RTPTCP               bool `xml:"RTP_TCP,attr"`
+	RTPRTSPTCP           bool `xml:"RTP_RTSP_TCP,attr"`
+	SupportedReceivers   int  `xml:"SupportedReceivers,attr"`
+	MaximumRTSPURILength int  `xml:"MaximumRTSPURILength,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"trv:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the receiver service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
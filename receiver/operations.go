@@ -0,0 +1,130 @@
+package receiver
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// GetReceivers is an ONVIF GetReceivers operation
+type GetReceivers struct {
+	XMLName xml.Name `xml:"trv:GetReceivers"`
+}
+
+// GetReceiversResponse is an ONVIF GetReceiversResponse response
+type GetReceiversResponse struct {
+	Receivers []*Receiver
+}
+
+// GetReceivers returns the device's receivers
+func (c *Client) GetReceivers() ([]*Receiver, error) {
+	return c.GetReceiversContext(context.Background())
+}
+
+// GetReceiversContext is like GetReceivers, but ctx controls the request
+func (c *Client) GetReceiversContext(ctx context.Context) ([]*Receiver, error) {
+	resp := new(GetReceiversResponse)
+	if err := c.CallContext(ctx, &GetReceivers{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Receivers, nil
+}
+
+// CreateReceiver is an ONVIF CreateReceiver operation
+type CreateReceiver struct {
+	XMLName       xml.Name       `xml:"trv:CreateReceiver"`
+	Configuration *Configuration `xml:"trv:Configuration"`
+}
+
+// CreateReceiverResponse is an ONVIF CreateReceiverResponse response
+type CreateReceiverResponse struct {
+	Receiver *Receiver
+}
+
+// CreateReceiver creates a receiver with the given configuration, returning it with its token
+func (c *Client) CreateReceiver(config *Configuration) (*Receiver, error) {
+	return c.CreateReceiverContext(context.Background(), config)
+}
+
+// CreateReceiverContext is like CreateReceiver, but ctx controls the request
+func (c *Client) CreateReceiverContext(ctx context.Context, config *Configuration) (*Receiver, error) {
+	resp := new(CreateReceiverResponse)
+	if err := c.CallContext(ctx, &CreateReceiver{Configuration: config}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Receiver, nil
+}
+
+// DeleteReceiver is an ONVIF DeleteReceiver operation
+type DeleteReceiver struct {
+	XMLName       xml.Name `xml:"trv:DeleteReceiver"`
+	ReceiverToken string   `xml:"trv:ReceiverToken"`
+}
+
+// DeleteReceiver deletes the receiver with the given token
+func (c *Client) DeleteReceiver(token string) error {
+	return c.DeleteReceiverContext(context.Background(), token)
+}
+
+// DeleteReceiverContext is like DeleteReceiver, but ctx controls the request
+func (c *Client) DeleteReceiverContext(ctx context.Context, token string) error {
+	return c.CallContext(ctx, &DeleteReceiver{ReceiverToken: token}, nil)
+}
+
+// ConfigureReceiver is an ONVIF ConfigureReceiver operation
+type ConfigureReceiver struct {
+	XMLName       xml.Name       `xml:"trv:ConfigureReceiver"`
+	ReceiverToken string         `xml:"trv:ReceiverToken"`
+	Configuration *Configuration `xml:"trv:Configuration"`
+}
+
+// ConfigureReceiver replaces the configuration of the receiver with the given token
+func (c *Client) ConfigureReceiver(token string, config *Configuration) error {
+	return c.ConfigureReceiverContext(context.Background(), token, config)
+}
+
+// ConfigureReceiverContext is like ConfigureReceiver, but ctx controls the request
+func (c *Client) ConfigureReceiverContext(ctx context.Context, token string, config *Configuration) error {
+	return c.CallContext(ctx, &ConfigureReceiver{ReceiverToken: token, Configuration: config}, nil)
+}
+
+// SetReceiverMode is an ONVIF SetReceiverMode operation
+type SetReceiverMode struct {
+	XMLName       xml.Name `xml:"trv:SetReceiverMode"`
+	ReceiverToken string   `xml:"trv:ReceiverToken"`
+	Mode          Mode     `xml:"trv:Mode"`
+}
+
+// SetReceiverMode sets the connection mode of the receiver with the given token
+func (c *Client) SetReceiverMode(token string, mode Mode) error {
+	return c.SetReceiverModeContext(context.Background(), token, mode)
+}
+
+// SetReceiverModeContext is like SetReceiverMode, but ctx controls the request
+func (c *Client) SetReceiverModeContext(ctx context.Context, token string, mode Mode) error {
+	return c.CallContext(ctx, &SetReceiverMode{ReceiverToken: token, Mode: mode}, nil)
+}
+
+// GetReceiverState is an ONVIF GetReceiverState operation
+type GetReceiverState struct {
+	XMLName       xml.Name `xml:"trv:GetReceiverState"`
+	ReceiverToken string   `xml:"trv:ReceiverToken"`
+}
+
+// GetReceiverStateResponse is an ONVIF GetReceiverStateResponse response
+type GetReceiverStateResponse struct {
+	ReceiverState *StateInformation
+}
+
+// GetReceiverState returns the connection state of the receiver with the given token
+func (c *Client) GetReceiverState(token string) (*StateInformation, error) {
+	return c.GetReceiverStateContext(context.Background(), token)
+}
+
+// GetReceiverStateContext is like GetReceiverState, but ctx controls the request
+func (c *Client) GetReceiverStateContext(ctx context.Context, token string) (*StateInformation, error) {
+	resp := new(GetReceiverStateResponse)
+	if err := c.CallContext(ctx, &GetReceiverState{ReceiverToken: token}, resp); err != nil {
+		return nil, err
+	}
+	return resp.ReceiverState, nil
+}
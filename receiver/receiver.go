@@ -0,0 +1,26 @@
+// Package receiver implements typed operations for the ONVIF Receiver (ver10) service
+package receiver
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Receiver service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Receiver service client using c to make requests to the Receiver service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceReceiver, soap.Namespaces{"trv": onvif.NamespaceReceiver, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Receiver service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
@@ -0,0 +1,63 @@
+package receiver
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Mode is an ONVIF ReceiverMode
+type Mode string
+
+// Modes
+const (
+	// ModeAutoConnect connects the receiver when its stream is needed, e.g. when it's shown on a display pane
+	ModeAutoConnect   Mode = "AutoConnect"
+	ModeAlwaysConnect Mode = "AlwaysConnect"
+	ModeNeverConnect  Mode = "NeverConnect"
+)
+
+// State is an ONVIF ReceiverState
+type State string
+
+// States
+const (
+	StateNotConnected State = "NotConnected"
+	StateConnecting   State = "Connecting"
+	StateConnected    State = "Connected"
+	StateUnknown      State = "Unknown"
+)
+
+// StreamSetup is an ONVIF StreamSetup type. Unlike media.StreamSetup, it can be decoded from responses
+type StreamSetup struct {
+	Stream   media.StreamType
+	Protocol media.TransportProtocol `xml:"Transport>Protocol"`
+}
+
+// Configuration is an ONVIF ReceiverConfiguration type
+type Configuration struct {
+	Mode Mode
+	// MediaURI is the RTSP URI of the stream, e.g. from media.Client.GetStreamUri
+	MediaURI    string `xml:"MediaUri"`
+	StreamSetup *StreamSetup
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (c *Configuration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type configuration Configuration
+	return soap.EncodeElementPrefixed(enc, (*configuration)(c), start, "tt")
+}
+
+// Receiver is an ONVIF Receiver type
+type Receiver struct {
+	Token         string
+	Configuration *Configuration
+}
+
+// StateInformation is an ONVIF ReceiverStateInformation type
+type StateInformation struct {
+	State State
+	// AutoCreated is true if the receiver was created by the device, e.g. for a display pane
+	AutoCreated bool
+}
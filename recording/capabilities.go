@@ -0,0 +1,47 @@
+package recording
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF recording Capabilities type
+type Capabilities struct {
+	DynamicRecordings bool `xml:"DynamicRecordings,attr"`
+	DynamicTracks     bool `xml:"DynamicTracks,attr"`
+	// Encoding is a space separated list of supported encodings, e.g. H264 G711
+	Encoding                   string  `xml:"Encoding,attr"`
+	MaxRate                    float64 `xml:"MaxRate,attr"`
+	MaxTotalRate               float64 `xml:"MaxTotalRate,attr"`
+	MaxRecordings              int     `xml:"MaxRecordings,attr"`
+	MaxRecordingJobs           int     `xml:"MaxRecordingJobs,attr"`
+	Options                    bool    `xml:"Options,attr"`
+	MetadataRecording          bool    `xml:"MetadataRecording,attr"`
+	SupportedExportFileFormats string  `xml:"SupportedExportFileFormats,attr"`
+	// EventRecording indicates the device supports RecordingJobConfiguration.EventFilter
+	EventRecording bool `xml:"EventRecording,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"trc:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the recording service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
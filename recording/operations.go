@@ -0,0 +1,218 @@
+package recording
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// CreateRecording is an ONVIF CreateRecording operation
+type CreateRecording struct {
+	XMLName                xml.Name                `xml:"trc:CreateRecording"`
+	RecordingConfiguration *RecordingConfiguration `xml:"trc:RecordingConfiguration"`
+}
+
+// CreateRecordingResponse is an ONVIF CreateRecordingResponse response
+type CreateRecordingResponse struct {
+	RecordingToken string
+}
+
+// CreateRecording creates a recording with the given configuration, returning the recording token.
+// Devices usually create default tracks for a new recording
+func (c *Client) CreateRecording(config *RecordingConfiguration) (string, error) {
+	return c.CreateRecordingContext(context.Background(), config)
+}
+
+// CreateRecordingContext is like CreateRecording, but ctx controls the request
+func (c *Client) CreateRecordingContext(ctx context.Context, config *RecordingConfiguration) (string, error) {
+	resp := new(CreateRecordingResponse)
+	if err := c.CallContext(ctx, &CreateRecording{RecordingConfiguration: config}, resp); err != nil {
+		return "", err
+	}
+	return resp.RecordingToken, nil
+}
+
+// DeleteRecording is an ONVIF DeleteRecording operation
+type DeleteRecording struct {
+	XMLName        xml.Name `xml:"trc:DeleteRecording"`
+	RecordingToken string   `xml:"trc:RecordingToken"`
+}
+
+// DeleteRecording deletes the recording with the given token, including its tracks and recorded data
+func (c *Client) DeleteRecording(recordingToken string) error {
+	return c.DeleteRecordingContext(context.Background(), recordingToken)
+}
+
+// DeleteRecordingContext is like DeleteRecording, but ctx controls the request
+func (c *Client) DeleteRecordingContext(ctx context.Context, recordingToken string) error {
+	return c.CallContext(ctx, &DeleteRecording{RecordingToken: recordingToken}, nil)
+}
+
+// GetRecordings is an ONVIF GetRecordings operation
+type GetRecordings struct {
+	XMLName xml.Name `xml:"trc:GetRecordings"`
+}
+
+// GetRecordingsResponse is an ONVIF GetRecordingsResponse response
+type GetRecordingsResponse struct {
+	RecordingItem []*Recording
+}
+
+// GetRecordings returns the device's recordings and their tracks
+func (c *Client) GetRecordings() ([]*Recording, error) {
+	return c.GetRecordingsContext(context.Background())
+}
+
+// GetRecordingsContext is like GetRecordings, but ctx controls the request
+func (c *Client) GetRecordingsContext(ctx context.Context) ([]*Recording, error) {
+	resp := new(GetRecordingsResponse)
+	if err := c.CallContext(ctx, &GetRecordings{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.RecordingItem, nil
+}
+
+// CreateTrack is an ONVIF CreateTrack operation
+type CreateTrack struct {
+	XMLName            xml.Name            `xml:"trc:CreateTrack"`
+	RecordingToken     string              `xml:"trc:RecordingToken"`
+	TrackConfiguration *TrackConfiguration `xml:"trc:TrackConfiguration"`
+}
+
+// CreateTrackResponse is an ONVIF CreateTrackResponse response
+type CreateTrackResponse struct {
+	TrackToken string
+}
+
+// CreateTrack adds a track to the recording with the given token, returning the track token
+func (c *Client) CreateTrack(recordingToken string, config *TrackConfiguration) (string, error) {
+	return c.CreateTrackContext(context.Background(), recordingToken, config)
+}
+
+// CreateTrackContext is like CreateTrack, but ctx controls the request
+func (c *Client) CreateTrackContext(ctx context.Context, recordingToken string, config *TrackConfiguration) (string, error) {
+	resp := new(CreateTrackResponse)
+	if err := c.CallContext(ctx, &CreateTrack{RecordingToken: recordingToken, TrackConfiguration: config}, resp); err != nil {
+		return "", err
+	}
+	return resp.TrackToken, nil
+}
+
+// GetRecordingJobs is an ONVIF GetRecordingJobs operation
+type GetRecordingJobs struct {
+	XMLName xml.Name `xml:"trc:GetRecordingJobs"`
+}
+
+// GetRecordingJobsResponse is an ONVIF GetRecordingJobsResponse response
+type GetRecordingJobsResponse struct {
+	JobItem []*RecordingJob
+}
+
+// GetRecordingJobs returns the device's recording jobs
+func (c *Client) GetRecordingJobs() ([]*RecordingJob, error) {
+	return c.GetRecordingJobsContext(context.Background())
+}
+
+// GetRecordingJobsContext is like GetRecordingJobs, but ctx controls the request
+func (c *Client) GetRecordingJobsContext(ctx context.Context) ([]*RecordingJob, error) {
+	resp := new(GetRecordingJobsResponse)
+	if err := c.CallContext(ctx, &GetRecordingJobs{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.JobItem, nil
+}
+
+// CreateRecordingJob is an ONVIF CreateRecordingJob operation
+type CreateRecordingJob struct {
+	XMLName          xml.Name                   `xml:"trc:CreateRecordingJob"`
+	JobConfiguration *RecordingJobConfiguration `xml:"trc:JobConfiguration"`
+}
+
+// CreateRecordingJobResponse is an ONVIF CreateRecordingJobResponse response
+type CreateRecordingJobResponse struct {
+	JobToken         string
+	JobConfiguration *RecordingJobConfiguration
+}
+
+// CreateRecordingJob creates a recording job with the given configuration.
+// The job is returned with the configuration applied by the device, which may differ from config, e.g. if receivers were created
+func (c *Client) CreateRecordingJob(config *RecordingJobConfiguration) (*RecordingJob, error) {
+	return c.CreateRecordingJobContext(context.Background(), config)
+}
+
+// CreateRecordingJobContext is like CreateRecordingJob, but ctx controls the request
+func (c *Client) CreateRecordingJobContext(ctx context.Context, config *RecordingJobConfiguration) (*RecordingJob, error) {
+	resp := new(CreateRecordingJobResponse)
+	if err := c.CallContext(ctx, &CreateRecordingJob{JobConfiguration: config}, resp); err != nil {
+		return nil, err
+	}
+	return &RecordingJob{JobToken: resp.JobToken, JobConfiguration: resp.JobConfiguration}, nil
+}
+
+// SetRecordingJobMode is an ONVIF SetRecordingJobMode operation
+type SetRecordingJobMode struct {
+	XMLName  xml.Name         `xml:"trc:SetRecordingJobMode"`
+	JobToken string           `xml:"trc:JobToken"`
+	Mode     RecordingJobMode `xml:"trc:Mode"`
+}
+
+// SetRecordingJobMode starts (RecordingJobModeActive) or stops (RecordingJobModeIdle) the recording job with the given token
+func (c *Client) SetRecordingJobMode(jobToken string, mode RecordingJobMode) error {
+	return c.SetRecordingJobModeContext(context.Background(), jobToken, mode)
+}
+
+// SetRecordingJobModeContext is like SetRecordingJobMode, but ctx controls the request
+func (c *Client) SetRecordingJobModeContext(ctx context.Context, jobToken string, mode RecordingJobMode) error {
+	return c.CallContext(ctx, &SetRecordingJobMode{JobToken: jobToken, Mode: mode}, nil)
+}
+
+// GetRecordingJobConfiguration is an ONVIF GetRecordingJobConfiguration operation
+type GetRecordingJobConfiguration struct {
+	XMLName  xml.Name `xml:"trc:GetRecordingJobConfiguration"`
+	JobToken string   `xml:"trc:JobToken"`
+}
+
+// GetRecordingJobConfigurationResponse is an ONVIF GetRecordingJobConfigurationResponse response
+type GetRecordingJobConfigurationResponse struct {
+	JobConfiguration *RecordingJobConfiguration
+}
+
+// GetRecordingJobConfiguration returns the configuration of the recording job with the given token
+func (c *Client) GetRecordingJobConfiguration(jobToken string) (*RecordingJobConfiguration, error) {
+	return c.GetRecordingJobConfigurationContext(context.Background(), jobToken)
+}
+
+// GetRecordingJobConfigurationContext is like GetRecordingJobConfiguration, but ctx controls the request
+func (c *Client) GetRecordingJobConfigurationContext(ctx context.Context, jobToken string) (*RecordingJobConfiguration, error) {
+	resp := new(GetRecordingJobConfigurationResponse)
+	if err := c.CallContext(ctx, &GetRecordingJobConfiguration{JobToken: jobToken}, resp); err != nil {
+		return nil, err
+	}
+	return resp.JobConfiguration, nil
+}
+
+// SetRecordingJobConfiguration is an ONVIF SetRecordingJobConfiguration operation
+type SetRecordingJobConfiguration struct {
+	XMLName          xml.Name                   `xml:"trc:SetRecordingJobConfiguration"`
+	JobToken         string                     `xml:"trc:JobToken"`
+	JobConfiguration *RecordingJobConfiguration `xml:"trc:JobConfiguration"`
+}
+
+// SetRecordingJobConfigurationResponse is an ONVIF SetRecordingJobConfigurationResponse response
+type SetRecordingJobConfigurationResponse struct {
+	JobConfiguration *RecordingJobConfiguration
+}
+
+// SetRecordingJobConfiguration changes the configuration of the recording job with the given token,
+// returning the configuration applied by the device
+func (c *Client) SetRecordingJobConfiguration(jobToken string, config *RecordingJobConfiguration) (*RecordingJobConfiguration, error) {
+	return c.SetRecordingJobConfigurationContext(context.Background(), jobToken, config)
+}
+
+// SetRecordingJobConfigurationContext is like SetRecordingJobConfiguration, but ctx controls the request
+func (c *Client) SetRecordingJobConfigurationContext(ctx context.Context, jobToken string, config *RecordingJobConfiguration) (*RecordingJobConfiguration, error) {
+	resp := new(SetRecordingJobConfigurationResponse)
+	if err := c.CallContext(ctx, &SetRecordingJobConfiguration{JobToken: jobToken, JobConfiguration: config}, resp); err != nil {
+		return nil, err
+	}
+	return resp.JobConfiguration, nil
+}
@@ -0,0 +1,27 @@
+// Package recording implements typed operations for the ONVIF Recording (ver10) service
+package recording
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Recording service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Recording service client using c to make requests to the Recording service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceRecording, soap.Namespaces{"trc": onvif.NamespaceRecording, "tt": onvif.NamespaceONVIF, "wsnt": events.NamespaceWSNT})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Recording service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
@@ -0,0 +1,56 @@
+package recording_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/korylprince/go-onvif/recording"
+)
+
+func TestCreateRecordingMarshal(t *testing.T) {
+	buf, err := xml.Marshal(&recording.CreateRecording{RecordingConfiguration: &recording.RecordingConfiguration{
+		Source:               &recording.RecordingSourceInformation{SourceID: "rtsp://192.168.0.64/stream1", Name: "Camera 1"},
+		Content:              "Lobby",
+		MaximumRetentionTime: "PT24H",
+	}})
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	for _, s := range []string{
+		"<trc:CreateRecording><trc:RecordingConfiguration><tt:Source>",
+		"<tt:SourceId>rtsp://192.168.0.64/stream1</tt:SourceId><tt:Name>Camera 1</tt:Name>",
+		"</tt:Source><tt:Content>Lobby</tt:Content><tt:MaximumRetentionTime>PT24H</tt:MaximumRetentionTime></trc:RecordingConfiguration>",
+	} {
+		if !strings.Contains(string(buf), s) {
+			t.Errorf("expected %s in %s", s, buf)
+		}
+	}
+}
+
+func TestSourceReferenceMarshal(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		source *recording.SourceReference
+		expect string
+	}{
+		{"profile", &recording.SourceReference{Type: recording.SourceReferenceProfile, Token: "Profile_1"},
+			`<tt:SourceToken Type="http://www.onvif.org/ver10/schema/Profile"><tt:Token>Profile_1</tt:Token></tt:SourceToken>`},
+		{"default", &recording.SourceReference{Token: "Receiver_1"}, `<tt:SourceToken><tt:Token>Receiver_1</tt:Token></tt:SourceToken>`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := xml.Marshal(&recording.CreateRecordingJob{JobConfiguration: &recording.RecordingJobConfiguration{
+				RecordingToken: "Recording_1",
+				Mode:           recording.RecordingJobModeActive,
+				Source:         []*recording.RecordingJobSource{{SourceToken: test.source}},
+			}})
+			if err != nil {
+				t.Fatalf("could not marshal: %v", err)
+			}
+			if !strings.Contains(string(buf), "<tt:Source>"+test.expect+"</tt:Source>") {
+				t.Errorf("expected %s in %s", test.expect, buf)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// stopTimeout is how long RecordOnEvents waits to set the job Idle after ctx is done
+const stopTimeout = 5 * time.Second
+
+// EventStateItems are the Data items DefaultEventActive checks, in order
+var EventStateItems = []string{"State", "IsMotion", "IsInside", "LogicalState", "IsTamper"}
+
+// DefaultEventActive returns the value of the first of EventStateItems in n's Data, or true if n has none of them
+func DefaultEventActive(n *events.Notification) bool {
+	for _, name := range EventStateItems {
+		if _, ok := n.Data.Get(name); ok {
+			return n.Data.Bool(name)
+		}
+	}
+	return true
+}
+
+// EventRecording describes a recording job that records while events are active. See Client.RecordOnEvents
+type EventRecording struct {
+	// JobToken is the token of an existing recording job
+	JobToken string
+	// Topics are the event topics that start recording, e.g. events.TopicMotionAlarm or events.TopicCellMotion
+	Topics []string
+	// Cooldown is how long recording continues after the events are no longer active
+	Cooldown time.Duration
+	// Active returns true if n means its event is active. If nil, DefaultEventActive is used. It's only used when the library drives the job
+	Active func(n *events.Notification) bool
+	// DisableOnDevice always drives the job from notifications, even if the device supports event recording
+	DisableOnDevice bool
+}
+
+// RecordOnEvents records to r's job while r's events are active, and for r.Cooldown afterwards.
+//
+// If the device supports event recording (Capabilities.EventRecording), the job is configured with an event filter for r.Topics
+// and r.Cooldown, so the device starts and stops recording itself, and RecordOnEvents returns without reading notifications.
+//
+// Otherwise RecordOnEvents sets the job Active when it receives an active notification for one of r.Topics from notifications
+// (e.g. from an events.NotificationServer subscribed with events.TopicFilter(r.Topics...)), and Idle once no property event
+// (keyed by topic and Source items) is active and no notification has been received for r.Cooldown.
+// Notifications that aren't property events, i.e. without a PropertyOperation, keep recording for r.Cooldown.
+// It returns when notifications is closed or ctx is done, leaving the job Idle
+func (c *Client) RecordOnEvents(ctx context.Context, r *EventRecording, notifications <-chan *events.Notification) error {
+	if !r.DisableOnDevice {
+		ok, err := c.recordOnDevice(ctx, r)
+		if err != nil || ok {
+			return err
+		}
+	}
+	return c.recordOnEvents(ctx, r, notifications)
+}
+
+// recordOnDevice configures r's job with an event filter, returning false if the device doesn't support event recording
+func (c *Client) recordOnDevice(ctx context.Context, r *EventRecording) (bool, error) {
+	caps, err := c.GetServiceCapabilitiesContext(ctx)
+	if err != nil {
+		var f *soap.Fault
+		if errors.As(err, &f) && !errors.Is(err, soap.ErrNotAuthorized) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get capabilities: %w", err)
+	}
+	if caps == nil || !caps.EventRecording {
+		return false, nil
+	}
+
+	config, err := c.GetRecordingJobConfigurationContext(ctx, r.JobToken)
+	if err != nil {
+		return false, fmt.Errorf("could not get job configuration: %w", err)
+	}
+	if config == nil {
+		config = new(RecordingJobConfiguration)
+	}
+	config.Mode = RecordingJobModeActive
+	config.EventFilter = &RecordingJobEventFilter{
+		Filter: []*events.Filter{{TopicExpression: events.TopicFilter(r.Topics...)}},
+		After:  soap.FormatDuration(r.Cooldown),
+	}
+	if _, err = c.SetRecordingJobConfigurationContext(ctx, r.JobToken, config); err != nil {
+		return false, fmt.Errorf("could not set job configuration: %w", err)
+	}
+	return true, nil
+}
+
+// recordOnEvents drives r's job mode from notifications
+func (c *Client) recordOnEvents(ctx context.Context, r *EventRecording, notifications <-chan *events.Notification) (err error) {
+	activeFunc := r.Active
+	if activeFunc == nil {
+		activeFunc = DefaultEventActive
+	}
+	clock := c.Clock
+	if clock == nil {
+		clock = onvif.SystemClock
+	}
+
+	var recording bool
+	setMode := func(ctx context.Context, mode RecordingJobMode) error {
+		if err := c.SetRecordingJobModeContext(ctx, r.JobToken, mode); err != nil {
+			return fmt.Errorf("could not set job mode %s: %w", mode, err)
+		}
+		recording = mode == RecordingJobModeActive
+		return nil
+	}
+	defer func() {
+		if !recording {
+			return
+		}
+		sctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		if serr := setMode(sctx, RecordingJobModeIdle); err == nil {
+			err = serr
+		}
+	}()
+
+	// active is the set of active property events
+	active := make(map[string]bool)
+	var cooldown <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-cooldown:
+			cooldown = nil
+			if err = setMode(ctx, RecordingJobModeIdle); err != nil {
+				return err
+			}
+		case n, ok := <-notifications:
+			if !ok {
+				return nil
+			}
+			if !matchTopic(n, r.Topics) {
+				continue
+			}
+
+			on := activeFunc(n)
+			if n.PropertyOperation != "" {
+				key := n.TopicPath() + fmt.Sprint(n.Source)
+				if on && n.PropertyOperation != events.PropertyDeleted {
+					active[key] = true
+				} else {
+					delete(active, key)
+				}
+			}
+
+			if on && !recording {
+				if err = setMode(ctx, RecordingJobModeActive); err != nil {
+					return err
+				}
+			}
+			if len(active) > 0 {
+				cooldown = nil
+			} else if recording {
+				cooldown = clock.After(r.Cooldown)
+			}
+		}
+	}
+}
+
+// matchTopic returns true if n is one of topics or their subtopics
+func matchTopic(n *events.Notification, topics []string) bool {
+	for _, t := range topics {
+		if n.Is(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package recording_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/recording"
+)
+
+const responseEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:trc="http://www.onvif.org/ver10/recording/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+// manualClock is a Clock whose After channels are sent on afters, to be fired by the test
+type manualClock struct {
+	afters chan chan time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.afters <- ch
+	return ch
+}
+
+var modeRegexp = regexp.MustCompile(`<trc:Mode>(\w+)</trc:Mode>`)
+
+// newRecordingServer returns a recording client for a server that reports eventRecording in its capabilities.
+// SetRecordingJobMode modes are sent on modes, and SetRecordingJobConfiguration requests on configs
+func newRecordingServer(t *testing.T, eventRecording bool, clock onvif.Clock) (*recording.Client, chan string, chan string, func()) {
+	modes, configs := make(chan string, 10), make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(buf, []byte("<trc:GetServiceCapabilities>")):
+			fmt.Fprintf(w, responseEnvelope, fmt.Sprintf(`<trc:GetServiceCapabilitiesResponse><trc:Capabilities EventRecording="%t"/></trc:GetServiceCapabilitiesResponse>`, eventRecording))
+		case bytes.Contains(buf, []byte("<trc:GetRecordingJobConfiguration>")):
+			fmt.Fprintf(w, responseEnvelope, `<trc:GetRecordingJobConfigurationResponse><trc:JobConfiguration>
+<tt:RecordingToken>Recording_1</tt:RecordingToken><tt:Mode>Idle</tt:Mode><tt:Priority>1</tt:Priority></trc:JobConfiguration></trc:GetRecordingJobConfigurationResponse>`)
+		case bytes.Contains(buf, []byte("<trc:SetRecordingJobConfiguration>")):
+			configs <- string(buf)
+			fmt.Fprintf(w, responseEnvelope, `<trc:SetRecordingJobConfigurationResponse/>`)
+		case bytes.Contains(buf, []byte("<trc:SetRecordingJobMode>")):
+			modes <- modeRegexp.FindStringSubmatch(string(buf))[1]
+			fmt.Fprintf(w, responseEnvelope, `<trc:SetRecordingJobModeResponse/>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+
+	c, err := recording.NewClient(&onvif.Client{Clock: clock}, onvif.Services{{Namespace: onvif.NamespaceRecording, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	return c, modes, configs, srv.Close
+}
+
+func motion(op events.PropertyOperation, state string) *events.Notification {
+	return &events.Notification{
+		Topic:             "tns1:VideoSource/MotionAlarm",
+		PropertyOperation: op,
+		Source:            events.Items{"Source": "VideoSource_1"},
+		Data:              events.Items{"State": state},
+	}
+}
+
+func expectMode(t *testing.T, modes chan string, mode string) {
+	t.Helper()
+	if m := <-modes; m != mode {
+		t.Fatalf("expected mode %s, got %s", mode, m)
+	}
+}
+
+func TestRecordOnEventsLibrary(t *testing.T) {
+	clock := &manualClock{afters: make(chan chan time.Time, 10)}
+	c, modes, _, stop := newRecordingServer(t, false, clock)
+	defer stop()
+
+	notifications := make(chan *events.Notification)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.RecordOnEvents(context.Background(), &recording.EventRecording{
+			JobToken: "Job_1",
+			Topics:   []string{events.TopicMotionAlarm},
+			Cooldown: 30 * time.Second,
+		}, notifications)
+	}()
+
+	notifications <- motion(events.PropertyChanged, "true")
+	expectMode(t, modes, "Active")
+
+	// other topics are ignored
+	notifications <- &events.Notification{Topic: "tns1:Device/Trigger/DigitalInput", Data: events.Items{"LogicalState": "true"}}
+
+	// recording stops after the cooldown once motion ends
+	notifications <- motion(events.PropertyChanged, "false")
+	(<-clock.afters) <- time.Now()
+	expectMode(t, modes, "Idle")
+
+	// events that aren't property events start recording, and the job is left idle when notifications is closed
+	notifications <- &events.Notification{Topic: "tns1:VideoSource/MotionAlarm"}
+	expectMode(t, modes, "Active")
+	<-clock.afters
+	close(notifications)
+	if err := <-errc; err != nil {
+		t.Fatalf("could not record on events: %v", err)
+	}
+	expectMode(t, modes, "Idle")
+
+	select {
+	case m := <-modes:
+		t.Errorf("unexpected mode change: %s", m)
+	default:
+	}
+}
+
+func TestRecordOnEventsDevice(t *testing.T) {
+	c, modes, configs, stop := newRecordingServer(t, true, nil)
+	defer stop()
+
+	if err := c.RecordOnEvents(context.Background(), &recording.EventRecording{
+		JobToken: "Job_1",
+		Topics:   []string{events.TopicMotionAlarm},
+		Cooldown: 30 * time.Second,
+	}, nil); err != nil {
+		t.Fatalf("could not record on events: %v", err)
+	}
+
+	config := <-configs
+	for _, s := range []string{
+		"<trc:JobToken>Job_1</trc:JobToken>",
+		"<tt:RecordingToken>Recording_1</tt:RecordingToken><tt:Mode>Active</tt:Mode>",
+		`<tt:EventFilter><tt:Filter><wsnt:TopicExpression Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet"`,
+		">tns1:VideoSource/MotionAlarm</wsnt:TopicExpression></tt:Filter><tt:After>PT30S</tt:After></tt:EventFilter>",
+	} {
+		if !bytes.Contains([]byte(config), []byte(s)) {
+			t.Errorf("expected %s in %s", s, config)
+		}
+	}
+	if len(modes) != 0 {
+		t.Errorf("expected no mode changes, got %d", len(modes))
+	}
+}
@@ -0,0 +1,140 @@
+package recording
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// RecordingSourceInformation is an ONVIF RecordingSourceInformation type
+type RecordingSourceInformation struct {
+	// SourceID identifies the source, e.g. a URI of the camera
+	SourceID    string `xml:"SourceId"`
+	Name        string
+	Location    string
+	Description string
+	// Address is the URI of the source
+	Address string
+}
+
+// RecordingConfiguration is an ONVIF RecordingConfiguration type
+type RecordingConfiguration struct {
+	Source  *RecordingSourceInformation
+	Content string
+	// MaximumRetentionTime is an xsd:duration, e.g. PT24H. PT0S means recordings are kept indefinitely
+	MaximumRetentionTime string
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (r *RecordingConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type config RecordingConfiguration
+	return soap.EncodeElementPrefixed(enc, (*config)(r), start, "tt")
+}
+
+// TrackType is an ONVIF TrackType
+type TrackType string
+
+// TrackTypes
+const (
+	TrackTypeVideo    TrackType = "Video"
+	TrackTypeAudio    TrackType = "Audio"
+	TrackTypeMetadata TrackType = "Metadata"
+	TrackTypeExtended TrackType = "Extended"
+)
+
+// TrackConfiguration is an ONVIF TrackConfiguration type
+type TrackConfiguration struct {
+	TrackType   TrackType
+	Description string
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (t *TrackConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type config TrackConfiguration
+	return soap.EncodeElementPrefixed(enc, (*config)(t), start, "tt")
+}
+
+// Track is an ONVIF GetTrackResponseItem type
+type Track struct {
+	TrackToken    string
+	Configuration *TrackConfiguration
+}
+
+// Recording is an ONVIF GetRecordingsResponseItem type
+type Recording struct {
+	RecordingToken string
+	Configuration  *RecordingConfiguration
+	Tracks         []*Track `xml:"Tracks>Track"`
+}
+
+// RecordingJobMode is an ONVIF recording job mode
+type RecordingJobMode string
+
+// RecordingJobModes
+const (
+	RecordingJobModeIdle   RecordingJobMode = "Idle"
+	RecordingJobModeActive RecordingJobMode = "Active"
+)
+
+// SourceReference types
+const (
+	SourceReferenceProfile  = "http://www.onvif.org/ver10/schema/Profile"
+	SourceReferenceReceiver = "http://www.onvif.org/ver10/schema/Receiver"
+)
+
+// SourceReference is an ONVIF SourceReference type
+type SourceReference struct {
+	// Type is the type of the token, e.g. SourceReferenceProfile.
+	// If empty, it isn't sent and devices use the schema default, SourceReferenceReceiver
+	Type  string `xml:"Type,attr,omitempty"`
+	Token string
+}
+
+// RecordingJobTrack is an ONVIF RecordingJobTrack type
+type RecordingJobTrack struct {
+	// SourceTag identifies the track of the source, e.g. VIDEO001
+	SourceTag string
+	// Destination is the token of the recording track to record to
+	Destination string
+}
+
+// RecordingJobSource is an ONVIF RecordingJobSource type
+type RecordingJobSource struct {
+	SourceToken *SourceReference `xml:",omitempty"`
+	// AutoCreateReceiver, if true, creates a receiver for the source
+	AutoCreateReceiver *bool                `xml:",omitempty"`
+	Tracks             []*RecordingJobTrack `xml:",omitempty"`
+}
+
+// RecordingJobConfiguration is an ONVIF RecordingJobConfiguration type
+type RecordingJobConfiguration struct {
+	RecordingToken string
+	Mode           RecordingJobMode
+	// Priority is used to resolve conflicts between jobs recording to the same recording. Higher values take precedence
+	Priority int
+	Source   []*RecordingJobSource `xml:",omitempty"`
+	// EventFilter, if set, makes the device record only while the events it matches are active.
+	// Only devices with Capabilities.EventRecording support it
+	EventFilter *RecordingJobEventFilter `xml:",omitempty"`
+}
+
+// RecordingJobEventFilter is an ONVIF RecordingJobEventFilter type
+type RecordingJobEventFilter struct {
+	Filter []*events.Filter `xml:",omitempty"`
+	// Before and After are how long the device records before and after the events, as xsd:durations. See soap.FormatDuration
+	Before string `xml:",omitempty"`
+	After  string `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (j *RecordingJobConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type config RecordingJobConfiguration
+	return soap.EncodeElementPrefixed(enc, (*config)(j), start, "tt")
+}
+
+// RecordingJob is an ONVIF GetRecordingJobsResponseItem type
+type RecordingJob struct {
+	JobToken         string
+	JobConfiguration *RecordingJobConfiguration
+}
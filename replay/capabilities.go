@@ -0,0 +1,39 @@
+package replay
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Capabilities is an ONVIF replay Capabilities type
+type Capabilities struct {
+	ReversePlayback bool `xml:"ReversePlayback,attr"`
+	// SessionTimeoutRange is the minimum and maximum session timeout in seconds, separated by a space
+	SessionTimeoutRange string `xml:"SessionTimeoutRange,attr"`
+	RTPRTSPTCP          bool   `xml:"RTP_RTSP_TCP,attr"`
+	RTSPWebSocketURI    string `xml:"RTSPWebSocketUri,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"trp:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the replay service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	return c.GetServiceCapabilitiesContext(context.Background())
+}
+
+// GetServiceCapabilitiesContext is like GetServiceCapabilities, but ctx controls the request
+func (c *Client) GetServiceCapabilitiesContext(ctx context.Context) (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.CallContext(ctx, &GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
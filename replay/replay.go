@@ -0,0 +1,113 @@
+// Package replay implements typed operations for the ONVIF Replay (ver10) service
+package replay
+
+import (
+	"context"
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/media"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Replay service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Replay service client using c to make requests to the Replay service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceReplay, soap.Namespaces{"trp": onvif.NamespaceReplay, "tt": onvif.NamespaceONVIF})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Replay service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
+
+// GetReplayUri is an ONVIF GetReplayUri operation
+type GetReplayUri struct {
+	XMLName        xml.Name           `xml:"trp:GetReplayUri"`
+	StreamSetup    *media.StreamSetup `xml:"trp:StreamSetup"`
+	RecordingToken string             `xml:"trp:RecordingToken"`
+}
+
+// GetReplayUriResponse is an ONVIF GetReplayUriResponse response
+type GetReplayUriResponse struct {
+	URI string `xml:"Uri"`
+}
+
+// GetReplayUri returns the RTSP URI for replaying the recording with the given token.
+// If setup is nil, a unicast RTSP stream is requested. Playback is controlled with RTSP, e.g. using the Range and Rate-Control headers
+func (c *Client) GetReplayUri(recordingToken string, setup *media.StreamSetup) (string, error) {
+	return c.GetReplayUriContext(context.Background(), recordingToken, setup)
+}
+
+// GetReplayUriContext is like GetReplayUri, but ctx controls the request
+func (c *Client) GetReplayUriContext(ctx context.Context, recordingToken string, setup *media.StreamSetup) (string, error) {
+	if setup == nil {
+		setup = &media.StreamSetup{Stream: media.StreamTypeUnicast, Protocol: media.TransportProtocolRTSP}
+	}
+
+	resp := new(GetReplayUriResponse)
+	if err := c.CallContext(ctx, &GetReplayUri{StreamSetup: setup, RecordingToken: recordingToken}, resp); err != nil {
+		return "", err
+	}
+	return resp.URI, nil
+}
+
+// ReplayConfiguration is an ONVIF ReplayConfiguration type
+type ReplayConfiguration struct {
+	// SessionTimeout is the xsd:duration a replay session is kept without RTSP keep alives, e.g. PT60S. See soap.ParseDuration
+	SessionTimeout string
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (r *ReplayConfiguration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type config ReplayConfiguration
+	return soap.EncodeElementPrefixed(enc, (*config)(r), start, "tt")
+}
+
+// GetReplayConfiguration is an ONVIF GetReplayConfiguration operation
+type GetReplayConfiguration struct {
+	XMLName xml.Name `xml:"trp:GetReplayConfiguration"`
+}
+
+// GetReplayConfigurationResponse is an ONVIF GetReplayConfigurationResponse response
+type GetReplayConfigurationResponse struct {
+	Configuration *ReplayConfiguration
+}
+
+// GetReplayConfiguration returns the replay configuration of the device
+func (c *Client) GetReplayConfiguration() (*ReplayConfiguration, error) {
+	return c.GetReplayConfigurationContext(context.Background())
+}
+
+// GetReplayConfigurationContext is like GetReplayConfiguration, but ctx controls the request
+func (c *Client) GetReplayConfigurationContext(ctx context.Context) (*ReplayConfiguration, error) {
+	resp := new(GetReplayConfigurationResponse)
+	if err := c.CallContext(ctx, &GetReplayConfiguration{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Configuration, nil
+}
+
+// SetReplayConfiguration is an ONVIF SetReplayConfiguration operation
+type SetReplayConfiguration struct {
+	XMLName       xml.Name             `xml:"trp:SetReplayConfiguration"`
+	Configuration *ReplayConfiguration `xml:"trp:Configuration"`
+}
+
+// SetReplayConfiguration sets the replay configuration of the device
+func (c *Client) SetReplayConfiguration(config *ReplayConfiguration) error {
+	return c.SetReplayConfigurationContext(context.Background(), config)
+}
+
+// SetReplayConfigurationContext is like SetReplayConfiguration, but ctx controls the request
+func (c *Client) SetReplayConfigurationContext(ctx context.Context, config *ReplayConfiguration) error {
+	return c.CallContext(ctx, &SetReplayConfiguration{Configuration: config}, nil)
+}
@@ -0,0 +1,153 @@
+package onvif
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// FaultRetry matches SOAP faults that should be retried, e.g. devices returning "Device busy" or "Too many users" faults
+type FaultRetry struct {
+	// SubCode matches any of the fault's nested subcodes, ignoring the namespace prefix, e.g. "TooManySessions" matches "ter:TooManySessions".
+	// If empty, any subcode matches
+	SubCode string
+	// Reason, if non-nil, must match the fault reason
+	Reason *regexp.Regexp
+	// Backoff is the delay before retrying the request
+	Backoff time.Duration
+}
+
+// match returns true if f matches the fault
+func (f *FaultRetry) match(fault *soap.Fault) bool {
+	if f.SubCode != "" && !fault.HasSubCode(soap.ErrorCode(f.SubCode)) {
+		return false
+	}
+	if f.Reason != nil && !f.Reason.MatchString(fault.Reason) {
+		return false
+	}
+	return true
+}
+
+// ErrRequestTimeout is returned (wrapped) when a single attempt exceeds Request.Timeout or RetryPolicy.Timeout
+var ErrRequestTimeout = errors.New("request timed out")
+
+// HTTPError is returned when the device responds with an unexpected HTTP status and no SOAP envelope
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Err is the error decoding the response
+	Err error
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %s: %v", e.Status, e.Err)
+}
+
+// Unwrap allows HTTPError to be used with errors.Is and errors.As
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a request, including the first attempt.
+	// Values less than 1 are treated as 1
+	MaxAttempts int
+	// Faults is the list of SOAP faults to retry. The first match is used
+	Faults []*FaultRetry
+	// If Transient is true, transient network errors are retried, e.g. connection resets, refused connections, and timeouts
+	Transient bool
+	// If ServerErrors is true, HTTP 5xx responses without a SOAP fault are retried. See HTTPError
+	ServerErrors bool
+	// Backoff is the delay before retrying a transient or server error. The delay doubles after each attempt, up to MaxBackoff
+	Backoff time.Duration
+	// MaxBackoff, if greater than zero, limits the delay before retrying a transient or server error
+	MaxBackoff time.Duration
+	// Timeout, if greater than zero, limits each attempt. Request.Timeout overrides it
+	Timeout time.Duration
+}
+
+// retry returns the backoff delay and true if the request should be retried after the given attempt number returned err
+func (p *RetryPolicy) retry(attempt int, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	// the credentials won't become valid if the request is retried
+	var u *soap.UnauthorizedError
+	if errors.As(err, &u) {
+		return 0, false
+	}
+
+	var f *soap.Fault
+	if errors.As(err, &f) {
+		for _, fr := range p.Faults {
+			if fr.match(f) {
+				return fr.Backoff, true
+			}
+		}
+		return 0, false
+	}
+
+	var h *HTTPError
+	if errors.As(err, &h) {
+		if p.ServerErrors && h.StatusCode >= 500 {
+			return p.backoff(attempt), true
+		}
+		return 0, false
+	}
+
+	if p.Transient && isTransient(err) {
+		return p.backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+// backoff returns the exponential backoff delay after the given attempt number
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// timeout returns the per attempt timeout for r, or zero if there is none
+func (p *RetryPolicy) timeout(r *Request) time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	if p != nil {
+		return p.Timeout
+	}
+	return 0
+}
+
+// isTransient returns true if err is a network error that may succeed if retried
+func isTransient(err error) bool {
+	if errors.Is(err, ErrRequestTimeout) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
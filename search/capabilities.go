@@ -0,0 +1,28 @@
+package search
+
+import "encoding/xml"
+
+// Capabilities is an ONVIF search Capabilities type
+type Capabilities struct {
+	MetadataSearch     bool `xml:"MetadataSearch,attr"`
+	GeneralStartEvents bool `xml:"GeneralStartEvents,attr"`
+}
+
+// GetServiceCapabilities is an ONVIF GetServiceCapabilities operation
+type GetServiceCapabilities struct {
+	XMLName xml.Name `xml:"tse:GetServiceCapabilities"`
+}
+
+// GetServiceCapabilitiesResponse is an ONVIF GetServiceCapabilitiesResponse response
+type GetServiceCapabilitiesResponse struct {
+	Capabilities *Capabilities
+}
+
+// GetServiceCapabilities returns the capabilities of the search service
+func (c *Client) GetServiceCapabilities() (*Capabilities, error) {
+	resp := new(GetServiceCapabilitiesResponse)
+	if err := c.Call(&GetServiceCapabilities{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Capabilities, nil
+}
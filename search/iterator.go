@@ -0,0 +1,205 @@
+package search
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Search defaults
+const (
+	DefaultKeepAlive = time.Minute
+	DefaultWaitTime  = 5 * time.Second
+)
+
+// PollInterval is the delay between result requests when a device returns no results before WaitTime
+var PollInterval = time.Second
+
+// Options configures SearchRecordings and SearchEvents. A nil *Options uses the defaults
+type Options struct {
+	// MaxMatches limits the total number of results. If zero, the results aren't limited
+	MaxMatches int
+	// KeepAlive is how long the device keeps the search if results aren't requested. If zero, DefaultKeepAlive is used
+	KeepAlive time.Duration
+	// WaitTime is how long the device waits for results for each request. If zero, DefaultWaitTime is used
+	WaitTime time.Duration
+	// MaxResults limits the number of results returned by each call to Next. If zero, the results aren't limited
+	MaxResults int
+}
+
+func (o *Options) keepAlive() string {
+	if o == nil || o.KeepAlive <= 0 {
+		return soap.FormatDuration(DefaultKeepAlive)
+	}
+	return soap.FormatDuration(o.KeepAlive)
+}
+
+func (o *Options) waitTime() string {
+	if o == nil || o.WaitTime <= 0 {
+		return soap.FormatDuration(DefaultWaitTime)
+	}
+	return soap.FormatDuration(o.WaitTime)
+}
+
+func (o *Options) maxMatches() int {
+	if o == nil {
+		return 0
+	}
+	return o.MaxMatches
+}
+
+func (o *Options) maxResults() int {
+	if o == nil {
+		return 0
+	}
+	return o.MaxResults
+}
+
+// search is the state shared by RecordingSearch and EventSearch
+type search struct {
+	client *Client
+	opts   *Options
+	// Token is the search token returned by the device
+	Token string
+	done  bool
+}
+
+// wait waits PollInterval, returning early if ctx is done
+func (s *search) wait(ctx context.Context) error {
+	clock := s.client.Clock
+	if clock == nil {
+		clock = onvif.SystemClock
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(PollInterval):
+		return nil
+	}
+}
+
+// Close ends the search if it hasn't completed, releasing it on the device
+func (s *search) Close(ctx context.Context) error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	_, err := s.client.endSearch(ctx, s.Token)
+	return err
+}
+
+// RecordingSearch is an in progress recording search. See Client.SearchRecordings
+type RecordingSearch struct {
+	search
+}
+
+// SearchRecordings starts a recording search in scope, which may be nil to search all recordings.
+// Results are read with RecordingSearch.Next, which also keeps the search alive.
+// RecordingSearch.Close should be called if the search is abandoned before Next returns io.EOF
+func (c *Client) SearchRecordings(ctx context.Context, scope *SearchScope, opts *Options) (*RecordingSearch, error) {
+	if scope == nil {
+		scope = new(SearchScope)
+	}
+	token, err := c.find(ctx, &FindRecordings{Scope: scope, MaxMatches: opts.maxMatches(), KeepAliveTime: opts.keepAlive()})
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingSearch{search{client: c, opts: opts, Token: token}}, nil
+}
+
+// Next returns the next results as they become available, waiting until at least one is found.
+// io.EOF is returned when the search is complete
+func (s *RecordingSearch) Next(ctx context.Context) ([]*RecordingInformation, error) {
+	for !s.done {
+		results, err := s.client.getRecordingSearchResults(ctx, &GetRecordingSearchResults{
+			SearchToken: s.Token,
+			MinResults:  1,
+			MaxResults:  s.opts.maxResults(),
+			WaitTime:    s.opts.waitTime(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.done = results.SearchState == SearchStateCompleted
+		if len(results.RecordingInformation) > 0 {
+			return results.RecordingInformation, nil
+		}
+		if !s.done {
+			if err = s.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, io.EOF
+}
+
+// EventSearch is an in progress event search. See Client.SearchEvents
+type EventSearch struct {
+	search
+}
+
+// SearchEvents starts a search for events between start and end in scope, which may be nil to search all recordings.
+// end may be zero to search to the end of the recordings, and if end is before start the search goes backwards in time.
+// filter may be nil to return all events.
+// If includeStartState is true, the state of properties at start is also returned.
+// Results are read with EventSearch.Next, which also keeps the search alive.
+// EventSearch.Close should be called if the search is abandoned before Next returns io.EOF
+func (c *Client) SearchEvents(ctx context.Context, start, end time.Time, scope *SearchScope, filter *EventFilter, includeStartState bool, opts *Options) (*EventSearch, error) {
+	if scope == nil {
+		scope = new(SearchScope)
+	}
+	if filter == nil {
+		filter = new(EventFilter)
+	}
+	req := &FindEvents{
+		StartPoint:        formatDateTime(start),
+		Scope:             scope,
+		SearchFilter:      filter,
+		IncludeStartState: includeStartState,
+		MaxMatches:        opts.maxMatches(),
+		KeepAliveTime:     opts.keepAlive(),
+	}
+	if !end.IsZero() {
+		req.EndPoint = formatDateTime(end)
+	}
+
+	token, err := c.find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &EventSearch{search{client: c, opts: opts, Token: token}}, nil
+}
+
+// Next returns the next results as they become available, waiting until at least one is found.
+// io.EOF is returned when the search is complete
+func (s *EventSearch) Next(ctx context.Context) ([]*FindEventResult, error) {
+	for !s.done {
+		results, err := s.client.getEventSearchResults(ctx, &GetEventSearchResults{
+			SearchToken: s.Token,
+			MinResults:  1,
+			MaxResults:  s.opts.maxResults(),
+			WaitTime:    s.opts.waitTime(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.done = results.SearchState == SearchStateCompleted
+		if len(results.Result) > 0 {
+			return results.Result, nil
+		}
+		if !s.done {
+			if err = s.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, io.EOF
+}
+
+// formatDateTime formats t as an xsd:dateTime in UTC
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// FindRecordings is an ONVIF FindRecordings operation
+type FindRecordings struct {
+	XMLName    xml.Name     `xml:"tse:FindRecordings"`
+	Scope      *SearchScope `xml:"tse:Scope"`
+	MaxMatches int          `xml:"tse:MaxMatches,omitempty"`
+	// KeepAliveTime is an xsd:duration. See soap.FormatDuration
+	KeepAliveTime string `xml:"tse:KeepAliveTime"`
+}
+
+// FindEvents is an ONVIF FindEvents operation
+type FindEvents struct {
+	XMLName xml.Name `xml:"tse:FindEvents"`
+	// StartPoint and EndPoint are xsd:dateTimes. If EndPoint is before StartPoint, the search goes backwards in time
+	StartPoint        string       `xml:"tse:StartPoint"`
+	EndPoint          string       `xml:"tse:EndPoint,omitempty"`
+	Scope             *SearchScope `xml:"tse:Scope"`
+	SearchFilter      *EventFilter `xml:"tse:SearchFilter"`
+	IncludeStartState bool         `xml:"tse:IncludeStartState"`
+	MaxMatches        int          `xml:"tse:MaxMatches,omitempty"`
+	// KeepAliveTime is an xsd:duration. See soap.FormatDuration
+	KeepAliveTime string `xml:"tse:KeepAliveTime"`
+}
+
+// FindResponse is an ONVIF FindRecordingsResponse or FindEventsResponse response
+type FindResponse struct {
+	SearchToken string
+}
+
+// FindRecordings starts a recording search, returning the search token. See SearchRecordings for a helper that polls for results
+func (c *Client) FindRecordings(req *FindRecordings) (string, error) {
+	return c.find(context.Background(), req)
+}
+
+// FindEvents starts an event search, returning the search token. See SearchEvents for a helper that polls for results
+func (c *Client) FindEvents(req *FindEvents) (string, error) {
+	return c.find(context.Background(), req)
+}
+
+func (c *Client) find(ctx context.Context, req interface{}) (string, error) {
+	resp := new(FindResponse)
+	if err := c.CallContext(ctx, req, resp); err != nil {
+		return "", err
+	}
+	return resp.SearchToken, nil
+}
+
+// GetRecordingSearchResults is an ONVIF GetRecordingSearchResults operation
+type GetRecordingSearchResults struct {
+	XMLName     xml.Name `xml:"tse:GetRecordingSearchResults"`
+	SearchToken string   `xml:"tse:SearchToken"`
+	MinResults  int      `xml:"tse:MinResults,omitempty"`
+	MaxResults  int      `xml:"tse:MaxResults,omitempty"`
+	// WaitTime is an xsd:duration the device waits for MinResults results. See soap.FormatDuration
+	WaitTime string `xml:"tse:WaitTime,omitempty"`
+}
+
+// RecordingSearchResults is an ONVIF FindRecordingResultList type
+type RecordingSearchResults struct {
+	SearchState          SearchState
+	RecordingInformation []*RecordingInformation
+}
+
+// GetRecordingSearchResultsResponse is an ONVIF GetRecordingSearchResultsResponse response
+type GetRecordingSearchResultsResponse struct {
+	ResultList *RecordingSearchResults
+}
+
+// GetRecordingSearchResults returns the results of the recording search with req.SearchToken found since the last call
+func (c *Client) GetRecordingSearchResults(req *GetRecordingSearchResults) (*RecordingSearchResults, error) {
+	return c.getRecordingSearchResults(context.Background(), req)
+}
+
+func (c *Client) getRecordingSearchResults(ctx context.Context, req *GetRecordingSearchResults) (*RecordingSearchResults, error) {
+	resp := new(GetRecordingSearchResultsResponse)
+	if err := c.CallContext(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.ResultList == nil {
+		return &RecordingSearchResults{SearchState: SearchStateUnknown}, nil
+	}
+	return resp.ResultList, nil
+}
+
+// GetEventSearchResults is an ONVIF GetEventSearchResults operation
+type GetEventSearchResults struct {
+	XMLName     xml.Name `xml:"tse:GetEventSearchResults"`
+	SearchToken string   `xml:"tse:SearchToken"`
+	MinResults  int      `xml:"tse:MinResults,omitempty"`
+	MaxResults  int      `xml:"tse:MaxResults,omitempty"`
+	// WaitTime is an xsd:duration the device waits for MinResults results. See soap.FormatDuration
+	WaitTime string `xml:"tse:WaitTime,omitempty"`
+}
+
+// EventSearchResults is an ONVIF FindEventResultList type
+type EventSearchResults struct {
+	SearchState SearchState
+	Result      []*FindEventResult
+}
+
+// GetEventSearchResultsResponse is an ONVIF GetEventSearchResultsResponse response
+type GetEventSearchResultsResponse struct {
+	ResultList *EventSearchResults
+}
+
+// GetEventSearchResults returns the results of the event search with req.SearchToken found since the last call
+func (c *Client) GetEventSearchResults(req *GetEventSearchResults) (*EventSearchResults, error) {
+	return c.getEventSearchResults(context.Background(), req)
+}
+
+func (c *Client) getEventSearchResults(ctx context.Context, req *GetEventSearchResults) (*EventSearchResults, error) {
+	resp := new(GetEventSearchResultsResponse)
+	if err := c.CallContext(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.ResultList == nil {
+		return &EventSearchResults{SearchState: SearchStateUnknown}, nil
+	}
+	return resp.ResultList, nil
+}
+
+// EndSearch is an ONVIF EndSearch operation
+type EndSearch struct {
+	XMLName     xml.Name `xml:"tse:EndSearch"`
+	SearchToken string   `xml:"tse:SearchToken"`
+}
+
+// EndSearchResponse is an ONVIF EndSearchResponse response
+type EndSearchResponse struct {
+	// Endpoint is the xsd:dateTime the search reached. See events.ParseDateTime
+	Endpoint string
+}
+
+// EndSearch ends the search with the given token, returning the xsd:dateTime the search reached
+func (c *Client) EndSearch(searchToken string) (string, error) {
+	return c.endSearch(context.Background(), searchToken)
+}
+
+func (c *Client) endSearch(ctx context.Context, searchToken string) (string, error) {
+	resp := new(EndSearchResponse)
+	if err := c.CallContext(ctx, &EndSearch{SearchToken: searchToken}, resp); err != nil {
+		return "", err
+	}
+	return resp.Endpoint, nil
+}
+
+// GetRecordingSummary is an ONVIF GetRecordingSummary operation
+type GetRecordingSummary struct {
+	XMLName xml.Name `xml:"tse:GetRecordingSummary"`
+}
+
+// RecordingSummary is an ONVIF RecordingSummary type
+type RecordingSummary struct {
+	// DataFrom and DataUntil are the xsd:dateTimes of the oldest and newest data in any recording. See events.ParseDateTime
+	DataFrom         string
+	DataUntil        string
+	NumberRecordings int
+}
+
+// GetRecordingSummaryResponse is an ONVIF GetRecordingSummaryResponse response
+type GetRecordingSummaryResponse struct {
+	Summary *RecordingSummary
+}
+
+// GetRecordingSummary returns a summary of the data stored on the device
+func (c *Client) GetRecordingSummary() (*RecordingSummary, error) {
+	return c.GetRecordingSummaryContext(context.Background())
+}
+
+// GetRecordingSummaryContext is like GetRecordingSummary, but ctx controls the request
+func (c *Client) GetRecordingSummaryContext(ctx context.Context) (*RecordingSummary, error) {
+	resp := new(GetRecordingSummaryResponse)
+	if err := c.CallContext(ctx, &GetRecordingSummary{}, resp); err != nil {
+		return nil, err
+	}
+	if resp.Summary == nil {
+		return &RecordingSummary{}, nil
+	}
+	return resp.Summary, nil
+}
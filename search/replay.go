@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/korylprince/go-onvif/events"
+)
+
+// ReplayEvents searches for events between start and end (see SearchEvents) and sends them to notifications as they're found,
+// so stored events can be handled by the same code as live notifications from an events.NotificationServer, e.g. to backfill gaps after downtime.
+// Notifications without a UtcTime are given the result's time. Results that can't be decoded are skipped.
+// It returns nil when the search completes, or the error that stopped it (e.g. ctx.Err()), in which case the search is closed.
+// notifications isn't closed
+func (c *Client) ReplayEvents(ctx context.Context, start, end time.Time, scope *SearchScope, filter *EventFilter, opts *Options, notifications chan<- *events.Notification) error {
+	s, err := c.SearchEvents(ctx, start, end, scope, filter, false, opts)
+	if err != nil {
+		return err
+	}
+
+	if err = s.replay(ctx, notifications); err != nil {
+		// the device discards the search after its keep alive time anyway, so closing it is best effort
+		cctx, cancel := context.WithTimeout(context.Background(), DefaultWaitTime)
+		defer cancel()
+		s.Close(cctx) //nolint:errcheck
+		return err
+	}
+	return nil
+}
+
+// replay sends the search's results to notifications until the search completes
+func (s *EventSearch) replay(ctx context.Context, notifications chan<- *events.Notification) error {
+	for {
+		results, err := s.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, r := range results {
+			if r.Event == nil {
+				continue
+			}
+			n, err := events.DecodeElement(r.Event)
+			if err != nil {
+				continue
+			}
+			if n.Time.IsZero() {
+				n.Time, _ = events.ParseDateTime(r.Time)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case notifications <- n:
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Package search implements typed operations for the ONVIF Search (ver10) service
+package search
+
+import (
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Client is an ONVIF Search service client
+type Client struct {
+	*onvif.ServiceClient
+}
+
+// NewClient returns a new Search service client using c to make requests to the Search service URL in services
+func NewClient(c *onvif.Client, services onvif.Services) (*Client, error) {
+	s, err := onvif.NewServiceClient(c, services, onvif.NamespaceSearch, soap.Namespaces{
+		"tse":  onvif.NamespaceSearch,
+		"tt":   onvif.NamespaceONVIF,
+		"wsnt": events.NamespaceWSNT,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ServiceClient: s}, nil
+}
+
+// FromDevice returns a new Search service client for dev. See NewClient
+func FromDevice(dev *onvif.Device) (*Client, error) {
+	return NewClient(dev.Client, dev.Services)
+}
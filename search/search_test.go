@@ -0,0 +1,135 @@
+package search_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/search"
+)
+
+const responseEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tse="http://www.onvif.org/ver10/search/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema"
+xmlns:wsnt="http://docs.oasis-open.org/wsn/b-2">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+const responseResults = `<tse:GetRecordingSearchResultsResponse><tse:ResultList>
+<tt:SearchState>%s</tt:SearchState>%s
+</tse:ResultList></tse:GetRecordingSearchResultsResponse>`
+
+const recordingInformation = `<tt:RecordingInformation><tt:RecordingToken>%s</tt:RecordingToken></tt:RecordingInformation>`
+
+func TestRecordingSearch(t *testing.T) {
+	// results returned for each GetRecordingSearchResults request
+	pages := []string{
+		fmt.Sprintf(responseResults, "Searching", ""),
+		fmt.Sprintf(responseResults, "Searching", fmt.Sprintf(recordingInformation, "rec1")),
+		fmt.Sprintf(responseResults, "Completed", fmt.Sprintf(recordingInformation, "rec2")+fmt.Sprintf(recordingInformation, "rec3")),
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(buf, []byte("<tse:FindRecordings>")):
+			fmt.Fprintf(w, responseEnvelope, `<tse:FindRecordingsResponse><tse:SearchToken>search1</tse:SearchToken></tse:FindRecordingsResponse>`)
+		case bytes.Contains(buf, []byte("<tse:GetRecordingSearchResults>")):
+			if !bytes.Contains(buf, []byte("<tse:SearchToken>search1</tse:SearchToken>")) {
+				t.Errorf("unexpected search token: %s", buf)
+			}
+			fmt.Fprintf(w, responseEnvelope, pages[0])
+			pages = pages[1:]
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	search.PollInterval = time.Millisecond
+	c, err := search.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceSearch, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx := context.Background()
+	s, err := c.SearchRecordings(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("could not start search: %v", err)
+	}
+	defer s.Close(ctx)
+
+	var tokens []string
+	for {
+		results, err := s.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not get results: %v", err)
+		}
+		for _, r := range results {
+			tokens = append(tokens, r.RecordingToken)
+		}
+	}
+
+	if fmt.Sprint(tokens) != "[rec1 rec2 rec3]" {
+		t.Errorf("expected [rec1 rec2 rec3], got %v", tokens)
+	}
+}
+
+const findEventResult = `<tt:Result><tt:RecordingToken>rec1</tt:RecordingToken><tt:TrackToken>track1</tt:TrackToken><tt:Time>%s</tt:Time>
+<tt:Event><wsnt:Topic Dialect="http://www.onvif.org/ver10/tev/topicExpression/ConcreteSet">tns1:VideoSource/MotionAlarm</wsnt:Topic>
+<wsnt:Message><tt:Message %s><tt:Data><tt:SimpleItem Name="State" Value="true"/></tt:Data></tt:Message></wsnt:Message></tt:Event></tt:Result>`
+
+func TestReplayEvents(t *testing.T) {
+	pages := []string{
+		`<tse:GetEventSearchResultsResponse><tse:ResultList><tt:SearchState>Searching</tt:SearchState>` +
+			fmt.Sprintf(findEventResult, "2020-01-01T00:00:00Z", `UtcTime="2020-01-01T00:00:00Z"`) + `</tse:ResultList></tse:GetEventSearchResultsResponse>`,
+		`<tse:GetEventSearchResultsResponse><tse:ResultList><tt:SearchState>Completed</tt:SearchState>` +
+			fmt.Sprintf(findEventResult, "2020-01-01T00:01:00Z", "") + `</tse:ResultList></tse:GetEventSearchResultsResponse>`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(buf, []byte("<tse:FindEvents>")):
+			fmt.Fprintf(w, responseEnvelope, `<tse:FindEventsResponse><tse:SearchToken>search1</tse:SearchToken></tse:FindEventsResponse>`)
+		case bytes.Contains(buf, []byte("<tse:GetEventSearchResults>")):
+			fmt.Fprintf(w, responseEnvelope, pages[0])
+			pages = pages[1:]
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	search.PollInterval = time.Millisecond
+	c, err := search.NewClient(&onvif.Client{}, onvif.Services{{Namespace: onvif.NamespaceSearch, URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	notifications := make(chan *events.Notification, 2)
+	if err = c.ReplayEvents(context.Background(), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}, nil, nil, nil, notifications); err != nil {
+		t.Fatalf("could not replay events: %v", err)
+	}
+	close(notifications)
+
+	var times []string
+	for n := range notifications {
+		if !n.Is(events.TopicMotionAlarm) || !n.Data.Bool("State") {
+			t.Errorf("unexpected notification: %#v", n)
+		}
+		times = append(times, n.Time.Format(time.RFC3339))
+	}
+	if fmt.Sprint(times) != "[2020-01-01T00:00:00Z 2020-01-01T00:01:00Z]" {
+		t.Errorf("unexpected notification times: %v", times)
+	}
+}
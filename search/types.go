@@ -0,0 +1,75 @@
+package search
+
+import (
+	"encoding/xml"
+
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/recording"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// SearchState is an ONVIF SearchState
+type SearchState string
+
+// SearchStates
+const (
+	SearchStateQueued    SearchState = "Queued"
+	SearchStateSearching SearchState = "Searching"
+	SearchStateCompleted SearchState = "Completed"
+	SearchStateUnknown   SearchState = "Unknown"
+)
+
+// SearchScope is an ONVIF SearchScope type. Empty fields don't limit the search
+type SearchScope struct {
+	IncludedSources    []*recording.SourceReference `xml:",omitempty"`
+	IncludedRecordings []string                     `xml:",omitempty"`
+	// RecordingInformationFilter is an XPath expression matching the recordings to search
+	RecordingInformationFilter string `xml:",omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler. The tt prefix is used for the child elements
+func (s *SearchScope) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type scope SearchScope
+	return soap.EncodeElementPrefixed(enc, (*scope)(s), start, "tt")
+}
+
+// EventFilter is an ONVIF EventFilter type, used to limit the events returned by FindEvents
+type EventFilter struct {
+	TopicExpression *events.TopicExpressionFilter
+	MessageContent  *events.MessageContentFilter
+}
+
+// TrackInformation is an ONVIF TrackInformation type
+type TrackInformation struct {
+	TrackToken  string
+	TrackType   recording.TrackType
+	Description string
+	// DataFrom and DataTo are the xsd:dateTime range of the track's data. See events.ParseDateTime
+	DataFrom string
+	DataTo   string
+}
+
+// RecordingInformation is an ONVIF RecordingInformation type
+type RecordingInformation struct {
+	RecordingToken string
+	Source         *recording.RecordingSourceInformation
+	// EarliestRecording and LatestRecording are xsd:dateTimes. See events.ParseDateTime
+	EarliestRecording string
+	LatestRecording   string
+	Content           string
+	Track             []*TrackInformation
+	// RecordingStatus is Initiated, Recording, Stopped, Removing, Removed, or Unknown
+	RecordingStatus string
+}
+
+// FindEventResult is an ONVIF FindEventResult type
+type FindEventResult struct {
+	RecordingToken string
+	TrackToken     string
+	// Time is the xsd:dateTime of the event. See events.ParseDateTime
+	Time string
+	// Event is the raw wsnt:NotificationMessage. See events.DecodeElement
+	Event *soap.Element
+	// StartStateEvent is true if the result is the state of a property at the start of the search, rather than a change
+	StartStateEvent bool
+}
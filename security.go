@@ -0,0 +1,64 @@
+package onvif
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// MaxSecurityReuse is the maximum value of Client.SecurityReuse. Larger values are clamped
+const MaxSecurityReuse = 30 * time.Second
+
+// cachedSecurity is a reusable WS-Security header. It only holds password digests, so password is a hash of the password used to detect changes
+type cachedSecurity struct {
+	security *soap.Security
+	password [sha256.Size]byte
+	expires  time.Time
+}
+
+// security returns a WS-Security header for cred, with a plain text password if text is true,
+// reusing a cached header if Client.SecurityReuse is set. Headers with plain text passwords are never cached, so the password isn't held between requests
+func (c *Client) security(cred *credentials, text bool) (*soap.Security, error) {
+	reuse := c.SecurityReuse
+	opts := &soap.SecurityOptions{Clock: c.securityClock(), Rand: c.rand(), TimestampTTL: c.TimestampTTL, PasswordText: text}
+	if reuse <= 0 || text {
+		return soap.NewSecurityBytes(cred.username, cred.password, opts)
+	}
+	if reuse > MaxSecurityReuse {
+		reuse = MaxSecurityReuse
+	}
+	// don't reuse a header after its timestamp expires
+	if c.TimestampTTL > 0 && reuse > c.TimestampTTL {
+		reuse = c.TimestampTTL
+	}
+
+	password := sha256.Sum256(cred.password)
+	now := c.clock().Now()
+
+	c.securityMu.Lock()
+	defer c.securityMu.Unlock()
+
+	if s, ok := c.securityCache[cred.username]; ok && s.password == password && now.Before(s.expires) {
+		return s.security, nil
+	}
+
+	s, err := soap.NewSecurityBytes(cred.username, cred.password, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.securityCache == nil {
+		c.securityCache = make(map[string]*cachedSecurity)
+	}
+	c.securityCache[cred.username] = &cachedSecurity{security: s, password: password, expires: now.Add(reuse)}
+
+	return s, nil
+}
+
+// forgetSecurity removes any cached WS-Security header for cred
+func (c *Client) forgetSecurity(cred *credentials) {
+	c.securityMu.Lock()
+	defer c.securityMu.Unlock()
+	delete(c.securityCache, cred.username)
+}
@@ -1,8 +1,12 @@
 package onvif
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/korylprince/go-onvif/soap"
@@ -53,22 +57,113 @@ type Service struct {
 	URL          string `xml:"XAddr"`
 	VersionMajor int    `xml:"Version>Major"`
 	VersionMinor int    `xml:"Version>Minor"`
+	// Capabilities is the raw capabilities of the service, if returned by GetServicesWithCapabilities. See DecodeCapabilities
+	Capabilities *soap.Element `xml:"Capabilities,omitempty"`
+}
+
+// DecodeCapabilities unmarshals the service's capabilities into v, the service sub-package's Capabilities type (e.g. *ptz.Capabilities).
+// An error wrapping soap.ErrNoResponse is returned if the capabilities weren't returned
+func (s *Service) DecodeCapabilities(v interface{}) error {
+	if s.Capabilities == nil || len(bytes.TrimSpace(s.Capabilities.InnerXML)) == 0 {
+		return fmt.Errorf("capabilities not returned for %s: %w", s.Namespace, soap.ErrNoResponse)
+	}
+
+	if err := xml.Unmarshal(s.Capabilities.InnerXML, v); err != nil {
+		return fmt.Errorf("could not unmarshal capabilities: %w", err)
+	}
+
+	return nil
 }
 
 // Services is a list of Services
 type Services []*Service
 
-// URL returns the service URL for the given namespace or the empty string if the service isn't found
-func (s Services) URL(namespace string) string {
+// knownNamespaces are the service namespaces GetServices normalizes reported namespaces to
+var knownNamespaces = []string{
+	NamespaceDevice, NamespaceEvents, NamespaceAccessControl, NamespaceAccessRules, NamespaceActionEngine, NamespaceAdvancedSecurity,
+	NamespaceAnalytics, NamespaceAnalyticsDevice, NamespaceAppMgmt, NamespaceAuthenticationBehavior, NamespaceCredential, NamespaceDeviceIO,
+	NamespaceDisplay, NamespaceDoorControl, NamespaceFederatedSearch, NamespaceImaging, NamespaceMedia, NamespaceMedia2, NamespacePTZ,
+	NamespaceProvisioning, NamespaceReceiver, NamespaceRecording, NamespaceReplay, NamespaceSchedule, NamespaceSearch, NamespaceThermal,
+	NamespaceUplink,
+}
+
+var versionRegexp = regexp.MustCompile(`/ver\d+/`)
+
+// normalizeNamespace returns ns without surrounding whitespace or a trailing slash, in lower case
+func normalizeNamespace(ns string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(ns), "/"))
+}
+
+// namespaceFamily returns the normalized ns with its version removed, e.g. http://www.onvif.org/*/imaging/wsdl.
+// The media service returns the empty string, since its versions are different services
+func namespaceFamily(ns string) string {
+	ns = normalizeNamespace(ns)
+	if ns == normalizeNamespace(NamespaceMedia) || ns == normalizeNamespace(NamespaceMedia2) || !versionRegexp.MatchString(ns) {
+		return ""
+	}
+	return versionRegexp.ReplaceAllString(ns, "/*/")
+}
+
+// Matches returns true if the service's namespace matches namespace, ignoring case and trailing slashes
+func (s *Service) Matches(namespace string) bool {
+	return normalizeNamespace(s.Namespace) == normalizeNamespace(namespace)
+}
+
+// Find returns the service with the given namespace, or nil if the service isn't found.
+// Namespaces match ignoring case and trailing slashes. If no service matches, a service with a different version
+// of the namespace (e.g. ver10 instead of ver20) is returned, except for Media and Media2, which are different services
+func (s Services) Find(namespace string) *Service {
 	for _, svc := range s {
-		if svc.Namespace == namespace {
-			return svc.URL
+		if svc.Matches(namespace) {
+			return svc
 		}
 	}
 
+	family := namespaceFamily(namespace)
+	if family == "" {
+		return nil
+	}
+	for _, svc := range s {
+		if namespaceFamily(svc.Namespace) == family {
+			return svc
+		}
+	}
+
+	return nil
+}
+
+// Best returns the service for the first of namespaces that's found, in order of preference, or nil if none are found.
+// For example, Best(NamespaceMedia2, NamespaceMedia) prefers Media2 over Media. See Find
+func (s Services) Best(namespaces ...string) *Service {
+	for _, ns := range namespaces {
+		if svc := s.Find(ns); svc != nil {
+			return svc
+		}
+	}
+	return nil
+}
+
+// URL returns the service URL for the given namespace or the empty string if the service isn't found. See Find
+func (s Services) URL(namespace string) string {
+	if svc := s.Find(namespace); svc != nil {
+		return svc.URL
+	}
 	return ""
 }
 
+// normalize sets the namespace of services matching a known namespace, ignoring case and trailing slashes (see Service.Matches),
+// to the namespace constant, so Service.Namespace can be used in requests. The reported version is kept
+func (s Services) normalize() {
+	for _, svc := range s {
+		for _, ns := range knownNamespaces {
+			if svc.Matches(ns) {
+				svc.Namespace = ns
+				break
+			}
+		}
+	}
+}
+
 // GetServicesResponse is an ONVIF GetServicesResponse response
 type GetServicesResponse struct {
 	Service Services
@@ -76,18 +171,35 @@ type GetServicesResponse struct {
 
 // GetServices returns the service urls from the remote device.
 // addr is the host:port pair of the device. Just the host part can be specified as well.
+// A full URL (e.g. https://192.168.0.64:8443 or https://192.168.0.64/onvif/device_service) can also be used. See Client.UseTLS
 func (c *Client) GetServices(addr string) (Services, error) {
+	return c.GetServicesContext(context.Background(), addr)
+}
+
+// GetServicesContext is like GetServices, but ctx controls the request(s)
+func (c *Client) GetServicesContext(ctx context.Context, addr string) (Services, error) {
+	return c.getServices(ctx, addr, false)
+}
+
+// GetServicesWithCapabilities is like GetServicesContext, but the capabilities of each service are also returned. See Service.DecodeCapabilities.
+// If the device doesn't support GetServices, the services from GetCapabilities are returned without capabilities
+func (c *Client) GetServicesWithCapabilities(ctx context.Context, addr string) (Services, error) {
+	return c.getServices(ctx, addr, true)
+}
+
+func (c *Client) getServices(ctx context.Context, addr string, includeCapability bool) (Services, error) {
 	req := &Request{
-		URL:        fmt.Sprintf("http://%s/onvif/device_service", addr),
+		URL:        c.deviceServiceURL(addr),
 		Namespaces: soap.Namespaces{"tds": NamespaceDevice},
-		Body:       &GetServices{IncludeCapability: false},
+		Body:       &GetServices{IncludeCapability: includeCapability},
 	}
-	env, err := c.Do(req)
+	env, err := c.DoContext(ctx, req)
 	if err != nil {
 		// if GetServices isn't implemented, try GetCapabilities
-		if f, ok := err.(*soap.Fault); ok {
+		var f *soap.Fault
+		if errors.As(err, &f) {
 			if strings.Contains(strings.ToLower(f.Reason), "unknown action") || strings.Contains(strings.ToLower(f.Reason), "not implemented") {
-				services, err := c.GetCapabilities(addr)
+				services, err := c.GetCapabilitiesContext(ctx, addr)
 				if err != nil {
 					return nil, fmt.Errorf("could not get services via GetServices or GetCapabilities: %w", err)
 				}
@@ -103,6 +215,11 @@ func (c *Client) GetServices(addr string) (Services, error) {
 		return nil, fmt.Errorf("could not unmarshal response: %w", err)
 	}
 
+	services.Service.normalize()
+	if q := c.CurrentQuirks(); q != nil && q.RewriteXAddrHost {
+		services.Service.rewriteHost(c.deviceHost(addr))
+	}
+
 	return services.Service, nil
 }
 
@@ -129,59 +246,22 @@ type GetCapabilitiesResponse struct {
 }
 
 // GetCapabilities returns the service urls from the remote device. Most users should use GetServices instead.
-// addr is the host:port pair of the device. Just the host part can be specified as well.
+// See GetAllCapabilities to get the full capability details.
+// addr is the host:port pair of the device, or a full URL. See GetServices.
 func (c *Client) GetCapabilities(addr string) (Services, error) {
-	req := &Request{
-		URL:        fmt.Sprintf("http://%s/onvif/device_service", addr),
-		Namespaces: soap.Namespaces{"tds": NamespaceDevice},
-		Body:       &GetCapabilities{Category: "All"},
-	}
-	env, err := c.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("could not complete operation: %w", err)
-	}
+	return c.GetCapabilitiesContext(context.Background(), addr)
+}
 
-	cap := new(GetCapabilitiesResponse)
-	if err := env.Body.Unmarshal(cap); err != nil {
-		return nil, fmt.Errorf("could not unmarshal response: %w", err)
+// GetCapabilitiesContext is like GetCapabilities, but ctx controls the request
+func (c *Client) GetCapabilitiesContext(ctx context.Context, addr string) (Services, error) {
+	cap, err := c.GetAllCapabilitiesContext(ctx, addr)
+	if err != nil {
+		return nil, err
 	}
 
-	var services Services
-	if url := cap.DeviceURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceDevice, URL: url})
-	}
-	if url := cap.EventsURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceEvents, URL: url})
-	}
-	if url := cap.ImagingURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceImaging, URL: url})
-	}
-	if url := cap.MediaURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceMedia, URL: url})
-	}
-	if url := cap.PTZURL; url != "" {
-		services = append(services, &Service{Namespace: NamespacePTZ, URL: url})
-	}
-	if url := cap.DeviceIOURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceDeviceIO, URL: url})
-	}
-	if url := cap.DisplayURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceDisplay, URL: url})
-	}
-	if url := cap.RecordingURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceRecording, URL: url})
-	}
-	if url := cap.SearchURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceSearch, URL: url})
-	}
-	if url := cap.ReplayURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceReplay, URL: url})
-	}
-	if url := cap.ReceiverURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceReceiver, URL: url})
-	}
-	if url := cap.AnalyticsDeviceURL; url != "" {
-		services = append(services, &Service{Namespace: NamespaceAnalyticsDevice, URL: url})
+	services := cap.Services()
+	if q := c.CurrentQuirks(); q != nil && q.RewriteXAddrHost {
+		services.rewriteHost(c.deviceHost(addr))
 	}
 
 	return services, nil
@@ -0,0 +1,61 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// ErrServiceNotSupported indicates the device doesn't support a service
+var ErrServiceNotSupported = errors.New("service not supported")
+
+// ServiceClient is a client bound to a single ONVIF service.
+// Service sub-packages embed it, so all service clients created from the same Client share its transport, authentication state, and options
+type ServiceClient struct {
+	*Client
+	// URL is the service URL
+	URL string
+	// Namespaces will be added to the SOAP envelope of each request
+	Namespaces soap.Namespaces
+}
+
+// NewServiceClient returns a ServiceClient using c to make requests to the service with the given namespace.
+// The service URL is looked up in services. An error wrapping ErrServiceNotSupported is returned if the service isn't found.
+// namespaces will be added to the SOAP envelope of each request
+func NewServiceClient(c *Client, services Services, namespace string, namespaces soap.Namespaces) (*ServiceClient, error) {
+	url := services.URL(namespace)
+	if url == "" {
+		return nil, fmt.Errorf("could not find %s: %w", namespace, ErrServiceNotSupported)
+	}
+
+	return &ServiceClient{Client: c, URL: url, Namespaces: namespaces}, nil
+}
+
+// Call executes the operation req and unmarshals the response body into resp, if resp is not nil
+func (s *ServiceClient) Call(req, resp interface{}) error {
+	return s.CallContext(context.Background(), req, resp)
+}
+
+// CallContext is like Call, but ctx controls the request. See Client.DoContext
+func (s *ServiceClient) CallContext(ctx context.Context, req, resp interface{}) error {
+	env, err := s.DoContext(ctx, &Request{
+		URL:        s.URL,
+		Namespaces: s.Namespaces,
+		Body:       req,
+	})
+	if err != nil {
+		return fmt.Errorf("could not complete operation: %w", err)
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	if err := env.Body.Unmarshal(resp); err != nil {
+		return fmt.Errorf("could not unmarshal response: %w", err)
+	}
+
+	return nil
+}
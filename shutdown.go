@@ -0,0 +1,100 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClientClosed is returned (wrapped) by requests made after Client.Close or Client.Shutdown is called
+var ErrClientClosed = errors.New("client closed")
+
+// acquire registers an in-flight request, returning false if the Client is closed
+func (c *Client) acquire() bool {
+	c.lifeMu.Lock()
+	defer c.lifeMu.Unlock()
+	if c.closed {
+		return false
+	}
+	c.inflight.Add(1)
+	return true
+}
+
+// release unregisters an in-flight request
+func (c *Client) release() {
+	c.inflight.Done()
+}
+
+// RegisterShutdown registers f to be called by Client.Shutdown before the Client stops making new requests,
+// e.g. to unsubscribe from events. f is called with Shutdown's context. Calling unregister removes f
+func (c *Client) RegisterShutdown(f func(ctx context.Context)) (unregister func()) {
+	c.lifeMu.Lock()
+	defer c.lifeMu.Unlock()
+	if c.shutdownHooks == nil {
+		c.shutdownHooks = make(map[int]func(context.Context))
+	}
+	id := c.nextHook
+	c.nextHook++
+	c.shutdownHooks[id] = f
+
+	return func() {
+		c.lifeMu.Lock()
+		defer c.lifeMu.Unlock()
+		delete(c.shutdownHooks, id)
+	}
+}
+
+// Shutdown calls the functions registered with RegisterShutdown, stops the Client from making new requests,
+// waits for in-flight requests and open Streams to complete, then closes idle connections.
+// If ctx is done first, idle connections are closed and ctx.Err() is returned; in-flight requests are not canceled.
+// Requests made after Shutdown is called return ErrClientClosed
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.lifeMu.Lock()
+	hooks := make([]func(context.Context), 0, len(c.shutdownHooks))
+	for _, f := range c.shutdownHooks {
+		hooks = append(hooks, f)
+	}
+	c.shutdownHooks = nil
+	c.lifeMu.Unlock()
+
+	for _, f := range hooks {
+		f(ctx)
+	}
+
+	c.lifeMu.Lock()
+	c.closed = true
+	c.lifeMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+
+	defer c.closeIdleConnections()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the Client from making new requests and closes idle connections without waiting for in-flight requests
+// or calling the functions registered with RegisterShutdown. See Shutdown
+func (c *Client) Close() error {
+	c.lifeMu.Lock()
+	c.closed = true
+	c.lifeMu.Unlock()
+	c.closeIdleConnections()
+	return nil
+}
+
+// closeIdleConnections closes the idle connections of the Client's HTTPClient if the Client created it with its own transport.
+// User-provided HTTPClients and transports shared with other Clients (e.g. http.DefaultTransport or a Factory's transport) are left open
+func (c *Client) closeIdleConnections() {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if c.HTTPClient != nil && c.HTTPClient == c.ownedClient {
+		c.HTTPClient.CloseIdleConnections()
+	}
+}
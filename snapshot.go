@@ -0,0 +1,27 @@
+package onvif
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+// ErrNotJPEG is returned by GetSnapshot when the device returns content that isn't a JPEG, e.g. an HTML error page
+var ErrNotJPEG = errors.New("snapshot is not a JPEG")
+
+// GetSnapshot fetches the JPEG snapshot at uri (e.g. a URI returned by media.Client.GetSnapshotUri) using the Client's HTTPClient and credentials.
+// If the device requests HTTP digest or basic authentication, the request is retried with it. See DownloadContext.
+// The snapshot package can decode the returned JPEG
+func (c *Client) GetSnapshot(ctx context.Context, uri string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if _, err := c.DownloadContext(ctx, uri, buf); err != nil {
+		return nil, err
+	}
+
+	// check for the JPEG start of image marker
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0xff, 0xd8}) {
+		return nil, ErrNotJPEG
+	}
+
+	return buf.Bytes(), nil
+}
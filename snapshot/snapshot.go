@@ -0,0 +1,189 @@
+// Package snapshot decodes JPEG snapshots fetched from ONVIF devices
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+)
+
+// ResolutionError indicates a snapshot doesn't have the expected resolution
+type ResolutionError struct {
+	Width          int
+	Height         int
+	ExpectedWidth  int
+	ExpectedHeight int
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("unexpected resolution: got %dx%d, expected %dx%d", e.Width, e.Height, e.ExpectedWidth, e.ExpectedHeight)
+}
+
+// Decode decodes a JPEG snapshot from r and applies its EXIF orientation, if present
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := decode(r)
+	return img, err
+}
+
+// DecodeResolution is like Decode, but also returns a *ResolutionError if the encoded snapshot isn't width x height,
+// e.g. the resolution configured in the profile's video encoder configuration.
+// The resolution is checked before the EXIF orientation is applied. The decoded image is returned with a *ResolutionError
+func DecodeResolution(r io.Reader, width, height int) (image.Image, error) {
+	img, size, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if size.X != width || size.Y != height {
+		return img, &ResolutionError{Width: size.X, Height: size.Y, ExpectedWidth: width, ExpectedHeight: height}
+	}
+
+	return img, nil
+}
+
+// decode decodes and orients the JPEG in r, returning the image and its encoded size
+func decode(r io.Reader) (image.Image, image.Point, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, image.Point{}, fmt.Errorf("could not read snapshot: %w", err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, image.Point{}, fmt.Errorf("could not decode snapshot: %w", err)
+	}
+
+	return orient(img, exifOrientation(buf)), img.Bounds().Size(), nil
+}
+
+// exifOrientation returns the EXIF orientation in the JPEG buf, or 1 (normal) if it can't be found
+func exifOrientation(buf []byte) int {
+	if len(buf) < 2 || buf[0] != 0xFF || buf[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(buf); {
+		if buf[i] != 0xFF {
+			return 1
+		}
+		marker := buf[i+1]
+		// start of scan; no more metadata
+		if marker == 0xDA {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(buf[i+2:]))
+		if length < 2 || i+2+length > len(buf) {
+			return 1
+		}
+		// APP1
+		if marker == 0xE1 {
+			if o := tiffOrientation(buf[i+4 : i+2+length]); o != 0 {
+				return o
+			}
+		}
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// tiffOrientation returns the orientation tag from the Exif APP1 segment data, or 0 if it can't be found
+func tiffOrientation(seg []byte) int {
+	if len(seg) < 14 || string(seg[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := seg[6:]
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		// orientation tag, type SHORT
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+
+	return 0
+}
+
+// orient returns img transformed for display according to the EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// src returns the source point for the destination point (x, y)
+	var (
+		src    func(x, y int) (int, int)
+		dw, dh = w, h
+	)
+
+	switch orientation {
+	case 2: // flip horizontal
+		src = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3: // rotate 180
+		src = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4: // flip vertical
+		src = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5: // transpose
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return y, x }
+	case 6: // rotate 90 clockwise
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return y, h - 1 - x }
+	case 7: // transverse
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case 8: // rotate 90 counter-clockwise
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	// convert to a type with fast pixel access
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	} else {
+		rgba = rgba.SubImage(b).(*image.RGBA)
+	}
+	rb := rgba.Bounds()
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := src(x, y)
+			dst.SetRGBA(x, y, rgba.RGBAAt(rb.Min.X+sx, rb.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
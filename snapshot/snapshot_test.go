@@ -0,0 +1,105 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/korylprince/go-onvif/snapshot"
+)
+
+// testJPEG returns a 16x8 JPEG with a white left half and black right half and the given EXIF orientation
+func testJPEG(t *testing.T, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			if x < 8 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("could not encode jpeg: %v", err)
+	}
+
+	if orientation == 0 {
+		return buf.Bytes()
+	}
+
+	// big endian TIFF header with a single orientation IFD entry
+	seg := new(bytes.Buffer)
+	seg.WriteString("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08")
+	for _, v := range []interface{}{uint16(1), uint16(0x0112), uint16(3), uint32(1), orientation, [6]byte{}} {
+		binary.Write(seg, binary.BigEndian, v)
+	}
+
+	app1 := new(bytes.Buffer)
+	app1.Write([]byte{0xFF, 0xE1})
+	binary.Write(app1, binary.BigEndian, uint16(seg.Len()+2))
+	app1.Write(seg.Bytes())
+
+	b := buf.Bytes()
+	return append(append(append([]byte{}, b[:2]...), app1.Bytes()...), b[2:]...)
+}
+
+func isWhite(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xc000 && g > 0xc000 && b > 0xc000
+}
+
+func TestDecodeOrientation(t *testing.T) {
+	for _, test := range []struct {
+		orientation   uint16
+		width, height int
+		// white is a point that should be white after orientation
+		white image.Point
+	}{
+		{0, 16, 8, image.Pt(0, 0)},
+		{1, 16, 8, image.Pt(0, 0)},
+		{3, 16, 8, image.Pt(15, 7)},
+		{6, 8, 16, image.Pt(0, 0)},
+		{8, 8, 16, image.Pt(0, 15)},
+	} {
+		img, err := snapshot.Decode(bytes.NewReader(testJPEG(t, test.orientation)))
+		if err != nil {
+			t.Fatalf("orientation %d: could not decode: %v", test.orientation, err)
+		}
+
+		if size := img.Bounds().Size(); size.X != test.width || size.Y != test.height {
+			t.Errorf("orientation %d: expected %dx%d, got %dx%d", test.orientation, test.width, test.height, size.X, size.Y)
+			continue
+		}
+
+		if !isWhite(img.At(test.white.X, test.white.Y)) {
+			t.Errorf("orientation %d: expected %v to be white", test.orientation, test.white)
+		}
+	}
+}
+
+func TestDecodeResolution(t *testing.T) {
+	buf := testJPEG(t, 6)
+
+	if _, err := snapshot.DecodeResolution(bytes.NewReader(buf), 16, 8); err != nil {
+		t.Errorf("expected resolution to match, got: %v", err)
+	}
+
+	img, err := snapshot.DecodeResolution(bytes.NewReader(buf), 1920, 1080)
+	var resErr *snapshot.ResolutionError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("expected *snapshot.ResolutionError, got: %v", err)
+	}
+	if resErr.Width != 16 || resErr.Height != 8 {
+		t.Errorf("expected resolution 16x8, got %dx%d", resErr.Width, resErr.Height)
+	}
+	if img == nil {
+		t.Error("expected image to be returned with resolution error")
+	}
+}
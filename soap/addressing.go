@@ -0,0 +1,93 @@
+package soap
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// NamespaceWSAddressing is the WS-Addressing 1.0 namespace
+const NamespaceWSAddressing = "http://www.w3.org/2005/08/addressing"
+
+// AddressAnonymous is the WS-Addressing anonymous address, which indicates the reply is sent in the HTTP response
+const AddressAnonymous = NamespaceWSAddressing + "/anonymous"
+
+// Addressing is a set of WS-Addressing message headers. Empty fields are not marshaled
+type Addressing struct {
+	// Namespace is the WS-Addressing namespace. If empty, NamespaceWSAddressing is used
+	Namespace string
+	Action    string
+	To        string
+	MessageID string
+	// ReplyTo is the address of the wsa:ReplyTo endpoint reference, e.g. AddressAnonymous
+	ReplyTo string
+	// ReferenceParameters, if set, are the reference parameters of the endpoint reference the message is sent to.
+	// Each parameter is sent as a header with wsa:IsReferenceParameter="true"
+	ReferenceParameters *ReferenceParameters
+}
+
+// ReferenceParameters is the wsa:ReferenceParameters of an endpoint reference, e.g. a subscription manager.
+// Devices may use them instead of the address to identify the endpoint, so they must be sent with each message to it. See Addressing
+type ReferenceParameters struct {
+	Parameters []*Element `xml:",any"`
+}
+
+// MarshalXML implements xml.Marshaler. The headers are encoded as siblings, without an enclosing element
+func (a *Addressing) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	ns := a.Namespace
+	if ns == "" {
+		ns = NamespaceWSAddressing
+	}
+	xmlns := []xml.Attr{{Name: xml.Name{Local: "xmlns:wsa"}, Value: ns}}
+
+	for _, h := range []struct {
+		name  string
+		value string
+	}{
+		{"wsa:Action", a.Action},
+		{"wsa:To", a.To},
+		{"wsa:MessageID", a.MessageID},
+	} {
+		if h.value == "" {
+			continue
+		}
+		if err := enc.EncodeElement(h.value, xml.StartElement{Name: xml.Name{Local: h.name}, Attr: xmlns}); err != nil {
+			return fmt.Errorf("could not encode %s: %w", h.name, err)
+		}
+	}
+
+	if a.ReplyTo != "" {
+		v := struct {
+			Address string `xml:"wsa:Address"`
+		}{a.ReplyTo}
+		if err := enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: "wsa:ReplyTo"}, Attr: xmlns}); err != nil {
+			return fmt.Errorf("could not encode wsa:ReplyTo: %w", err)
+		}
+	}
+
+	if a.ReferenceParameters != nil {
+		for _, p := range a.ReferenceParameters.Parameters {
+			if err := p.encodeReferenceParameter(enc, xmlns[0]); err != nil {
+				return fmt.Errorf("could not encode reference parameter %s: %w", p.XMLName.Local, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeReferenceParameter encodes e as a header marked with wsa:IsReferenceParameter. xmlns declares the wsa prefix.
+// Namespace declarations from the unmarshaled element are dropped, since the element's namespace is declared when it's encoded
+func (e *Element) encodeReferenceParameter(enc *xml.Encoder, xmlns xml.Attr) error {
+	attrs := []xml.Attr{xmlns, {Name: xml.Name{Local: "wsa:IsReferenceParameter"}, Value: "true"}}
+	for _, attr := range e.Attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") || attr.Name.Local == "IsReferenceParameter" {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+
+	v := struct {
+		InnerXML []byte `xml:",innerxml"`
+	}{e.InnerXML}
+	return enc.EncodeElement(v, xml.StartElement{Name: e.XMLName, Attr: attrs})
+}
@@ -0,0 +1,190 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Decoder decodes an envelope incrementally, so large bodies (e.g. search results) can be processed without buffering them.
+// NewDecoder reads the envelope up to the first body element. The body contents are then read with Token, Decode, or DecodeElement
+type Decoder struct {
+	// Envelope is the envelope without its body contents. Envelope.Body.Fault is set if the body contains a fault
+	Envelope *Envelope
+
+	d *xml.Decoder
+	// next is the first body element, returned by the next call to Token
+	next *xml.StartElement
+	// depth is the element depth inside the body
+	depth int
+	done  bool
+}
+
+// NewDecoder returns a Decoder reading the envelope from r, with unexpected tokens handled according to strictness.
+// If the body contains a fault, it's decoded into Envelope.Body.Fault and the body contents are not available
+func NewDecoder(r io.Reader, strictness Strictness) (*Decoder, error) {
+	dec := &Decoder{Envelope: &Envelope{Strictness: strictness}, d: xml.NewDecoder(r)}
+	env := dec.Envelope
+
+	start, err := dec.nextStart()
+	if err != nil {
+		return nil, err
+	}
+	if start.Name.Local != "Envelope" {
+		return nil, UnexpectedTokenError(start.Name)
+	}
+	env.start(start)
+
+	// read up to the body
+	for {
+		tok, err := dec.d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("could not decode token: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Header" {
+				h := new(Header)
+				if err = dec.d.DecodeElement(h, &t); err != nil {
+					return nil, fmt.Errorf("could not decode header: %w", err)
+				}
+				env.Header = h
+			} else if t.Name.Local == "Body" {
+				return dec, dec.startBody()
+			} else if err = env.unexpectedElement(dec.d, t); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			// no body
+			dec.done = true
+			return dec, nil
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) != 0 {
+				if err = env.unexpected(UnexpectedTokenTypeError{Token: xml.CopyToken(tok)}); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if err = env.unexpected(UnexpectedTokenTypeError{Token: xml.CopyToken(tok)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// nextStart returns the next start element, skipping other tokens, e.g. the XML declaration
+func (dec *Decoder) nextStart() (xml.StartElement, error) {
+	for {
+		tok, err := dec.d.Token()
+		if err != nil {
+			return xml.StartElement{}, fmt.Errorf("could not decode token: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// startBody reads up to the first body element, decoding it if it's a fault
+func (dec *Decoder) startBody() error {
+	for {
+		tok, err := dec.d.Token()
+		if err != nil {
+			return fmt.Errorf("could not decode token: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Fault" {
+				f := new(Fault)
+				if err = dec.d.DecodeElement(f, &t); err != nil {
+					return fmt.Errorf("could not decode fault: %w", err)
+				}
+				f.Namespaces = dec.Envelope.Namespaces
+				dec.Envelope.Body.Fault = f
+				dec.done = true
+				return nil
+			}
+			start := t.Copy()
+			dec.next = &start
+			return nil
+		case xml.EndElement:
+			dec.done = true
+			return nil
+		}
+	}
+}
+
+// Token returns the next XML token in the body, like xml.Decoder.Token.
+// io.EOF is returned at the end of the body
+func (dec *Decoder) Token() (xml.Token, error) {
+	if dec.next != nil {
+		start := *dec.next
+		dec.next = nil
+		dec.depth++
+		return start, nil
+	}
+	if dec.done {
+		return nil, io.EOF
+	}
+
+	tok, err := dec.d.Token()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode token: %w", err)
+	}
+
+	switch tok.(type) {
+	case xml.StartElement:
+		dec.depth++
+	case xml.EndElement:
+		if dec.depth == 0 {
+			dec.done = true
+			return nil, io.EOF
+		}
+		dec.depth--
+	}
+
+	return tok, nil
+}
+
+// DecodeElement decodes the element started by start, which was returned by Token, into v, like xml.Decoder.DecodeElement
+func (dec *Decoder) DecodeElement(v interface{}, start *xml.StartElement) error {
+	if err := dec.d.DecodeElement(v, start); err != nil {
+		return fmt.Errorf("could not unmarshal: %w", err)
+	}
+	dec.depth--
+	return nil
+}
+
+// Decode decodes the next element at the current depth into v, e.g. the next response element in the body.
+// io.EOF is returned if there are no more elements at the current depth
+func (dec *Decoder) Decode(v interface{}) error {
+	depth := dec.depth
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return dec.DecodeElement(v, &t)
+		case xml.EndElement:
+			if dec.depth < depth {
+				// the enclosing element ended
+				return io.EOF
+			}
+		}
+	}
+}
+
+// Skip skips the rest of the element started by the last start element returned by Token, like xml.Decoder.Skip
+func (dec *Decoder) Skip() error {
+	if err := dec.d.Skip(); err != nil {
+		return fmt.Errorf("could not skip element: %w", err)
+	}
+	dec.depth--
+	return nil
+}
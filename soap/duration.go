@@ -0,0 +1,59 @@
+package soap
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationRegexp = regexp.MustCompile(`^(-)?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration parses an xsd:duration, e.g. PT60S or P1DT2H. Years and months aren't supported, since their length varies, unless they're zero
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	m := durationRegexp.FindStringSubmatch(s)
+	if m == nil || strings.HasSuffix(s, "P") || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	}
+
+	for _, ym := range m[2:4] {
+		if ym != "" {
+			if n, _ := strconv.Atoi(ym); n != 0 {
+				return 0, fmt.Errorf("unsupported duration with years or months: %q", s)
+			}
+		}
+	}
+
+	var d float64
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if v := m[4+i]; v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration: %q", s)
+			}
+			d += n * float64(unit)
+		}
+	}
+	if d > math.MaxInt64 {
+		return 0, fmt.Errorf("duration out of range: %q", s)
+	}
+
+	if m[1] == "-" {
+		d = -d
+	}
+
+	return time.Duration(d), nil
+}
+
+// FormatDuration formats d as an xsd:duration in seconds, e.g. PT60S
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	return sign + "PT" + strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+}
@@ -0,0 +1,81 @@
+package soap
+
+import "strings"
+
+// ErrorCode is an ONVIF fault subcode in the ter (NamespaceONVIFError) namespace.
+// Faults match ErrorCodes with errors.Is if any of their subcodes match, e.g.
+//
+//	if errors.Is(err, soap.ErrNoProfile) { ... }
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return "ONVIF fault: " + string(c)
+}
+
+// Generic ONVIF fault subcodes
+const (
+	ErrWellFormed          ErrorCode = "WellFormed"
+	ErrTagMismatch         ErrorCode = "TagMismatch"
+	ErrTag                 ErrorCode = "Tag"
+	ErrNamespace           ErrorCode = "Namespace"
+	ErrMissingAttr         ErrorCode = "MissingAttr"
+	ErrProhibAttr          ErrorCode = "ProhibAttr"
+	ErrInvalidArgs         ErrorCode = "InvalidArgs"
+	ErrInvalidArgVal       ErrorCode = "InvalidArgVal"
+	ErrUnknownAction       ErrorCode = "UnknownAction"
+	ErrOperationProhibited ErrorCode = "OperationProhibited"
+	ErrNotAuthorized       ErrorCode = "NotAuthorized"
+	ErrActionNotSupported  ErrorCode = "ActionNotSupported"
+	ErrAction              ErrorCode = "Action"
+	ErrOutOfMemory         ErrorCode = "OutofMemory"
+	ErrCriticalError       ErrorCode = "CriticalError"
+)
+
+// Specific ONVIF fault subcodes, usually nested in one of the generic subcodes
+const (
+	ErrNoProfile               ErrorCode = "NoProfile"
+	ErrNoConfig                ErrorCode = "NoConfig"
+	ErrNoEntity                ErrorCode = "NoEntity"
+	ErrNoSource                ErrorCode = "NoSource"
+	ErrNoToken                 ErrorCode = "NoToken"
+	ErrConfigModify            ErrorCode = "ConfigModify"
+	ErrConfigurationConflict   ErrorCode = "ConfigurationConflict"
+	ErrInvalidStreamSetup      ErrorCode = "InvalidStreamSetup"
+	ErrStreamConflict          ErrorCode = "StreamConflict"
+	ErrIncompleteConfiguration ErrorCode = "IncompleteConfiguration"
+	ErrMaxNVTProfiles          ErrorCode = "MaxNVTProfiles"
+	ErrNoPTZProfile            ErrorCode = "NoPTZProfile"
+	ErrInvalidPosition         ErrorCode = "InvalidPosition"
+	ErrInvalidSpeed            ErrorCode = "InvalidSpeed"
+	ErrInvalidTranslation      ErrorCode = "InvalidTranslation"
+	ErrInvalidPresetName       ErrorCode = "InvalidPresetName"
+	ErrTooManyPresets          ErrorCode = "TooManyPresets"
+	ErrNoHomePosition          ErrorCode = "NoHomePosition"
+	ErrNoImagingForSource      ErrorCode = "NoImagingForSource"
+	ErrSettingsInvalid         ErrorCode = "SettingsInvalid"
+	ErrInvalidDateTime         ErrorCode = "InvalidDateTime"
+	ErrInvalidTimeZone         ErrorCode = "InvalidTimeZone"
+	ErrNtpServerUndefined      ErrorCode = "NtpServerUndefined"
+	ErrUsernameClash           ErrorCode = "UsernameClash"
+	ErrPasswordTooLong         ErrorCode = "PasswordTooLong"
+	ErrPasswordTooWeak         ErrorCode = "PasswordTooWeak"
+	ErrUsernameMissing         ErrorCode = "UsernameMissing"
+	ErrFixedUser               ErrorCode = "FixedUser"
+	ErrTooManyUsers            ErrorCode = "TooManyUsers"
+	ErrInvalidFilterFault      ErrorCode = "InvalidFilterFault"
+	ErrInvalidToken            ErrorCode = "InvalidToken"
+	ErrNoRecording             ErrorCode = "NoRecording"
+	ErrNoTrack                 ErrorCode = "NoTrack"
+	ErrNoRecordingJob          ErrorCode = "NoRecordingJob"
+	ErrMaxRecordings           ErrorCode = "MaxRecordings"
+	ErrNoSuchService           ErrorCode = "NoSuchService"
+	ErrTooManySessions         ErrorCode = "TooManySessions"
+)
+
+// localName returns name without its namespace prefix
+func localName(name string) string {
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		return name[idx+1:]
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// prefixed returns name with its raw namespace prefix, so it can be encoded without namespace translation
+func prefixed(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
+
+// Indent returns the XML document buf indented with the given indent string, e.g. for debug output or test fixtures.
+// Namespace prefixes are preserved as-is and whitespace-only text is removed
+func Indent(buf []byte, indent string) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(buf))
+	out := new(bytes.Buffer)
+	enc := xml.NewEncoder(out)
+	enc.Indent("", indent)
+
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not decode token: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			t.Name = prefixed(t.Name)
+			attrs := make([]xml.Attr, len(t.Attr))
+			for i, attr := range t.Attr {
+				attrs[i] = xml.Attr{Name: prefixed(attr.Name), Value: attr.Value}
+			}
+			t.Attr = attrs
+			tok = t
+		case xml.EndElement:
+			t.Name = prefixed(t.Name)
+			tok = t
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		case xml.ProcInst:
+			// the encoder only allows the xml declaration as the first token
+			if out.Len() != 0 {
+				continue
+			}
+		}
+
+		if err = enc.EncodeToken(tok); err != nil {
+			return nil, fmt.Errorf("could not encode token: %w", err)
+		}
+
+		// the encoder doesn't indent the first element after the xml declaration
+		if _, ok := tok.(xml.ProcInst); ok {
+			if err = enc.Flush(); err != nil {
+				return nil, fmt.Errorf("could not flush encoder: %w", err)
+			}
+			out.WriteByte('\n')
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, fmt.Errorf("could not flush encoder: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// EncodeElementPrefixed encodes v with enc as the element start, adding prefix to the names of child elements without one.
+// This allows a type with unprefixed tags (needed to unmarshal responses) to also be used in requests.
+// v must not be a type whose MarshalXML calls EncodeElementPrefixed with itself; use a local type definition instead
+func EncodeElementPrefixed(enc *xml.Encoder, v interface{}, start xml.StartElement, prefix string) error {
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(buf))
+	depth := 0
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not decode token: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				t.Name = start.Name
+				t.Attr = append(append([]xml.Attr(nil), start.Attr...), t.Attr...)
+			} else {
+				t.Name = prefixName(t.Name, prefix)
+			}
+			depth++
+			tok = t
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				t.Name = start.Name
+			} else {
+				t.Name = prefixName(t.Name, prefix)
+			}
+			tok = t
+		}
+
+		if err = enc.EncodeToken(tok); err != nil {
+			return fmt.Errorf("could not encode token: %w", err)
+		}
+	}
+}
+
+// prefixName returns name with prefix added if it doesn't have one
+func prefixName(name xml.Name, prefix string) xml.Name {
+	if name.Space == "" {
+		return xml.Name{Local: prefix + ":" + name.Local}
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
@@ -6,16 +6,21 @@ import (
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"time"
 )
 
 const (
-	typePassword = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
-	typeNonce    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+	typePassword     = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+	typePasswordText = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordText"
+	typeNonce        = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+
+	timestampFormat = "2006-01-02T15:04:05Z"
 )
 
 // Security is a SOAP security header
 type Security struct {
+	Timestamp     *Timestamp
 	UsernameToken *UsernameToken
 }
 
@@ -29,6 +34,12 @@ func (s *Security) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
 		return fmt.Errorf("could not encode start token: %w", err)
 	}
 
+	if s.Timestamp != nil {
+		if err := enc.Encode(s.Timestamp); err != nil {
+			return fmt.Errorf("could not encode timestamp: %w", err)
+		}
+	}
+
 	if err := enc.Encode(s.UsernameToken); err != nil {
 		return fmt.Errorf("could not encode username token: %w", err)
 	}
@@ -40,6 +51,13 @@ func (s *Security) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
 	return nil
 }
 
+// Timestamp is a WS-Security timestamp, which limits how long a message is valid
+type Timestamp struct {
+	XMLName xml.Name `xml:"wsu:Timestamp"`
+	Created string   `xml:"wsu:Created"`
+	Expires string   `xml:"wsu:Expires"`
+}
+
 // Password is the password part of the username token
 type Password struct {
 	XMLName  xml.Name `xml:"wsse:Password"`
@@ -63,26 +81,92 @@ type UsernameToken struct {
 	Created  string `xml:"wsu:Created"`
 }
 
+// Clock is a source of the current time
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the Clock returning the system time
+var SystemClock Clock = systemClock{}
+
+// SecurityOptions configures the Security header created by NewSecurityWithOptions
+type SecurityOptions struct {
+	// Clock is used for the Created time. If nil, SystemClock is used
+	Clock Clock
+	// Rand is used to generate the nonce. If nil, crypto/rand.Reader is used
+	Rand io.Reader
+	// TimestampTTL, if greater than zero, adds a Timestamp that expires TimestampTTL after it's created
+	TimestampTTL time.Duration
+	// If PasswordText is true, the password is sent in plain text instead of as a digest, for devices that don't support PasswordDigest.
+	// It should only be used over TLS
+	PasswordText bool
+}
+
 // NewSecurity returns the SOAP Security header
 func NewSecurity(username, password string) (*Security, error) {
+	return NewSecurityWithOptions(username, password, nil)
+}
+
+// NewSecurityText returns the SOAP Security header with a plain text password. See SecurityOptions.PasswordText
+func NewSecurityText(username, password string) (*Security, error) {
+	return NewSecurityWithOptions(username, password, &SecurityOptions{PasswordText: true})
+}
+
+// NewSecurityWithOptions returns the SOAP Security header configured with opts, which may be nil
+func NewSecurityWithOptions(username, password string, opts *SecurityOptions) (*Security, error) {
+	return NewSecurityBytes(username, []byte(password), opts)
+}
+
+// NewSecurityBytes is like NewSecurityWithOptions, but the password is a byte slice, so the caller can zero it when the header is no longer needed.
+// The password digest is computed from password without copying it. With SecurityOptions.PasswordText, the header holds a copy of the plain text password
+func NewSecurityBytes(username string, password []byte, opts *SecurityOptions) (*Security, error) {
+	var (
+		clock  = SystemClock
+		random = rand.Reader
+	)
+	if opts != nil && opts.Clock != nil {
+		clock = opts.Clock
+	}
+	if opts != nil && opts.Rand != nil {
+		random = opts.Rand
+	}
+
 	nonce := make([]byte, 16)
-	if _, err := rand.Read(nonce); err != nil {
+	if _, err := io.ReadFull(random, nonce); err != nil {
 		return nil, fmt.Errorf("could not generate nonce: %w", err)
 	}
-	created := time.Now().UTC().Format("2006-01-02T15:04:05")
+	now := clock.Now().UTC()
+	created := now.Format("2006-01-02T15:04:05")
 
 	hash := sha1.New()
 	hash.Write(nonce)
 	hash.Write([]byte(created))
-	hash.Write([]byte(password))
+	hash.Write(password)
+
+	p := &Password{Type: typePassword, Password: base64.StdEncoding.EncodeToString(hash.Sum(nil))}
+	if opts != nil && opts.PasswordText {
+		p = &Password{Type: typePasswordText, Password: string(password)}
+	}
+
+	var ts *Timestamp
+	if opts != nil && opts.TimestampTTL > 0 {
+		ts = &Timestamp{
+			Created: now.Format(timestampFormat),
+			Expires: now.Add(opts.TimestampTTL).Format(timestampFormat),
+		}
+	}
 
 	return &Security{
+		Timestamp: ts,
 		UsernameToken: &UsernameToken{
 			Username: username,
-			Password: &Password{
-				Type:     typePassword,
-				Password: base64.StdEncoding.EncodeToString(hash.Sum(nil)),
-			},
+			Password: p,
 			Nonce: &Nonce{
 				EncodingType: typeNonce,
 				Nonce:        base64.StdEncoding.EncodeToString(nonce),
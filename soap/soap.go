@@ -5,12 +5,14 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
 // SOAP Namespaces
 const (
-	NamespaceEnvelope = "http://www.w3.org/2003/05/soap-envelope"
+	NamespaceEnvelope   = "http://www.w3.org/2003/05/soap-envelope"
+	NamespaceEnvelope11 = "http://schemas.xmlsoap.org/soap/envelope/"
 
 	NamspaceWSSSecExt   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
 	NamespaceWSSUtility = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
@@ -46,43 +48,135 @@ func (e *UnauthorizedError) Error() string {
 	return fmt.Sprintf("unauthorized: %s", e.Err.Error())
 }
 
+// Unwrap allows UnauthorizedError to be used with errors.Is and errors.As, e.g. to get the *Fault returned by the device
+func (e *UnauthorizedError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows UnauthorizedError to be matched with errors.Is against ErrNotAuthorized, including HTTP 401 responses without a fault
+func (e *UnauthorizedError) Is(target error) bool {
+	return target == ErrNotAuthorized
+}
+
 // Namespaces is a mapping of XML namespaces of the form xmlns:<name> -> <url>
 //
 // Example: Namespaces{"tds": "http://www.onvif.org/ver10/device/wsdl"}
 type Namespaces map[string]string
 
+// attrs returns the xmlns attributes for the namespaces, sorted by prefix so marshaled output is stable
+func (n Namespaces) attrs() []xml.Attr {
+	names := make([]string, 0, len(n))
+	for name := range n {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]xml.Attr, 0, len(n))
+	for _, name := range names {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + name}, Value: n[name]})
+	}
+
+	return attrs
+}
+
+// Strictness controls how unexpected tokens in an envelope are handled when unmarshaling
+type Strictness int
+
+// Strictness levels
+const (
+	// StrictnessError returns an error for unexpected tokens. This is the default
+	StrictnessError Strictness = iota
+	// StrictnessWarn skips unexpected tokens and records them in Envelope.Warnings
+	StrictnessWarn
+	// StrictnessCollect is like StrictnessWarn, but unexpected elements are also collected in Envelope.Extra
+	StrictnessCollect
+)
+
+// Version is a SOAP version. The zero value is SOAP 1.2
+type Version int
+
+// SOAP versions
+const (
+	Version12 Version = iota
+	// Version11 is SOAP 1.1, spoken by some older devices. Its envelopes use NamespaceEnvelope11 and its faults have a different shape
+	Version11
+)
+
+// Namespace returns the envelope namespace for the version
+func (v Version) Namespace() string {
+	if v == Version11 {
+		return NamespaceEnvelope11
+	}
+	return NamespaceEnvelope
+}
+
+// ContentType returns the HTTP content type for the version. SOAP 1.1 requests must also send the action in the SOAPAction header
+func (v Version) ContentType() string {
+	if v == Version11 {
+		return "text/xml; charset=utf-8"
+	}
+	return "application/soap+xml; charset=utf-8"
+}
+
+func (v Version) String() string {
+	if v == Version11 {
+		return "SOAP 1.1"
+	}
+	return "SOAP 1.2"
+}
+
+// Element is a raw XML element
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML []byte     `xml:",innerxml"`
+}
+
 // Envelope is the body of the SOAP message
 type Envelope struct {
 	// Namespaces is the additional namespaces set on the envelope
 	Namespaces map[string]string `xml:"-"`
-	Header     *Header
+	// Version is the SOAP version used when marshaling. When unmarshaling, it's set from the envelope namespace
+	Version Version `xml:"-"`
+	Header  *Header
 	// Body is guaranteed to be non-nil when the envelope is unmarshaled from XML
 	Body *Body
+	// Strictness controls how unexpected tokens are handled when unmarshaling. It must be set before unmarshaling
+	Strictness Strictness `xml:"-"`
+	// Warnings is the unexpected tokens skipped when unmarshaling, if Strictness is not StrictnessError
+	Warnings []error `xml:"-"`
+	// Extra is the unexpected elements collected when unmarshaling, if Strictness is StrictnessCollect
+	Extra []*Element `xml:"-"`
 }
 
 // MarshalXML implements xml.Marshaler
 func (e *Envelope) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
 	start.Name = xml.Name{Local: "env:Envelope"}
 
-	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:env"}, Value: NamespaceEnvelope})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:env"}, Value: e.Version.Namespace()})
 
-	for name, val := range e.Namespaces {
-		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:" + name}, Value: val})
-	}
+	start.Attr = append(start.Attr, Namespaces(e.Namespaces).attrs()...)
 
 	if err := enc.EncodeToken(start); err != nil {
 		return fmt.Errorf("could not encode start token: %w", err)
 	}
 
 	if e.Header != nil {
-		h := &header{Security: e.Header.Security}
+		h := &header{Security: e.Header.Security, Addressing: e.Header.Addressing, InnerXML: e.Header.InnerXML}
+		h.Attrs = Namespaces(e.Header.Namespaces).attrs()
 		if err := enc.Encode(h); err != nil {
 			return fmt.Errorf("could not encode header: %w", err)
 		}
 	}
 
 	if e.Body != nil {
-		b := &body{Fault: e.Body.Fault, InnerXML: e.Body.InnerXML}
+		b := &body{InnerXML: e.Body.InnerXML}
+		if e.Body.Fault != nil {
+			b.Fault = e.Body.Fault
+			if e.Version == Version11 {
+				b.Fault = newFault11(e.Body.Fault)
+			}
+		}
 		if err := enc.Encode(b); err != nil {
 			return fmt.Errorf("could not encode body: %w", err)
 		}
@@ -97,19 +191,7 @@ func (e *Envelope) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
 
 // UnmarshalXML implements xml.Unmarshaler
 func (e *Envelope) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	if e.Namespaces == nil {
-		e.Namespaces = make(Namespaces)
-	}
-	for _, attr := range start.Attr {
-		if strings.ToLower(attr.Name.Space) == "xmlns" {
-			e.Namespaces[attr.Name.Local] = attr.Value
-		}
-	}
-
-	// guarantee body is not nil
-	if e.Body == nil {
-		e.Body = new(Body)
-	}
+	e.start(start)
 
 	for {
 		tok, err := d.Token()
@@ -134,46 +216,288 @@ func (e *Envelope) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 				if e.Body.Fault != nil {
 					e.Body.Fault.Namespaces = e.Namespaces
 				}
-			} else {
-				return UnexpectedTokenError(t.Name)
+			} else if err = e.unexpectedElement(d, t); err != nil {
+				return err
 			}
 		case xml.EndElement:
 			return nil
 		case xml.CharData:
 			if len(bytes.TrimSpace(t)) != 0 {
-				return UnexpectedTokenTypeError{Token: tok}
+				if err = e.unexpected(UnexpectedTokenTypeError{Token: xml.CopyToken(tok)}); err != nil {
+					return err
+				}
 			}
 		default:
-			return UnexpectedTokenTypeError{Token: tok}
+			if err = e.unexpected(UnexpectedTokenTypeError{Token: xml.CopyToken(tok)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// start sets the envelope's version and namespaces from its start element
+func (e *Envelope) start(start xml.StartElement) {
+	if e.Namespaces == nil {
+		e.Namespaces = make(Namespaces)
+	}
+	if start.Name.Space == NamespaceEnvelope11 {
+		e.Version = Version11
+	}
+	for _, attr := range start.Attr {
+		if strings.ToLower(attr.Name.Space) == "xmlns" {
+			e.Namespaces[attr.Name.Local] = attr.Value
+		}
+	}
+
+	// guarantee body is not nil
+	if e.Body == nil {
+		e.Body = new(Body)
+	}
+}
+
+// unexpected returns err if e.Strictness is StrictnessError, otherwise it's recorded in e.Warnings
+func (e *Envelope) unexpected(err error) error {
+	if e.Strictness == StrictnessError {
+		return err
+	}
+	e.Warnings = append(e.Warnings, err)
+	return nil
+}
+
+// unexpectedElement handles an unexpected element according to e.Strictness
+func (e *Envelope) unexpectedElement(d *xml.Decoder, start xml.StartElement) error {
+	if err := e.unexpected(UnexpectedTokenError(start.Name)); err != nil {
+		return err
+	}
+
+	if e.Strictness != StrictnessCollect {
+		if err := d.Skip(); err != nil {
+			return fmt.Errorf("could not skip element: %w", err)
 		}
+		return nil
 	}
+
+	el := new(Element)
+	if err := d.DecodeElement(el, &start); err != nil {
+		return fmt.Errorf("could not decode element: %w", err)
+	}
+	e.Extra = append(e.Extra, el)
+
+	return nil
 }
 
 // Header is a SOAP message header
 type Header struct {
-	XMLName  xml.Name  `xml:"Header"`
-	Security *Security `xml:",omitempty"`
+	XMLName xml.Name `xml:"Header"`
+	// Namespaces is the additional namespaces set on the header
+	Namespaces map[string]string `xml:"-"`
+	Security   *Security         `xml:",omitempty"`
+	// Addressing, if set, is marshaled after Security. Like Security, unmarshaled WS-Addressing headers are preserved in InnerXML
+	Addressing *Addressing `xml:"-"`
+	// InnerXML is the raw XML of the header elements. It is written as-is after Security and Addressing when marshaling.
+	// When unmarshaling, all header elements (including any security header) are preserved in InnerXML
+	// so they can be inspected or forwarded
+	InnerXML []byte `xml:",innerxml"`
+}
+
+// UnmarshalXML implements xml.Unmarshaler
+func (h *Header) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		InnerXML []byte `xml:",innerxml"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	h.XMLName = start.Name
+	h.InnerXML = v.InnerXML
+	for _, attr := range start.Attr {
+		if strings.ToLower(attr.Name.Space) == "xmlns" {
+			if h.Namespaces == nil {
+				h.Namespaces = make(Namespaces)
+			}
+			h.Namespaces[attr.Name.Local] = attr.Value
+		}
+	}
+
+	return nil
 }
 
 type header struct {
-	XMLName  xml.Name  `xml:"env:Header"`
-	Security *Security `xml:",omitempty"`
+	XMLName    xml.Name    `xml:"env:Header"`
+	Attrs      []xml.Attr  `xml:",any,attr"`
+	Security   *Security   `xml:",omitempty"`
+	Addressing *Addressing `xml:",omitempty"`
+	InnerXML   []byte      `xml:",innerxml"`
 }
 
-// Fault is a SOAP message error
+// Text is a SOAP fault reason text
+type Text struct {
+	// Lang is the xml:lang attribute of the text, e.g. "en"
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// Subcode is a SOAP fault subcode, which may contain a more specific subcode
+type Subcode struct {
+	Value   string
+	Subcode *Subcode `xml:",omitempty"`
+}
+
+// Fault is a SOAP message error. SOAP 1.1 faults are unmarshaled into the same fields:
+// faultcode is Code (and SubCode, if it's not a standard SOAP 1.1 code), faultstring is Reason, and faultactor is Role
 type Fault struct {
 	Namespaces map[string]string `xml:"-"`
 	XMLName    xml.Name          `xml:"Fault"`
 	Code       string            `xml:"Code>Value"`
-	SubCode    string            `xml:"Code>Subcode>Value"`
-	Reason     string            `xml:"Reason>Text"`
-	Node       string
-	Role       string
-	Detail     struct {
+	// SubCode is the first subcode value. See Fault.SubCodes for nested subcodes
+	SubCode string `xml:"-"`
+	// Subcodes is the full subcode chain. If nil when marshaling, SubCode is used
+	Subcodes *Subcode `xml:"Code>Subcode,omitempty"`
+	// Reason is the first reason text returned. See Fault.ReasonLang to select a reason by language
+	Reason string `xml:"-"`
+	// Reasons is all reason texts returned, usually one per language
+	Reasons []*Text `xml:"Reason>Text"`
+	Node    string
+	Role    string
+	Detail  struct {
 		InnerXML []byte `xml:",innerxml"`
 	}
 }
 
+// MarshalXML implements xml.Marshaler
+func (f *Fault) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type fault Fault
+	v := *f
+	if len(v.Reasons) == 0 && v.Reason != "" {
+		v.Reasons = []*Text{{Text: v.Reason}}
+	}
+	if v.Subcodes == nil && v.SubCode != "" {
+		v.Subcodes = &Subcode{Value: v.SubCode}
+	}
+	return enc.EncodeElement((*fault)(&v), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler
+func (f *Fault) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// the embedded types must be exported for encoding/xml to set their fields
+	type (
+		Fault12 Fault
+		Fault11 fault11
+	)
+	var v struct {
+		Fault12
+		Fault11
+	}
+	v.Fault12 = Fault12(*f)
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*f = Fault(v.Fault12)
+	if len(f.Reasons) > 0 {
+		f.Reason = f.Reasons[0].Text
+	}
+	if f.Subcodes != nil {
+		f.SubCode = f.Subcodes.Value
+	}
+
+	if code := strings.TrimSpace(v.FaultCode); code != "" {
+		f.Code = code
+		switch localName(code) {
+		case "VersionMismatch", "MustUnderstand", "Client", "Server":
+		default:
+			// devices put ONVIF codes (e.g. ter:NotAuthorized) directly in faultcode
+			f.SubCode = code
+		}
+		f.Reason = strings.TrimSpace(v.FaultString)
+		f.Role = v.FaultActor
+		if v.FaultDetail != nil {
+			f.Detail.InnerXML = v.FaultDetail.InnerXML
+		}
+	}
+	return nil
+}
+
+// fault11 is a SOAP 1.1 fault
+type fault11 struct {
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	FaultActor  string `xml:"faultactor,omitempty"`
+	FaultDetail *struct {
+		InnerXML []byte `xml:",innerxml"`
+	} `xml:"detail,omitempty"`
+}
+
+type envFault11 struct {
+	XMLName xml.Name `xml:"env:Fault"`
+	fault11
+}
+
+// newFault11 returns f in the SOAP 1.1 shape
+func newFault11(f *Fault) *envFault11 {
+	code := f.Code
+	if f.SubCode != "" {
+		code = f.SubCode
+	}
+	v := &envFault11{fault11: fault11{FaultCode: code, FaultString: f.Reason, FaultActor: f.Role}}
+	if v.FaultString == "" && len(f.Reasons) > 0 {
+		v.FaultString = f.Reasons[0].Text
+	}
+	if len(f.Detail.InnerXML) > 0 {
+		v.FaultDetail = &struct {
+			InnerXML []byte `xml:",innerxml"`
+		}{f.Detail.InnerXML}
+	}
+	return v
+}
+
+// SubCodes returns the values of all nested subcodes, from least to most specific, e.g. [ter:InvalidArgVal ter:NoProfile]
+func (f *Fault) SubCodes() []string {
+	var codes []string
+	for sc := f.Subcodes; sc != nil; sc = sc.Subcode {
+		codes = append(codes, sc.Value)
+	}
+	if len(codes) == 0 && f.SubCode != "" {
+		codes = append(codes, f.SubCode)
+	}
+	return codes
+}
+
+// Is allows a Fault to be matched with errors.Is against an ErrorCode, which matches any of the fault's subcodes
+func (f *Fault) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && f.HasSubCode(code)
+}
+
+// HasSubCode returns true if any of the fault's subcodes is code, ignoring namespace prefixes
+func (f *Fault) HasSubCode(code ErrorCode) bool {
+	for _, sc := range f.SubCodes() {
+		if localName(sc) == localName(string(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReasonLang returns the reason text for the first matching language in langs, in order of preference.
+// A language matches if it is equal to the reason language, or is its primary tag, e.g. "en" matches "en-US".
+// Fault.Reason is returned if no languages match
+func (f *Fault) ReasonLang(langs ...string) string {
+	for _, lang := range langs {
+		for _, r := range f.Reasons {
+			if strings.EqualFold(r.Lang, lang) {
+				return r.Text
+			}
+		}
+		for _, r := range f.Reasons {
+			if primary := strings.SplitN(r.Lang, "-", 2)[0]; strings.EqualFold(primary, lang) {
+				return r.Text
+			}
+		}
+	}
+	return f.Reason
+}
+
 func (f *Fault) Error() string {
 	var codes []string
 	if f.Code != "" {
@@ -195,13 +519,15 @@ func (f *Fault) IsUnauthorizedError() bool {
 	soapPrefix := ""
 	errPrefix := ""
 	for prefix, ns := range f.Namespaces {
-		if ns == NamespaceEnvelope {
+		if ns == NamespaceEnvelope || ns == NamespaceEnvelope11 {
 			soapPrefix = prefix + ":"
 		} else if ns == NamespaceONVIFError {
 			errPrefix = prefix + ":"
 		}
 	}
-	return f.Code == soapPrefix+"Sender" && f.SubCode == errPrefix+"NotAuthorized"
+	// SOAP 1.1 faults use Client instead of Sender, or only the ONVIF code
+	sender := f.Code == soapPrefix+"Sender" || f.Code == soapPrefix+"Client" || f.Code == f.SubCode
+	return sender && f.SubCode == errPrefix+"NotAuthorized"
 }
 
 // Body is a SOAP message body
@@ -225,7 +551,8 @@ func (b *Body) Unmarshal(v interface{}) error {
 }
 
 type body struct {
-	XMLName  xml.Name `xml:"env:Body"`
-	Fault    *Fault   `xml:",omitempty"`
-	InnerXML []byte   `xml:",innerxml"`
+	XMLName xml.Name `xml:"env:Body"`
+	// Fault is a *Fault or, for SOAP 1.1, an *envFault11
+	Fault    interface{} `xml:",omitempty"`
+	InnerXML []byte      `xml:",innerxml"`
 }
@@ -0,0 +1,461 @@
+package soap_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+const faultMultiLang = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body><env:Fault>
+<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:InvalidArgVal</env:Value></env:Subcode></env:Code>
+<env:Reason>
+<env:Text xml:lang="de">Ungültiges Argument</env:Text>
+<env:Text xml:lang="en-US">Invalid argument</env:Text>
+</env:Reason>
+</env:Fault></env:Body>
+</env:Envelope>`
+
+func TestFaultReasonLang(t *testing.T) {
+	env := new(soap.Envelope)
+	if err := xml.Unmarshal([]byte(faultMultiLang), env); err != nil {
+		t.Fatalf("could not unmarshal envelope: %v", err)
+	}
+
+	f := env.Body.Fault
+	if f == nil {
+		t.Fatal("expected fault")
+	}
+
+	if len(f.Reasons) != 2 {
+		t.Fatalf("expected 2 reasons, got %d", len(f.Reasons))
+	}
+
+	for _, test := range []struct {
+		langs  []string
+		reason string
+	}{
+		{nil, "Ungültiges Argument"},
+		{[]string{"en"}, "Invalid argument"},
+		{[]string{"EN-us"}, "Invalid argument"},
+		{[]string{"fr", "de"}, "Ungültiges Argument"},
+		{[]string{"fr"}, "Ungültiges Argument"},
+	} {
+		if r := f.ReasonLang(test.langs...); r != test.reason {
+			t.Errorf("langs %v: expected reason %q, got %q", test.langs, test.reason, r)
+		}
+	}
+}
+
+const faultNested = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body><env:Fault>
+<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>ter:InvalidArgVal</env:Value><env:Subcode><env:Value>ter:NoProfile</env:Value></env:Subcode></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">No such profile</env:Text></env:Reason>
+</env:Fault></env:Body>
+</env:Envelope>`
+
+func TestFaultErrorCode(t *testing.T) {
+	env := new(soap.Envelope)
+	if err := xml.Unmarshal([]byte(faultNested), env); err != nil {
+		t.Fatalf("could not unmarshal envelope: %v", err)
+	}
+
+	var err error = fmt.Errorf("wrapped: %w", env.Body.Fault)
+	if f := env.Body.Fault; f.SubCode != "ter:InvalidArgVal" || fmt.Sprint(f.SubCodes()) != "[ter:InvalidArgVal ter:NoProfile]" {
+		t.Errorf("unexpected subcodes: %q, %v", f.SubCode, f.SubCodes())
+	}
+	if !errors.Is(err, soap.ErrInvalidArgVal) || !errors.Is(err, soap.ErrNoProfile) {
+		t.Error("expected fault to match its subcodes")
+	}
+	if errors.Is(err, soap.ErrNoConfig) {
+		t.Error("expected fault not to match other subcodes")
+	}
+
+	// SubCode is marshaled if Subcodes isn't set
+	buf, err := xml.Marshal(&soap.Fault{Code: "env:Sender", SubCode: "ter:NotAuthorized"})
+	if err != nil {
+		t.Fatalf("could not marshal fault: %v", err)
+	}
+	if !bytes.Contains(buf, []byte("<Subcode><Value>ter:NotAuthorized</Value></Subcode>")) {
+		t.Errorf("expected subcode, got %s", buf)
+	}
+}
+
+const envelopeHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:wsa="http://www.w3.org/2005/08/addressing">
+<env:Header xmlns:v="urn:vendor"><wsa:To>http://192.168.0.64/subscription</wsa:To><v:Session>1234</v:Session></env:Header>
+<env:Body><Response/></env:Body>
+</env:Envelope>`
+
+func TestHeaderPreserved(t *testing.T) {
+	env := new(soap.Envelope)
+	if err := xml.Unmarshal([]byte(envelopeHeader), env); err != nil {
+		t.Fatalf("could not unmarshal envelope: %v", err)
+	}
+
+	if env.Header == nil {
+		t.Fatal("expected header")
+	}
+
+	inner := `<wsa:To>http://192.168.0.64/subscription</wsa:To><v:Session>1234</v:Session>`
+	if string(env.Header.InnerXML) != inner {
+		t.Errorf("expected header InnerXML %q, got %q", inner, string(env.Header.InnerXML))
+	}
+	if ns := env.Header.Namespaces["v"]; ns != "urn:vendor" {
+		t.Errorf("expected header namespace %q, got %q", "urn:vendor", ns)
+	}
+
+	buf, err := xml.Marshal(env)
+	if err != nil {
+		t.Fatalf("could not marshal envelope: %v", err)
+	}
+
+	env2 := new(soap.Envelope)
+	if err := xml.Unmarshal(buf, env2); err != nil {
+		t.Fatalf("could not unmarshal marshaled envelope: %v", err)
+	}
+
+	if string(env2.Header.InnerXML) != inner {
+		t.Errorf("expected forwarded header InnerXML %q, got %q", inner, string(env2.Header.InnerXML))
+	}
+	if ns := env2.Header.Namespaces["v"]; ns != "urn:vendor" {
+		t.Errorf("expected forwarded header namespace %q, got %q", "urn:vendor", ns)
+	}
+}
+
+func TestMarshalStable(t *testing.T) {
+	env := &soap.Envelope{
+		Namespaces: soap.Namespaces{"tt": "urn:tt", "tds": "urn:tds", "a": "urn:a", "z": "urn:z"},
+		Body:       &soap.Body{InnerXML: []byte("<tds:Test/>")},
+	}
+
+	expected := `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:a="urn:a" xmlns:tds="urn:tds" xmlns:tt="urn:tt" xmlns:z="urn:z">` +
+		`<env:Body><tds:Test/></env:Body></env:Envelope>`
+
+	for i := 0; i < 10; i++ {
+		buf, err := xml.Marshal(env)
+		if err != nil {
+			t.Fatalf("could not marshal envelope: %v", err)
+		}
+		if string(buf) != expected {
+			t.Fatalf("expected:\n%s\ngot:\n%s", expected, string(buf))
+		}
+	}
+}
+
+func TestIndent(t *testing.T) {
+	buf, err := soap.Indent([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="urn:env"><env:Body>  <a:Test a:attr="1">text</a:Test></env:Body></env:Envelope>`), "  ")
+	if err != nil {
+		t.Fatalf("could not indent: %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="urn:env">
+  <env:Body>
+    <a:Test a:attr="1">text</a:Test>
+  </env:Body>
+</env:Envelope>`
+	if string(buf) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, string(buf))
+	}
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestNewSecurityWithOptions(t *testing.T) {
+	opts := &soap.SecurityOptions{Clock: fixedClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))}
+
+	var tokens []*soap.UsernameToken
+	for i := 0; i < 2; i++ {
+		opts.Rand = bytes.NewReader(make([]byte, 16))
+		s, err := soap.NewSecurityWithOptions("admin", "password", opts)
+		if err != nil {
+			t.Fatalf("could not create security header: %v", err)
+		}
+		tokens = append(tokens, s.UsernameToken)
+	}
+
+	if tokens[0].Created != "2020-01-02T03:04:05" {
+		t.Errorf("unexpected Created time: %q", tokens[0].Created)
+	}
+	if tokens[0].Nonce.Nonce != tokens[1].Nonce.Nonce || tokens[0].Password.Password != tokens[1].Password.Password {
+		t.Error("expected identical security headers")
+	}
+}
+
+const envelopeExtra = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+<Vendor id="1"><Info>extra</Info></Vendor>
+<env:Body><User>admin</User></env:Body>
+<!-- trailing comment -->
+</env:Envelope>`
+
+func TestEnvelopeStrictness(t *testing.T) {
+	if err := xml.Unmarshal([]byte(envelopeExtra), new(soap.Envelope)); err == nil {
+		t.Error("expected error with default strictness")
+	}
+
+	env := &soap.Envelope{Strictness: soap.StrictnessWarn}
+	if err := xml.Unmarshal([]byte(envelopeExtra), env); err != nil {
+		t.Fatalf("could not unmarshal envelope: %v", err)
+	}
+	if len(env.Warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %d", len(env.Warnings))
+	}
+	if len(env.Extra) != 0 {
+		t.Errorf("expected no extra elements, got %d", len(env.Extra))
+	}
+	if string(env.Body.InnerXML) != "<User>admin</User>" {
+		t.Errorf("unexpected body: %q", env.Body.InnerXML)
+	}
+
+	env = &soap.Envelope{Strictness: soap.StrictnessCollect}
+	if err := xml.Unmarshal([]byte(envelopeExtra), env); err != nil {
+		t.Fatalf("could not unmarshal envelope: %v", err)
+	}
+	if len(env.Extra) != 1 || env.Extra[0].XMLName.Local != "Vendor" || string(env.Extra[0].InnerXML) != "<Info>extra</Info>" {
+		t.Errorf("unexpected extra elements: %#v", env.Extra)
+	}
+}
+
+func TestSecurityTimestamp(t *testing.T) {
+	s, err := soap.NewSecurityWithOptions("admin", "password", &soap.SecurityOptions{
+		Clock:        fixedClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		TimestampTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("could not create security header: %v", err)
+	}
+
+	buf, err := xml.Marshal(s)
+	if err != nil {
+		t.Fatalf("could not marshal security header: %v", err)
+	}
+
+	expected := `<wsu:Timestamp><wsu:Created>2020-01-02T03:04:05Z</wsu:Created><wsu:Expires>2020-01-02T03:05:05Z</wsu:Expires></wsu:Timestamp><wsse:UsernameToken>`
+	if !bytes.Contains(buf, []byte(expected)) {
+		t.Errorf("expected timestamp before username token, got %s", buf)
+	}
+}
+
+type prefixedChild struct {
+	Level *float64 `xml:",omitempty"`
+}
+
+type prefixed struct {
+	Token string `xml:"token,attr"`
+	Mode  string
+	Child *prefixedChild
+}
+
+func (p *prefixed) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type alias prefixed
+	return soap.EncodeElementPrefixed(enc, (*alias)(p), start, "tt")
+}
+
+func TestEncodeElementPrefixed(t *testing.T) {
+	level := 0.5
+	v := struct {
+		XMLName xml.Name  `xml:"timg:Settings"`
+		Value   *prefixed `xml:"timg:Value"`
+	}{Value: &prefixed{Token: "1", Mode: "AUTO", Child: &prefixedChild{Level: &level}}}
+
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	expected := `<timg:Settings><timg:Value token="1"><tt:Mode>AUTO</tt:Mode><tt:Child><tt:Level>0.5</tt:Level></tt:Child></timg:Value></timg:Settings>`
+	if string(buf) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf)
+	}
+
+	p := new(prefixed)
+	if err = xml.Unmarshal([]byte(`<Value token="1"><tt:Mode>AUTO</tt:Mode></Value>`), p); err != nil || p.Mode != "AUTO" {
+		t.Errorf("could not unmarshal: %v", err)
+	}
+}
+
+const fault11 = `<?xml version="1.0" encoding="UTF-8"?>
+<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ter="http://www.onvif.org/ver10/error">
+<SOAP-ENV:Body><SOAP-ENV:Fault>
+<faultcode>ter:NotAuthorized</faultcode>
+<faultstring>Sender not Authorized</faultstring>
+<detail><Text>bad password</Text></detail>
+</SOAP-ENV:Fault></SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`
+
+func TestFault11(t *testing.T) {
+	env := new(soap.Envelope)
+	if err := xml.Unmarshal([]byte(fault11), env); err != nil {
+		t.Fatalf("could not unmarshal envelope: %v", err)
+	}
+
+	if env.Version != soap.Version11 {
+		t.Errorf("expected %v, got %v", soap.Version11, env.Version)
+	}
+
+	f := env.Body.Fault
+	if f == nil {
+		t.Fatal("expected fault")
+	}
+	if f.Code != "ter:NotAuthorized" || f.SubCode != "ter:NotAuthorized" || f.Reason != "Sender not Authorized" {
+		t.Errorf("unexpected fault: %#v", f)
+	}
+	if string(f.Detail.InnerXML) != "<Text>bad password</Text>" {
+		t.Errorf("unexpected detail: %s", f.Detail.InnerXML)
+	}
+	if !f.IsUnauthorizedError() {
+		t.Error("expected unauthorized error")
+	}
+
+	// SOAP 1.1 envelopes are marshaled with the 1.1 namespace and fault shape
+	buf, err := xml.Marshal(&soap.Envelope{Version: soap.Version11, Body: &soap.Body{Fault: &soap.Fault{Code: "env:Client", Reason: "bad request"}}})
+	if err != nil {
+		t.Fatalf("could not marshal envelope: %v", err)
+	}
+	expected := `<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body><env:Fault><faultcode>env:Client</faultcode><faultstring>bad request</faultstring></env:Fault></env:Body></env:Envelope>`
+	if string(buf) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf)
+	}
+}
+
+func TestAddressing(t *testing.T) {
+	env := &soap.Envelope{
+		Header: &soap.Header{Addressing: &soap.Addressing{
+			Action:    "urn:action",
+			To:        "http://192.168.0.64/subscription",
+			MessageID: "urn:uuid:1",
+			ReplyTo:   soap.AddressAnonymous,
+		}},
+		Body: &soap.Body{InnerXML: []byte("<Request/>")},
+	}
+
+	buf, err := xml.Marshal(env)
+	if err != nil {
+		t.Fatalf("could not marshal envelope: %v", err)
+	}
+
+	ns := `xmlns:wsa="` + soap.NamespaceWSAddressing + `"`
+	expected := `<env:Header><wsa:Action ` + ns + `>urn:action</wsa:Action><wsa:To ` + ns + `>http://192.168.0.64/subscription</wsa:To>` +
+		`<wsa:MessageID ` + ns + `>urn:uuid:1</wsa:MessageID><wsa:ReplyTo ` + ns + `><wsa:Address>` + soap.AddressAnonymous + `</wsa:Address></wsa:ReplyTo></env:Header>`
+	if !bytes.Contains(buf, []byte(expected)) {
+		t.Errorf("expected header:\n%s\ngot:\n%s", expected, buf)
+	}
+}
+
+func TestNewSecurityText(t *testing.T) {
+	s, err := soap.NewSecurityText("admin", "password")
+	if err != nil {
+		t.Fatalf("could not create security header: %v", err)
+	}
+
+	if p := s.UsernameToken.Password; p.Password != "password" || !strings.HasSuffix(p.Type, "#PasswordText") {
+		t.Errorf("unexpected password: %#v", p)
+	}
+}
+
+const envelopeResults = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tse="http://www.onvif.org/ver10/search/wsdl">
+<env:Header><Session>1</Session></env:Header>
+<env:Body><tse:Response>
+<tse:Result><tse:Token>1</tse:Token></tse:Result>
+<tse:Result><tse:Token>2</tse:Token></tse:Result>
+<tse:Result><tse:Token>3</tse:Token></tse:Result>
+</tse:Response></env:Body>
+</env:Envelope>`
+
+func TestDecoder(t *testing.T) {
+	dec, err := soap.NewDecoder(strings.NewReader(envelopeResults), soap.StrictnessError)
+	if err != nil {
+		t.Fatalf("could not create decoder: %v", err)
+	}
+	if dec.Envelope.Header == nil || dec.Envelope.Namespaces["tse"] != "http://www.onvif.org/ver10/search/wsdl" {
+		t.Errorf("unexpected envelope: %#v", dec.Envelope)
+	}
+
+	// descend into the response element
+	tok, err := dec.Token()
+	if start, ok := tok.(xml.StartElement); err != nil || !ok || start.Name.Local != "Response" {
+		t.Fatalf("unexpected token: %#v, %v", tok, err)
+	}
+
+	var tokens []string
+	for {
+		var v struct {
+			Token string
+		}
+		if err = dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("could not decode result: %v", err)
+		}
+		tokens = append(tokens, v.Token)
+	}
+	if fmt.Sprint(tokens) != "[1 2 3]" {
+		t.Errorf("unexpected tokens: %v", tokens)
+	}
+
+	if _, err = dec.Token(); err != io.EOF {
+		t.Errorf("expected EOF at end of body, got %v", err)
+	}
+
+	dec, err = soap.NewDecoder(strings.NewReader(faultNested), soap.StrictnessError)
+	if err != nil {
+		t.Fatalf("could not create decoder: %v", err)
+	}
+	if f := dec.Envelope.Body.Fault; f == nil || !errors.Is(f, soap.ErrNoProfile) {
+		t.Errorf("expected fault, got %v", f)
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		t.Errorf("expected EOF after fault, got %v", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, test := range []struct {
+		s        string
+		expected time.Duration
+		err      bool
+	}{
+		{"PT60S", time.Minute, false},
+		{"PT1.5S", 1500 * time.Millisecond, false},
+		{"P1DT2H3M", 26*time.Hour + 3*time.Minute, false},
+		{"P0Y0M0DT0H1M0S", time.Minute, false},
+		{"-PT10S", -10 * time.Second, false},
+		{"P1M", 0, true},
+		{"PT", 0, true},
+		{"60", 0, true},
+	} {
+		d, err := soap.ParseDuration(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.s, err)
+		} else if d != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.s, test.expected, d)
+		}
+	}
+
+	if s := soap.FormatDuration(90 * time.Second); s != "PT90S" {
+		t.Errorf("expected PT90S, got %s", s)
+	}
+}
@@ -0,0 +1,264 @@
+// Package storage monitors the recording capacity and storage health of ONVIF recorders (Profile G)
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/device"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/search"
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// DefaultCheckInterval and DefaultStallTimeout are used by Monitor if its Interval or StallTimeout isn't set
+const (
+	DefaultCheckInterval = time.Minute
+	DefaultStallTimeout  = 5 * time.Minute
+)
+
+// Items of storage failure events (events.TopicStorageFailure)
+const (
+	ItemToken  = "Token"
+	ItemFailed = "Failed"
+)
+
+// AlertType is the type of an Alert
+type AlertType string
+
+// AlertTypes
+const (
+	// AlertStalled is raised when no new data has been recorded for the monitor's StallTimeout, and AlertResumed when it's recorded again
+	AlertStalled AlertType = "Stalled"
+	AlertResumed AlertType = "Resumed"
+	// AlertStorageFailed and AlertStorageRecovered are raised for storage failure events
+	AlertStorageFailed    AlertType = "StorageFailed"
+	AlertStorageRecovered AlertType = "StorageRecovered"
+)
+
+// Alert is a change in a device's recording health
+type Alert struct {
+	Type AlertType
+	// StorageToken is the token of the storage, for AlertStorageFailed and AlertStorageRecovered
+	StorageToken string
+	// Time is the local time the alert was raised
+	Time time.Time
+}
+
+// Status is the recording health of a device
+type Status struct {
+	// Checked is the local time of the check
+	Checked time.Time
+	// DataFrom and DataUntil are the device times of the oldest and newest recorded data. They're zero if the device has no data
+	DataFrom         time.Time
+	DataUntil        time.Time
+	NumberRecordings int
+	// Storage is the device's storage configurations, or nil if the device doesn't support storage configuration
+	Storage []*device.StorageConfiguration
+	// FailedStorage is the tokens of the storage that has reported failure with a storage failure event, and not recovered since
+	FailedStorage []string
+	// Stalled is true if the device has recordings but no new data has been recorded for the monitor's StallTimeout
+	Stalled bool
+}
+
+// Retention returns the span of recorded data on the device, i.e. how far back recordings go
+func (s *Status) Retention() time.Duration {
+	if s.DataFrom.IsZero() || s.DataUntil.IsZero() {
+		return 0
+	}
+	return s.DataUntil.Sub(s.DataFrom)
+}
+
+// Monitor periodically checks a device's recording summary and storage configurations, and raises alerts when recording stalls
+// or storage failure events are received. Create one Monitor per device. Monitor is safe for concurrent use
+type Monitor struct {
+	Device *onvif.Device
+	// Interval is how often Run checks the device. If zero, DefaultCheckInterval is used
+	Interval time.Duration
+	// StallTimeout is how long the newest recorded data can stay the same before recording is considered stalled.
+	// It should be longer than Interval. If zero, DefaultStallTimeout is used
+	StallTimeout time.Duration
+	// OnAlert, if set, is called with each alert raised
+	OnAlert func(a *Alert)
+	// OnError, if set, is called with errors checking the device and Run continues. Otherwise Run returns the error
+	OnError func(err error)
+	// Clock is used for check times and intervals. If nil, onvif.SystemClock is used
+	Clock onvif.Clock
+
+	mu     sync.Mutex
+	status *Status
+	// advanced is the local time DataUntil last advanced
+	advanced time.Time
+	failed   map[string]bool
+}
+
+func (m *Monitor) clock() onvif.Clock {
+	if m.Clock != nil {
+		return m.Clock
+	}
+	return onvif.SystemClock
+}
+
+func (m *Monitor) alert(alerts []*Alert) {
+	if m.OnAlert == nil {
+		return
+	}
+	for _, a := range alerts {
+		m.OnAlert(a)
+	}
+}
+
+// Check checks the device and returns its status, raising an alert if recording has stalled or resumed since the last check
+func (m *Monitor) Check(ctx context.Context) (*Status, error) {
+	sc, err := search.FromDevice(m.Device)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := sc.GetRecordingSummaryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get recording summary: %w", err)
+	}
+
+	status := &Status{NumberRecordings: summary.NumberRecordings}
+	if summary.DataFrom != "" {
+		if status.DataFrom, err = events.ParseDateTime(summary.DataFrom); err != nil {
+			return nil, fmt.Errorf("could not parse DataFrom: %w", err)
+		}
+	}
+	if summary.DataUntil != "" {
+		if status.DataUntil, err = events.ParseDateTime(summary.DataUntil); err != nil {
+			return nil, fmt.Errorf("could not parse DataUntil: %w", err)
+		}
+	}
+
+	dc, err := device.FromDevice(m.Device)
+	if err != nil {
+		return nil, err
+	}
+	// devices without storage configuration support return a fault
+	var f *soap.Fault
+	if status.Storage, err = dc.GetStorageConfigurationsContext(ctx); err != nil && (!errors.As(err, &f) || errors.Is(err, soap.ErrNotAuthorized)) {
+		return nil, fmt.Errorf("could not get storage configurations: %w", err)
+	}
+
+	stallTimeout := m.StallTimeout
+	if stallTimeout <= 0 {
+		stallTimeout = DefaultStallTimeout
+	}
+
+	m.mu.Lock()
+	now := m.clock().Now()
+	status.Checked = now
+	wasStalled := m.status != nil && m.status.Stalled
+	if m.status == nil || status.DataUntil.After(m.status.DataUntil) {
+		m.advanced = now
+	}
+	status.Stalled = status.NumberRecordings > 0 && now.Sub(m.advanced) >= stallTimeout
+	m.status = status
+
+	var alerts []*Alert
+	if status.Stalled && !wasStalled {
+		alerts = append(alerts, &Alert{Type: AlertStalled, Time: now})
+	} else if !status.Stalled && wasStalled {
+		alerts = append(alerts, &Alert{Type: AlertResumed, Time: now})
+	}
+	status = m.statusLocked()
+	m.mu.Unlock()
+
+	m.alert(alerts)
+	return status, nil
+}
+
+// statusLocked returns a copy of m.status with FailedStorage set. m.mu must be held
+func (m *Monitor) statusLocked() *Status {
+	status := *m.status
+	status.FailedStorage = make([]string, 0, len(m.failed))
+	for token := range m.failed {
+		status.FailedStorage = append(status.FailedStorage, token)
+	}
+	sort.Strings(status.FailedStorage)
+	return &status
+}
+
+// Status returns the status from the last check, or nil if the device hasn't been checked
+func (m *Monitor) Status() *Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status == nil {
+		return nil
+	}
+	return m.statusLocked()
+}
+
+// Handle updates the failed storage from n, raising an alert if the storage failed or recovered.
+// It returns true if n is a storage failure event
+func (m *Monitor) Handle(n *events.Notification) bool {
+	if !n.Is(events.TopicStorageFailure) {
+		return false
+	}
+	token, _ := n.Source.Get(ItemToken)
+	failed := n.Data.Bool(ItemFailed)
+
+	m.mu.Lock()
+	var a *Alert
+	if failed && !m.failed[token] {
+		if m.failed == nil {
+			m.failed = make(map[string]bool)
+		}
+		m.failed[token] = true
+		a = &Alert{Type: AlertStorageFailed, StorageToken: token, Time: m.clock().Now()}
+	} else if !failed && m.failed[token] {
+		delete(m.failed, token)
+		a = &Alert{Type: AlertStorageRecovered, StorageToken: token, Time: m.clock().Now()}
+	}
+	m.mu.Unlock()
+
+	if a != nil {
+		m.alert([]*Alert{a})
+	}
+	return true
+}
+
+// Run checks the device every Interval and calls Handle for each notification from notifications, until ctx is done.
+// notifications is usually from an events.NotificationServer or events.Subscription.Pull subscribed to events.TopicStorageFailure, and may be nil.
+// ctx's error is returned if ctx is done first
+func (m *Monitor) Run(ctx context.Context, notifications <-chan *events.Notification) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+
+	for {
+		if _, err := m.Check(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if m.OnError == nil {
+				return fmt.Errorf("could not check device: %w", err)
+			}
+			m.OnError(err)
+		}
+
+		wait := m.clock().After(interval)
+	handle:
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wait:
+				break handle
+			case n, ok := <-notifications:
+				if !ok {
+					notifications = nil
+					continue
+				}
+				m.Handle(n)
+			}
+		}
+	}
+}
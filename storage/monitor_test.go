@@ -0,0 +1,127 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korylprince/go-onvif"
+	"github.com/korylprince/go-onvif/events"
+	"github.com/korylprince/go-onvif/storage"
+)
+
+const responseEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tse="http://www.onvif.org/ver10/search/wsdl"
+xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+// testClock is a Clock whose time is set by the test
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (c *testClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestMonitor(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		dataUntil = "2020-01-02T00:00:00Z"
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case bytes.Contains(buf, []byte("<tse:GetRecordingSummary>")):
+			fmt.Fprintf(w, responseEnvelope, `<tse:GetRecordingSummaryResponse><tse:Summary><tt:DataFrom>2020-01-01T00:00:00Z</tt:DataFrom>
+<tt:DataUntil>`+dataUntil+`</tt:DataUntil><tt:NumberRecordings>2</tt:NumberRecordings></tse:Summary></tse:GetRecordingSummaryResponse>`)
+		case bytes.Contains(buf, []byte("<tds:GetStorageConfigurations>")):
+			fmt.Fprintf(w, responseEnvelope, `<tds:GetStorageConfigurationsResponse><tds:StorageConfigurations token="SD_1">
+<tds:Data type="ONVIF://www.onvif.org/StorageType/Local"><tds:LocalPath>/mnt/sd</tds:LocalPath></tds:Data>
+</tds:StorageConfigurations></tds:GetStorageConfigurationsResponse>`)
+		default:
+			t.Errorf("unexpected request: %s", buf)
+		}
+	}))
+	defer srv.Close()
+
+	var alerts []storage.AlertType
+	clock := &testClock{now: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}
+	m := &storage.Monitor{
+		Device: &onvif.Device{Client: &onvif.Client{}, Services: onvif.Services{
+			{Namespace: onvif.NamespaceDevice, URL: srv.URL},
+			{Namespace: onvif.NamespaceSearch, URL: srv.URL},
+		}},
+		StallTimeout: 5 * time.Minute,
+		OnAlert:      func(a *storage.Alert) { alerts = append(alerts, a.Type) },
+		Clock:        clock,
+	}
+
+	status, err := m.Check(context.Background())
+	if err != nil {
+		t.Fatalf("could not check: %v", err)
+	}
+	if status.Retention() != 24*time.Hour || status.NumberRecordings != 2 || status.Stalled {
+		t.Errorf("unexpected status: %#v", status)
+	}
+	if len(status.Storage) != 1 || status.Storage[0].Token != "SD_1" || status.Storage[0].Data.LocalPath != "/mnt/sd" {
+		t.Errorf("unexpected storage: %v", status.Storage)
+	}
+
+	// no data is recorded for the stall timeout
+	clock.add(5 * time.Minute)
+	if status, err = m.Check(context.Background()); err != nil || !status.Stalled {
+		t.Errorf("expected stalled status, got %#v, %v", status, err)
+	}
+
+	mu.Lock()
+	dataUntil = "2020-01-02T00:05:00Z"
+	mu.Unlock()
+	clock.add(time.Minute)
+	if status, err = m.Check(context.Background()); err != nil || status.Stalled || status.Retention() != 24*time.Hour+5*time.Minute {
+		t.Errorf("expected recording status, got %#v, %v", status, err)
+	}
+
+	failure := func(failed string) *events.Notification {
+		return &events.Notification{Topic: "tns1:" + events.TopicStorageFailure, Source: events.Items{storage.ItemToken: "SD_1"}, Data: events.Items{storage.ItemFailed: failed}}
+	}
+	if m.Handle(&events.Notification{Topic: "tns1:" + events.TopicMotionAlarm}) {
+		t.Error("expected other topics to be ignored")
+	}
+	m.Handle(failure("false"))
+	m.Handle(failure("true"))
+	m.Handle(failure("true"))
+	if status = m.Status(); fmt.Sprint(status.FailedStorage) != "[SD_1]" {
+		t.Errorf("unexpected failed storage: %v", status.FailedStorage)
+	}
+	m.Handle(failure("false"))
+	if status = m.Status(); len(status.FailedStorage) != 0 {
+		t.Errorf("unexpected failed storage: %v", status.FailedStorage)
+	}
+
+	if fmt.Sprint(alerts) != "[Stalled Resumed StorageFailed StorageRecovered]" {
+		t.Errorf("unexpected alerts: %v", alerts)
+	}
+}
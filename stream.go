@@ -0,0 +1,79 @@
+package onvif
+
+import (
+	"context"
+	"io"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// Stream is a response whose body is decoded incrementally. See Client.DoStream.
+// The body contents are read with the embedded *soap.Decoder's Token, Decode, and DecodeElement methods
+type Stream struct {
+	*soap.Decoder
+
+	body    io.Closer
+	cancels []context.CancelFunc
+	// release unregisters the Stream from Client.Shutdown
+	release func()
+}
+
+// Close closes the response body and releases the request's resources. It must be called when finished with the Stream
+func (s *Stream) Close() error {
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+	s.cancels = nil
+	if s.release != nil {
+		s.release()
+		s.release = nil
+	}
+
+	if s.body == nil {
+		return nil
+	}
+	return s.body.Close()
+}
+
+type streamKey struct{}
+
+// DoStream is like DoContext, but the response body isn't buffered, so large responses (e.g. GetRecordingSearchResults or GetEventProperties)
+// can be processed incrementally without holding the whole response in memory. Faults are returned as from DoContext.
+// Timeouts (Request.Timeout, RetryPolicy.Timeout, and Client.OperationBudgets) keep applying while the body is read.
+// Requests aren't hedged. Middleware receives an envelope without body contents. The caller must close the returned Stream.
+// Client.Shutdown waits for open Streams to be closed
+func (c *Client) DoStream(ctx context.Context, r *Request) (*Stream, error) {
+	s := new(Stream)
+	if c.acquire() {
+		s.release = c.release
+	}
+	if _, err := c.DoContext(context.WithValue(ctx, streamKey{}, s), r); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// stream returns the Stream of a DoStream request context, or nil
+func stream(ctx context.Context) *Stream {
+	s, _ := ctx.Value(streamKey{}).(*Stream)
+	return s
+}
+
+// withoutStream returns ctx without its Stream, so nested requests made during a DoStream request (e.g. GetSystemDateAndTime for time syncing)
+// are decoded and closed normally
+func withoutStream(ctx context.Context) context.Context {
+	if stream(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, streamKey{}, (*Stream)(nil))
+}
+
+// cancelLater calls cancel when the Stream of ctx is closed if the request succeeded, or immediately otherwise
+func cancelLater(ctx context.Context, cancel context.CancelFunc) {
+	if s := stream(ctx); s != nil && s.body != nil {
+		s.cancels = append(s.cancels, cancel)
+		return
+	}
+	cancel()
+}
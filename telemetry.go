@@ -0,0 +1,93 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// TelemetryRequest describes a call to Client.DoContext. See Telemetry
+type TelemetryRequest struct {
+	// CorrelationID is the request's correlation ID. See Request.CorrelationID
+	CorrelationID string
+	URL           string
+	// Operation is the local name of the request body element, e.g. GetProfiles.
+	// Action is the SOAP action of the request. They're empty if they couldn't be determined
+	Operation string
+	Action    string
+
+	// The remaining fields are set when the call completes
+
+	// Requests is the number of HTTP requests made, including retries, hedged requests, and authentication mode detection
+	Requests int
+	// StatusCode is the HTTP status code of the last response, or 0 if no response was received
+	StatusCode int
+	// FaultCode is the most specific code of the returned *soap.Fault, e.g. ter:NoProfile, if any
+	FaultCode string
+	Duration  time.Duration
+	Err       error
+}
+
+// Telemetry receives the start and end of each call to Client.DoContext, e.g. to create trace spans and record request metrics.
+// It is an extension point for instrumentation libraries like OpenTelemetry, so the package doesn't depend on them.
+// Methods may be called concurrently
+type Telemetry interface {
+	// StartRequest is called when a call starts. The returned context is used for the request, e.g. to carry a span to HTTP transport instrumentation
+	StartRequest(ctx context.Context, r *TelemetryRequest) context.Context
+	// EndRequest is called with the context returned by StartRequest when the call completes
+	EndRequest(ctx context.Context, r *TelemetryRequest)
+}
+
+type telemetryKey struct{}
+
+// telemetryState collects the HTTP responses of a call
+type telemetryState struct {
+	mu         sync.Mutex
+	requests   int
+	statusCode int
+}
+
+// recordResponse records an HTTP request to the call in ctx, if it's instrumented. statusCode is 0 if no response was received
+func recordResponse(ctx context.Context, statusCode int) {
+	s, ok := ctx.Value(telemetryKey{}).(*telemetryState)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.statusCode = statusCode
+}
+
+// instrument calls do, reporting the call to Client.Telemetry
+func (c *Client) instrument(ctx context.Context, r *Request, id string, do func(context.Context) (*soap.Envelope, error)) (*soap.Envelope, error) {
+	t := &TelemetryRequest{CorrelationID: id, URL: r.URL}
+	if op, err := requestOperation(r); err == nil {
+		t.Operation, t.Action = op.name, op.action
+	}
+
+	state := new(telemetryState)
+	ctx = c.Telemetry.StartRequest(ctx, t)
+	start := c.clock().Now()
+
+	env, err := do(context.WithValue(ctx, telemetryKey{}, state))
+
+	t.Duration = c.clock().Now().Sub(start)
+	state.mu.Lock()
+	t.Requests, t.StatusCode = state.requests, state.statusCode
+	state.mu.Unlock()
+	var f *soap.Fault
+	if errors.As(err, &f) {
+		t.FaultCode = f.Code
+		if codes := f.SubCodes(); len(codes) > 0 {
+			t.FaultCode = codes[len(codes)-1]
+		}
+	}
+	t.Err = err
+	c.Telemetry.EndRequest(ctx, t)
+
+	return env, err
+}
@@ -0,0 +1,123 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+type getSystemDateAndTime struct {
+	XMLName xml.Name `xml:"tds:GetSystemDateAndTime"`
+}
+
+type getSystemDateAndTimeResponse struct {
+	Hour   int `xml:"SystemDateAndTime>UTCDateTime>Time>Hour"`
+	Minute int `xml:"SystemDateAndTime>UTCDateTime>Time>Minute"`
+	Second int `xml:"SystemDateAndTime>UTCDateTime>Time>Second"`
+	Year   int `xml:"SystemDateAndTime>UTCDateTime>Date>Year"`
+	Month  int `xml:"SystemDateAndTime>UTCDateTime>Date>Month"`
+	Day    int `xml:"SystemDateAndTime>UTCDateTime>Date>Day"`
+}
+
+type timeRetryKey struct{}
+
+// offsetClock adds offset to the time returned by Clock
+type offsetClock struct {
+	Clock
+	offset time.Duration
+}
+
+func (c offsetClock) Now() time.Time {
+	return c.Clock.Now().Add(c.offset)
+}
+
+// securityClock returns the clock used for WS-Security timestamps, adjusted by the Client's time offset
+func (c *Client) securityClock() Clock {
+	c.timeMu.Lock()
+	offset := c.TimeOffset
+	c.timeMu.Unlock()
+
+	if offset == 0 {
+		return c.clock()
+	}
+	return offsetClock{Clock: c.clock(), offset: offset}
+}
+
+// SyncTime sets Client.TimeOffset to the difference between the device's clock and the local clock, using an unauthenticated GetSystemDateAndTime request.
+// addr is the host:port pair of the device, or a full URL. See GetServices
+func (c *Client) SyncTime(addr string) error {
+	return c.SyncTimeContext(context.Background(), addr)
+}
+
+// SyncTimeContext is like SyncTime, but ctx controls the request
+func (c *Client) SyncTimeContext(ctx context.Context, addr string) error {
+	return c.syncTime(ctx, c.deviceServiceURL(addr))
+}
+
+// syncTime sets Client.TimeOffset using the device service at deviceURL
+func (c *Client) syncTime(ctx context.Context, deviceURL string) error {
+	start := c.clock().Now()
+	env, err := c.DoContext(withoutStream(ctx), &Request{
+		URL:        deviceURL,
+		Namespaces: soap.Namespaces{"tds": NamespaceDevice},
+		Body:       &getSystemDateAndTime{},
+		noAuth:     true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not get system date and time: %w", err)
+	}
+	end := c.clock().Now()
+
+	resp := new(getSystemDateAndTimeResponse)
+	if err = env.Body.Unmarshal(resp); err != nil {
+		return fmt.Errorf("could not unmarshal response: %w", err)
+	}
+	if resp.Year == 0 {
+		return fmt.Errorf("could not get system date and time: %w", soap.ErrNoResponse)
+	}
+
+	device := time.Date(resp.Year, time.Month(resp.Month), resp.Day, resp.Hour, resp.Minute, resp.Second, 0, time.UTC)
+	// compare to the midpoint of the request
+	local := start.Add(end.Sub(start) / 2)
+
+	c.timeMu.Lock()
+	c.TimeOffset = device.Sub(local).Truncate(time.Second)
+	c.timeSynced = true
+	c.timeMu.Unlock()
+
+	return nil
+}
+
+// autoSyncTime syncs the time once, using the device service on the same host as requestURL, if AutoTimeSync is enabled
+func (c *Client) autoSyncTime(ctx context.Context, requestURL string) {
+	if !c.AutoTimeSync {
+		return
+	}
+
+	c.timeMu.Lock()
+	synced := c.timeSynced
+	// only try once, even if syncing fails, so devices without support aren't queried for every request
+	c.timeSynced = true
+	c.timeMu.Unlock()
+
+	if synced {
+		return
+	}
+
+	if u, err := deviceURL(requestURL); err == nil {
+		c.syncTime(ctx, u)
+	}
+}
+
+// deviceURL returns the standard device service URL on the same host as requestURL
+func deviceURL(requestURL string) (string, error) {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/onvif/device_service"}).String(), nil
+}
@@ -0,0 +1,89 @@
+package onvif
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings collects connection-level timings for a request.
+// Use Timings.ClientTrace to create a trace for Request.Trace.
+// If a request is sent more than once (e.g. during authentication mode detection), the timings of the last attempt are kept
+type Timings struct {
+	mu sync.Mutex
+	// ConnReused is true if an idle connection was reused
+	ConnReused bool
+	// DNS is the time spent resolving the host
+	DNS time.Duration
+	// Connect is the time spent establishing the TCP connection
+	Connect time.Duration
+	// TLSHandshake is the time spent on the TLS handshake
+	TLSHandshake time.Duration
+	// FirstByte is the time from writing the full request to receiving the first response byte.
+	// This is roughly the time the device spent processing the SOAP request
+	FirstByte time.Duration
+	// Total is the time from getting a connection to receiving the first response byte
+	Total time.Duration
+
+	start, dnsStart, connectStart, tlsStart, wrote time.Time
+}
+
+// ClientTrace returns an *httptrace.ClientTrace that records timings to t
+func (t *Timings) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.start = time.Now()
+			t.ConnReused = false
+			t.DNS, t.Connect, t.TLSHandshake, t.FirstByte, t.Total = 0, 0, 0, 0, 0
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.ConnReused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.DNS = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.Connect = time.Since(t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.TLSHandshake = time.Since(t.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wrote = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.FirstByte = time.Since(t.wrote)
+			t.Total = time.Since(t.start)
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package onvif
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/korylprince/go-onvif/soap"
+)
+
+// progressReader calls progress after each read
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
+
+// Upload POSTs the content of r to uri (e.g. a firmware upload URI) with the given content type.
+// The Client's HTTPClient and credentials are used. If the device requests HTTP digest or basic authentication, the request is retried with it,
+// so r is read again from the start. If progress is not nil, it's called as the content is sent, and again from zero if the request is retried.
+// If the Client's AuthMode is AuthModeDigest, digest authentication is used from the start
+func (c *Client) Upload(uri, contentType string, r io.ReadSeeker, progress func(sent, total int64)) error {
+	return c.UploadContext(context.Background(), uri, contentType, r, progress)
+}
+
+// UploadContext is like Upload, but ctx controls the request
+func (c *Client) UploadContext(ctx context.Context, uri, contentType string, r io.ReadSeeker, progress func(sent, total int64)) error {
+	if !c.acquire() {
+		return ErrClientClosed
+	}
+	defer c.release()
+
+	total, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("could not get content size: %w", err)
+	}
+
+	getBody := func() (io.ReadCloser, error) {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("could not seek content: %w", err)
+		}
+		body := io.LimitReader(r, total)
+		if progress != nil {
+			body = &progressReader{r: body, total: total, progress: progress}
+		}
+		return io.NopCloser(body), nil
+	}
+
+	body, err := getBody()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, body)
+	if err != nil {
+		return fmt.Errorf("could not create http request: %w", err)
+	}
+	req.ContentLength = total
+	req.GetBody = getBody
+	req.Header.Set("Content-Type", contentType)
+	// let the device reject the request before the content is sent, e.g. to request authentication
+	req.Header.Set("Expect", "100-continue")
+
+	client, req, cred, err := c.transferClient(req)
+	if err != nil {
+		return err
+	}
+	defer cred.release()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not POST uri: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		cred, err := c.clientCredentials()
+		if err != nil {
+			resp.Body.Close()
+			return err
+		}
+		defer cred.release()
+		if cred != nil {
+			resp.Body.Close()
+			if req.Body, err = getBody(); err != nil {
+				return err
+			}
+			resp, err = c.downloadAuth(req, cred, resp.Header.Values("WWW-Authenticate"))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return &soap.UnauthorizedError{Err: errors.New(resp.Status)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return nil
+}